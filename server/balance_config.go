@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"gopkg.in/yaml.v3"
+)
+
+// BalanceConfig is the game balance shared by every room. Each value starts out as its default from
+// the constants package, so a balance file only needs to list what it changes.
+type BalanceConfig struct {
+	Difficulty          string                     `json:"difficulty"`          // Difficulty new games start at
+	Settings            GameSettings               `json:"settings"`            // Settings new games start with
+	GuideHighlightSizes []float64                  `json:"guideHighlightSizes"` // Grid coverage per guide threshold level
+	GridSizeBreakpoints []constants.GridBreakpoint `json:"gridSizeBreakpoints"` // Puzzle grid size by player count
+	DifficultyModifiers DifficultyModifierSet      `json:"difficultyModifiers"`
+	Presets             map[string]GamePreset      `json:"presets"`    // Named settings the host can pick in the lobby
+	RateLimits          RateLimitConfig            `json:"rateLimits"` // Flood protection for every connection
+}
+
+// DifficultyModifierSet holds the modifiers for each difficulty level
+type DifficultyModifierSet struct {
+	Easy   constants.DifficultyModifiers `json:"easy"`
+	Medium constants.DifficultyModifiers `json:"medium"`
+	Hard   constants.DifficultyModifiers `json:"hard"`
+}
+
+// GamePreset is a named settings change the host can apply in one step. It is applied on top of
+// the default settings, so fields it leaves out keep their default.
+type GamePreset struct {
+	Description string             `json:"description"`
+	Settings    GameSettingsUpdate `json:"settings"`
+}
+
+// PresetInfo describes a preset to the host
+type PresetInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// BalanceConfigError lists every problem found in a balance file
+type BalanceConfigError struct {
+	Problems []ValidationError
+}
+
+func (e *BalanceConfigError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		lines[i] = fmt.Sprintf("%s: %s", problem.Field, problem.Message)
+	}
+	return "invalid balance config: " + strings.Join(lines, "; ")
+}
+
+// DefaultBalanceConfig returns the built-in balance taken from the constants package
+func DefaultBalanceConfig() *BalanceConfig {
+	return &BalanceConfig{
+		Difficulty: "medium",
+		Settings:   DefaultGameSettings(),
+		// Copied so decoding a balance file over the defaults never writes into the constants
+		GuideHighlightSizes: append([]float64(nil), constants.GuideHighlightSizes...),
+		GridSizeBreakpoints: append([]constants.GridBreakpoint(nil), constants.GridSizeBreakpoints...),
+		DifficultyModifiers: DifficultyModifierSet{
+			Easy:   constants.EasyMode,
+			Medium: constants.MediumMode,
+			Hard:   constants.HardMode,
+		},
+		Presets:    defaultPresets(),
+		RateLimits: DefaultRateLimitConfig(),
+	}
+}
+
+// defaultPresets are offered to every host; a balance file may replace them or add its own
+func defaultPresets() map[string]GamePreset {
+	intPtr := func(v int) *int { return &v }
+	stringPtr := func(v string) *string { return &v }
+
+	return map[string]GamePreset{
+		"classroom": {
+			Description: "Easier questions and longer rounds that fit in a class period",
+			Settings: GameSettingsUpdate{
+				Difficulty:     stringPtr("easy"),
+				ResourceRounds: intPtr(4),
+				RoundDuration:  intPtr(90),
+				PuzzleBaseTime: intPtr(600),
+			},
+		},
+		"corporate-90": {
+			Description: "A 90 minute team building session with ten long rounds and a 30 minute puzzle",
+			Settings: GameSettingsUpdate{
+				ResourceRounds:   intPtr(10),
+				RoundDuration:    intPtr(180),
+				PuzzleBaseTime:   intPtr(1800),
+				AnchorThreshold:  intPtr(2 * constants.AnchorTokenThresholds),
+				ChronosThreshold: intPtr(2 * constants.ChronosTokenThresholds),
+				GuideThreshold:   intPtr(2 * constants.GuideTokenThresholds),
+				ClarityThreshold: intPtr(2 * constants.ClarityTokenThresholds),
+			},
+		},
+		"speed-round": {
+			Description: "Three quick rounds and a short puzzle, about ten minutes in all",
+			Settings: GameSettingsUpdate{
+				ResourceRounds:   intPtr(3),
+				RoundDuration:    intPtr(30),
+				PuzzleBaseTime:   intPtr(180),
+				AnchorThreshold:  intPtr(constants.AnchorTokenThresholds / 2),
+				ChronosThreshold: intPtr(constants.ChronosTokenThresholds / 2),
+				GuideThreshold:   intPtr(constants.GuideTokenThresholds / 2),
+				ClarityThreshold: intPtr(constants.ClarityTokenThresholds / 2),
+			},
+		},
+	}
+}
+
+// LoadBalanceConfig reads a YAML (.yaml, .yml) or JSON (.json) balance file over the defaults
+func LoadBalanceConfig(path string) (*BalanceConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read balance config: %v", err)
+	}
+
+	config, err := ParseBalanceConfig(data, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return config, nil
+}
+
+// ParseBalanceConfig decodes a balance file in the format named by its extension and validates it.
+// Unknown fields are rejected so a misspelt setting can't silently fall back to its default.
+func ParseBalanceConfig(data []byte, ext string) (*BalanceConfig, error) {
+	switch strings.ToLower(ext) {
+	case ".json":
+	case ".yaml", ".yml":
+		// YAML is converted to JSON so both formats share one set of field names and one decoder
+		var document interface{}
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %v", err)
+		}
+		converted, err := json.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML: keys must be strings: %v", err)
+		}
+		data = converted
+	default:
+		return nil, fmt.Errorf("unsupported balance config format %q (use .yaml, .yml or .json)", ext)
+	}
+
+	config := DefaultBalanceConfig()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("invalid balance config: %v", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate checks every value and returns a *BalanceConfigError listing all problems found
+func (bc *BalanceConfig) Validate() error {
+	var problems []ValidationError
+	add := func(field, format string, args ...interface{}) {
+		problems = append(problems, ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	addAll := func(prefix string, errs []ValidationError) {
+		for _, err := range errs {
+			problems = append(problems, ValidationError{Field: prefix + err.Field, Message: err.Message})
+		}
+	}
+
+	// Default settings share the host's limits
+	if !validDifficulties[bc.Difficulty] {
+		add("difficulty", "must be easy, medium or hard")
+	}
+	addAll("settings.", checkPayload(settingsAsUpdate(bc.Settings)))
+	if bc.Settings.gridTooSmall() {
+		add("settings.gridSize", constants.ErrGridTooSmall)
+	}
+
+	// Guide highlights narrow from a wide area to a precise one as thresholds are reached
+	if len(bc.GuideHighlightSizes) == 0 {
+		add("guideHighlightSizes", "at least one level is required")
+	}
+	for i, size := range bc.GuideHighlightSizes {
+		field := fmt.Sprintf("guideHighlightSizes[%d]", i)
+		if size <= 0 || size > 1 {
+			add(field, "must be a fraction of the grid above 0 and at most 1")
+		} else if i > 0 && size > bc.GuideHighlightSizes[i-1] {
+			add(field, "must not be larger than the level before it")
+		}
+	}
+
+	// Breakpoints must cover every player count without gaps, each with a fragment per player
+	if len(bc.GridSizeBreakpoints) == 0 {
+		add("gridSizeBreakpoints", "at least one breakpoint is required")
+	}
+	nextMin := 1
+	for i, breakpoint := range bc.GridSizeBreakpoints {
+		field := fmt.Sprintf("gridSizeBreakpoints[%d]", i)
+		if breakpoint.MinPlayers != nextMin {
+			add(field+".minPlayers", "must be %d to continue from the previous breakpoint", nextMin)
+		}
+		if breakpoint.MaxPlayers < breakpoint.MinPlayers {
+			add(field+".maxPlayers", "must not be less than minPlayers")
+		}
+		if breakpoint.GridSize < constants.MinGridSize || breakpoint.GridSize > constants.MaxGridSize {
+			add(field+".gridSize", "must be between %d and %d", constants.MinGridSize, constants.MaxGridSize)
+		} else if breakpoint.GridSize*breakpoint.GridSize < breakpoint.MaxPlayers {
+			add(field+".gridSize", "a %dx%d grid has no fragment for each of %d players",
+				breakpoint.GridSize, breakpoint.GridSize, breakpoint.MaxPlayers)
+		}
+		if breakpoint.TotalFragments != breakpoint.GridSize*breakpoint.GridSize {
+			add(field+".totalFragments", "must be gridSize squared (%d)", breakpoint.GridSize*breakpoint.GridSize)
+		}
+		nextMin = breakpoint.MaxPlayers + 1
+	}
+	if len(bc.GridSizeBreakpoints) > 0 && nextMin <= constants.MaxPlayers {
+		add("gridSizeBreakpoints", "must cover up to %d players", constants.MaxPlayers)
+	}
+
+	// Modifiers scale times and thresholds, so they must stay positive
+	levels := []struct {
+		name      string
+		modifiers constants.DifficultyModifiers
+	}{
+		{"easy", bc.DifficultyModifiers.Easy},
+		{"medium", bc.DifficultyModifiers.Medium},
+		{"hard", bc.DifficultyModifiers.Hard},
+	}
+	for _, level := range levels {
+		field := "difficultyModifiers." + level.name
+		if level.modifiers.TriviaModifier <= 0 {
+			add(field+".triviaModifier", "must be greater than 0")
+		}
+		if level.modifiers.TimeLimitModifier <= 0 {
+			add(field+".timeLimitModifier", "must be greater than 0")
+		}
+		if level.modifiers.TokenThresholdModifier <= 0 {
+			add(field+".tokenThresholdModifier", "must be greater than 0")
+		}
+	}
+
+	// Presets are checked as the host would see them: applied on top of the defaults
+	for _, name := range bc.presetNames() {
+		preset := bc.Presets[name]
+		field := "presets." + name
+		if !presetNameRegex.MatchString(name) {
+			add(field, "name must be lowercase letters, digits, '-' or '_' (at most 40)")
+		}
+		if preset.Settings.Preset != nil {
+			add(field+".settings.preset", "a preset cannot apply another preset")
+		}
+		addAll(field+".settings.", checkPayload(preset.Settings))
+		// A grid the defaults already make too small is reported once, under settings
+		if settings, _ := applySettingsUpdate(bc.Settings, bc.Difficulty, preset.Settings); settings.gridTooSmall() && !bc.Settings.gridTooSmall() {
+			add(field+".settings.gridSize", constants.ErrGridTooSmall)
+		}
+	}
+
+	bc.RateLimits.validate(add)
+
+	if len(problems) > 0 {
+		return &BalanceConfigError{Problems: problems}
+	}
+	return nil
+}
+
+// ForDifficulty returns the modifiers for a difficulty level; unknown levels play as medium
+func (set DifficultyModifierSet) ForDifficulty(difficulty string) constants.DifficultyModifiers {
+	switch difficulty {
+	case "easy":
+		return set.Easy
+	case "hard":
+		return set.Hard
+	default:
+		return set.Medium
+	}
+}
+
+// PresetList returns the presets sorted by name
+func (bc *BalanceConfig) PresetList() []PresetInfo {
+	names := bc.presetNames()
+	presets := make([]PresetInfo, len(names))
+	for i, name := range names {
+		presets[i] = PresetInfo{Name: name, Description: bc.Presets[name].Description}
+	}
+	return presets
+}
+
+func (bc *BalanceConfig) presetNames() []string {
+	names := make([]string, 0, len(bc.Presets))
+	for name := range bc.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// settingsAsUpdate expresses complete settings as an update so they can share its validation
+func settingsAsUpdate(settings GameSettings) GameSettingsUpdate {
+	return GameSettingsUpdate{
+		ResourceRounds:   &settings.ResourceRounds,
+		RoundDuration:    &settings.RoundDuration,
+		PuzzleBaseTime:   &settings.PuzzleBaseTime,
+		AnchorThreshold:  &settings.AnchorThreshold,
+		ChronosThreshold: &settings.ChronosThreshold,
+		GuideThreshold:   &settings.GuideThreshold,
+		ClarityThreshold: &settings.ClarityThreshold,
+		GridSize:         &settings.GridSize,
+		MinPlayers:       &settings.MinPlayers,
+		ReconnectGrace:   &settings.ReconnectGrace,
+	}
+}
+
+// UseBalance replaces the built-in balance. New games start from its default settings, so it
+// should be called before the lobby opens.
+func (gm *GameManager) UseBalance(balance *BalanceConfig) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	gm.balance = balance
+	if gm.state.Phase == PhaseSetup {
+		gm.state.Settings = balance.Settings
+		gm.state.Difficulty = balance.Difficulty
+	}
+}
+
+// UseBalance replaces the built-in difficulty modifiers used to pick question difficulty
+func (tm *TriviaManager) UseBalance(balance *BalanceConfig) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.balance = balance
+}
+
+// UseBalance makes every room created from now on play with balance. The per-address connection
+// limit applies to every room at once.
+func (rm *RoomManager) UseBalance(balance *BalanceConfig) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.balance = balance
+	rm.connections.SetLimit(balance.RateLimits.ConnectionsPerIP)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultBalanceConfig(t *testing.T) {
+	balance := DefaultBalanceConfig()
+	assert.NoError(t, balance.Validate())
+
+	assert.Equal(t, "medium", balance.Difficulty)
+	assert.Equal(t, DefaultGameSettings(), balance.Settings)
+	assert.Equal(t, constants.GuideHighlightSizes, balance.GuideHighlightSizes)
+	assert.Equal(t, constants.GridSizeBreakpoints, balance.GridSizeBreakpoints)
+	assert.Equal(t, constants.HardMode, balance.DifficultyModifiers.ForDifficulty("hard"))
+	assert.Equal(t, constants.MediumMode, balance.DifficultyModifiers.ForDifficulty("unknown"))
+
+	names := make([]string, 0)
+	for _, preset := range balance.PresetList() {
+		names = append(names, preset.Name)
+		assert.NotEmpty(t, preset.Description)
+	}
+	assert.Equal(t, []string{"classroom", "corporate-90", "speed-round"}, names)
+
+	// Each config owns its lists
+	balance.GuideHighlightSizes[0] = 0.5
+	assert.Equal(t, 0.25, constants.GuideHighlightSizes[0])
+}
+
+func TestParseBalanceConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     string
+		data    string
+		wantErr string
+		check   func(t *testing.T, balance *BalanceConfig)
+	}{
+		{
+			name: "YAML overrides only what it lists",
+			ext:  ".yaml",
+			data: `
+settings:
+  roundDuration: 45
+difficultyModifiers:
+  easy:
+    timeLimitModifier: 1.5
+guideHighlightSizes: [0.3, 0.1]
+presets:
+  workshop:
+    description: Half day workshop
+    settings:
+      resourceRounds: 8
+`,
+			check: func(t *testing.T, balance *BalanceConfig) {
+				assert.Equal(t, 45, balance.Settings.RoundDuration)
+				assert.Equal(t, constants.ResourceGatheringRounds, balance.Settings.ResourceRounds)
+				assert.Equal(t, 1.5, balance.DifficultyModifiers.Easy.TimeLimitModifier)
+				assert.Equal(t, constants.EasyMode.TokenThresholdModifier, balance.DifficultyModifiers.Easy.TokenThresholdModifier)
+				assert.Equal(t, []float64{0.3, 0.1}, balance.GuideHighlightSizes)
+				assert.Len(t, balance.Presets, 4)
+				assert.Equal(t, 8, *balance.Presets["workshop"].Settings.ResourceRounds)
+			},
+		},
+		{
+			name: "JSON",
+			ext:  ".json",
+			data: `{"difficulty": "hard", "gridSizeBreakpoints": [{"minPlayers": 1, "maxPlayers": 64, "gridSize": 8, "totalFragments": 64}]}`,
+			check: func(t *testing.T, balance *BalanceConfig) {
+				assert.Equal(t, "hard", balance.Difficulty)
+				assert.Len(t, balance.GridSizeBreakpoints, 1)
+			},
+		},
+		{
+			name: "Empty YAML keeps the defaults",
+			ext:  ".yml",
+			data: "",
+			check: func(t *testing.T, balance *BalanceConfig) {
+				assert.Equal(t, DefaultBalanceConfig(), balance)
+			},
+		},
+		{
+			name:    "Misspelt field",
+			ext:     ".yaml",
+			data:    "settings:\n  roundDurations: 45\n",
+			wantErr: `unknown field "roundDurations"`,
+		},
+		{
+			name:    "Wrong type",
+			ext:     ".json",
+			data:    `{"settings": {"resourceRounds": "five"}}`,
+			wantErr: "settings.resourceRounds",
+		},
+		{
+			name:    "Malformed YAML",
+			ext:     ".yaml",
+			data:    "settings: [",
+			wantErr: "invalid YAML",
+		},
+		{
+			name:    "Unsupported format",
+			ext:     ".toml",
+			data:    "difficulty = 'hard'",
+			wantErr: "unsupported balance config format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			balance, err := ParseBalanceConfig([]byte(tt.data), tt.ext)
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+				return
+			}
+			if assert.NoError(t, err) {
+				tt.check(t, balance)
+			}
+		})
+	}
+}
+
+func TestBalanceConfigValidation(t *testing.T) {
+	data := `
+difficulty: extreme
+settings:
+  roundDuration: 5
+  gridSize: 3
+  minPlayers: 12
+guideHighlightSizes: [0.1, 0.2, 0]
+gridSizeBreakpoints:
+  - { minPlayers: 1, maxPlayers: 9, gridSize: 3, totalFragments: 9 }
+  - { minPlayers: 11, maxPlayers: 30, gridSize: 5, totalFragments: 25 }
+difficultyModifiers:
+  hard:
+    tokenThresholdModifier: 0
+presets:
+  Speed Round:
+    description: Bad name
+    settings:
+      resourceRounds: 11
+  nested:
+    settings:
+      preset: classroom
+rateLimits:
+  messageTypes:
+    player_join: { perSecond: 1, burst: 1 }
+    fragment_move_request: { perSecond: 2 }
+  abuseLimit: 0
+`
+	_, err := ParseBalanceConfig([]byte(data), ".yaml")
+
+	var configErr *BalanceConfigError
+	if !assert.True(t, errors.As(err, &configErr)) {
+		return
+	}
+
+	fields := make([]string, len(configErr.Problems))
+	for i, problem := range configErr.Problems {
+		fields[i] = problem.Field
+	}
+	assert.Equal(t, []string{
+		"difficulty",
+		"settings.roundDuration",
+		"settings.gridSize",
+		"guideHighlightSizes[1]",
+		"guideHighlightSizes[2]",
+		"gridSizeBreakpoints[1].minPlayers",
+		"gridSizeBreakpoints[1].gridSize",
+		"gridSizeBreakpoints",
+		"difficultyModifiers.hard.tokenThresholdModifier",
+		"presets.Speed Round",
+		"presets.Speed Round.settings.resourceRounds",
+		"presets.nested.settings.preset",
+		"rateLimits.messageTypes.fragment_move_request.burst",
+		"rateLimits.messageTypes.player_join",
+		"rateLimits.abuseLimit",
+	}, fields)
+
+	assert.Contains(t, err.Error(), "settings.roundDuration: must be between 15 and 300")
+	assert.Contains(t, err.Error(), "gridSizeBreakpoints: must cover up to 64 players")
+}
+
+func TestLoadBalanceConfig(t *testing.T) {
+	// The example shipped with the server must always load
+	balance, err := LoadBalanceConfig("balance.example.yaml")
+	if assert.NoError(t, err) {
+		assert.Contains(t, balance.Presets, "small-group")
+		assert.Equal(t, DefaultBalanceConfig().Presets["speed-round"], balance.Presets["speed-round"])
+	}
+
+	path := filepath.Join(t.TempDir(), "balance.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"settings": {"puzzleBaseTime": 10}}`), 0o644))
+	_, err = LoadBalanceConfig(path)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), path)
+		assert.Contains(t, err.Error(), "settings.puzzleBaseTime")
+	}
+
+	_, err = LoadBalanceConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestBalanceShapesTheGame(t *testing.T) {
+	gm, _, tm, _ := createTestGameManager()
+	defer cleanupTestGameManager(tm)
+
+	balance, err := ParseBalanceConfig([]byte(`
+difficulty: hard
+settings:
+  resourceRounds: 2
+gridSizeBreakpoints:
+  - { minPlayers: 1, maxPlayers: 16, gridSize: 4, totalFragments: 16 }
+  - { minPlayers: 17, maxPlayers: 64, gridSize: 8, totalFragments: 64 }
+difficultyModifiers:
+  hard:
+    tokenThresholdModifier: 2
+`), ".yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	gm.UseBalance(balance)
+	assert.Equal(t, 2, gm.GetSettings().ResourceRounds)
+	assert.Equal(t, "hard", gm.state.Difficulty)
+	assert.Equal(t, 4, gm.calculateGridSize(5))
+	assert.Equal(t, 8, gm.calculateGridSize(17))
+	assert.Equal(t, 5, gm.thresholdsReached(50, 5)) // 50 / (5 * 2)
+
+	// A new game starts from the balance defaults, not the constants
+	rounds := 7
+	_, err = gm.UpdateSettings(GameSettingsUpdate{ResourceRounds: &rounds})
+	assert.NoError(t, err)
+	gm.resetGame()
+	assert.Equal(t, 2, gm.GetSettings().ResourceRounds)
+	assert.Equal(t, "hard", gm.state.Difficulty)
+}
@@ -18,6 +18,7 @@ function App() {
   const [gameState, setGameState] = useState({
     availableRoles: [],
     triviaCategories: [],
+    triviaCategoryDetails: [],
     teamTokens: {
       anchorTokens: 0,
       chronosTokens: 0,
@@ -58,7 +59,8 @@ function App() {
           setGameState(prev => ({
             ...prev,
             availableRoles: payload.roles,
-            triviaCategories: payload.triviaCategories
+            triviaCategories: payload.triviaCategories,
+            triviaCategoryDetails: payload.triviaCategoryDetails || []
           }));
         }
         break;
@@ -172,6 +174,7 @@ function App() {
         setGameState({
           availableRoles: [],
           triviaCategories: [],
+          triviaCategoryDetails: [],
           teamTokens: {
             anchorTokens: 0,
             chronosTokens: 0,
@@ -282,6 +285,7 @@ function App() {
             key="setup"
             availableRoles={gameState.availableRoles}
             triviaCategories={gameState.triviaCategories}
+            triviaCategoryDetails={gameState.triviaCategoryDetails}
             onRoleSelect={handleRoleSelection}
             onSpecialtySelect={handleSpecialtySelection}
             playerRole={gameState.playerRole}
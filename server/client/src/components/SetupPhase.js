@@ -6,6 +6,7 @@ import './SetupPhase.css';
 const SetupPhase = ({ 
   availableRoles, 
   triviaCategories, 
+  triviaCategoryDetails = [],
   onRoleSelect, 
   onSpecialtySelect,
   playerRole,
@@ -17,6 +18,21 @@ const SetupPhase = ({
   const [selectedSpecialties, setSelectedSpecialties] = useState(playerSpecialties || []);
   const [isWaiting, setIsWaiting] = useState(playerSpecialties.length > 0);
 
+  const categoryIcons = {
+    general: '🌍',
+    geography: '🗺️',
+    history: '📚',
+    music: '🎵',
+    science: '🔬',
+    video_games: '🎮'
+  };
+
+  // Categories come from the server's question bank, so unknown ones get a name from their ID
+  const categoryName = (category) => {
+    const details = triviaCategoryDetails.find(d => d.id === category);
+    return details ? details.name : category.replace(/_/g, ' ');
+  };
+
   const roleInfo = {
     [RoleType.ART_ENTHUSIAST]: {
       title: 'Art Enthusiast',
@@ -154,14 +170,9 @@ const SetupPhase = ({
                       style={{ '--animation-delay': `${index * 0.05}s` }}
                     >
                       <div className="specialty-icon">
-                        {category === 'general' && '🌍'}
-                        {category === 'geography' && '🗺️'}
-                        {category === 'history' && '📚'}
-                        {category === 'music' && '🎵'}
-                        {category === 'science' && '🔬'}
-                        {category === 'video_games' && '🎮'}
+                        {categoryIcons[category] || '💡'}
                       </div>
-                      <span>{category.replace('_', ' ')}</span>
+                      <span>{categoryName(category)}</span>
                       {selectedSpecialties.includes(category) && (
                         <div className="specialty-check">✓</div>
                       )}
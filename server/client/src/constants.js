@@ -39,7 +39,8 @@ export const MessageType = {
   PIECE_RECOMMENDATION_REQUEST: 'piece_recommendation_request',
   PIECE_RECOMMENDATION_RESPONSE: 'piece_recommendation_response',
   HOST_START_GAME: 'host_start_game',
-  HOST_START_PUZZLE: 'host_start_puzzle'
+  HOST_START_PUZZLE: 'host_start_puzzle',
+  HOST_UPDATE_SETTINGS: 'host_update_settings'
 };
 
 // Token Types
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for game logic so games can run against a fake clock during replay
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is the subset of *time.Timer used by the game loops
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// Ticker is the subset of *time.Ticker used by the game loops
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// realClock is the wall clock used in production
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTimer struct{ t *time.Timer }
+
+func (rt realTimer) C() <-chan time.Time { return rt.t.C }
+func (rt realTimer) Stop() bool          { return rt.t.Stop() }
+
+type realTicker struct{ t *time.Ticker }
+
+func (rt realTicker) C() <-chan time.Time { return rt.t.C }
+func (rt realTicker) Stop()               { rt.t.Stop() }
+
+// FakeClock only moves when told to. Timers and tickers fire as the clock is advanced past them.
+type FakeClock struct {
+	now     time.Time
+	waiters []*fakeWaiter
+	mu      sync.Mutex
+}
+
+type fakeWaiter struct {
+	clock    *FakeClock
+	deadline time.Time
+	period   time.Duration // zero for one-shot timers
+	ch       chan time.Time
+}
+
+// NewFakeClock creates a fake clock reading the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the fake current time
+func (fc *FakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.now
+}
+
+// After returns a channel that receives once the clock is advanced by d
+func (fc *FakeClock) After(d time.Duration) <-chan time.Time {
+	return fc.NewTimer(d).C()
+}
+
+// NewTimer creates a one-shot timer that fires once the clock is advanced by d
+func (fc *FakeClock) NewTimer(d time.Duration) Timer {
+	return fakeTimer{fc.addWaiter(d, 0)}
+}
+
+// NewTicker creates a ticker that fires every d of fake time
+func (fc *FakeClock) NewTicker(d time.Duration) Ticker {
+	return fakeTicker{fc.addWaiter(d, d)}
+}
+
+// Advance moves the clock forward by d
+func (fc *FakeClock) Advance(d time.Duration) {
+	fc.AdvanceTo(fc.Now().Add(d))
+}
+
+// AdvanceTo moves the clock forward to t, firing every timer and ticker that comes due on the way.
+// Moving the clock backwards is ignored.
+func (fc *FakeClock) AdvanceTo(t time.Time) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if t.Before(fc.now) {
+		return
+	}
+
+	for {
+		// Fire the earliest due waiter first so tickers and timers interleave in order
+		sort.SliceStable(fc.waiters, func(i, j int) bool {
+			return fc.waiters[i].deadline.Before(fc.waiters[j].deadline)
+		})
+		if len(fc.waiters) == 0 || fc.waiters[0].deadline.After(t) {
+			break
+		}
+
+		waiter := fc.waiters[0]
+		fc.now = waiter.deadline
+
+		// Like the real thing, a ticker drops ticks nobody has received yet
+		select {
+		case waiter.ch <- fc.now:
+		default:
+		}
+
+		if waiter.period > 0 {
+			waiter.deadline = waiter.deadline.Add(waiter.period)
+		} else {
+			fc.waiters = fc.waiters[1:]
+		}
+	}
+
+	fc.now = t
+}
+
+// PendingTimers reports how many timers and tickers are waiting to fire, so tests can wait for a
+// goroutine to arm its timer before advancing the clock
+func (fc *FakeClock) PendingTimers() int {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return len(fc.waiters)
+}
+
+func (fc *FakeClock) addWaiter(d, period time.Duration) *fakeWaiter {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	waiter := &fakeWaiter{
+		clock:    fc,
+		deadline: fc.now.Add(d),
+		period:   period,
+		ch:       make(chan time.Time, 1),
+	}
+	fc.waiters = append(fc.waiters, waiter)
+	return waiter
+}
+
+// remove unregisters the waiter; it reports whether it was still pending, matching time.Timer.Stop
+func (fw *fakeWaiter) remove() bool {
+	fc := fw.clock
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	for i, waiter := range fc.waiters {
+		if waiter == fw {
+			fc.waiters = append(fc.waiters[:i], fc.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct{ w *fakeWaiter }
+
+func (ft fakeTimer) C() <-chan time.Time { return ft.w.ch }
+func (ft fakeTimer) Stop() bool          { return ft.w.remove() }
+
+type fakeTicker struct{ w *fakeWaiter }
+
+func (ft fakeTicker) C() <-chan time.Time { return ft.w.ch }
+func (ft fakeTicker) Stop()               { ft.w.remove() }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClockTimers(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	after := clock.After(10 * time.Second)
+	timer := clock.NewTimer(20 * time.Second)
+	stopped := clock.NewTimer(5 * time.Second)
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+
+	clock.Advance(9 * time.Second)
+	select {
+	case <-after:
+		t.Fatal("timer fired early")
+	default:
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case fired := <-after:
+		assert.Equal(t, start.Add(10*time.Second), fired)
+	default:
+		t.Fatal("timer did not fire")
+	}
+
+	clock.AdvanceTo(start.Add(time.Minute))
+	select {
+	case <-timer.C():
+	default:
+		t.Fatal("timer did not fire")
+	}
+	select {
+	case <-stopped.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+
+	assert.Equal(t, start.Add(time.Minute), clock.Now())
+
+	// Moving backwards is ignored
+	clock.AdvanceTo(start)
+	assert.Equal(t, start.Add(time.Minute), clock.Now())
+}
+
+func TestFakeClockTicker(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	ticker := clock.NewTicker(5 * time.Second)
+
+	ticks := 0
+	for i := 0; i < 3; i++ {
+		clock.Advance(5 * time.Second)
+		select {
+		case <-ticker.C():
+			ticks++
+		default:
+		}
+	}
+	assert.Equal(t, 3, ticks)
+
+	// Ticks nobody received are dropped rather than queued
+	clock.Advance(time.Minute)
+	<-ticker.C()
+	select {
+	case <-ticker.C():
+		t.Fatal("ticker queued more than one tick")
+	default:
+	}
+
+	ticker.Stop()
+	clock.Advance(time.Minute)
+	select {
+	case <-ticker.C():
+		t.Fatal("stopped ticker fired")
+	default:
+	}
+}
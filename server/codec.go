@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket subprotocols a client can offer during the upgrade to pick its encoding
+const (
+	SubprotocolJSON = "canvas-conundrum.json"
+	SubprotocolCBOR = "canvas-conundrum.cbor"
+)
+
+// messageCodec converts between JSON, which the server builds and validates every message as, and
+// the encoding a connection negotiated. Both encodings carry the same BaseMessage envelope.
+type messageCodec interface {
+	// frameType is the WebSocket frame type messages are sent in
+	frameType() int
+	// encode converts an encoded BaseMessage from JSON
+	encode(data []byte) ([]byte, error)
+	// decode converts a received frame to JSON
+	decode(data []byte) ([]byte, error)
+}
+
+// jsonCodec is the default encoding; messages pass through unchanged
+type jsonCodec struct{}
+
+func (jsonCodec) frameType() int                     { return websocket.TextMessage }
+func (jsonCodec) encode(data []byte) ([]byte, error) { return data, nil }
+func (jsonCodec) decode(data []byte) ([]byte, error) { return data, nil }
+
+// cborCodec sends messages as CBOR (RFC 8949) binary frames
+type cborCodec struct {
+	enc cbor.EncMode
+	dec cbor.DecMode
+}
+
+var cborMessages = newCBORCodec()
+
+func newCBORCodec() *cborCodec {
+	enc, err := cbor.EncOptions{ShortestFloat: cbor.ShortestFloat16}.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	// Maps decode with string keys so they convert back to JSON objects
+	dec, err := cbor.DecOptions{DefaultMapType: reflect.TypeOf(map[string]interface{}(nil))}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+	return &cborCodec{enc: enc, dec: dec}
+}
+
+func (c *cborCodec) frameType() int { return websocket.BinaryMessage }
+
+func (c *cborCodec) encode(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return c.enc.Marshal(cborValue(value))
+}
+
+func (c *cborCodec) decode(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := c.dec.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("invalid CBOR message: %v", err)
+	}
+	return json.Marshal(value)
+}
+
+// cborValue turns JSON numbers into integers where they are whole, so they don't arrive as floats
+func cborValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = cborValue(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = cborValue(item)
+		}
+	}
+	return value
+}
+
+// codecFor returns the codec for the subprotocol a connection negotiated; none means JSON
+func codecFor(conn *websocket.Conn) messageCodec {
+	if conn.Subprotocol() == SubprotocolCBOR {
+		return cborMessages
+	}
+	return jsonCodec{}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCBORCodecRoundTrip(t *testing.T) {
+	message := `{"type":"fragment_move_request","payload":{"fragmentId":"fragment_1","newPosition":{"x":2,"y":0},"ratio":1.5,"flags":[true,null,"s"]}}`
+
+	frame, err := cborMessages.encode([]byte(message))
+	assert.NoError(t, err)
+	assert.Less(t, len(frame), len(message))
+
+	// Whole numbers stay integers rather than becoming floats
+	var decoded map[string]interface{}
+	assert.NoError(t, cborMessages.dec.Unmarshal(frame, &decoded))
+	position := decoded["payload"].(map[string]interface{})["newPosition"].(map[string]interface{})
+	assert.Equal(t, uint64(2), position["x"])
+
+	data, err := cborMessages.decode(frame)
+	assert.NoError(t, err)
+	assert.JSONEq(t, message, string(data))
+
+	// Decoded messages go through the same envelope as JSON ones
+	var baseMsg BaseMessage
+	assert.NoError(t, json.Unmarshal(data, &baseMsg))
+	assert.Equal(t, MsgFragmentMoveRequest, baseMsg.Type)
+
+	_, err = cborMessages.decode([]byte{0xff, 0x00})
+	assert.Error(t, err)
+}
+
+func TestCodecNegotiation(t *testing.T) {
+	t.Run("JSON by default", func(t *testing.T) {
+		conn, client := dialTestConnection(t)
+		assert.Equal(t, "", client.Subprotocol())
+		assert.IsType(t, jsonCodec{}, codecFor(conn))
+	})
+
+	t.Run("CBOR when offered", func(t *testing.T) {
+		conn, client := dialTestConnection(t, SubprotocolJSON, SubprotocolCBOR)
+		assert.Equal(t, SubprotocolCBOR, client.Subprotocol())
+
+		player := &Player{ID: "player-1", Connection: conn}
+		writer := startPlayerWriter(player, newWebSocketTransport(conn))
+		defer writer.stop()
+
+		// Large enough to be compressed on the way
+		assert.NoError(t, sendToPlayer(player, MsgError, map[string]string{"error": strings.Repeat("slow down ", 50)}))
+
+		client.SetReadDeadline(time.Now().Add(time.Second))
+		frameType, frame, err := client.ReadMessage()
+		assert.NoError(t, err)
+		assert.Equal(t, websocket.BinaryMessage, frameType)
+
+		var msg map[string]interface{}
+		assert.NoError(t, cborMessages.dec.Unmarshal(frame, &msg))
+		assert.Equal(t, MsgError, msg["type"])
+	})
+}
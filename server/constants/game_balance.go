@@ -2,6 +2,9 @@ package constants
 
 import "time"
 
+// Most values in this file are the built-in game balance. DefaultBalanceConfig in balance_config.go
+// starts from them, and a balance file passed with -config may replace any of them.
+
 // Character Role Settings
 const (
 	// RoleResourceMultiplier - Multiplier applied to resource collection for character role bonuses
@@ -33,15 +36,18 @@ var RoleTokenBonuses = map[string]string{
 	RoleJanitor:       TokenAnchor,
 }
 
-// Trivia Categories - Used in trivia_manager.go and event_handlers.go
-var TriviaCategories = []string{
-	"general",
-	"geography",
-	"history",
-	"music",
-	"science",
-	"video_games",
-}
+// Trivia Question Bank - Used in trivia_manager.go, trivia_categories.go and trivia_import.go
+// Categories are not listed here: each directory of the question bank is one
+const (
+	// TriviaDirectory - Question bank the server loads, one directory per category
+	TriviaDirectory = "trivia"
+
+	// TriviaCategoryMetadataFile - Optional file in a category directory giving its display name and description
+	TriviaCategoryMetadataFile = "category.json"
+
+	// MaxTriviaCategoryIDLength - Longest category directory name the server loads
+	MaxTriviaCategoryIDLength = 40
+)
 
 // Trivia Mechanics - All used in game_manager.go and trivia_manager.go
 const (
@@ -148,6 +154,48 @@ const (
 	// PostGameAnalyticsDuration - Time to display analytics before reset (seconds)
 	// Used in: game_manager.go endGame()
 	PostGameAnalyticsDuration int = 60
+
+	// PuzzleReconnectGrace - How long a player who drops during puzzle assembly keeps their fragment
+	// and may reconnect before it is handed to everyone (seconds)
+	// Used in: fragment_hold.go holdFragment()
+	PuzzleReconnectGrace int = 60
+)
+
+// Host Settings Limits - Used in validation.go ValidateHostUpdateSettings(), game_manager.go UpdateSettings()
+// and balance_config.go. A balance file or the host may change the defaults above within these bounds.
+const (
+	MinResourceRounds = 1
+	MaxResourceRounds = 10
+
+	// Round duration bounds (seconds)
+	MinRoundDuration = 15
+	MaxRoundDuration = 300
+
+	// Puzzle base time bounds (seconds)
+	MinPuzzleBaseTime = 60
+	MaxPuzzleBaseTime = 1800
+
+	// Tokens per threshold bounds, shared by all four token types
+	MinTokenThreshold = 1
+	MaxTokenThreshold = 100
+
+	// Fixed grid size bounds; a grid size of 0 scales with the player count
+	MinGridSize = 3
+	MaxGridSize = 8
+
+	// Lowest MinPlayers a host may choose; the upper bound is MaxPlayers
+	MinPlayersFloor = 1
+
+	// Puzzle reconnect grace bounds (seconds); 0 hands a dropped player's fragment over at once
+	MinReconnectGrace = 0
+	MaxReconnectGrace = 600
+)
+
+// Host Timer Controls - Used in validation.go ValidateHostAddTime() and game_pause.go AddTime()
+const (
+	// Seconds the host may add to the running round or puzzle in one host_add_time message
+	MinAddTimeSeconds = 1
+	MaxAddTimeSeconds = 600
 )
 
 // Resource Station Hashes - Used in game_manager.go and player_manager.go
@@ -166,10 +214,10 @@ const (
 
 // Grid Scaling Configuration - Used in game_manager.go calculateGridSize()
 type GridBreakpoint struct {
-	MinPlayers     int
-	MaxPlayers     int
-	GridSize       int
-	TotalFragments int
+	MinPlayers     int `json:"minPlayers"`
+	MaxPlayers     int `json:"maxPlayers"`
+	GridSize       int `json:"gridSize"`
+	TotalFragments int `json:"totalFragments"`
 }
 
 // GridSizeBreakpoints - Player count breakpoints for determining puzzle grid size
@@ -184,12 +232,12 @@ var GridSizeBreakpoints = []GridBreakpoint{
 
 // Difficulty Level Modifiers - All used in game_manager.go and trivia_manager.go
 type DifficultyModifiers struct {
-	TriviaModifier         float64 // Affects question difficulty selection
-	TimeLimitModifier      float64 // Affects time limits for all phases
-	TokenThresholdModifier float64 // Affects token requirements for thresholds
+	TriviaModifier         float64 `json:"triviaModifier"`         // Affects question difficulty selection
+	TimeLimitModifier      float64 `json:"timeLimitModifier"`      // Affects time limits for all phases
+	TokenThresholdModifier float64 `json:"tokenThresholdModifier"` // Affects token requirements for thresholds
 }
 
-// Difficulty settings - All used in game_manager.go getDifficultyModifiers()
+// Difficulty settings - Used in game_manager.go getDifficultyModifiers() and trivia_manager.go
 var (
 	// EasyMode - Modifiers applied for easy difficulty level
 	EasyMode = DifficultyModifiers{
@@ -217,28 +265,243 @@ var (
 const (
 	WebSocketPingInterval    = 30 * time.Second
 	WebSocketPongTimeout     = 60 * time.Second
+	MaxClientMessageBytes    = 8192 // Largest message a client may send, over any transport
+	MaxRequestIDLength       = 64   // Longest requestId a client may tag a message with
+	RecentRequestsPerPlayer  = 128  // requestIds remembered per player to answer retries
 	BroadcastChannelBuffer   = 256
 	PlayerEventChannelBuffer = 64
 )
 
-// Error Messages - Used throughout the application for consistent error handling
+// Protocol - Used in protocol.go, validation.go and event_handlers.go
+const (
+	ProtocolVersion    = 2  // Newest protocol version the server speaks; sent in available_roles and welcome
+	MinProtocolVersion = 1  // Oldest protocol version still served; clients that never say hello are version 1
+	MaxHelloFeatures   = 32 // Most features a hello may offer
+)
+
+// Compression - Used in websocket_handlers.go and player_writer.go
+const (
+	// WebSocketCompressionLevel - flate level for permessage-deflate; fastest, since most messages are small
+	WebSocketCompressionLevel = 1
+
+	// WebSocketCompressionThreshold - Messages smaller than this many bytes are sent uncompressed,
+	// where deflate would save little or even add to their size
+	WebSocketCompressionThreshold = 256
+)
+
+// Outbound Queue - Used in player_writer.go
+const (
+	// PlayerWriteQueueSize - Messages that may wait for a slow client before progress updates are
+	// dropped, and once only critical messages are left, the client is disconnected
+	PlayerWriteQueueSize = 256
+
+	// WebSocketWriteTimeout - How long one write to a client may take before the connection is dropped
+	WebSocketWriteTimeout = 10 * time.Second
+)
+
+// Rate Limits - Used in rate_limiter.go
+const (
+	// MessageRatePerSecond, MessageRateBurst - Messages of any type one player may send: a steady
+	// rate, and a burst on top of it for quick taps during trivia and the puzzle
+	MessageRatePerSecond = 10
+	MessageRateBurst     = 20
+
+	// FragmentMoveRatePerSecond, FragmentMoveRateBurst - Fragment moves, each of which locks the game
+	FragmentMoveRatePerSecond = 4
+	FragmentMoveRateBurst     = 8
+
+	// RecommendationRatePerSecond, RecommendationRateBurst - Piece recommendations, which other
+	// players have to answer
+	RecommendationRatePerSecond = 0.5
+	RecommendationRateBurst     = 3
+
+	// SetNameRatePerSecond, SetNameRateBurst - Name changes, which are announced to the lobby
+	SetNameRatePerSecond = 1
+	SetNameRateBurst     = 3
+
+	// ResyncRatePerSecond, ResyncRateBurst - Puzzle resyncs, each a full puzzle snapshot
+	ResyncRatePerSecond = 0.5
+	ResyncRateBurst     = 2
+
+	// MaxConnectionsPerIP - Connections open at once from one address. Generous because a whole
+	// venue often shares one address behind NAT.
+	MaxConnectionsPerIP = 80
+
+	// RateLimitAbuseLimit, RateLimitAbuseWindow - A player with this many messages refused within
+	// the window is disconnected
+	RateLimitAbuseLimit  = 50
+	RateLimitAbuseWindow = 10 * time.Second
+)
+
+// Session Tokens - Used in session_tokens.go and main.go
+const (
+	// SessionTokenLifetime - How long a session token stays valid. A fresh one is issued on every
+	// connection, so this only needs to outlast one sitting.
+	SessionTokenLifetime = 12 * time.Hour
+
+	// SessionSecretBytes - Size of the random key session tokens are signed with
+	SessionSecretBytes = 32
+
+	// SessionSecretFile - Where the signing key is kept in the snapshot directory, so players of a
+	// restored game can still reconnect
+	SessionSecretFile = "session.key"
+)
+
+// Player Names - Used in name_filter.go unless the server is started with -name-blocklist
+var (
+	// DefaultNameBlocklist - Words player-chosen names may not contain. Matching ignores case,
+	// separators and common swaps like 4 for a, so only words that rarely hide inside others belong here.
+	DefaultNameBlocklist = []string{
+		"asshole", "bastard", "bitch", "cunt", "fag", "fuck", "nazi", "nigger", "nigga", "pussy",
+		"retard", "shit", "slut", "whore",
+	}
+)
+
+// Room Limits - Used in room_manager.go and main.go
+const (
+	// MaxRooms - Maximum number of concurrent game rooms hosted by one server process
+	MaxRooms = 32
+
+	// RoomIdleTimeout - How long a non-default room may have no connected players before it is torn down
+	RoomIdleTimeout = 30 * time.Minute
+
+	// RoomCleanupInterval - How often the room manager checks for idle rooms
+	RoomCleanupInterval = 1 * time.Minute
+)
+
+// Join Codes - Used in join_codes.go, room_manager.go and validation.go
+const (
+	// JoinCodeLength - Number of characters in a room join code
+	JoinCodeLength = 5
+
+	// JoinCodeAlphabet - Characters used in join codes; excludes look-alikes (0/O, 1/I/L, 2/Z, 5/S, 8/B)
+	JoinCodeAlphabet = "ACDEFGHJKMNPQRTUVWXY34679"
+
+	// JoinCodeReuseCooldown - How long a released join code is held back before it can be issued again
+	JoinCodeReuseCooldown = 2 * time.Hour
+)
+
+// Snapshots - Used in snapshot_store.go and game_recovery.go
+const (
+	// SnapshotInterval - How often an in-progress game is snapshotted even without state changes
+	SnapshotInterval = 15 * time.Second
+
+	// SnapshotFormatVersion - Bumped whenever the snapshot file layout changes incompatibly
+	SnapshotFormatVersion = 1
+)
+
+// Game History - Used in game_store_sqlite.go and main.go
 const (
+	// DefaultGameHistoryPageSize - Games returned by GET /games when no limit is given
+	DefaultGameHistoryPageSize = 20
+
+	// MaxGameHistoryPageSize - Upper bound on the limit accepted by GET /games
+	MaxGameHistoryPageSize = 100
+)
+
+// Event Log - Used in event_log.go, game_replay.go and main.go
+const (
+	// ReplayCheckpointTimeout - How long a replay waits for the game to reach a recorded checkpoint
+	ReplayCheckpointTimeout = 5 * time.Second
+)
+
+// Error Messages - Used throughout the application for consistent error handling. Each one has a
+// sentinel error with a stable code in error_codes.go.
+const (
+	// Request errors
+	ErrInvalidMessage       = "invalid message"
+	ErrUnknownMessageType   = "unknown message type"
+	ErrInvalidPayload       = "invalid payload"
+	ErrValidationFailed     = "validation failed"
+	ErrAuthenticationFailed = "authentication failed"
+
 	// Fragment ownership errors
-	ErrFragmentOwnership  = "you can only move your own fragment or unassigned fragments"
-	ErrFragmentNotVisible = "fragment is not yet visible"
-	ErrFragmentUnassigned = "invalid unassigned fragment access"
+	ErrFragmentOwnership   = "you can only move your own fragment or unassigned fragments"
+	ErrFragmentNotVisible  = "fragment is not yet visible"
+	ErrFragmentUnassigned  = "invalid unassigned fragment access"
+	ErrFragmentNotFound    = "fragment not found"
+	ErrFragmentCooldown    = "fragment was moved too recently"
+	ErrPositionOutOfBounds = "position out of bounds"
 
 	// Recommendation errors
-	ErrInvalidRecommendation = "can only recommend moves for unassigned fragments"
-	ErrRecommendationAuth    = "not authorized to respond to this recommendation"
+	ErrInvalidRecommendation  = "can only recommend moves for unassigned fragments"
+	ErrRecommendationAuth     = "not authorized to respond to this recommendation"
+	ErrRecommendationNotFound = "recommendation not found"
 
 	// Phase errors
 	ErrWrongPhase            = "action not allowed in current game phase"
 	ErrReconnectionForbidden = "reconnection not allowed during puzzle assembly phase"
+	ErrGameNotStarted        = "no game is in progress"
+	ErrGameNotOver           = "a rematch can only start after the game ends"
+	ErrNoRoundsToSkip        = "trivia rounds can only be skipped during resource gathering"
+	ErrGameAlreadyStarted    = "game already started"
+	ErrNotInResourcePhase    = "not in resource gathering phase"
 
 	// Host errors
-	ErrHostOnly   = "only host can perform this action"
-	ErrHostExists = "a host is already connected to this game"
+	ErrHostOnly            = "only host can perform this action"
+	ErrHostOnlyStartGame   = "only host can start the game"
+	ErrHostOnlyStartPuzzle = "only host can start puzzle phase"
+	ErrHostExists          = "a host is already connected to this game"
+	ErrHostTarget          = "the host cannot be kicked, renamed or reassigned"
+	ErrPlayerBanned        = "you were removed from this game by the host"
+	ErrPlayersLocked       = "players can only be reassigned before the game starts"
+	ErrPlayerNotFound      = "player not found"
+
+	// Join errors
+	ErrJoinFailed       = "failed to join game"
+	ErrCannotJoin       = "cannot join game at this time"
+	ErrRoleNotAvailable = "role not available"
+
+	// Session errors
+	ErrSessionTokenRequired = "a session token is required to reconnect"
+	ErrInvalidSessionToken  = "invalid or expired session token"
+	ErrSessionTokenMismatch = "session token does not belong to this player"
+
+	// Protocol errors
+	ErrProtocolUnsupported = "unsupported protocol version"
+
+	// Name errors
+	ErrNameTaken      = "that name is already taken"
+	ErrNameNotAllowed = "that name is not allowed"
+	ErrNamesLocked    = "names can only be chosen before the game starts"
+
+	// Settings errors
+	ErrSettingsLocked    = "settings can only be changed before the game starts"
+	ErrGridTooSmall      = "grid is too small for the minimum number of players"
+	ErrInvalidDifficulty = "invalid difficulty"
+	ErrUnknownPreset     = "unknown settings preset"
+
+	// Trivia errors
+	ErrNotAtStation     = "player must be at a resource station to answer questions"
+	ErrQuestionNotFound = "invalid or expired question ID"
+	ErrInvalidTimestamp = "invalid timestamp"
+
+	// Pause errors
+	ErrGamePaused     = "game is paused"
+	ErrGameNotPaused  = "game is not paused"
+	ErrNoRunningTimer = "no round or puzzle timer is running"
+
+	// Event stream errors
+	ErrEventStreamRequired = "open the event stream before posting messages"
+
+	// Rate limit errors
+	ErrRateLimited        = "too many messages; slow down"
+	ErrMessageFlood       = "disconnected for sending too many messages"
+	ErrTooManyConnections = "too many connections from this address"
+
+	// Puzzle errors
+	ErrNotInPuzzlePhase = "not in puzzle assembly phase"
+
+	// Room errors
+	ErrRoomNotFound    = "game room not found"
+	ErrInvalidJoinCode = "invalid join code format"
+
+	// Snapshot errors
+	ErrSnapshotNotResumable = "snapshot is not from an in-progress game"
+	ErrSnapshotVersion      = "unsupported snapshot format version"
+
+	// Game history errors
+	ErrGameNotFound = "game not found"
 
 	// Validation errors
 	ErrInvalidOwnership = "invalid fragment ownership format"
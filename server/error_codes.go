@@ -0,0 +1,300 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+)
+
+// ErrorCode identifies an error for clients. Codes never change once released, while the
+// human-readable message alongside them may.
+type ErrorCode string
+
+// Error codes sent in the code field of every error message
+const (
+	// Requests
+	CodeInvalidMessage       ErrorCode = "INVALID_MESSAGE"
+	CodeUnknownMessageType   ErrorCode = "UNKNOWN_MESSAGE_TYPE"
+	CodeInvalidPayload       ErrorCode = "INVALID_PAYLOAD"
+	CodeValidationFailed     ErrorCode = "VALIDATION_FAILED"
+	CodeAuthenticationFailed ErrorCode = "AUTHENTICATION_FAILED"
+	CodeRequestFailed        ErrorCode = "REQUEST_FAILED" // Anything without a code of its own
+
+	// Connections and sessions
+	CodeJoinFailed           ErrorCode = "JOIN_FAILED"
+	CodeCannotJoin           ErrorCode = "CANNOT_JOIN"
+	CodeSessionTokenRequired ErrorCode = "SESSION_TOKEN_REQUIRED"
+	CodeInvalidSessionToken  ErrorCode = "INVALID_SESSION_TOKEN"
+	CodeSessionTokenMismatch ErrorCode = "SESSION_TOKEN_MISMATCH"
+	CodeProtocolUnsupported  ErrorCode = "PROTOCOL_UNSUPPORTED"
+	CodeEventStreamRequired  ErrorCode = "EVENT_STREAM_REQUIRED"
+	CodeRoomNotFound         ErrorCode = "ROOM_NOT_FOUND"
+	CodeInvalidJoinCode      ErrorCode = "INVALID_JOIN_CODE"
+	CodeRoomClosed           ErrorCode = "ROOM_CLOSED"
+	CodeServerShutdown       ErrorCode = "SERVER_SHUTDOWN"
+
+	// Rate limits
+	CodeRateLimited        ErrorCode = "RATE_LIMITED"
+	CodeMessageFlood       ErrorCode = "MESSAGE_FLOOD"
+	CodeTooManyConnections ErrorCode = "TOO_MANY_CONNECTIONS"
+
+	// Host
+	CodeHostOnly         ErrorCode = "HOST_ONLY"
+	CodeHostExists       ErrorCode = "HOST_EXISTS"
+	CodeHostTarget       ErrorCode = "HOST_TARGET"
+	CodeHostDisconnected ErrorCode = "HOST_DISCONNECTED"
+	CodePlayerBanned     ErrorCode = "PLAYER_BANNED"
+	CodePlayersLocked    ErrorCode = "PLAYERS_LOCKED"
+	CodePlayerNotFound   ErrorCode = "PLAYER_NOT_FOUND"
+	CodeHostNotPlayer    ErrorCode = "HOST_NOT_PLAYER" // The host doesn't take part in play
+
+	// Game state
+	CodeWrongPhase            ErrorCode = "WRONG_PHASE"
+	CodeNotInPuzzlePhase      ErrorCode = "NOT_IN_PUZZLE_PHASE"
+	CodeNotInResourcePhase    ErrorCode = "NOT_IN_RESOURCE_PHASE"
+	CodeReconnectionForbidden ErrorCode = "RECONNECTION_FORBIDDEN"
+	CodeGameNotStarted        ErrorCode = "GAME_NOT_STARTED"
+	CodeGameAlreadyStarted    ErrorCode = "GAME_ALREADY_STARTED"
+	CodeGameNotOver           ErrorCode = "GAME_NOT_OVER"
+	CodeNoRoundsToSkip        ErrorCode = "NO_ROUNDS_TO_SKIP"
+	CodeGamePaused            ErrorCode = "GAME_PAUSED"
+	CodeGameNotPaused         ErrorCode = "GAME_NOT_PAUSED"
+	CodeNoRunningTimer        ErrorCode = "NO_RUNNING_TIMER"
+
+	// Lobby
+	CodeRoleNotAvailable  ErrorCode = "ROLE_NOT_AVAILABLE"
+	CodeNameTaken         ErrorCode = "NAME_TAKEN"
+	CodeNameNotAllowed    ErrorCode = "NAME_NOT_ALLOWED"
+	CodeNamesLocked       ErrorCode = "NAMES_LOCKED"
+	CodeSettingsLocked    ErrorCode = "SETTINGS_LOCKED"
+	CodeGridTooSmall      ErrorCode = "GRID_TOO_SMALL"
+	CodeInvalidDifficulty ErrorCode = "INVALID_DIFFICULTY"
+	CodeUnknownPreset     ErrorCode = "UNKNOWN_PRESET"
+	CodeCannotStartGame   ErrorCode = "CANNOT_START_GAME" // Not enough players, or some not ready
+
+	// Trivia
+	CodeNotAtStation     ErrorCode = "NOT_AT_STATION"
+	CodeQuestionNotFound ErrorCode = "QUESTION_NOT_FOUND"
+	CodeInvalidTimestamp ErrorCode = "INVALID_TIMESTAMP"
+
+	// Puzzle
+	CodeNotOwner                ErrorCode = "NOT_OWNER"
+	CodeFragmentNotVisible      ErrorCode = "FRAGMENT_NOT_VISIBLE"
+	CodeFragmentUnassigned      ErrorCode = "FRAGMENT_UNASSIGNED"
+	CodeFragmentNotFound        ErrorCode = "FRAGMENT_NOT_FOUND"
+	CodeFragmentCooldown        ErrorCode = "FRAGMENT_COOLDOWN"
+	CodePositionOutOfBounds     ErrorCode = "POSITION_OUT_OF_BOUNDS"
+	CodeInvalidOwnership        ErrorCode = "INVALID_OWNERSHIP"
+	CodeInvalidRecommendation   ErrorCode = "INVALID_RECOMMENDATION"
+	CodeRecommendationNotFound  ErrorCode = "RECOMMENDATION_NOT_FOUND"
+	CodeNotRecommendationTarget ErrorCode = "NOT_RECOMMENDATION_TARGET"
+	CodeFragmentCompleted       ErrorCode = "FRAGMENT_COMPLETED"
+
+	// Server side, never sent over a game connection
+	CodeSnapshotNotResumable ErrorCode = "SNAPSHOT_NOT_RESUMABLE"
+	CodeSnapshotVersion      ErrorCode = "SNAPSHOT_VERSION"
+	CodeGameNotFound         ErrorCode = "GAME_NOT_FOUND"
+)
+
+// Errors with a code of their own. Each has the message of its constants error; wrap one with %w
+// to add detail ("fragment not found: fragment_3") and it keeps its code.
+var (
+	errInvalidMessage       = newSentinelError(CodeInvalidMessage, constants.ErrInvalidMessage)
+	errUnknownMessageType   = newSentinelError(CodeUnknownMessageType, constants.ErrUnknownMessageType)
+	errInvalidPayload       = newSentinelError(CodeInvalidPayload, constants.ErrInvalidPayload)
+	errValidationFailed     = newSentinelError(CodeValidationFailed, constants.ErrValidationFailed)
+	errAuthenticationFailed = newSentinelError(CodeAuthenticationFailed, constants.ErrAuthenticationFailed)
+
+	errJoinFailed           = newSentinelError(CodeJoinFailed, constants.ErrJoinFailed)
+	errCannotJoin           = newSentinelError(CodeCannotJoin, constants.ErrCannotJoin)
+	errSessionTokenRequired = newSentinelError(CodeSessionTokenRequired, constants.ErrSessionTokenRequired)
+	errInvalidSessionToken  = newSentinelError(CodeInvalidSessionToken, constants.ErrInvalidSessionToken)
+	errSessionTokenMismatch = newSentinelError(CodeSessionTokenMismatch, constants.ErrSessionTokenMismatch)
+	errProtocolUnsupported  = newSentinelError(CodeProtocolUnsupported, constants.ErrProtocolUnsupported)
+	errEventStreamRequired  = newSentinelError(CodeEventStreamRequired, constants.ErrEventStreamRequired)
+	errRoomNotFound         = newSentinelError(CodeRoomNotFound, constants.ErrRoomNotFound)
+	errInvalidJoinCode      = newSentinelError(CodeInvalidJoinCode, constants.ErrInvalidJoinCode)
+
+	errRateLimited        = newSentinelError(CodeRateLimited, constants.ErrRateLimited)
+	errMessageFlood       = newSentinelError(CodeMessageFlood, constants.ErrMessageFlood)
+	errTooManyConnections = newSentinelError(CodeTooManyConnections, constants.ErrTooManyConnections)
+
+	errHostOnly            = newSentinelError(CodeHostOnly, constants.ErrHostOnly)
+	errHostOnlyStartGame   = newSentinelError(CodeHostOnly, constants.ErrHostOnlyStartGame)
+	errHostOnlyStartPuzzle = newSentinelError(CodeHostOnly, constants.ErrHostOnlyStartPuzzle)
+	errHostExists          = newSentinelError(CodeHostExists, constants.ErrHostExists)
+	errHostTarget          = newSentinelError(CodeHostTarget, constants.ErrHostTarget)
+	errPlayerBanned        = newSentinelError(CodePlayerBanned, constants.ErrPlayerBanned)
+	errPlayersLocked       = newSentinelError(CodePlayersLocked, constants.ErrPlayersLocked)
+	errPlayerNotFound      = newSentinelError(CodePlayerNotFound, constants.ErrPlayerNotFound)
+
+	errWrongPhase            = newSentinelError(CodeWrongPhase, constants.ErrWrongPhase)
+	errNotInPuzzlePhase      = newSentinelError(CodeNotInPuzzlePhase, constants.ErrNotInPuzzlePhase)
+	errNotInResourcePhase    = newSentinelError(CodeNotInResourcePhase, constants.ErrNotInResourcePhase)
+	errReconnectionForbidden = newSentinelError(CodeReconnectionForbidden, constants.ErrReconnectionForbidden)
+	errGameNotStarted        = newSentinelError(CodeGameNotStarted, constants.ErrGameNotStarted)
+	errGameAlreadyStarted    = newSentinelError(CodeGameAlreadyStarted, constants.ErrGameAlreadyStarted)
+	errGameNotOver           = newSentinelError(CodeGameNotOver, constants.ErrGameNotOver)
+	errNoRoundsToSkip        = newSentinelError(CodeNoRoundsToSkip, constants.ErrNoRoundsToSkip)
+	errGamePaused            = newSentinelError(CodeGamePaused, constants.ErrGamePaused)
+	errGameNotPaused         = newSentinelError(CodeGameNotPaused, constants.ErrGameNotPaused)
+	errNoRunningTimer        = newSentinelError(CodeNoRunningTimer, constants.ErrNoRunningTimer)
+
+	errRoleNotAvailable  = newSentinelError(CodeRoleNotAvailable, constants.ErrRoleNotAvailable)
+	errNameTaken         = newSentinelError(CodeNameTaken, constants.ErrNameTaken)
+	errNameNotAllowed    = newSentinelError(CodeNameNotAllowed, constants.ErrNameNotAllowed)
+	errNamesLocked       = newSentinelError(CodeNamesLocked, constants.ErrNamesLocked)
+	errSettingsLocked    = newSentinelError(CodeSettingsLocked, constants.ErrSettingsLocked)
+	errGridTooSmall      = newSentinelError(CodeGridTooSmall, constants.ErrGridTooSmall)
+	errInvalidDifficulty = newSentinelError(CodeInvalidDifficulty, constants.ErrInvalidDifficulty)
+	errUnknownPreset     = newSentinelError(CodeUnknownPreset, constants.ErrUnknownPreset)
+
+	errNotAtStation     = newSentinelError(CodeNotAtStation, constants.ErrNotAtStation)
+	errQuestionNotFound = newSentinelError(CodeQuestionNotFound, constants.ErrQuestionNotFound)
+	errInvalidTimestamp = newSentinelError(CodeInvalidTimestamp, constants.ErrInvalidTimestamp)
+
+	errFragmentOwnership      = newSentinelError(CodeNotOwner, constants.ErrFragmentOwnership)
+	errFragmentNotVisible     = newSentinelError(CodeFragmentNotVisible, constants.ErrFragmentNotVisible)
+	errFragmentUnassigned     = newSentinelError(CodeFragmentUnassigned, constants.ErrFragmentUnassigned)
+	errFragmentNotFound       = newSentinelError(CodeFragmentNotFound, constants.ErrFragmentNotFound)
+	errFragmentCooldown       = newSentinelError(CodeFragmentCooldown, constants.ErrFragmentCooldown)
+	errPositionOutOfBounds    = newSentinelError(CodePositionOutOfBounds, constants.ErrPositionOutOfBounds)
+	errInvalidOwnership       = newSentinelError(CodeInvalidOwnership, constants.ErrInvalidOwnership)
+	errInvalidRecommendation  = newSentinelError(CodeInvalidRecommendation, constants.ErrInvalidRecommendation)
+	errRecommendationNotFound = newSentinelError(CodeRecommendationNotFound, constants.ErrRecommendationNotFound)
+	errRecommendationAuth     = newSentinelError(CodeNotRecommendationTarget, constants.ErrRecommendationAuth)
+
+	errSnapshotNotResumable = newSentinelError(CodeSnapshotNotResumable, constants.ErrSnapshotNotResumable)
+	errSnapshotVersion      = newSentinelError(CodeSnapshotVersion, constants.ErrSnapshotVersion)
+	errGameNotFound         = newSentinelError(CodeGameNotFound, constants.ErrGameNotFound)
+)
+
+// errorTypes groups codes into the broad type sent alongside them; codes not listed are
+// game_state_error
+var errorTypes = map[ErrorCode]string{
+	CodeInvalidMessage:      "validation_error",
+	CodeUnknownMessageType:  "validation_error",
+	CodeInvalidPayload:      "validation_error",
+	CodeValidationFailed:    "validation_error",
+	CodeInvalidTimestamp:    "validation_error",
+	CodePositionOutOfBounds: "validation_error",
+	CodeInvalidOwnership:    "validation_error",
+	CodeInvalidDifficulty:   "validation_error",
+	CodeUnknownPreset:       "validation_error",
+	CodeNameNotAllowed:      "validation_error",
+
+	CodeAuthenticationFailed: "authentication_error",
+	CodeSessionTokenRequired: "authentication_error",
+	CodeInvalidSessionToken:  "authentication_error",
+	CodeSessionTokenMismatch: "authentication_error",
+	CodeEventStreamRequired:  "authentication_error",
+
+	CodeHostOnly:         "host_error",
+	CodeHostExists:       "host_error",
+	CodeHostTarget:       "host_error",
+	CodeHostNotPlayer:    "host_error",
+	CodeHostDisconnected: "host_disconnected",
+
+	CodeRateLimited:        "rate_limited",
+	CodeMessageFlood:       "rate_limited",
+	CodeTooManyConnections: "rate_limited",
+
+	CodeProtocolUnsupported: "connection_error",
+
+	CodeRoomClosed:     "room_closed",
+	CodeServerShutdown: "server_shutdown",
+	CodeRequestFailed:  "general_error",
+	CodeJoinFailed:     "general_error",
+}
+
+// CodedError is an error with a code, and the fields at fault
+type CodedError struct {
+	Code    ErrorCode
+	Message string
+	Fields  []ValidationError
+}
+
+func (e *CodedError) Error() string {
+	return e.Message
+}
+
+// newCodedError creates an error with an explicit code
+func newCodedError(code ErrorCode, format string, args ...interface{}) *CodedError {
+	return &CodedError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// sentinelErrors lists the errors above, in declaration order
+var sentinelErrors []*CodedError
+
+// newSentinelError creates one of the errors above. Sentinels are shared and never modified; compare
+// with errors.Is.
+func newSentinelError(code ErrorCode, message string) *CodedError {
+	err := &CodedError{Code: code, Message: message}
+	sentinelErrors = append(sentinelErrors, err)
+	return err
+}
+
+// validationFailure reports payload fields that failed validation
+func validationFailure(fields []ValidationError) error {
+	return &CodedError{
+		Code:    CodeValidationFailed,
+		Message: fmt.Sprintf("%s: %v", constants.ErrValidationFailed, fields),
+		Fields:  fields,
+	}
+}
+
+// errorCodeOf returns the code of the CodedError an error is or wraps, or CodeRequestFailed for
+// errors without one
+func errorCodeOf(err error) ErrorCode {
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return CodeRequestFailed
+}
+
+// errorTypeOf returns the broad type of a code
+func errorTypeOf(code ErrorCode) string {
+	if errorType, found := errorTypes[code]; found {
+		return errorType
+	}
+	return "game_state_error"
+}
+
+// ErrorPayload is the payload of every error message
+type ErrorPayload struct {
+	Code         ErrorCode         `json:"code"`                   // Stable and machine-readable
+	Error        string            `json:"error"`                  // Human-readable, may change
+	Type         string            `json:"type"`                   // Broad type of the code
+	RequestType  string            `json:"requestType,omitempty"`  // Message that failed
+	RequestID    string            `json:"requestId,omitempty"`    // requestId of the message that failed
+	Fields       []ValidationError `json:"fields,omitempty"`       // Fields that failed validation
+	RetryAfterMs int64             `json:"retryAfterMs,omitempty"` // When a rate limited message may be sent again
+	Timestamp    int64             `json:"timestamp,omitempty"`    // When a connection was refused
+	Duplicate    bool              `json:"duplicate,omitempty"`    // Repeats the answer to an earlier message with this requestId
+}
+
+// newErrorPayload describes err to a client
+func newErrorPayload(err error) ErrorPayload {
+	code := errorCodeOf(err)
+	payload := ErrorPayload{
+		Code:  code,
+		Error: err.Error(),
+		Type:  errorTypeOf(code),
+	}
+
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		payload.Fields = coded.Fields
+	}
+	return payload
+}
+
+// requestErrorPayload describes err to a client as the failure of the message it sent
+func requestErrorPayload(baseMsg BaseMessage, err error) ErrorPayload {
+	payload := newErrorPayload(err)
+	payload.RequestType = baseMsg.Type
+	payload.RequestID = baseMsg.RequestID
+	return payload
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	pm := NewPlayerManager()
+	_, unknownPlayer := pm.GetPlayer("nobody")
+	host := pm.CreatePlayer(nil, true)
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{"Sentinel", errWrongPhase, CodeWrongPhase},
+		{"Sentinel with detail", fmt.Errorf("%w: %s", errFragmentNotFound, "fragment_3"), CodeFragmentNotFound},
+		{"Wrapped sentinel", fmt.Errorf("moving fragment: %w", errFragmentOwnership), CodeNotOwner},
+		{"Coded error", newCodedError(CodeJoinFailed, "could not join"), CodeJoinFailed},
+		{"Validation failure", validationFailure([]ValidationError{{Field: "role", Message: "invalid role selection"}}), CodeValidationFailed},
+		{"Unknown player", unknownPlayer, CodePlayerNotFound},
+		{"Host taking part", pm.SetPlayerSpecialties(host.ID, []string{"science"}), CodeHostNotPlayer},
+		{"Uncoded error", fmt.Errorf("something unexpected"), CodeRequestFailed},
+		{"Catalogue message without a sentinel", fmt.Errorf(constants.ErrWrongPhase), CodeRequestFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, errorCodeOf(tt.err))
+		})
+	}
+}
+
+func TestSentinelErrorCodesAreDistinct(t *testing.T) {
+	// Only the host-only variants share a code; any other repeat is a copy and paste slip
+	messages := make(map[ErrorCode][]string)
+	for _, err := range sentinelErrors {
+		messages[err.Code] = append(messages[err.Code], err.Message)
+	}
+	for code, list := range messages {
+		if code != CodeHostOnly {
+			assert.Len(t, list, 1, "code %s is used by %v", code, list)
+		}
+	}
+}
+
+func TestEveryConstantsErrorHasSentinel(t *testing.T) {
+	// Read the Err* constants from the source, since a package's constants can't be listed at run time
+	fset := token.NewFileSet()
+	packages, err := parser.ParseDir(fset, "constants", nil, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	sentinels := make(map[string]ErrorCode)
+	for _, err := range sentinelErrors {
+		sentinels[err.Message] = err.Code
+	}
+
+	found := 0
+	for _, file := range packages["constants"].Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			spec, ok := node.(*ast.ValueSpec)
+			if !ok {
+				return true
+			}
+			for i, name := range spec.Names {
+				if !strings.HasPrefix(name.Name, "Err") || i >= len(spec.Values) {
+					continue
+				}
+				literal, ok := spec.Values[i].(*ast.BasicLit)
+				if !assert.True(t, ok, "%s is not a string literal", name.Name) {
+					continue
+				}
+				message, err := strconv.Unquote(literal.Value)
+				assert.NoError(t, err)
+
+				found++
+				code, exists := sentinels[message]
+				assert.True(t, exists, "constants.%s has no sentinel error", name.Name)
+				assert.NotEqual(t, CodeRequestFailed, code, "constants.%s has no code of its own", name.Name)
+			}
+			return false
+		})
+	}
+	assert.Equal(t, len(sentinelErrors), found, "every sentinel error has a constants message")
+}
+
+func TestRequestErrorPayload(t *testing.T) {
+	gm, pm, tm, broadcastChan := createTestGameManager()
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	eh := NewEventHandlers(gm, pm, broadcastChan)
+	wsh := NewWebSocketHandler(pm, gm, eh, broadcastChan)
+	player := pm.CreatePlayer(nil, false)
+	writer := idleTestWriter(t, player)
+	token := wsh.sessionTokens.Issue(player.ID)
+	authed := func(payload interface{}) []byte {
+		return mustMarshal(AuthWrapper{Auth: AuthData{PlayerID: player.ID, Token: token}, Payload: mustMarshal(payload)})
+	}
+
+	// Field problems are listed, and the message's type and requestId come back with them
+	wsh.handleClientMessage(player, BaseMessage{
+		Type:      MsgRoleSelection,
+		RequestID: "req-7",
+		Payload:   authed(map[string]string{"role": "wizard"}),
+	})
+
+	// Errors from the game are coded from their message
+	wsh.handleClientMessage(player, BaseMessage{
+		Type:    MsgHostStartGame,
+		Payload: authed(map[string]string{}),
+	})
+
+	// A missing token fails authentication
+	wsh.handleClientMessage(player, BaseMessage{
+		Type:    MsgPlayerReady,
+		Payload: CreateAuthWrapper(player.ID, map[string]bool{"ready": true}),
+	})
+
+	errors := queuedPayloads(t, writer, MsgError)
+	if !assert.Len(t, errors, 3) {
+		return
+	}
+
+	assert.Equal(t, string(CodeValidationFailed), errors[0]["code"])
+	assert.Equal(t, "validation_error", errors[0]["type"])
+	assert.Equal(t, MsgRoleSelection, errors[0]["requestType"])
+	assert.Equal(t, "req-7", errors[0]["requestId"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"field": "role", "message": "invalid role selection"}}, errors[0]["fields"])
+
+	assert.Equal(t, string(CodeHostOnly), errors[1]["code"])
+	assert.Equal(t, "host_error", errors[1]["type"])
+	assert.Equal(t, constants.ErrHostOnlyStartGame, errors[1]["error"])
+	assert.NotContains(t, errors[1], "requestId")
+	assert.NotContains(t, errors[1], "fields")
+
+	assert.Equal(t, string(CodeAuthenticationFailed), errors[2]["code"])
+	assert.Equal(t, "authentication_error", errors[2]["type"])
+	assert.Equal(t, "auth.token", errors[2]["fields"].([]interface{})[0].(map[string]interface{})["field"])
+}
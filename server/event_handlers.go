@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/gorilla/websocket"
 )
 
 // EventHandlers contains all WebSocket event handler functions
@@ -32,39 +33,38 @@ func (eh *EventHandlers) HandlePlayerJoin(player *Player, payload json.RawMessag
 	// Send available roles (only relevant for non-host players)
 	player.mu.RLock()
 	isHost := player.IsHost
+	sessionToken := player.SessionToken
 	player.mu.RUnlock()
 
 	if isHost {
 		// Host gets a different response - no roles or specialties needed
-		response := map[string]interface{}{
-			"playerId": player.ID,
-			"isHost":   true,
-			"message":  "Connected as game host",
+		response := AvailableRolesPayload{
+			PlayerID:        player.ID,
+			SessionToken:    sessionToken,
+			IsHost:          true,
+			Message:         "Connected as game host",
+			ProtocolVersion: constants.ProtocolVersion,
 		}
 		return sendToPlayer(player, MsgAvailableRoles, response)
 	} else {
 		// Regular player gets roles and trivia categories
 		roles := eh.playerManager.GetAvailableRoles()
-		response := map[string]interface{}{
-			"playerId":         player.ID,
-			"isHost":           false,
-			"roles":            roles,
-			"triviaCategories": constants.TriviaCategories,
+		categories := eh.gameManager.triviaManager.GetCategoryDetails()
+		response := AvailableRolesPayload{
+			PlayerID:              player.ID,
+			SessionToken:          sessionToken,
+			IsHost:                false,
+			Roles:                 roles,
+			TriviaCategories:      categoryIDs(categories),
+			TriviaCategoryDetails: categories,
+			ProtocolVersion:       constants.ProtocolVersion,
 		}
 		return sendToPlayer(player, MsgAvailableRoles, response)
 	}
 }
 
 // HandleRoleSelection handles player role selection
-func (eh *EventHandlers) HandleRoleSelection(playerID string, payload json.RawMessage) error {
-	var data struct {
-		Role string `json:"role"`
-	}
-
-	if err := json.Unmarshal(payload, &data); err != nil {
-		return fmt.Errorf("invalid payload: %v", err)
-	}
-
+func (eh *EventHandlers) HandleRoleSelection(playerID string, data RoleSelectionPayload) error {
 	// Set player role
 	if err := eh.playerManager.SetPlayerRole(playerID, data.Role); err != nil {
 		return err
@@ -77,15 +77,7 @@ func (eh *EventHandlers) HandleRoleSelection(playerID string, payload json.RawMe
 }
 
 // HandleTriviaSpecialtySelection handles player specialty selection
-func (eh *EventHandlers) HandleTriviaSpecialtySelection(playerID string, payload json.RawMessage) error {
-	var data struct {
-		Specialties []string `json:"specialties"`
-	}
-
-	if err := json.Unmarshal(payload, &data); err != nil {
-		return fmt.Errorf("invalid payload: %v", err)
-	}
-
+func (eh *EventHandlers) HandleTriviaSpecialtySelection(playerID string, data SpecialtySelectionPayload) error {
 	// Set player specialties
 	if err := eh.playerManager.SetPlayerSpecialties(playerID, data.Specialties); err != nil {
 		return err
@@ -101,27 +93,13 @@ func (eh *EventHandlers) HandleTriviaSpecialtySelection(playerID string, payload
 }
 
 // HandlePlayerReady handles player ready status
-func (eh *EventHandlers) HandlePlayerReady(playerID string, payload json.RawMessage) error {
-	// Check if ready field exists
-	var checkFields map[string]interface{}
-	if err := json.Unmarshal(payload, &checkFields); err != nil {
-		return fmt.Errorf("invalid payload: %v", err)
-	}
-
-	if _, ok := checkFields["ready"]; !ok {
-		return fmt.Errorf("invalid payload: ready field is required")
-	}
-
-	var data struct {
-		Ready bool `json:"ready"`
-	}
-
-	if err := json.Unmarshal(payload, &data); err != nil {
-		return fmt.Errorf("invalid payload: %v", err)
+func (eh *EventHandlers) HandlePlayerReady(playerID string, data PlayerReadyPayload) error {
+	if data.Ready == nil {
+		return fmt.Errorf("%w: ready field is required", errInvalidPayload)
 	}
 
 	// Set player ready status (this will fail for hosts, which is intended)
-	if err := eh.playerManager.SetPlayerReady(playerID, data.Ready); err != nil {
+	if err := eh.playerManager.SetPlayerReady(playerID, *data.Ready); err != nil {
 		return err
 	}
 
@@ -137,7 +115,7 @@ func (eh *EventHandlers) HandlePlayerReady(playerID string, payload json.RawMess
 }
 
 // HandleHostStartGame handles host starting the game
-func (eh *EventHandlers) HandleHostStartGame(playerID string, payload json.RawMessage) error {
+func (eh *EventHandlers) HandleHostStartGame(playerID string) error {
 	// Verify player is host
 	player, err := eh.playerManager.GetPlayer(playerID)
 	if err != nil {
@@ -145,218 +123,347 @@ func (eh *EventHandlers) HandleHostStartGame(playerID string, payload json.RawMe
 	}
 
 	if !player.IsHost {
-		return fmt.Errorf("only host can start the game")
+		return errHostOnlyStartGame
 	}
 
 	// Check if game can be started
 	canStart, reason := eh.gameManager.CanStartGame()
 	if !canStart {
-		return fmt.Errorf("cannot start game: %s", reason)
+		return newCodedError(CodeCannotStartGame, "cannot start game: %s", reason)
 	}
 
 	// Start the game
 	return eh.gameManager.StartGame()
 }
 
-// HandleResourceLocationVerified handles player location verification
-func (eh *EventHandlers) HandleResourceLocationVerified(playerID string, payload json.RawMessage) error {
-	var data struct {
-		VerifiedHash string `json:"verifiedHash"`
+// HandleHostUpdateSettings applies the host's lobby settings and shows the result to everyone
+func (eh *EventHandlers) HandleHostUpdateSettings(playerID string, update GameSettingsUpdate) error {
+	player, err := eh.playerManager.GetPlayer(playerID)
+	if err != nil {
+		return err
+	}
+
+	if !player.IsHost {
+		return errHostOnly
 	}
 
-	if err := json.Unmarshal(payload, &data); err != nil {
-		return fmt.Errorf("invalid payload: %v", err)
+	if _, err := eh.gameManager.UpdateSettings(update); err != nil {
+		return err
 	}
 
+	// Lobby status carries the effective settings, and the player count needed may have changed
+	eh.broadcastLobbyStatus()
+
+	return nil
+}
+
+// HandleResourceLocationVerified handles player location verification
+func (eh *EventHandlers) HandleResourceLocationVerified(playerID string, data LocationVerificationPayload) error {
 	// Update player location (will fail for hosts, which is intended)
 	return eh.playerManager.UpdatePlayerLocation(playerID, data.VerifiedHash)
 }
 
 // HandleTriviaAnswer handles player trivia answers
-func (eh *EventHandlers) HandleTriviaAnswer(playerID string, payload json.RawMessage) error {
-	// Check if required fields exist
-	var checkFields map[string]interface{}
-	if err := json.Unmarshal(payload, &checkFields); err != nil {
-		return fmt.Errorf("invalid payload: %v", err)
+func (eh *EventHandlers) HandleTriviaAnswer(playerID string, data TriviaAnswerPayload) error {
+	// Validate timestamp
+	if data.Timestamp <= 0 {
+		return errInvalidTimestamp
+	}
+
+	// Process the answer (hosts don't participate in trivia)
+	return eh.gameManager.ProcessTriviaAnswer(playerID, data.QuestionID, data.Answer)
+}
+
+// HandleSegmentCompleted handles puzzle segment completion
+func (eh *EventHandlers) HandleSegmentCompleted(playerID string, data SegmentCompletionPayload) error {
+	// Validate segment ID format (should be segment_[a-z][0-9])
+	if len(data.SegmentID) < 9 || !strings.HasPrefix(data.SegmentID, "segment_") {
+		return newCodedError(CodeValidationFailed, "invalid segment ID format")
 	}
 
-	if _, ok := checkFields["questionId"]; !ok {
-		return fmt.Errorf("invalid payload: questionId is required")
+	// Validate timestamp
+	if data.CompletionTimestamp <= 0 {
+		return errInvalidTimestamp
 	}
-	if _, ok := checkFields["answer"]; !ok {
-		return fmt.Errorf("invalid payload: answer is required")
+
+	// Process segment completion (hosts don't have puzzle segments)
+	return eh.gameManager.ProcessSegmentCompleted(playerID, data.SegmentID)
+}
+
+// HandleFragmentMoveRequest handles puzzle fragment movement
+func (eh *EventHandlers) HandleFragmentMoveRequest(playerID string, data FragmentMoveRequestPayload) error {
+	// Validate required fields
+	if data.FragmentID == "" {
+		return fmt.Errorf("%w: fragmentId is required", errInvalidPayload)
 	}
-	if _, ok := checkFields["timestamp"]; !ok {
-		return fmt.Errorf("invalid payload: timestamp is required")
+
+	if data.Timestamp <= 0 {
+		return errInvalidTimestamp
 	}
 
-	var data struct {
-		QuestionID string `json:"questionId"`
-		Answer     string `json:"answer"`
-		Timestamp  int64  `json:"timestamp"`
+	// Get current game state to validate position
+	eh.gameManager.mu.RLock()
+	gridSize := eh.gameManager.state.GridSize
+	eh.gameManager.mu.RUnlock()
+
+	// Validate position bounds
+	if err := validateGridPosition(data.NewPosition, gridSize); err != nil {
+		return errPositionOutOfBounds
 	}
 
-	if err := json.Unmarshal(payload, &data); err != nil {
-		return fmt.Errorf("invalid payload: %v", err)
+	// Process fragment move
+	return eh.gameManager.ProcessFragmentMove(playerID, data.FragmentID, data.NewPosition)
+}
+
+// HandlePuzzleResyncRequest handles a client that missed a puzzle delta asking for the full state
+func (eh *EventHandlers) HandlePuzzleResyncRequest(playerID string) error {
+	return eh.gameManager.ResyncPuzzle(playerID)
+}
+
+// HandleHostStartPuzzle handles host starting the puzzle phase
+func (eh *EventHandlers) HandleHostStartPuzzle(playerID string) error {
+	// Verify player is host
+	player, err := eh.playerManager.GetPlayer(playerID)
+	if err != nil {
+		return err
 	}
 
-	// Validate timestamp
-	if data.Timestamp <= 0 {
-		return fmt.Errorf("invalid timestamp")
+	if !player.IsHost {
+		return errHostOnlyStartPuzzle
 	}
 
-	// Process the answer (hosts don't participate in trivia)
-	return eh.gameManager.ProcessTriviaAnswer(playerID, data.QuestionID, data.Answer)
+	// Start puzzle timer
+	return eh.gameManager.StartPuzzle()
 }
 
-// HandleSegmentCompleted handles puzzle segment completion
-func (eh *EventHandlers) HandleSegmentCompleted(playerID string, payload json.RawMessage) error {
-	// Check if required fields exist
-	var checkFields map[string]interface{}
-	if err := json.Unmarshal(payload, &checkFields); err != nil {
-		return fmt.Errorf("invalid payload: %v", err)
+// HandleHostPause handles the host freezing the round or puzzle clock
+func (eh *EventHandlers) HandleHostPause(playerID string) error {
+	player, err := eh.playerManager.GetPlayer(playerID)
+	if err != nil {
+		return err
 	}
 
-	if _, ok := checkFields["segmentId"]; !ok {
-		return fmt.Errorf("invalid payload: segmentId is required")
+	if !player.IsHost {
+		return errHostOnly
 	}
-	if _, ok := checkFields["completionTimestamp"]; !ok {
-		return fmt.Errorf("invalid payload: completionTimestamp is required")
+
+	return eh.gameManager.Pause()
+}
+
+// HandleHostResume handles the host restarting a paused clock
+func (eh *EventHandlers) HandleHostResume(playerID string) error {
+	player, err := eh.playerManager.GetPlayer(playerID)
+	if err != nil {
+		return err
 	}
 
-	var data struct {
-		SegmentID           string `json:"segmentId"`
-		CompletionTimestamp int64  `json:"completionTimestamp"`
+	if !player.IsHost {
+		return errHostOnly
 	}
 
-	if err := json.Unmarshal(payload, &data); err != nil {
-		return fmt.Errorf("invalid payload: %v", err)
+	return eh.gameManager.Resume()
+}
+
+// HandleHostAddTime handles the host extending the running round or puzzle
+func (eh *EventHandlers) HandleHostAddTime(playerID string, data HostAddTimePayload) error {
+	player, err := eh.playerManager.GetPlayer(playerID)
+	if err != nil {
+		return err
 	}
 
-	// Validate segment ID format
-	if data.SegmentID == "" {
-		return fmt.Errorf("invalid payload: segmentId cannot be empty")
+	if !player.IsHost {
+		return errHostOnly
 	}
 
-	// Validate segment ID format (should be segment_[a-z][0-9])
-	if len(data.SegmentID) < 9 || !strings.HasPrefix(data.SegmentID, "segment_") {
-		return fmt.Errorf("invalid segment ID format")
+	if data.Seconds == nil {
+		return fmt.Errorf("%w: seconds is required", errInvalidPayload)
 	}
 
-	// Validate timestamp
-	if data.CompletionTimestamp <= 0 {
-		return fmt.Errorf("invalid timestamp")
+	return eh.gameManager.AddTime(*data.Seconds)
+}
+
+// HandleHostAbortGame handles the host ending the game early
+func (eh *EventHandlers) HandleHostAbortGame(playerID string, data HostAbortGamePayload) error {
+	player, err := eh.playerManager.GetPlayer(playerID)
+	if err != nil {
+		return err
 	}
 
-	// Process segment completion (hosts don't have puzzle segments)
-	return eh.gameManager.ProcessSegmentCompleted(playerID, data.SegmentID)
+	if !player.IsHost {
+		return errHostOnly
+	}
+
+	return eh.gameManager.AbortGame(data.ShowResults)
 }
 
-// HandleFragmentMoveRequest handles puzzle fragment movement
-func (eh *EventHandlers) HandleFragmentMoveRequest(playerID string, payload json.RawMessage) error {
-	// First, check if the required fields exist in the JSON
-	var checkFields map[string]interface{}
-	if err := json.Unmarshal(payload, &checkFields); err != nil {
-		return fmt.Errorf("invalid payload: %v", err)
+// HandleHostSkipPhase handles the host skipping the remaining trivia rounds
+func (eh *EventHandlers) HandleHostSkipPhase(playerID string) error {
+	player, err := eh.playerManager.GetPlayer(playerID)
+	if err != nil {
+		return err
 	}
 
-	// Check for required fields
-	if _, ok := checkFields["fragmentId"]; !ok {
-		return fmt.Errorf("invalid payload: fragmentId is required")
+	if !player.IsHost {
+		return errHostOnly
 	}
-	if _, ok := checkFields["newPosition"]; !ok {
-		return fmt.Errorf("invalid payload: newPosition is required")
+
+	return eh.gameManager.SkipToPuzzle()
+}
+
+// HandleHostRematch handles the host bringing everyone back to the lobby for another game
+func (eh *EventHandlers) HandleHostRematch(playerID string) error {
+	player, err := eh.playerManager.GetPlayer(playerID)
+	if err != nil {
+		return err
 	}
-	if _, ok := checkFields["timestamp"]; !ok {
-		return fmt.Errorf("invalid payload: timestamp is required")
+
+	if !player.IsHost {
+		return errHostOnly
 	}
 
-	// Now unmarshal into the struct
-	var data struct {
-		FragmentID  string  `json:"fragmentId"`
-		NewPosition GridPos `json:"newPosition"`
-		Timestamp   int64   `json:"timestamp"`
+	if err := eh.gameManager.Rematch(); err != nil {
+		return err
 	}
 
-	if err := json.Unmarshal(payload, &data); err != nil {
-		return fmt.Errorf("invalid payload: %v", err)
+	// Everyone is still ready, so the lobby can show the host they may start right away
+	eh.broadcastLobbyStatus()
+	return nil
+}
+
+// HandleHostKickPlayer handles the host removing a player from the game, optionally for good
+func (eh *EventHandlers) HandleHostKickPlayer(playerID string, data HostKickPlayerPayload) error {
+	target, err := eh.hostTarget(playerID, data.PlayerID)
+	if err != nil {
+		return err
 	}
 
-	// Validate required fields
-	if data.FragmentID == "" {
-		return fmt.Errorf("invalid payload: fragmentId is required")
+	sendToPlayer(target, MsgPlayerKicked, PlayerKickedPayload{
+		Message: "You were removed from the game by the host",
+		Banned:  data.Ban,
+	})
+
+	// Out of the player manager first, so the closing connection isn't handled as a disconnect
+	if _, err := eh.playerManager.RemovePlayer(target.ID, data.Ban); err != nil {
+		return err
 	}
+	eh.gameManager.RemovePlayer(target.ID)
+	closePlayerConnection(target, websocket.ClosePolicyViolation, "removed by host")
 
-	if data.Timestamp <= 0 {
-		return fmt.Errorf("invalid timestamp")
+	log.Printf("Host removed player %s (banned: %t)", target.ID, data.Ban)
+
+	if eh.gameManager.GetPhase() == PhaseSetup {
+		eh.broadcastLobbyStatus()
 	}
+	return nil
+}
 
-	// Get current game state to validate position
-	eh.gameManager.mu.RLock()
-	gridSize := eh.gameManager.state.GridSize
-	eh.gameManager.mu.RUnlock()
+// HandleHostRenamePlayer handles the host changing a player's display name
+func (eh *EventHandlers) HandleHostRenamePlayer(playerID string, data HostRenamePlayerPayload) error {
+	target, err := eh.hostTarget(playerID, data.PlayerID)
+	if err != nil {
+		return err
+	}
 
-	// Validate position bounds
-	if err := validateGridPosition(data.NewPosition, gridSize); err != nil {
-		return fmt.Errorf("position out of bounds")
+	if err := eh.playerManager.RenamePlayer(target.ID, data.Name); err != nil {
+		return err
 	}
+	eh.gameManager.RenamePlayer(target.ID, data.Name)
+	eh.sendPlayerUpdate(target)
 
-	// Process fragment move
-	return eh.gameManager.ProcessFragmentMove(playerID, data.FragmentID, data.NewPosition)
+	if eh.gameManager.GetPhase() == PhaseSetup {
+		eh.broadcastLobbyStatus()
+	}
+	return nil
 }
 
-// HandleHostStartPuzzle handles host starting the puzzle phase
-func (eh *EventHandlers) HandleHostStartPuzzle(playerID string, payload json.RawMessage) error {
-	// Verify player is host
-	player, err := eh.playerManager.GetPlayer(playerID)
+// HandleHostAssignPlayer handles the host setting a player's role or specialties during setup
+func (eh *EventHandlers) HandleHostAssignPlayer(playerID string, data HostAssignPlayerPayload) error {
+	target, err := eh.hostTarget(playerID, data.PlayerID)
 	if err != nil {
 		return err
 	}
 
-	if !player.IsHost {
-		return fmt.Errorf("only host can start puzzle phase")
+	if eh.gameManager.GetPhase() != PhaseSetup {
+		return errPlayersLocked
 	}
 
-	// Start puzzle timer
-	return eh.gameManager.StartPuzzle()
+	// The host may go over a role's usual share of the players
+	if data.Role != nil {
+		if err := eh.playerManager.AssignPlayerRole(target.ID, *data.Role); err != nil {
+			return err
+		}
+	}
+	if data.Specialties != nil {
+		if err := eh.playerManager.SetPlayerSpecialties(target.ID, *data.Specialties); err != nil {
+			return err
+		}
+	}
+
+	eh.sendPlayerUpdate(target)
+	eh.broadcastLobbyStatus()
+	return nil
 }
 
-// HandlePieceRecommendationRequest handles piece recommendation requests
-func (eh *EventHandlers) HandlePieceRecommendationRequest(playerID string, payload json.RawMessage) error {
-	// Check if required fields exist
-	var checkFields map[string]interface{}
-	if err := json.Unmarshal(payload, &checkFields); err != nil {
-		return fmt.Errorf("invalid payload: %v", err)
+// HandlePlayerSetName handles a player choosing their own display name in the lobby
+func (eh *EventHandlers) HandlePlayerSetName(playerID string, data PlayerSetNamePayload) error {
+	if eh.gameManager.GetPhase() != PhaseSetup {
+		return errNamesLocked
 	}
 
-	if _, ok := checkFields["toPlayerId"]; !ok {
-		return fmt.Errorf("invalid payload: toPlayerId is required")
+	if err := eh.playerManager.SetPlayerName(playerID, data.Name); err != nil {
+		return err
 	}
-	if _, ok := checkFields["fromFragmentId"]; !ok {
-		return fmt.Errorf("invalid payload: fromFragmentId is required")
+
+	player, err := eh.playerManager.GetPlayer(playerID)
+	if err != nil {
+		return err
 	}
-	if _, ok := checkFields["toFragmentId"]; !ok {
-		return fmt.Errorf("invalid payload: toFragmentId is required")
+
+	eh.gameManager.RenamePlayer(playerID, data.Name)
+	eh.sendPlayerUpdate(player)
+	eh.broadcastLobbyStatus()
+	return nil
+}
+
+// hostTarget checks that playerID is the host and returns the non-host player targetID names
+func (eh *EventHandlers) hostTarget(playerID, targetID string) (*Player, error) {
+	player, err := eh.playerManager.GetPlayer(playerID)
+	if err != nil {
+		return nil, err
 	}
-	if _, ok := checkFields["suggestedFromPos"]; !ok {
-		return fmt.Errorf("invalid payload: suggestedFromPos is required")
+
+	if !player.IsHost {
+		return nil, errHostOnly
 	}
-	if _, ok := checkFields["suggestedToPos"]; !ok {
-		return fmt.Errorf("invalid payload: suggestedToPos is required")
+
+	target, err := eh.playerManager.GetPlayer(targetID)
+	if err != nil {
+		return nil, err
 	}
 
-	var data struct {
-		ToPlayerID       string  `json:"toPlayerId"`
-		FromFragmentID   string  `json:"fromFragmentId"`
-		ToFragmentID     string  `json:"toFragmentId"`
-		SuggestedFromPos GridPos `json:"suggestedFromPos"`
-		SuggestedToPos   GridPos `json:"suggestedToPos"`
+	if target.IsHost {
+		return nil, errHostTarget
 	}
+	return target, nil
+}
 
-	if err := json.Unmarshal(payload, &data); err != nil {
-		return fmt.Errorf("invalid payload: %v", err)
+// sendPlayerUpdate tells a player their name, role and specialties after they or the host changed them
+func (eh *EventHandlers) sendPlayerUpdate(player *Player) {
+	player.mu.RLock()
+	update := PlayerUpdate{
+		PlayerID:    player.ID,
+		Name:        player.Name,
+		Role:        player.Role,
+		Specialties: append([]string(nil), player.Specialties...),
+		Ready:       player.Ready,
 	}
+	player.mu.RUnlock()
 
+	sendToPlayer(player, MsgPlayerUpdate, update)
+}
+
+// HandlePieceRecommendationRequest handles piece recommendation requests
+func (eh *EventHandlers) HandlePieceRecommendationRequest(playerID string, data PieceRecommendationRequestPayload) error {
 	// Get current game state to validate positions
 	eh.gameManager.mu.RLock()
 	gridSize := eh.gameManager.state.GridSize
@@ -364,15 +471,15 @@ func (eh *EventHandlers) HandlePieceRecommendationRequest(playerID string, paylo
 
 	// Validate positions
 	if err := validateGridPosition(data.SuggestedFromPos, gridSize); err != nil {
-		return fmt.Errorf("position out of bounds")
+		return errPositionOutOfBounds
 	}
 	if err := validateGridPosition(data.SuggestedToPos, gridSize); err != nil {
-		return fmt.Errorf("position out of bounds")
+		return errPositionOutOfBounds
 	}
 
 	// Validate that target player exists
 	if _, err := eh.playerManager.GetPlayer(data.ToPlayerID); err != nil {
-		return fmt.Errorf("target player not found")
+		return newCodedError(CodePlayerNotFound, "target player not found")
 	}
 
 	// Process the recommendation (no custom message support)
@@ -388,31 +495,13 @@ func (eh *EventHandlers) HandlePieceRecommendationRequest(playerID string, paylo
 }
 
 // HandlePieceRecommendationResponse handles piece recommendation responses
-func (eh *EventHandlers) HandlePieceRecommendationResponse(playerID string, payload json.RawMessage) error {
-	// Check if required fields exist
-	var checkFields map[string]interface{}
-	if err := json.Unmarshal(payload, &checkFields); err != nil {
-		return fmt.Errorf("invalid payload: %v", err)
-	}
-
-	if _, ok := checkFields["recommendationId"]; !ok {
-		return fmt.Errorf("invalid payload: recommendationId is required")
-	}
-	if _, ok := checkFields["accepted"]; !ok {
-		return fmt.Errorf("invalid payload: accepted is required")
-	}
-
-	var data struct {
-		RecommendationID string `json:"recommendationId"`
-		Accepted         bool   `json:"accepted"`
-	}
-
-	if err := json.Unmarshal(payload, &data); err != nil {
-		return fmt.Errorf("invalid payload: %v", err)
+func (eh *EventHandlers) HandlePieceRecommendationResponse(playerID string, data PieceRecommendationResponsePayload) error {
+	if data.Accepted == nil {
+		return fmt.Errorf("%w: accepted is required", errInvalidPayload)
 	}
 
 	// Process the response
-	return eh.gameManager.ProcessPieceRecommendationResponse(playerID, data.RecommendationID, data.Accepted)
+	return eh.gameManager.ProcessPieceRecommendationResponse(playerID, data.RecommendationID, *data.Accepted)
 }
 
 // Helper functions
@@ -427,12 +516,17 @@ func (eh *EventHandlers) broadcastLobbyStatus() {
 	readyCount := eh.playerManager.GetReadyCount()
 	nonHostCount := eh.playerManager.GetConnectedNonHostPlayers()
 	hasHost := eh.playerManager.IsHostConnected()
+	eh.gameManager.mu.RLock()
+	difficulty := eh.gameManager.state.Difficulty
+	settings := eh.gameManager.state.Settings
+	presets := eh.gameManager.balance.PresetList()
+	eh.gameManager.mu.RUnlock()
 
 	// Check for host
 	if !hasHost {
 		waitingMessage = "Waiting for host to connect..."
-	} else if len(nonHostCount) < constants.MinPlayers {
-		waitingMessage = fmt.Sprintf("Waiting for %d more players...", constants.MinPlayers-len(nonHostCount))
+	} else if len(nonHostCount) < settings.MinPlayers {
+		waitingMessage = fmt.Sprintf("Waiting for %d more players...", settings.MinPlayers-len(nonHostCount))
 	} else if readyCount < connectedCount {
 		waitingMessage = fmt.Sprintf("Waiting for all players to be ready (%d/%d)...", readyCount, connectedCount)
 	} else {
@@ -444,13 +538,17 @@ func (eh *EventHandlers) broadcastLobbyStatus() {
 		}
 	}
 
-	status := map[string]interface{}{
-		"currentPlayers": connectedCount,
-		"nonHostPlayers": len(nonHostCount),
-		"playerRoles":    roleDistribution,
-		"hasHost":        hasHost,
-		"gameStarting":   false,
-		"waitingMessage": waitingMessage,
+	status := LobbyStatusPayload{
+		CurrentPlayers: connectedCount,
+		NonHostPlayers: len(nonHostCount),
+		PlayerRoles:    roleDistribution,
+		HasHost:        hasHost,
+		GameStarting:   false,
+		WaitingMessage: waitingMessage,
+		Difficulty:     difficulty,
+		Settings:       settings,
+		Presets:        presets,
+		Players:        eh.playerManager.GetLobbyPlayers(),
 	}
 
 	eh.broadcastChan <- BroadcastMessage{
@@ -2,17 +2,19 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
 func createTestEventHandlers() (*EventHandlers, *PlayerManager, *GameManager, chan BroadcastMessage) {
 	playerMgr := NewPlayerManager()
-	triviaMgr := NewTriviaManager()
+	triviaMgr := NewTriviaManager(realClock{}, testSeed)
 	broadcastChan := make(chan BroadcastMessage, 256)
-	gameMgr := NewGameManager(playerMgr, triviaMgr, broadcastChan)
+	gameMgr := NewGameManager(playerMgr, triviaMgr, broadcastChan, realClock{}, testSeed)
 	eventHandlers := NewEventHandlers(gameMgr, playerMgr, broadcastChan)
 	return eventHandlers, playerMgr, gameMgr, broadcastChan
 }
@@ -36,33 +38,27 @@ func TestHandleRoleSelection(t *testing.T) {
 
 	tests := []struct {
 		name    string
-		payload json.RawMessage
+		payload RoleSelectionPayload
 		wantErr bool
 		errMsg  string
 	}{
 		{
 			name:    "Valid role selection",
-			payload: json.RawMessage(`{"role": "detective"}`),
+			payload: RoleSelectionPayload{Role: "detective"},
 			wantErr: false,
 		},
 		{
 			name:    "Invalid role",
-			payload: json.RawMessage(`{"role": "superhero"}`),
+			payload: RoleSelectionPayload{Role: "superhero"},
 			wantErr: true,
 			errMsg:  "invalid role",
 		},
 		{
 			name:    "Missing role field",
-			payload: json.RawMessage(`{}`),
+			payload: RoleSelectionPayload{},
 			wantErr: true,
 			errMsg:  "invalid role",
 		},
-		{
-			name:    "Invalid JSON",
-			payload: json.RawMessage(`{"role": "detective"`),
-			wantErr: true,
-			errMsg:  "unexpected end of JSON input",
-		},
 	}
 
 	for _, tt := range tests {
@@ -83,7 +79,7 @@ func TestHandleRoleSelection(t *testing.T) {
 	}
 
 	// Test with non-existent player
-	err := eh.HandleRoleSelection(uuid.New().String(), json.RawMessage(`{"role": "detective"}`))
+	err := eh.HandleRoleSelection(uuid.New().String(), RoleSelectionPayload{Role: "detective"})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "player not found")
 }
@@ -98,35 +94,35 @@ func TestHandleTriviaSpecialtySelection(t *testing.T) {
 
 	tests := []struct {
 		name    string
-		payload json.RawMessage
+		payload SpecialtySelectionPayload
 		wantErr bool
 		errMsg  string
 	}{
 		{
 			name:    "Valid single specialty",
-			payload: json.RawMessage(`{"specialties": ["science"]}`),
+			payload: SpecialtySelectionPayload{Specialties: []string{"science"}},
 			wantErr: false,
 		},
 		{
 			name:    "Valid two specialties",
-			payload: json.RawMessage(`{"specialties": ["science", "history"]}`),
+			payload: SpecialtySelectionPayload{Specialties: []string{"science", "history"}},
 			wantErr: false,
 		},
 		{
 			name:    "Too many specialties",
-			payload: json.RawMessage(`{"specialties": ["science", "history", "geography"]}`),
+			payload: SpecialtySelectionPayload{Specialties: []string{"science", "history", "geography"}},
 			wantErr: true,
 			errMsg:  "must select 1-2 specialties",
 		},
 		{
 			name:    "Invalid specialty",
-			payload: json.RawMessage(`{"specialties": ["magic"]}`),
+			payload: SpecialtySelectionPayload{Specialties: []string{"magic"}},
 			wantErr: true,
 			errMsg:  "invalid specialty",
 		},
 		{
 			name:    "Missing specialties field",
-			payload: json.RawMessage(`{}`),
+			payload: SpecialtySelectionPayload{},
 			wantErr: true,
 			errMsg:  "must select 1-2 specialties",
 		},
@@ -156,29 +152,29 @@ func TestHandleResourceLocationVerified(t *testing.T) {
 
 	tests := []struct {
 		name    string
-		payload json.RawMessage
+		payload LocationVerificationPayload
 		wantErr bool
 		errMsg  string
 	}{
 		{
 			name:    "Valid anchor station hash",
-			payload: json.RawMessage(`{"verifiedHash": "HASH_ANCHOR_STATION_2025"}`),
+			payload: LocationVerificationPayload{VerifiedHash: "HASH_ANCHOR_STATION_2025"},
 			wantErr: false,
 		},
 		{
 			name:    "Valid chronos station hash",
-			payload: json.RawMessage(`{"verifiedHash": "HASH_CHRONOS_STATION_2025"}`),
+			payload: LocationVerificationPayload{VerifiedHash: "HASH_CHRONOS_STATION_2025"},
 			wantErr: false,
 		},
 		{
 			name:    "Invalid hash",
-			payload: json.RawMessage(`{"verifiedHash": "INVALID_HASH"}`),
+			payload: LocationVerificationPayload{VerifiedHash: "INVALID_HASH"},
 			wantErr: true,
 			errMsg:  "invalid resource station hash",
 		},
 		{
 			name:    "Missing hash field",
-			payload: json.RawMessage(`{}`),
+			payload: LocationVerificationPayload{},
 			wantErr: true,
 			errMsg:  "invalid resource station hash",
 		},
@@ -198,7 +194,7 @@ func TestHandleResourceLocationVerified(t *testing.T) {
 
 	// Test wrong phase
 	gm.state.Phase = PhaseSetup
-	err := eh.HandleResourceLocationVerified(playerID, json.RawMessage(`{"verifiedHash": "HASH_ANCHOR_STATION_2025"}`))
+	err := eh.HandleResourceLocationVerified(playerID, LocationVerificationPayload{VerifiedHash: "HASH_ANCHOR_STATION_2025"})
 	if err != nil {
 		// Test passes if we get any error when in wrong phase
 		_ = err
@@ -216,30 +212,18 @@ func TestHandleTriviaAnswer(t *testing.T) {
 
 	tests := []struct {
 		name    string
-		payload json.RawMessage
+		payload TriviaAnswerPayload
 		wantErr bool
 		errMsg  string
 	}{
 		{
 			name:    "Valid answer format",
-			payload: json.RawMessage(`{"questionId": "test_question_1", "answer": "Paris", "timestamp": 1640995200}`),
+			payload: TriviaAnswerPayload{QuestionID: "test_question_1", Answer: "Paris", Timestamp: 1640995200},
 			wantErr: false, // Will fail because question doesn't exist, but format is valid
 		},
-		{
-			name:    "Missing question ID",
-			payload: json.RawMessage(`{"answer": "Paris", "timestamp": 1640995200}`),
-			wantErr: true,
-			errMsg:  "invalid payload",
-		},
-		{
-			name:    "Missing answer",
-			payload: json.RawMessage(`{"questionId": "test_question_1", "timestamp": 1640995200}`),
-			wantErr: true,
-			errMsg:  "invalid payload",
-		},
 		{
 			name:    "Invalid timestamp",
-			payload: json.RawMessage(`{"questionId": "test_question_1", "answer": "Paris", "timestamp": 0}`),
+			payload: TriviaAnswerPayload{QuestionID: "test_question_1", Answer: "Paris", Timestamp: 0},
 			wantErr: true,
 			errMsg:  "invalid timestamp",
 		},
@@ -261,7 +245,7 @@ func TestHandleTriviaAnswer(t *testing.T) {
 
 	// Test wrong phase
 	gm.state.Phase = PhaseSetup
-	err := eh.HandleTriviaAnswer(playerID, json.RawMessage(`{"questionId": "test_question_1", "answer": "Paris", "timestamp": 1640995200}`))
+	err := eh.HandleTriviaAnswer(playerID, TriviaAnswerPayload{QuestionID: "test_question_1", Answer: "Paris", Timestamp: 1640995200})
 	assert.Error(t, err)
 }
 
@@ -276,30 +260,30 @@ func TestHandleSegmentCompleted(t *testing.T) {
 
 	tests := []struct {
 		name    string
-		payload json.RawMessage
+		payload SegmentCompletionPayload
 		wantErr bool
 		errMsg  string
 	}{
 		{
 			name:    "Valid segment completion",
-			payload: json.RawMessage(`{"segmentId": "segment_a1", "completionTimestamp": 1640995200}`),
+			payload: SegmentCompletionPayload{SegmentID: "segment_a1", CompletionTimestamp: 1640995200},
 			wantErr: false, // May still error in game manager processing
 		},
 		{
 			name:    "Invalid segment ID format",
-			payload: json.RawMessage(`{"segmentId": "invalid_segment", "completionTimestamp": 1640995200}`),
+			payload: SegmentCompletionPayload{SegmentID: "invalid_segment", CompletionTimestamp: 1640995200},
 			wantErr: true,
 			errMsg:  "invalid segment ID format",
 		},
 		{
 			name:    "Missing segment ID",
-			payload: json.RawMessage(`{"completionTimestamp": 1640995200}`),
+			payload: SegmentCompletionPayload{CompletionTimestamp: 1640995200},
 			wantErr: true,
-			errMsg:  "invalid payload",
+			errMsg:  "invalid segment ID format",
 		},
 		{
 			name:    "Invalid timestamp",
-			payload: json.RawMessage(`{"segmentId": "segment_a1", "completionTimestamp": 0}`),
+			payload: SegmentCompletionPayload{SegmentID: "segment_a1", CompletionTimestamp: 0},
 			wantErr: true,
 			errMsg:  "invalid timestamp",
 		},
@@ -331,30 +315,24 @@ func TestHandleFragmentMoveRequest(t *testing.T) {
 
 	tests := []struct {
 		name    string
-		payload json.RawMessage
+		payload FragmentMoveRequestPayload
 		wantErr bool
 		errMsg  string
 	}{
 		{
 			name:    "Valid fragment move",
-			payload: json.RawMessage(`{"fragmentId": "fragment_player-uuid", "newPosition": {"x": 2, "y": 1}, "timestamp": 1640995200}`),
+			payload: FragmentMoveRequestPayload{FragmentID: "fragment_player-uuid", NewPosition: GridPos{X: 2, Y: 1}, Timestamp: 1640995200},
 			wantErr: false, // May error in game manager
 		},
 		{
 			name:    "Invalid position",
-			payload: json.RawMessage(`{"fragmentId": "fragment_player-uuid", "newPosition": {"x": 4, "y": 4}, "timestamp": 1640995200}`),
+			payload: FragmentMoveRequestPayload{FragmentID: "fragment_player-uuid", NewPosition: GridPos{X: 4, Y: 4}, Timestamp: 1640995200},
 			wantErr: true,
 			errMsg:  "position out of bounds",
 		},
 		{
 			name:    "Missing fragment ID",
-			payload: json.RawMessage(`{"newPosition": {"x": 2, "y": 1}, "timestamp": 1640995200}`),
-			wantErr: true,
-			errMsg:  "invalid payload",
-		},
-		{
-			name:    "Missing position",
-			payload: json.RawMessage(`{"fragmentId": "fragment_player-uuid", "timestamp": 1640995200}`),
+			payload: FragmentMoveRequestPayload{NewPosition: GridPos{X: 2, Y: 1}, Timestamp: 1640995200},
 			wantErr: true,
 			errMsg:  "invalid payload",
 		},
@@ -388,26 +366,233 @@ func TestHandleHostStartGame(t *testing.T) {
 	regularPlayerID := regularPlayer.ID
 
 	// Test valid empty payload
-	err := eh.HandleHostStartGame(hostID, json.RawMessage(`{}`))
+	err := eh.HandleHostStartGame(hostID)
 	// Will error because not enough players, but should pass host validation
 	_ = err
 
 	// Test non-host trying to start game
-	err = eh.HandleHostStartGame(regularPlayerID, json.RawMessage(`{}`))
+	err = eh.HandleHostStartGame(regularPlayerID)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "only host can start the game")
 
 	// Test non-existent player
-	err = eh.HandleHostStartGame(uuid.New().String(), json.RawMessage(`{}`))
+	err = eh.HandleHostStartGame(uuid.New().String())
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "player not found")
 
 	// Test wrong phase
 	gm.state.Phase = PhaseResourceGathering
-	err = eh.HandleHostStartGame(hostID, json.RawMessage(`{}`))
+	err = eh.HandleHostStartGame(hostID)
 	assert.Error(t, err)
 }
 
+func TestHandleHostUpdateSettings(t *testing.T) {
+	eh, pm, gm, broadcastChan := createTestEventHandlers()
+
+	host := pm.CreatePlayer(nil, true)
+	player := pm.CreatePlayer(nil, false)
+
+	// Only the host may change settings
+	err := eh.HandleHostUpdateSettings(player.ID, GameSettingsUpdate{ResourceRounds: intPtr(3)})
+	assert.EqualError(t, err, constants.ErrHostOnly)
+
+	err = eh.HandleHostUpdateSettings(host.ID, GameSettingsUpdate{ResourceRounds: intPtr(3), MinPlayers: intPtr(1), Difficulty: stringPtr("easy")})
+	assert.NoError(t, err)
+
+	// The lobby sees the effective settings, and one player is now enough to start
+	var status *LobbyStatusPayload
+	for len(broadcastChan) > 0 {
+		msg := <-broadcastChan
+		if msg.Type == MsgGameLobbyStatus {
+			payload := msg.Payload.(LobbyStatusPayload)
+			status = &payload
+		}
+	}
+	if assert.NotNil(t, status) {
+		assert.Equal(t, 3, status.Settings.ResourceRounds)
+		assert.Equal(t, 1, status.Settings.MinPlayers)
+		assert.Equal(t, constants.ResourceGatheringRoundDuration, status.Settings.RoundDuration)
+		assert.Equal(t, "easy", status.Difficulty)
+		assert.NotContains(t, status.WaitingMessage, "more players")
+	}
+
+	// Settings are locked once the game starts
+	gm.state.Phase = PhaseResourceGathering
+	err = eh.HandleHostUpdateSettings(host.ID, GameSettingsUpdate{ResourceRounds: intPtr(4)})
+	assert.EqualError(t, err, constants.ErrSettingsLocked)
+	assert.Equal(t, 3, gm.GetSettings().ResourceRounds)
+}
+
+func TestHandleHostTimerControls(t *testing.T) {
+	eh, pm, gm, broadcastChan := createTestEventHandlers()
+
+	host := pm.CreatePlayer(nil, true)
+	player := pm.CreatePlayer(nil, false)
+
+	gm.mu.Lock()
+	gm.state.Phase = PhaseResourceGathering
+	gm.state.CurrentRound = 1
+	gm.state.RoundStartTime = gm.clock.Now()
+	gm.mu.Unlock()
+
+	// Only the host controls the clock
+	assert.EqualError(t, eh.HandleHostPause(player.ID), constants.ErrHostOnly)
+	assert.EqualError(t, eh.HandleHostAddTime(player.ID, HostAddTimePayload{Seconds: intPtr(30)}), constants.ErrHostOnly)
+	assert.EqualError(t, eh.HandleHostResume(player.ID), constants.ErrHostOnly)
+
+	assert.NoError(t, eh.HandleHostPause(host.ID))
+	assert.NoError(t, eh.HandleHostAddTime(host.ID, HostAddTimePayload{Seconds: intPtr(30)}))
+	assert.NoError(t, eh.HandleHostResume(host.ID))
+
+	// Every change reaches all players with the new clock
+	var updates []TimerUpdate
+	for len(broadcastChan) > 0 {
+		msg := <-broadcastChan
+		if msg.Type == MsgGameTimerUpdate {
+			updates = append(updates, msg.Payload.(TimerUpdate))
+		}
+	}
+	if assert.Len(t, updates, 3) {
+		assert.Equal(t, TimerActionPaused, updates[0].Action)
+		assert.True(t, updates[0].Paused)
+		assert.Equal(t, TimerActionTimeAdded, updates[1].Action)
+		assert.Equal(t, 30, updates[1].AddedSeconds)
+		assert.True(t, updates[1].Paused)
+		assert.Equal(t, TimerActionResumed, updates[2].Action)
+		assert.False(t, updates[2].Paused)
+		assert.NotZero(t, updates[2].Deadline)
+		assert.Equal(t, constants.ResourceGatheringRoundDuration+30, updates[2].TimeRemaining)
+	}
+}
+
+func TestHandleHostGameControls(t *testing.T) {
+	eh, pm, gm, broadcastChan := createTestEventHandlers()
+	defer gm.Stop()
+
+	host := pm.CreatePlayer(nil, true)
+	player := pm.CreatePlayer(nil, false)
+
+	// Only the host may end, skip or restart a game
+	assert.EqualError(t, eh.HandleHostAbortGame(player.ID, HostAbortGamePayload{}), constants.ErrHostOnly)
+	assert.EqualError(t, eh.HandleHostSkipPhase(player.ID), constants.ErrHostOnly)
+	assert.EqualError(t, eh.HandleHostRematch(player.ID), constants.ErrHostOnly)
+
+	assert.EqualError(t, eh.HandleHostSkipPhase(host.ID), constants.ErrNoRoundsToSkip)
+	assert.EqualError(t, eh.HandleHostRematch(host.ID), constants.ErrGameNotOver)
+
+	SimulateGamePhase(gm, PhaseResourceGathering)
+	assert.NoError(t, eh.HandleHostAbortGame(host.ID, HostAbortGamePayload{ShowResults: true}))
+	assert.Equal(t, PhasePostGame, gm.GetPhase())
+
+	for len(broadcastChan) > 0 {
+		<-broadcastChan
+	}
+	assert.NoError(t, eh.HandleHostRematch(host.ID))
+	assert.Equal(t, PhaseSetup, gm.GetPhase())
+
+	// Players are told they stay in the game, then see the lobby again
+	var types []string
+	for len(broadcastChan) > 0 {
+		msg := <-broadcastChan
+		types = append(types, msg.Type)
+		if msg.Type == MsgGameReset {
+			payload := msg.Payload.(GameResetPayload)
+			assert.False(t, payload.ReconnectRequired)
+			assert.True(t, payload.Rematch)
+		}
+	}
+	assert.Equal(t, []string{MsgGameReset, MsgGameLobbyStatus}, types)
+}
+
+func TestHandleHostModeration(t *testing.T) {
+	eh, pm, gm, _ := createTestEventHandlers()
+	defer gm.Stop()
+
+	host := pm.CreatePlayer(nil, true)
+	player := pm.CreatePlayer(nil, false)
+	other := pm.CreatePlayer(nil, false)
+	rename := HostRenamePlayerPayload{PlayerID: player.ID, Name: "Grace"}
+	assign := HostAssignPlayerPayload{PlayerID: player.ID, Role: stringPtr("janitor"), Specialties: &[]string{"history"}}
+
+	// Only the host moderates, and never themselves
+	assert.EqualError(t, eh.HandleHostRenamePlayer(other.ID, rename), constants.ErrHostOnly)
+	assert.EqualError(t, eh.HandleHostKickPlayer(other.ID, HostKickPlayerPayload{PlayerID: player.ID}), constants.ErrHostOnly)
+	hostTarget := HostRenamePlayerPayload{PlayerID: host.ID, Name: "Boss"}
+	assert.EqualError(t, eh.HandleHostRenamePlayer(host.ID, hostTarget), constants.ErrHostTarget)
+
+	assert.NoError(t, eh.HandleHostRenamePlayer(host.ID, rename))
+	assert.Equal(t, "Grace", player.Name)
+
+	assert.NoError(t, eh.HandleHostAssignPlayer(host.ID, assign))
+	assert.Equal(t, constants.RoleJanitor, player.Role)
+	assert.Equal(t, []string{"history"}, player.Specialties)
+	assert.True(t, player.Ready)
+
+	// Roles are locked once the game starts, names are not
+	SimulateGamePhase(gm, PhaseResourceGathering)
+	assert.EqualError(t, eh.HandleHostAssignPlayer(host.ID, assign), constants.ErrPlayersLocked)
+
+	gm.mu.Lock()
+	gm.state.PlayerAnalytics[player.ID] = &PlayerAnalytics{PlayerID: player.ID, PlayerName: "Grace"}
+	gm.mu.Unlock()
+	assert.NoError(t, eh.HandleHostRenamePlayer(host.ID, HostRenamePlayerPayload{PlayerID: player.ID, Name: "Grace H"}))
+	assert.Equal(t, "Grace H", gm.state.PlayerAnalytics[player.ID].PlayerName)
+
+	// Kicking mid-puzzle hands the player's fragment to everyone, as a disconnect would
+	gm.startPuzzlePhase()
+	fragmentID := fmt.Sprintf("fragment_%s", player.ID)
+	kick := HostKickPlayerPayload{PlayerID: player.ID, Ban: true}
+	assert.NoError(t, eh.HandleHostKickPlayer(host.ID, kick))
+
+	_, err := pm.GetPlayer(player.ID)
+	assert.Error(t, err)
+	assert.True(t, pm.IsBanned(player.ID))
+	gm.mu.RLock()
+	fragment := gm.state.PuzzleFragments[fragmentID]
+	gm.mu.RUnlock()
+	if assert.NotNil(t, fragment) {
+		assert.True(t, fragment.IsUnassigned)
+		assert.Empty(t, fragment.PlayerID)
+		assert.Equal(t, "anyone", fragment.MovableBy)
+	}
+
+	// The kicked player is no longer a valid target
+	assert.Error(t, eh.HandleHostKickPlayer(host.ID, kick))
+}
+
+func TestHandlePlayerSetName(t *testing.T) {
+	eh, pm, gm, broadcastChan := createTestEventHandlers()
+	defer gm.Stop()
+
+	pm.CreatePlayer(nil, true)
+	player := pm.CreatePlayer(nil, false)
+	other := pm.CreatePlayer(nil, false)
+
+	assert.NoError(t, eh.HandlePlayerSetName(player.ID, PlayerSetNamePayload{Name: "Ada"}))
+	assert.Equal(t, "Ada", player.Name)
+	assert.EqualError(t, eh.HandlePlayerSetName(other.ID, PlayerSetNamePayload{Name: "ada"}), constants.ErrNameTaken)
+
+	// Everyone sees the new name in the lobby, without the player IDs
+	var status *LobbyStatusPayload
+	for len(broadcastChan) > 0 {
+		msg := <-broadcastChan
+		if msg.Type == MsgGameLobbyStatus {
+			payload := msg.Payload.(LobbyStatusPayload)
+			status = &payload
+		}
+	}
+	if assert.NotNil(t, status) {
+		players := status.Players
+		if assert.Len(t, players, 2) {
+			assert.Equal(t, "Ada", players[0].Name)
+			assert.Equal(t, other.Name, players[1].Name)
+		}
+	}
+
+	SimulateGamePhase(gm, PhaseResourceGathering)
+	assert.EqualError(t, eh.HandlePlayerSetName(other.ID, PlayerSetNamePayload{Name: "Bob"}), constants.ErrNamesLocked)
+}
+
 func TestHandleHostStartPuzzle(t *testing.T) {
 	eh, pm, gm, _ := createTestEventHandlers()
 
@@ -425,12 +610,12 @@ func TestHandleHostStartPuzzle(t *testing.T) {
 	gm.state.Phase = PhaseResourceGathering
 
 	// Test valid host action
-	err := eh.HandleHostStartPuzzle(hostID, json.RawMessage(`{}`))
+	err := eh.HandleHostStartPuzzle(hostID)
 	// May error in game manager, but should pass host validation
 	_ = err
 
 	// Test non-host trying to start puzzle
-	err = eh.HandleHostStartPuzzle(regularPlayerID, json.RawMessage(`{}`))
+	err = eh.HandleHostStartPuzzle(regularPlayerID)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "only host can start puzzle phase")
 }
@@ -451,44 +636,44 @@ func TestHandlePieceRecommendationRequest(t *testing.T) {
 
 	tests := []struct {
 		name    string
-		payload string
+		payload PieceRecommendationRequestPayload
 		wantErr bool
 		errMsg  string
 	}{
 		{
 			name: "Valid recommendation",
-			payload: `{
-				"toPlayerId": "` + player2.ID + `",
-				"fromFragmentId": "fragment_player1",
-				"toFragmentId": "fragment_player2",
-				"suggestedFromPos": {"x": 1, "y": 1},
-				"suggestedToPos": {"x": 2, "y": 2}
-			}`,
+			payload: PieceRecommendationRequestPayload{
+				ToPlayerID:       player2.ID,
+				FromFragmentID:   "fragment_player1",
+				ToFragmentID:     "fragment_player2",
+				SuggestedFromPos: GridPos{X: 1, Y: 1},
+				SuggestedToPos:   GridPos{X: 2, Y: 2},
+			},
 			wantErr: false,
 		},
 		{
 			name: "Invalid position",
-			payload: `{
-				"toPlayerId": "` + player2.ID + `",
-				"fromFragmentId": "fragment_player1",
-				"toFragmentId": "fragment_player2",
-				"suggestedFromPos": {"x": 4, "y": 4},
-				"suggestedToPos": {"x": 2, "y": 2}
-			}`,
+			payload: PieceRecommendationRequestPayload{
+				ToPlayerID:       player2.ID,
+				FromFragmentID:   "fragment_player1",
+				ToFragmentID:     "fragment_player2",
+				SuggestedFromPos: GridPos{X: 4, Y: 4},
+				SuggestedToPos:   GridPos{X: 2, Y: 2},
+			},
 			wantErr: true,
 			errMsg:  "position out of bounds",
 		},
 		{
-			name:    "Missing toPlayerId",
-			payload: `{"fromFragmentId": "fragment_player1", "toFragmentId": "fragment_player2"}`,
+			name:    "Unknown toPlayerId",
+			payload: PieceRecommendationRequestPayload{FromFragmentID: "fragment_player1", ToFragmentID: "fragment_player2"},
 			wantErr: true,
-			errMsg:  "invalid payload",
+			errMsg:  "target player not found",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := eh.HandlePieceRecommendationRequest(player1ID, json.RawMessage(tt.payload))
+			err := eh.HandlePieceRecommendationRequest(player1ID, tt.payload)
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.errMsg)
@@ -510,29 +695,23 @@ func TestHandlePieceRecommendationResponse(t *testing.T) {
 
 	tests := []struct {
 		name    string
-		payload json.RawMessage
+		payload PieceRecommendationResponsePayload
 		wantErr bool
 		errMsg  string
 	}{
 		{
 			name:    "Valid acceptance",
-			payload: json.RawMessage(`{"recommendationId": "rec-123", "accepted": true}`),
+			payload: PieceRecommendationResponsePayload{RecommendationID: "rec-123", Accepted: boolPtr(true)},
 			wantErr: false, // May error in game manager
 		},
 		{
 			name:    "Valid rejection",
-			payload: json.RawMessage(`{"recommendationId": "rec-123", "accepted": false}`),
+			payload: PieceRecommendationResponsePayload{RecommendationID: "rec-123", Accepted: boolPtr(false)},
 			wantErr: false, // May error in game manager
 		},
-		{
-			name:    "Missing recommendation ID",
-			payload: json.RawMessage(`{"accepted": true}`),
-			wantErr: true,
-			errMsg:  "invalid payload",
-		},
 		{
 			name:    "Missing accepted field",
-			payload: json.RawMessage(`{"recommendationId": "rec-123"}`),
+			payload: PieceRecommendationResponsePayload{RecommendationID: "rec-123"},
 			wantErr: true,
 			errMsg:  "invalid payload",
 		},
@@ -562,27 +741,22 @@ func TestHandlePlayerReady(t *testing.T) {
 
 	tests := []struct {
 		name    string
-		payload json.RawMessage
+		payload PlayerReadyPayload
 		wantErr bool
 	}{
 		{
 			name:    "Valid ready true",
-			payload: json.RawMessage(`{"ready": true}`),
+			payload: PlayerReadyPayload{Ready: boolPtr(true)},
 			wantErr: false,
 		},
 		{
 			name:    "Valid ready false",
-			payload: json.RawMessage(`{"ready": false}`),
+			payload: PlayerReadyPayload{Ready: boolPtr(false)},
 			wantErr: false,
 		},
 		{
 			name:    "Missing ready field",
-			payload: json.RawMessage(`{}`),
-			wantErr: true,
-		},
-		{
-			name:    "Invalid JSON",
-			payload: json.RawMessage(`{"ready": true`),
+			payload: PlayerReadyPayload{},
 			wantErr: true,
 		},
 	}
@@ -610,26 +784,28 @@ func TestAuthenticationValidationAcrossEvents(t *testing.T) {
 	// Test that all events require valid authentication
 	eventTests := []struct {
 		name     string
-		handler  func(string, json.RawMessage) error
-		payload  json.RawMessage
+		handler  func(string) error
 		testDesc string
 	}{
 		{
-			name:     "role_selection",
-			handler:  eh.HandleRoleSelection,
-			payload:  json.RawMessage(`{"role": "detective"}`),
+			name: "role_selection",
+			handler: func(playerID string) error {
+				return eh.HandleRoleSelection(playerID, RoleSelectionPayload{Role: "detective"})
+			},
 			testDesc: "Role selection should require valid player ID",
 		},
 		{
-			name:     "trivia_specialty_selection",
-			handler:  eh.HandleTriviaSpecialtySelection,
-			payload:  json.RawMessage(`{"specialties": ["science"]}`),
+			name: "trivia_specialty_selection",
+			handler: func(playerID string) error {
+				return eh.HandleTriviaSpecialtySelection(playerID, SpecialtySelectionPayload{Specialties: []string{"science"}})
+			},
 			testDesc: "Specialty selection should require valid player ID",
 		},
 		{
-			name:     "player_ready",
-			handler:  eh.HandlePlayerReady,
-			payload:  json.RawMessage(`{"ready": true}`),
+			name: "player_ready",
+			handler: func(playerID string) error {
+				return eh.HandlePlayerReady(playerID, PlayerReadyPayload{Ready: boolPtr(true)})
+			},
 			testDesc: "Player ready should require valid player ID",
 		},
 	}
@@ -637,19 +813,19 @@ func TestAuthenticationValidationAcrossEvents(t *testing.T) {
 	for _, tt := range eventTests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Test with valid player ID
-			err := tt.handler(validPlayerID, tt.payload)
+			err := tt.handler(validPlayerID)
 			// May error due to game state, but should not be auth error
 			if err != nil && !assert.Contains(t, err.Error(), "player not found") {
 				t.Logf("Event %s with valid ID: %v", tt.name, err)
 			}
 
 			// Test with invalid player ID
-			err = tt.handler("invalid-player-id", tt.payload)
+			err = tt.handler("invalid-player-id")
 			assert.Error(t, err, tt.testDesc)
 			assert.Contains(t, err.Error(), "player not found")
 
 			// Test with empty player ID
-			err = tt.handler("", tt.payload)
+			err = tt.handler("")
 			assert.Error(t, err, tt.testDesc)
 		})
 	}
@@ -664,7 +840,7 @@ func TestPhaseSpecificEventRestrictions(t *testing.T) {
 	playerID := player.ID
 
 	// Test resource location verification only works in resource gathering phase
-	locationPayload := json.RawMessage(`{"verifiedHash": "HASH_ANCHOR_STATION_2025"}`)
+	locationPayload := LocationVerificationPayload{VerifiedHash: "HASH_ANCHOR_STATION_2025"}
 
 	// Should fail in setup phase
 	gm.state.Phase = PhaseSetup
@@ -682,7 +858,7 @@ func TestPhaseSpecificEventRestrictions(t *testing.T) {
 	}
 
 	// Test trivia answer phase restrictions
-	triviaPayload := json.RawMessage(`{"questionId": "test_question_1", "answer": "Paris", "timestamp": 1640995200}`)
+	triviaPayload := TriviaAnswerPayload{QuestionID: "test_question_1", Answer: "Paris", Timestamp: 1640995200}
 
 	// Should fail in setup phase
 	gm.state.Phase = PhaseSetup
@@ -690,7 +866,7 @@ func TestPhaseSpecificEventRestrictions(t *testing.T) {
 	assert.Error(t, err, "Trivia answer should fail in setup phase")
 
 	// Test puzzle-specific events
-	segmentPayload := json.RawMessage(`{"segmentId": "segment_a1", "completionTimestamp": 1640995200}`)
+	segmentPayload := SegmentCompletionPayload{SegmentID: "segment_a1", CompletionTimestamp: 1640995200}
 	gm.state.Phase = PhaseSetup
 	err = eh.HandleSegmentCompleted(playerID, segmentPayload)
 	if err != nil {
@@ -700,7 +876,7 @@ func TestPhaseSpecificEventRestrictions(t *testing.T) {
 
 	// Test fragment movement phase restrictions
 	gm.state.GridSize = 4
-	movePayload := json.RawMessage(`{"fragmentId": "fragment_test", "newPosition": {"x": 1, "y": 1}, "timestamp": 1640995200}`)
+	movePayload := FragmentMoveRequestPayload{FragmentID: "fragment_test", NewPosition: GridPos{X: 1, Y: 1}, Timestamp: 1640995200}
 
 	gm.state.Phase = PhaseSetup
 	err = eh.HandleFragmentMoveRequest(playerID, movePayload)
@@ -714,10 +890,10 @@ func TestPhaseSpecificEventRestrictions(t *testing.T) {
 func TestHostOnlyEventEnforcement(t *testing.T) {
 	// Create managers
 	pm := NewPlayerManager()
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	defer tm.Shutdown()
 	broadcastChan := make(chan BroadcastMessage, 256)
-	gm := NewGameManager(pm, tm, broadcastChan)
+	gm := NewGameManager(pm, tm, broadcastChan, realClock{}, testSeed)
 	eh := NewEventHandlers(gm, pm, broadcastChan)
 
 	// Create host and regular player
@@ -743,12 +919,12 @@ func TestHostOnlyEventEnforcement(t *testing.T) {
 	}
 
 	// Now test that regular player cannot start the game
-	err = eh.HandleHostStartGame(regularPlayer.ID, json.RawMessage("{}"))
+	err = eh.HandleHostStartGame(regularPlayer.ID)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "only host can start") // This will match "only host can start the game"
 
 	// Host should be able to start
-	err = eh.HandleHostStartGame(host.ID, json.RawMessage("{}"))
+	err = eh.HandleHostStartGame(host.ID)
 	assert.NoError(t, err)
 
 	// Test 2: Regular player trying to start puzzle timer
@@ -769,12 +945,12 @@ func TestHostOnlyEventEnforcement(t *testing.T) {
 	gm.mu.Unlock()
 
 	// Now test that regular player cannot start puzzle timer
-	err = eh.HandleHostStartPuzzle(regularPlayer.ID, json.RawMessage("{}"))
+	err = eh.HandleHostStartPuzzle(regularPlayer.ID)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "only host can start") // This will match "only host can start puzzle phase"
 
 	// Host should be able to start puzzle
-	err = eh.HandleHostStartPuzzle(host.ID, json.RawMessage("{}"))
+	err = eh.HandleHostStartPuzzle(host.ID)
 	// This might still error due to other puzzle setup requirements, but it should pass the host check
 	if err != nil {
 		// If there's an error, it should NOT be about host privileges
@@ -804,10 +980,10 @@ func TestHostPrivilegesDirectly(t *testing.T) {
 
 	t.Run("Test HandleHostStartGame privilege check only", func(t *testing.T) {
 		pm := NewPlayerManager()
-		tm := NewTriviaManager()
+		tm := NewTriviaManager(realClock{}, testSeed)
 		defer tm.Shutdown()
 		broadcastChan := make(chan BroadcastMessage, 256)
-		gm := NewGameManager(pm, tm, broadcastChan)
+		gm := NewGameManager(pm, tm, broadcastChan, realClock{}, testSeed)
 		eh := NewEventHandlers(gm, pm, broadcastChan)
 
 		// Create players
@@ -818,7 +994,7 @@ func TestHostPrivilegesDirectly(t *testing.T) {
 		// This tests ONLY the host privilege check
 
 		// First, let's check what happens with insufficient setup
-		err := eh.HandleHostStartGame(regular.ID, json.RawMessage("{}"))
+		err := eh.HandleHostStartGame(regular.ID)
 
 		// The actual implementation checks CanStartGame first, which will fail
 		// But we can verify the player lookup and host check logic
@@ -866,50 +1042,55 @@ func TestEventHandlerEdgeCases(t *testing.T) {
 	player := pm.CreatePlayer(nil, false)
 	playerID := player.ID
 
-	// Test malformed JSON payloads
+	// Malformed JSON never reaches a handler
 	malformedPayloads := []struct {
-		name    string
-		payload json.RawMessage
-		handler func(string, json.RawMessage) error
+		name     string
+		payload  json.RawMessage
+		validate func(json.RawMessage) []ValidationError
 	}{
 		{
 			name:    "role_selection_malformed",
 			payload: json.RawMessage(`{"role": }`),
-			handler: eh.HandleRoleSelection,
+			validate: func(payload json.RawMessage) []ValidationError {
+				_, errs := ValidateRoleSelection(payload)
+				return errs
+			},
 		},
 		{
 			name:    "specialty_selection_malformed",
 			payload: json.RawMessage(`{"specialties": [`),
-			handler: eh.HandleTriviaSpecialtySelection,
+			validate: func(payload json.RawMessage) []ValidationError {
+				_, errs := ValidateSpecialtySelection(payload, testTriviaCategories)
+				return errs
+			},
 		},
 		{
 			name:    "trivia_answer_malformed",
 			payload: json.RawMessage(`{"questionId": "test", "answer": `),
-			handler: eh.HandleTriviaAnswer,
+			validate: func(payload json.RawMessage) []ValidationError {
+				_, errs := ValidateTriviaAnswer(payload)
+				return errs
+			},
 		},
 	}
 
 	for _, tt := range malformedPayloads {
 		t.Run(tt.name, func(t *testing.T) {
-			err := tt.handler(playerID, tt.payload)
-			assert.Error(t, err, "Malformed JSON should cause error")
+			assert.NotEmpty(t, tt.validate(tt.payload), "Malformed JSON should cause error")
 		})
 	}
 
 	// Test extremely large payloads (should be handled by validation layer)
-	largePayload := json.RawMessage(`{"role": "` + string(make([]byte, 1000)) + `"}`)
-	err := eh.HandleRoleSelection(playerID, largePayload)
+	err := eh.HandleRoleSelection(playerID, RoleSelectionPayload{Role: string(make([]byte, 1000))})
 	assert.Error(t, err, "Extremely large payload should be rejected")
 
 	// Test empty payloads where data is required
-	emptyPayload := json.RawMessage(`{}`)
-	err = eh.HandleRoleSelection(playerID, emptyPayload)
+	err = eh.HandleRoleSelection(playerID, RoleSelectionPayload{})
 	assert.Error(t, err, "Empty payload should be rejected for role selection")
 
 	// Test null payloads
-	nullPayload := json.RawMessage(`null`)
-	err = eh.HandleRoleSelection(playerID, nullPayload)
-	assert.Error(t, err, "Null payload should be rejected")
+	_, errs := ValidateRoleSelection(json.RawMessage(`null`))
+	assert.NotEmpty(t, errs, "Null payload should be rejected")
 
 	// Test concurrent event handling
 	done := make(chan bool, 10)
@@ -918,8 +1099,7 @@ func TestEventHandlerEdgeCases(t *testing.T) {
 			defer func() { done <- true }()
 
 			// Try to set ready status concurrently
-			readyPayload := json.RawMessage(`{"ready": true}`)
-			err := eh.HandlePlayerReady(playerID, readyPayload)
+			err := eh.HandlePlayerReady(playerID, PlayerReadyPayload{Ready: boolPtr(true)})
 			// Should not panic, may error due to game state
 			_ = err
 		}(i)
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Event kinds written to the event log
+const (
+	EventLogStarted         = "log_started"         // First line of every log; payload is EventLogHeader
+	EventPlayerConnected    = "player_connected"    // payload is connectionRecord
+	EventPlayerDisconnected = "player_disconnected" // handleDisconnection ran for the player
+	EventHostDisconnected   = "host_disconnected"   // handleHostDisconnection ran for the host
+	EventInbound            = "inbound"             // A validated message about to be routed to the event handlers
+	EventOutbound           = "outbound"            // A message sent directly to one player
+	EventBroadcast          = "broadcast"           // A message delivered to every player in recipients
+	EventQuestionIssued     = "question_issued"     // The full question a player was asked, including the answer
+	EventCheckpoint         = "checkpoint"          // Canonical game state after a change; payload is ReplayState
+	EventSnapshotRestored   = "snapshot_restored"   // The game was resumed from a crash recovery snapshot
+)
+
+// GameEvent is one line of the event log
+type GameEvent struct {
+	Seq        int64           `json:"seq"`
+	Time       time.Time       `json:"time"`
+	Kind       string          `json:"kind"`
+	PlayerID   string          `json:"playerId,omitempty"`
+	Type       string          `json:"type,omitempty"` // Message type for message events, reason for checkpoints
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Recipients []string        `json:"recipients,omitempty"`
+	Error      string          `json:"error,omitempty"` // Handler error for checkpoints taken after an inbound message
+}
+
+// EventLogHeader identifies the room, the seed its game randomness was drawn from and the balance it played with
+type EventLogHeader struct {
+	RoomCode string         `json:"roomCode"`
+	Seed     int64          `json:"seed"`
+	Balance  *BalanceConfig `json:"balance,omitempty"` // Missing from logs written before balance files existed
+
+	// Words player names were checked against; nil in logs written before players could choose names
+	NameBlocklist []string `json:"nameBlocklist"`
+}
+
+// connectionRecord describes how a connection was admitted
+type connectionRecord struct {
+	IsHost      bool `json:"isHost"`
+	Reconnected bool `json:"reconnected"`
+}
+
+// logRedactor is implemented by payloads carrying secrets. The event log records the copy
+// redactedForLog returns instead of the payload itself.
+type logRedactor interface {
+	redactedForLog() interface{}
+}
+
+// redactedSecret replaces secrets in recorded payloads
+const redactedSecret = "[redacted]"
+
+// questionRecord keeps the correct answer that TriviaQuestion hides from clients
+type questionRecord struct {
+	TriviaQuestion
+	CorrectAnswer string `json:"correctAnswer"`
+}
+
+// EventLog appends ordered, timestamped game events as JSON Lines. A nil *EventLog records nothing,
+// so callers never need to check whether logging is enabled.
+type EventLog struct {
+	w      io.Writer
+	closer io.Closer
+	clock  Clock
+	seq    int64
+	failed bool
+	closed bool
+	mu     sync.Mutex
+}
+
+// NewEventLog creates an event log writing to w, timestamped by clock
+func NewEventLog(w io.Writer, clock Clock) *EventLog {
+	el := &EventLog{w: w, clock: clock}
+	if closer, ok := w.(io.Closer); ok {
+		el.closer = closer
+	}
+	return el
+}
+
+// OpenEventLogFile creates a new log file for a room in dir
+func OpenEventLogFile(dir, roomCode string, clock Clock) (*EventLog, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create event log directory: %v", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.jsonl", filepath.Base(roomCode), clock.Now().UTC().Format("20060102T150405.000"))
+	file, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %v", err)
+	}
+
+	return NewEventLog(file, clock), nil
+}
+
+// Record appends an event; payload may be raw JSON or any value that marshals to JSON
+func (el *EventLog) Record(kind, playerID, msgType string, payload interface{}) {
+	if el == nil {
+		return
+	}
+	el.write(GameEvent{Kind: kind, PlayerID: playerID, Type: msgType, Payload: el.encode(payload)})
+}
+
+// RecordBroadcast appends one event for a message delivered to several players
+func (el *EventLog) RecordBroadcast(msgType string, payload interface{}, recipients []string) {
+	if el == nil {
+		return
+	}
+	sort.Strings(recipients)
+	el.write(GameEvent{Kind: EventBroadcast, Type: msgType, Payload: el.encode(payload), Recipients: recipients})
+}
+
+// RecordCheckpoint appends the canonical game state, along with the error of the handler that produced it
+func (el *EventLog) RecordCheckpoint(reason string, state *ReplayState, handlerErr error) {
+	if el == nil {
+		return
+	}
+	event := GameEvent{Kind: EventCheckpoint, Type: reason, Payload: el.encode(state)}
+	if handlerErr != nil {
+		event.Error = handlerErr.Error()
+	}
+	el.write(event)
+}
+
+// Close closes the underlying file; events recorded afterwards (late disconnects) are dropped
+func (el *EventLog) Close() error {
+	if el == nil {
+		return nil
+	}
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	if el.closed {
+		return nil
+	}
+	el.closed = true
+
+	if el.closer == nil {
+		return nil
+	}
+	return el.closer.Close()
+}
+
+func (el *EventLog) encode(payload interface{}) json.RawMessage {
+	switch p := payload.(type) {
+	case nil:
+		return nil
+	case json.RawMessage:
+		return p
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Event log could not encode payload: %v", err)
+		return nil
+	}
+	return data
+}
+
+func (el *EventLog) write(event GameEvent) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	if el.closed {
+		return
+	}
+
+	el.seq++
+	event.Seq = el.seq
+	event.Time = el.clock.Now()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Event log could not encode event %d: %v", event.Seq, err)
+		return
+	}
+
+	if _, err := el.w.Write(append(data, '\n')); err != nil {
+		// Log the first failure only; a full disk would otherwise flood the server log
+		if !el.failed {
+			log.Printf("Event log write failed: %v", err)
+			el.failed = true
+		}
+	}
+}
+
+// ReadEventLog parses a JSON Lines event log
+func ReadEventLog(r io.Reader) ([]GameEvent, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	events := make([]GameEvent, 0)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var event GameEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		events = append(events, event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventLogRoundTrip(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	var buf bytes.Buffer
+	eventLog := NewEventLog(&buf, clock)
+
+	eventLog.Record(EventLogStarted, "", "", EventLogHeader{RoomCode: "ACDEF", Seed: 42})
+	clock.Advance(time.Second)
+	eventLog.Record(EventInbound, "player-1", MsgTriviaAnswer, json.RawMessage(`{"answer":"Paris"}`))
+	eventLog.RecordBroadcast(MsgGameLobbyStatus, map[string]int{"currentPlayers": 2}, []string{"player-2", "player-1"})
+	eventLog.RecordCheckpoint("inbound:"+MsgTriviaAnswer, &ReplayState{Phase: "setup"}, assert.AnError)
+
+	events, err := ReadEventLog(&buf)
+	assert.NoError(t, err)
+	assert.Len(t, events, 4)
+
+	for i, event := range events {
+		assert.Equal(t, int64(i+1), event.Seq)
+	}
+
+	var header EventLogHeader
+	assert.NoError(t, json.Unmarshal(events[0].Payload, &header))
+	assert.Equal(t, int64(42), header.Seed)
+
+	assert.Equal(t, clock.Now(), events[1].Time.UTC())
+	assert.Equal(t, "player-1", events[1].PlayerID)
+	assert.JSONEq(t, `{"answer":"Paris"}`, string(events[1].Payload))
+
+	assert.Equal(t, EventBroadcast, events[2].Kind)
+	assert.Equal(t, []string{"player-1", "player-2"}, events[2].Recipients)
+
+	assert.Equal(t, EventCheckpoint, events[3].Kind)
+	assert.Equal(t, assert.AnError.Error(), events[3].Error)
+}
+
+func TestNilEventLogIsNoOp(t *testing.T) {
+	var eventLog *EventLog
+	eventLog.Record(EventInbound, "player-1", MsgPlayerReady, nil)
+	eventLog.RecordBroadcast(MsgGameLobbyStatus, nil, nil)
+	eventLog.RecordCheckpoint("test", &ReplayState{}, nil)
+	assert.NoError(t, eventLog.Close())
+}
+
+func TestEventLogFileDropsEventsAfterClose(t *testing.T) {
+	dir := t.TempDir()
+	eventLog, err := OpenEventLogFile(dir, "ACDEF", realClock{})
+	assert.NoError(t, err)
+
+	eventLog.Record(EventLogStarted, "", "", EventLogHeader{RoomCode: "ACDEF"})
+	assert.NoError(t, eventLog.Close())
+	eventLog.Record(EventPlayerDisconnected, "player-1", "", nil)
+	assert.NoError(t, eventLog.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "ACDEF-*.jsonl"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	data, err := os.ReadFile(files[0])
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "\n"))
+}
+
+func TestReadEventLogRejectsGarbage(t *testing.T) {
+	_, err := ReadEventLog(strings.NewReader("{\"seq\":1}\nnot json\n"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestEventLogRedactsSessionTokens(t *testing.T) {
+	var buf bytes.Buffer
+	player := &Player{ID: "player-1", eventLog: NewEventLog(&buf, realClock{})}
+	writer := idleTestWriter(t, player)
+
+	token := "player-1.1735732800.signature"
+	assert.NoError(t, sendToPlayer(player, MsgAvailableRoles, AvailableRolesPayload{PlayerID: player.ID, SessionToken: token}))
+
+	// The player gets the token, the log doesn't
+	if assert.Len(t, writer.queue, 1) {
+		assert.Contains(t, string(writer.queue[0].data), token)
+	}
+	assert.NotContains(t, buf.String(), token)
+
+	events, err := ReadEventLog(&buf)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		var logged AvailableRolesPayload
+		assert.NoError(t, json.Unmarshal(events[0].Payload, &logged))
+		assert.Equal(t, redactedSecret, logged.SessionToken)
+		assert.Equal(t, player.ID, logged.PlayerID)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/gorilla/websocket"
+)
+
+// Some venue networks block WebSockets. Players there open an event stream (server-sent events) for
+// messages from the server and post their own messages over plain HTTP. Both ends feed the same
+// playerWriter and handleClientMessage a WebSocket does, so the game can't tell the difference.
+
+// eventStreamTransport sends messages as server-sent events. Each message is one event whose data
+// is the JSON BaseMessage, so an EventSource's onmessage sees exactly what a WebSocket client would.
+type eventStreamTransport struct {
+	w         http.ResponseWriter
+	rc        *http.ResponseController
+	closed    chan struct{} // Closed when the server ends the stream
+	closeOnce sync.Once
+	postMu    sync.Mutex // Handles the player's posts one at a time, in order, like WebSocket frames
+}
+
+func newEventStreamTransport(w http.ResponseWriter) *eventStreamTransport {
+	return &eventStreamTransport{
+		w:      w,
+		rc:     http.NewResponseController(w),
+		closed: make(chan struct{}),
+	}
+}
+
+// writeEvent writes one chunk of the stream and pushes it to the client
+func (t *eventStreamTransport) writeEvent(event string) error {
+	// Each write gets its own deadline; the server's WriteTimeout would otherwise end the stream
+	if err := t.rc.SetWriteDeadline(time.Now().Add(constants.WebSocketWriteTimeout)); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprint(t.w, event); err != nil {
+		return err
+	}
+	return t.rc.Flush()
+}
+
+func (t *eventStreamTransport) writeMessage(data []byte) error {
+	// Encoded JSON has no newlines, so it always fits on one data line
+	return t.writeEvent(fmt.Sprintf("data: %s\n\n", data))
+}
+
+func (t *eventStreamTransport) ping() error {
+	return t.writeEvent(": ping\n\n")
+}
+
+func (t *eventStreamTransport) writeClose(code int, reason string) error {
+	data := mustMarshal(map[string]interface{}{"code": code, "reason": reason})
+	return t.writeEvent(fmt.Sprintf("event: close\ndata: %s\n\n", data))
+}
+
+func (t *eventStreamTransport) close() {
+	t.closeOnce.Do(func() { close(t.closed) })
+}
+
+// HandleEventStream connects a player over server-sent events. It takes the same ?token= as the
+// WebSocket endpoint to reconnect. Hosts always connect over WebSocket.
+func (wsh *WebSocketHandler) HandleEventStream(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); origin != "" && !isValidOrigin(origin) {
+		http.Error(w, "Origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Keep reverse proxies from holding events back
+	stream := newEventStreamTransport(w)
+
+	// Refusals are sent on the stream, since an EventSource can't read the body of an error response
+	ip := clientIP(r)
+	err := wsh.connections.acquire(ip)
+	if err == nil {
+		defer wsh.connections.release(ip)
+
+		var playerID string
+		if playerID, err = wsh.sessionPlayerID(r); err == nil {
+			var player *Player
+			var reconnected bool
+			if player, reconnected, err = wsh.acceptPlayer(playerID, false, nil, ip); err == nil {
+				wsh.serveEventStream(r, stream, player, reconnected)
+				return
+			}
+		}
+	}
+
+	log.Printf("Refused event stream from %s: %v", r.RemoteAddr, err)
+	if err := stream.writeMessage(connectionErrorMessage(err)); err != nil {
+		log.Printf("Failed to send connection error message: %v", err)
+	}
+}
+
+// serveEventStream streams a player's messages until the client goes away or the server closes it
+func (wsh *WebSocketHandler) serveEventStream(r *http.Request, stream *eventStreamTransport, player *Player, reconnected bool) {
+	writer := startPlayerWriter(player, stream)
+	defer func() {
+		// The writer must be done with the response before the handler returns
+		writer.stop()
+		writer.wait()
+	}()
+
+	if err := wsh.admitPlayer(player, reconnected); err != nil {
+		log.Printf("Error handling player join: %v", err)
+		wsh.sendError(player, errJoinFailed)
+		writer.closeAfterFlush(websocket.CloseInternalServerErr, "failed to join game")
+		<-writer.done
+		return
+	}
+
+	select {
+	case <-r.Context().Done():
+	case <-stream.closed:
+	}
+
+	// A player who reconnected already replaced this stream
+	if !writer.superseded() {
+		wsh.handleDisconnection(player)
+	}
+}
+
+// HandleEventPost takes a message from a player connected over an event stream. The body is the
+// {type, payload} envelope a WebSocket client sends, with the session token in an
+// "Authorization: Bearer" header. Replies and errors arrive on the stream.
+func (wsh *WebSocketHandler) HandleEventPost(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); origin != "" && !isValidOrigin(origin) {
+		http.Error(w, "Origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		http.Error(w, constants.ErrSessionTokenRequired, http.StatusUnauthorized)
+		return
+	}
+
+	playerID, err := wsh.sessionTokens.Verify(token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	player, err := wsh.playerManager.GetPlayer(playerID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	// Without an open stream the player would never see the reply
+	player.mu.RLock()
+	writer := player.writer
+	player.mu.RUnlock()
+
+	var stream *eventStreamTransport
+	if writer != nil {
+		stream, _ = writer.transport.(*eventStreamTransport)
+	}
+	if stream == nil {
+		http.Error(w, constants.ErrEventStreamRequired, http.StatusConflict)
+		return
+	}
+
+	var baseMsg BaseMessage
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, constants.MaxClientMessageBytes)).Decode(&baseMsg); err != nil {
+		http.Error(w, fmt.Sprintf("invalid message: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	stream.postMu.Lock()
+	wsh.handleClientMessage(player, baseMsg)
+	stream.postMu.Unlock()
+
+	w.WriteHeader(http.StatusAccepted)
+}
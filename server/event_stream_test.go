@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+// streamWriteTimeout stands in for the server's WriteTimeout, short enough for a test to outlive it
+const streamWriteTimeout = 200 * time.Millisecond
+
+// startEventStreamServer serves the event stream endpoints of a fresh room through the server's middleware
+func startEventStreamServer(t *testing.T) (*httptest.Server, *WebSocketHandler, *PlayerManager) {
+	playerManager := NewPlayerManager()
+	triviaManager := NewTriviaManager(realClock{}, testSeed)
+	broadcastChan := make(chan BroadcastMessage, 256)
+	gameManager := NewGameManager(playerManager, triviaManager, broadcastChan, realClock{}, testSeed)
+	eventHandlers := NewEventHandlers(gameManager, playerManager, broadcastChan)
+	wsHandler := NewWebSocketHandler(playerManager, gameManager, eventHandlers, broadcastChan)
+	wsHandler.StartBroadcaster()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /events", wsHandler.HandleEventStream)
+	mux.HandleFunc("POST /events/messages", wsHandler.HandleEventPost)
+	srv := httptest.NewUnstartedServer(withMiddleware(mux))
+	srv.Config.WriteTimeout = streamWriteTimeout
+	srv.Start()
+
+	t.Cleanup(func() {
+		srv.Close()
+		wsHandler.StopBroadcaster()
+		gameManager.Stop()
+		triviaManager.Shutdown()
+	})
+	return srv, wsHandler, playerManager
+}
+
+// openEventStream connects to the stream and returns a function reading the next message of a type
+func openEventStream(t *testing.T, ctx context.Context, url string) func(msgType string) map[string]interface{} {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("opening event stream failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	messages := make(chan BaseMessage, 64)
+	go func() {
+		defer close(messages)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var msg BaseMessage
+			if json.Unmarshal([]byte(data), &msg) == nil {
+				messages <- msg
+			}
+		}
+	}()
+
+	return func(msgType string) map[string]interface{} {
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case msg, ok := <-messages:
+				if !ok {
+					t.Fatalf("stream ended before %s arrived", msgType)
+				}
+				if msg.Type != msgType {
+					continue
+				}
+				var payload map[string]interface{}
+				assert.NoError(t, json.Unmarshal(msg.Payload, &payload))
+				return payload
+			case <-timeout:
+				t.Fatalf("no %s on the stream", msgType)
+			}
+		}
+	}
+}
+
+// postEvent posts a message the way an event stream client does and returns the status code
+func postEvent(t *testing.T, url, token string, body interface{}) int {
+	req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(string(mustMarshal(body))))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("post failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestEventStreamTransport(t *testing.T) {
+	srv, wsHandler, playerManager := startEventStreamServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	next := openEventStream(t, ctx, srv.URL+"/events")
+
+	roles := next(MsgAvailableRoles)
+	playerID, _ := roles["playerId"].(string)
+	token, _ := roles["sessionToken"].(string)
+	assert.NotEmpty(t, token)
+
+	// Posted messages take the same route as WebSocket frames, and replies arrive on the stream
+	status := postEvent(t, srv.URL+"/events/messages", token, map[string]interface{}{
+		"type": MsgPlayerSetName,
+		"payload": map[string]interface{}{
+			"auth":    map[string]string{"playerId": playerID, "token": token},
+			"payload": map[string]string{"name": "Ada"},
+		},
+	})
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, "Ada", next(MsgPlayerUpdate)["name"])
+
+	// The stream outlives the server's WriteTimeout
+	time.Sleep(2 * streamWriteTimeout)
+	assert.Equal(t, http.StatusAccepted, postEvent(t, srv.URL+"/events/messages", token, map[string]interface{}{
+		"type": MsgPlayerSetName,
+		"payload": map[string]interface{}{
+			"auth":    map[string]string{"playerId": playerID, "token": token},
+			"payload": map[string]string{"name": "Grace"},
+		},
+	}))
+	assert.Equal(t, "Grace", next(MsgPlayerUpdate)["name"])
+
+	// Problems with a message are reported on the stream as well
+	assert.Equal(t, http.StatusAccepted, postEvent(t, srv.URL+"/events/messages", token, map[string]interface{}{"type": "no_such_message", "requestId": "r-1"}))
+	unknown := next(MsgError)
+	assert.Equal(t, string(CodeUnknownMessageType), unknown["code"])
+	assert.Equal(t, "no_such_message", unknown["requestType"])
+	assert.Equal(t, "r-1", unknown["requestId"])
+
+	t.Run("Posts need a token and an open stream", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, postEvent(t, srv.URL+"/events/messages", "", map[string]string{"type": MsgPlayerReady}))
+		assert.Equal(t, http.StatusUnauthorized, postEvent(t, srv.URL+"/events/messages", "forged", map[string]string{"type": MsgPlayerReady}))
+
+		other := playerManager.CreatePlayer(nil, false)
+		otherToken := wsHandler.sessionTokens.Issue(other.ID)
+		assert.Equal(t, http.StatusConflict, postEvent(t, srv.URL+"/events/messages", otherToken, map[string]string{"type": MsgPlayerReady}))
+	})
+
+	t.Run("Reconnection without a token is refused on the stream", func(t *testing.T) {
+		refused := openEventStream(t, ctx, srv.URL+"/events?playerId="+playerID)
+		assert.Equal(t, constants.ErrSessionTokenRequired, refused(MsgError)["error"])
+	})
+
+	// Closing the stream is a disconnect
+	cancel()
+	WaitForCondition(t, func() bool {
+		player, err := playerManager.GetPlayer(playerID)
+		if err != nil {
+			return false
+		}
+		player.mu.RLock()
+		defer player.mu.RUnlock()
+		return player.State == StateDisconnected
+	}, 2*time.Second, "player disconnected")
+}
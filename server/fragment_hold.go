@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// holdFragment keeps the fragment of a player who dropped out of the puzzle theirs for the reconnect
+// grace period, so a locked phone screen doesn't cost them the game. It returns when the fragment
+// will be handed over, and false if it was handed over at once because the game has no grace period.
+func (gm *GameManager) holdFragment(playerID string) (time.Time, bool) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	// Nothing to hold once the fragment was handed over
+	fragment, exists := gm.state.PuzzleFragments[fmt.Sprintf("fragment_%s", playerID)]
+	if !exists || fragment.PlayerID != playerID {
+		return time.Time{}, false
+	}
+
+	// A connection can report the same disconnect twice; the first one set the deadline
+	if deadline, held := gm.state.FragmentHolds[playerID]; held {
+		return deadline, true
+	}
+
+	grace := time.Duration(gm.state.Settings.ReconnectGrace) * time.Second
+	if grace <= 0 {
+		gm.handleFragmentDisconnectionInternal(playerID)
+		return time.Time{}, false
+	}
+
+	deadline := gm.clock.Now().Add(grace)
+	gm.state.FragmentHolds[playerID] = deadline
+	gm.requestSnapshotInternal()
+
+	// Armed before returning so the grace period counts from the disconnect
+	go gm.expireFragmentHold(playerID, deadline, gm.clock.NewTimer(grace), gm.gameCancel)
+
+	log.Printf("Holding fragment of player %s for %v while they reconnect", playerID, grace)
+	return deadline, true
+}
+
+// expireFragmentHold hands the fragment over once the grace period ends, unless the player came
+// back or dropped again in the meantime (which starts a hold of its own)
+func (gm *GameManager) expireFragmentHold(playerID string, deadline time.Time, timer Timer, cancel <-chan struct{}) {
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+	case <-cancel:
+		return
+	case <-gm.stopChan:
+		return
+	}
+
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if held, ok := gm.state.FragmentHolds[playerID]; !ok || !held.Equal(deadline) || gm.state.Phase != PhasePuzzleAssembly {
+		return
+	}
+
+	log.Printf("Player %s did not reconnect in time", playerID)
+	gm.handleFragmentDisconnectionInternal(playerID)
+	gm.requestSnapshotInternal()
+	gm.recordCheckpointInternal("fragment_hold_expired")
+}
+
+// ReclaimFragment gives a player who reconnected within the grace period their fragment back.
+// It reports whether the player had a fragment on hold.
+func (gm *GameManager) ReclaimFragment(playerID string) bool {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if _, held := gm.state.FragmentHolds[playerID]; !held {
+		return false
+	}
+
+	delete(gm.state.FragmentHolds, playerID)
+	gm.requestSnapshotInternal()
+
+	gm.broadcastChan <- BroadcastMessage{
+		Type: MsgCentralPuzzleState,
+		Payload: PlayerReconnectedNotice{
+			PlayerReconnected: playerID,
+			Phase:             "puzzle_assembly",
+		},
+	}
+	gm.sendCompletePuzzleStateToHost()
+
+	log.Printf("Player %s reconnected and reclaimed their fragment", playerID)
+	return true
+}
+
+// HasFragmentHold reports whether a player's fragment is waiting for them to reconnect
+func (gm *GameManager) HasFragmentHold(playerID string) bool {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	_, held := gm.state.FragmentHolds[playerID]
+	return held
+}
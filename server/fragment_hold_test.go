@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startFakeClockPuzzle sets up the puzzle phase for four players on a fake clock
+func startFakeClockPuzzle(t *testing.T) (*GameManager, *PlayerManager, *TriviaManager, *FakeClock) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	gm, pm, tm, _ := createSeededTestGameManager(clock, testSeed)
+
+	for i := 1; i <= 4; i++ {
+		pm.createPlayer(fmt.Sprintf("player-%d", i), nil, false)
+	}
+	gm.startPuzzlePhase()
+
+	return gm, pm, tm, clock
+}
+
+func fragmentOf(gm *GameManager, playerID string) PuzzleFragment {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	return *gm.state.PuzzleFragments["fragment_"+playerID]
+}
+
+func TestFragmentHoldExpires(t *testing.T) {
+	gm, _, tm, clock := startFakeClockPuzzle(t)
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	deadline, held := gm.holdFragment("player-1")
+	assert.True(t, held)
+	assert.Equal(t, clock.Now().Add(60*time.Second), deadline)
+
+	// A second report of the same disconnect keeps the first deadline
+	again, _ := gm.holdFragment("player-1")
+	assert.Equal(t, deadline, again)
+
+	// The fragment stays with its owner during the grace period
+	clock.Advance(59 * time.Second)
+	assert.True(t, gm.HasFragmentHold("player-1"))
+	assert.Equal(t, "player-1", fragmentOf(gm, "player-1").PlayerID)
+
+	clock.Advance(time.Second)
+	WaitForCondition(t, func() bool { return !gm.HasFragmentHold("player-1") }, time.Second, "hold expired")
+	fragment := fragmentOf(gm, "player-1")
+	assert.True(t, fragment.IsUnassigned)
+	assert.Equal(t, "anyone", fragment.MovableBy)
+	assert.Empty(t, fragment.PlayerID)
+
+	// Nothing is left to hold or reclaim
+	_, held = gm.holdFragment("player-1")
+	assert.False(t, held)
+	assert.False(t, gm.ReclaimFragment("player-1"))
+}
+
+func TestFragmentHoldReclaimed(t *testing.T) {
+	gm, _, tm, clock := startFakeClockPuzzle(t)
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	_, held := gm.holdFragment("player-2")
+	assert.True(t, held)
+	assert.True(t, gm.ReclaimFragment("player-2"))
+	assert.False(t, gm.HasFragmentHold("player-2"))
+
+	// The old grace period ending changes nothing
+	clock.Advance(time.Hour)
+	WaitForCondition(t, func() bool { return clock.PendingTimers() == 1 }, time.Second, "hold timer done")
+	assert.Equal(t, "player-2", fragmentOf(gm, "player-2").PlayerID)
+	assert.False(t, fragmentOf(gm, "player-2").IsUnassigned)
+}
+
+func TestFragmentHoldWithoutGrace(t *testing.T) {
+	gm, _, tm, _ := startFakeClockPuzzle(t)
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	gm.mu.Lock()
+	gm.state.Settings.ReconnectGrace = 0
+	gm.mu.Unlock()
+
+	_, held := gm.holdFragment("player-3")
+	assert.False(t, held)
+	assert.False(t, gm.HasFragmentHold("player-3"))
+	assert.True(t, fragmentOf(gm, "player-3").IsUnassigned)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// gameCancelChan returns the channel that closes when the current game is skipped, aborted or reset.
+// Goroutines that belong to one game (rounds, puzzle timer, post-game reset) capture it when they start.
+func (gm *GameManager) gameCancelChan() <-chan struct{} {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	return gm.gameCancel
+}
+
+// cancelGameInternal stops the running game's timers and arms a fresh channel for the next game
+// NOTE: This method assumes the caller already holds gm.mu lock
+func (gm *GameManager) cancelGameInternal() {
+	close(gm.gameCancel)
+	gm.gameCancel = make(chan struct{})
+}
+
+// isCancelled reports whether cancel has been closed
+func isCancelled(cancel <-chan struct{}) bool {
+	select {
+	case <-cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// AbortGame ends a game early. With showResults the players see the post-game analytics for the game
+// so far; otherwise, and always once the game is already over, the room resets straight away instead
+// of after the usual post-game delay.
+func (gm *GameManager) AbortGame(showResults bool) error {
+	gm.mu.Lock()
+	phase := gm.state.Phase
+	if phase == PhaseSetup {
+		gm.mu.Unlock()
+		return errGameNotStarted
+	}
+
+	// Stop the rounds or puzzle timer before they can end the game on their own
+	gm.cancelGameInternal()
+	gm.state.Paused = false
+	gm.recordCheckpointInternal("game_aborted")
+	gm.mu.Unlock()
+
+	log.Printf("Host aborted the game during %s phase", phase.String())
+
+	if showResults && phase != PhasePostGame {
+		gm.endGame(false)
+		return nil
+	}
+
+	gm.resetGame()
+	return nil
+}
+
+// SkipToPuzzle ends resource gathering after the current round and starts the puzzle phase
+func (gm *GameManager) SkipToPuzzle() error {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if gm.state.Phase != PhaseResourceGathering {
+		return errNoRoundsToSkip
+	}
+
+	skipped := gm.state.Settings.ResourceRounds - gm.state.CurrentRound
+	gm.cancelGameInternal()
+	gm.state.Paused = false
+	gm.state.PausedAt = time.Time{}
+	gm.state.RoundExtension = 0
+
+	log.Printf("Host skipped to the puzzle in round %d (%d rounds left)", gm.state.CurrentRound, skipped)
+	gm.startPuzzlePhaseInternal()
+	return nil
+}
+
+// Rematch returns everyone to the lobby after a game with their roles, specialties and readiness
+// kept, so the host can start again without players rejoining. The settings of the last game carry over.
+func (gm *GameManager) Rematch() error {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if gm.state.Phase != PhasePostGame {
+		return errGameNotOver
+	}
+
+	// The pending post-game reset would otherwise wipe the new lobby
+	gm.cancelGameInternal()
+
+	gm.broadcastChan <- BroadcastMessage{
+		Type: MsgGameReset,
+		Payload: GameResetPayload{
+			Message:           "Rematch! Everyone keeps their role and specialties.",
+			ReconnectRequired: false,
+			Rematch:           true,
+		},
+	}
+
+	// Players walk to a station again each game
+	for _, player := range gm.playerManager.GetAllPlayers() {
+		player.mu.Lock()
+		player.CurrentLocation = ""
+		player.mu.Unlock()
+	}
+
+	gm.state = &GameState{
+		Phase:                PhaseSetup,
+		Difficulty:           gm.state.Difficulty,
+		Settings:             gm.state.Settings,
+		Players:              make(map[string]*Player),
+		TeamTokens:           TeamTokens{},
+		QuestionHistory:      make(map[string]map[string]bool),
+		PlayerAnalytics:      make(map[string]*PlayerAnalytics),
+		PieceRecommendations: make(map[string]*PieceRecommendation),
+		CurrentQuestions:     make(map[string]*TriviaQuestion),
+		PuzzleFragments:      make(map[string]*PuzzleFragment),
+		FragmentHolds:        make(map[string]time.Time),
+	}
+	gm.recordCheckpointInternal("game_rematch")
+
+	log.Printf("Host started a rematch with %d players", len(gm.playerManager.GetConnectedNonHostPlayers()))
+	return nil
+}
+
+// RemovePlayer drops a kicked player from the running game. Their analytics stay so the results
+// still count what they did, and during the puzzle their fragment is handed over as on a disconnect.
+func (gm *GameManager) RemovePlayer(playerID string) {
+	gm.mu.Lock()
+	phase := gm.state.Phase
+	delete(gm.state.CurrentQuestions, playerID)
+	for id, recommendation := range gm.state.PieceRecommendations {
+		if recommendation.FromPlayerID == playerID || recommendation.ToPlayerID == playerID {
+			delete(gm.state.PieceRecommendations, id)
+		}
+	}
+	gm.requestSnapshotInternal()
+	gm.mu.Unlock()
+
+	if phase == PhasePuzzleAssembly {
+		gm.handleFragmentDisconnection(playerID)
+	}
+
+	gm.sendHostUpdate()
+}
+
+// RenamePlayer carries a new display name into the player's analytics
+func (gm *GameManager) RenamePlayer(playerID, name string) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if analytics, ok := gm.state.PlayerAnalytics[playerID]; ok {
+		analytics.PlayerName = name
+	}
+	gm.sendHostUpdateInternal()
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+// startFakeClockRounds runs the trivia rounds for four players and waits for the round 1 timer
+func startFakeClockRounds(t *testing.T) (*GameManager, *PlayerManager, *TriviaManager, *FakeClock) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	gm, pm, tm, _ := createSeededTestGameManager(clock, testSeed)
+
+	for i := 1; i <= 4; i++ {
+		pm.createPlayer(fmt.Sprintf("player-%d", i), nil, false)
+	}
+	SimulateGamePhase(gm, PhaseResourceGathering)
+
+	go gm.runResourceGatheringRounds(1, 0)
+	WaitForCondition(t, func() bool {
+		gm.mu.RLock()
+		defer gm.mu.RUnlock()
+		return gm.state.CurrentRound == 1 && clock.PendingTimers() == 2
+	}, time.Second, "round 1 timer")
+
+	return gm, pm, tm, clock
+}
+
+func TestSkipToPuzzle(t *testing.T) {
+	gm, _, tm, clock := startFakeClockRounds(t)
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	clock.Advance(time.Duration(constants.ResourceGatheringRoundDuration) * time.Second)
+	WaitForCondition(t, func() bool {
+		gm.mu.RLock()
+		defer gm.mu.RUnlock()
+		return gm.state.CurrentRound == 2 && clock.PendingTimers() == 2
+	}, time.Second, "round 2 timer")
+
+	assert.NoError(t, gm.Pause())
+	assert.NoError(t, gm.SkipToPuzzle())
+	assert.Equal(t, PhasePuzzleAssembly, gm.GetPhase())
+
+	// The round loop stops instead of running the remaining rounds or starting the puzzle again
+	WaitForCondition(t, func() bool { return clock.PendingTimers() == 1 }, time.Second, "round timer stopped")
+	gm.mu.RLock()
+	fragments := len(gm.state.PuzzleFragments)
+	gm.mu.RUnlock()
+	assert.Equal(t, 7, fragments) // Four players plus three unassigned fragments on a 3x3 grid
+
+	clock.Advance(time.Duration(constants.ResourceGatheringRounds*constants.ResourceGatheringRoundDuration) * time.Second)
+	gm.mu.RLock()
+	assert.Equal(t, 2, gm.state.CurrentRound)
+	assert.False(t, gm.state.Paused)
+	gm.mu.RUnlock()
+
+	assert.EqualError(t, gm.SkipToPuzzle(), constants.ErrNoRoundsToSkip)
+}
+
+func TestAbortGame(t *testing.T) {
+	t.Run("Before the game starts", func(t *testing.T) {
+		gm, _, tm, _ := createTestGameManager()
+		defer cleanupTestGameManager(tm)
+
+		assert.EqualError(t, gm.AbortGame(false), constants.ErrGameNotStarted)
+	})
+
+	t.Run("Reset straight away", func(t *testing.T) {
+		gm, _, tm, clock := startFakeClockRounds(t)
+		defer cleanupTestGameManager(tm)
+		defer gm.Stop()
+
+		assert.NoError(t, gm.AbortGame(false))
+		assert.Equal(t, PhaseSetup, gm.GetPhase())
+		WaitForCondition(t, func() bool { return clock.PendingTimers() == 1 }, time.Second, "round timer stopped")
+
+		// No later round or puzzle phase sneaks in
+		clock.Advance(time.Hour)
+		assert.Equal(t, PhaseSetup, gm.GetPhase())
+	})
+
+	t.Run("Show results first", func(t *testing.T) {
+		gm, _, tm, clock := startFakeClockRounds(t)
+		defer cleanupTestGameManager(tm)
+		defer gm.Stop()
+
+		assert.NoError(t, gm.AbortGame(true))
+		assert.Equal(t, PhasePostGame, gm.GetPhase())
+
+		// Aborting again skips the post-game wait
+		assert.NoError(t, gm.AbortGame(true))
+		assert.Equal(t, PhaseSetup, gm.GetPhase())
+		clock.Advance(time.Hour)
+		assert.Equal(t, PhaseSetup, gm.GetPhase())
+	})
+}
+
+func TestRematch(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	gm, pm, tm, _ := createSeededTestGameManager(clock, testSeed)
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	assert.EqualError(t, gm.Rematch(), constants.ErrGameNotOver)
+
+	rounds, minPlayers := 2, 2
+	_, err := gm.UpdateSettings(GameSettingsUpdate{ResourceRounds: &rounds, MinPlayers: &minPlayers})
+	assert.NoError(t, err)
+	assert.NoError(t, gm.SetDifficulty("hard"))
+
+	pm.createPlayer("host", nil, true)
+	for i, id := range []string{"player-1", "player-2"} {
+		pm.createPlayer(id, nil, false)
+		assert.NoError(t, pm.SetPlayerRole(id, []string{constants.RoleDetective, constants.RoleTourist}[i]))
+		assert.NoError(t, pm.SetPlayerSpecialties(id, []string{"science"}))
+		assert.NoError(t, pm.SetPlayerReady(id, true))
+	}
+	assert.NoError(t, pm.UpdatePlayerLocation("player-1", constants.ResourceStationHashes[constants.TokenAnchor]))
+
+	SimulateGamePhase(gm, PhaseResourceGathering)
+	gm.mu.Lock()
+	gm.state.TeamTokens.AnchorTokens = 20
+	gm.mu.Unlock()
+	gm.endGame(false)
+	assert.NoError(t, gm.Rematch())
+
+	// Same players, roles, specialties and settings; only the game itself starts over
+	assert.Equal(t, PhaseSetup, gm.GetPhase())
+	assert.Equal(t, 2, gm.GetSettings().ResourceRounds)
+	assert.Equal(t, "hard", gm.state.Difficulty)
+	assert.Zero(t, gm.state.TeamTokens.AnchorTokens)
+
+	player, err := pm.GetPlayer("player-1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.RoleDetective, player.Role)
+		assert.Equal(t, []string{"science"}, player.Specialties)
+		assert.True(t, player.Ready)
+		assert.Empty(t, player.CurrentLocation)
+	}
+	canStart, reason := gm.CanStartGame()
+	assert.True(t, canStart, reason)
+
+	// The post-game reset timer from the first game no longer fires
+	clock.Advance(time.Duration(constants.PostGameAnalyticsDuration) * time.Second)
+	assert.Equal(t, 2, gm.GetSettings().ResourceRounds)
+}
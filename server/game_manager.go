@@ -21,16 +21,46 @@ type GameManager struct {
 	triviaManager   *TriviaManager
 	broadcastChan   chan BroadcastMessage
 	stopChan        chan struct{}
+	stopOnce        sync.Once
 	countdownCancel chan struct{}
-	mu              sync.RWMutex
+	timerChanged    chan struct{} // Wakes the round or puzzle timer after a pause, resume or added time (see game_pause.go)
+	gameCancel      chan struct{} // Closed and replaced to stop the current game's timers (see game_control.go)
+
+	// Crash recovery (see game_recovery.go); snapshotStore is nil when snapshots are disabled
+	snapshotStore *SnapshotStore
+	snapshotRoom  RoomIdentity
+	snapshotChan  chan *GameSnapshot
+	snapshotDone  chan struct{}
+
+	// Finished game history (see game_store.go); gameStore is nil when history is disabled
+	gameStore         GameStore
+	gameStoreRoomCode string
+	pendingSaves      sync.WaitGroup // Background saves started by saveGameRecord
+
+	// Balance values (see balance_config.go); shared read-only between rooms, never nil
+	balance *BalanceConfig
+
+	// Time and randomness are injected so tests and replays can run a game deterministically
+	clock Clock
+	seed  int64
+	rng   *rand.Rand // Only used while holding gm.mu
+
+	// Event log and replay (see game_replay.go); eventLog is nil when logging is disabled
+	eventLog        *EventLog
+	replayQuestions map[string][]*TriviaQuestion // Recorded questions per player, only set during replay
+
+	mu sync.RWMutex
 }
 
-// NewGameManager creates a new game manager instance
-func NewGameManager(playerManager *PlayerManager, triviaManager *TriviaManager, broadcastChan chan BroadcastMessage) *GameManager {
+// NewGameManager creates a new game manager instance. All timers run on clock and every random
+// choice (roles, fragment layout, recommendations) is drawn from seed.
+func NewGameManager(playerManager *PlayerManager, triviaManager *TriviaManager, broadcastChan chan BroadcastMessage, clock Clock, seed int64) *GameManager {
+	balance := DefaultBalanceConfig()
 	gm := &GameManager{
 		state: &GameState{
 			Phase:                PhaseSetup,
-			Difficulty:           "medium",
+			Difficulty:           balance.Difficulty,
+			Settings:             balance.Settings,
 			Players:              make(map[string]*Player),
 			TeamTokens:           TeamTokens{},
 			QuestionHistory:      make(map[string]map[string]bool),
@@ -38,20 +68,39 @@ func NewGameManager(playerManager *PlayerManager, triviaManager *TriviaManager,
 			PieceRecommendations: make(map[string]*PieceRecommendation),
 			CurrentQuestions:     make(map[string]*TriviaQuestion),
 			PuzzleFragments:      make(map[string]*PuzzleFragment),
+			FragmentHolds:        make(map[string]time.Time),
 		},
 		playerManager:   playerManager,
 		triviaManager:   triviaManager,
 		broadcastChan:   broadcastChan,
 		stopChan:        make(chan struct{}),
 		countdownCancel: make(chan struct{}),
+		timerChanged:    make(chan struct{}, 1),
+		gameCancel:      make(chan struct{}),
+		balance:         balance,
+		clock:           clock,
+		seed:            seed,
+		rng:             rand.New(rand.NewSource(seed)),
 	}
 
-	// Initialize random seed
-	rand.Seed(time.Now().UnixNano())
+	// Players are stamped with the game's clock
+	playerManager.UseClock(clock)
+
+	// Players pick specialties from the categories this game's question bank has
+	if triviaManager != nil {
+		playerManager.UseTriviaCategories(triviaManager.GetAvailableCategories)
+	}
 
 	return gm
 }
 
+// Stop terminates all running game loops; used when the owning room is torn down
+func (gm *GameManager) Stop() {
+	gm.stopOnce.Do(func() {
+		close(gm.stopChan)
+	})
+}
+
 // GetPhase returns the current game phase
 func (gm *GameManager) GetPhase() GamePhase {
 	gm.mu.RLock()
@@ -59,42 +108,145 @@ func (gm *GameManager) GetPhase() GamePhase {
 	return gm.state.Phase
 }
 
+// validDifficulties lists the difficulty levels a game can be played at
+var validDifficulties = map[string]bool{
+	"easy":   true,
+	"medium": true,
+	"hard":   true,
+}
+
 // SetDifficulty sets the game difficulty
 func (gm *GameManager) SetDifficulty(difficulty string) error {
 	gm.mu.Lock()
 	defer gm.mu.Unlock()
 
 	if gm.state.Phase != PhaseSetup {
-		return fmt.Errorf("can only set difficulty during setup phase")
-	}
-
-	validDifficulties := map[string]bool{
-		"easy":   true,
-		"medium": true,
-		"hard":   true,
+		return newCodedError(CodeSettingsLocked, "can only set difficulty during setup phase")
 	}
 
 	if !validDifficulties[difficulty] {
-		return fmt.Errorf("invalid difficulty")
+		return errInvalidDifficulty
 	}
 
 	gm.state.Difficulty = difficulty
 	return nil
 }
 
+// DefaultGameSettings returns the built-in settings a game starts with; a balance file may override them
+func DefaultGameSettings() GameSettings {
+	return GameSettings{
+		ResourceRounds:   constants.ResourceGatheringRounds,
+		RoundDuration:    constants.ResourceGatheringRoundDuration,
+		PuzzleBaseTime:   constants.PuzzleAssemblyBaseTime,
+		AnchorThreshold:  constants.AnchorTokenThresholds,
+		ChronosThreshold: constants.ChronosTokenThresholds,
+		GuideThreshold:   constants.GuideTokenThresholds,
+		ClarityThreshold: constants.ClarityTokenThresholds,
+		GridSize:         0,
+		MinPlayers:       constants.MinPlayers,
+		ReconnectGrace:   constants.PuzzleReconnectGrace,
+	}
+}
+
+// GetSettings returns the effective settings for the current game
+func (gm *GameManager) GetSettings() GameSettings {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	return gm.state.Settings
+}
+
+// UpdateSettings applies a host's settings change; only the fields set in update are changed.
+// Values are range checked by ValidateHostUpdateSettings; this checks the phase and the
+// combination of the new values.
+func (gm *GameManager) UpdateSettings(update GameSettingsUpdate) (GameSettings, error) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if gm.state.Phase != PhaseSetup {
+		return gm.state.Settings, errSettingsLocked
+	}
+
+	settings := gm.state.Settings
+	difficulty := gm.state.Difficulty
+	if update.Preset != nil {
+		preset, exists := gm.balance.Presets[*update.Preset]
+		if !exists {
+			return gm.state.Settings, errUnknownPreset
+		}
+		settings, difficulty = applySettingsUpdate(gm.balance.Settings, gm.balance.Difficulty, preset.Settings)
+	}
+
+	if update.Difficulty != nil && !validDifficulties[*update.Difficulty] {
+		return gm.state.Settings, errInvalidDifficulty
+	}
+	settings, difficulty = applySettingsUpdate(settings, difficulty, update)
+
+	if settings.gridTooSmall() {
+		return gm.state.Settings, errGridTooSmall
+	}
+
+	gm.state.Settings = settings
+	gm.state.Difficulty = difficulty
+	return settings, nil
+}
+
+// applySettingsUpdate returns settings and difficulty with the fields set in update applied
+func applySettingsUpdate(settings GameSettings, difficulty string, update GameSettingsUpdate) (GameSettings, string) {
+	if update.Difficulty != nil {
+		difficulty = *update.Difficulty
+	}
+	if update.ResourceRounds != nil {
+		settings.ResourceRounds = *update.ResourceRounds
+	}
+	if update.RoundDuration != nil {
+		settings.RoundDuration = *update.RoundDuration
+	}
+	if update.PuzzleBaseTime != nil {
+		settings.PuzzleBaseTime = *update.PuzzleBaseTime
+	}
+	if update.AnchorThreshold != nil {
+		settings.AnchorThreshold = *update.AnchorThreshold
+	}
+	if update.ChronosThreshold != nil {
+		settings.ChronosThreshold = *update.ChronosThreshold
+	}
+	if update.GuideThreshold != nil {
+		settings.GuideThreshold = *update.GuideThreshold
+	}
+	if update.ClarityThreshold != nil {
+		settings.ClarityThreshold = *update.ClarityThreshold
+	}
+	if update.GridSize != nil {
+		settings.GridSize = *update.GridSize
+	}
+	if update.MinPlayers != nil {
+		settings.MinPlayers = *update.MinPlayers
+	}
+	if update.ReconnectGrace != nil {
+		settings.ReconnectGrace = *update.ReconnectGrace
+	}
+	return settings, difficulty
+}
+
+// gridTooSmall reports whether a fixed grid lacks a fragment for every player the game may start with
+func (s GameSettings) gridTooSmall() bool {
+	return s.GridSize > 0 && s.GridSize*s.GridSize < s.MinPlayers
+}
+
 // CanStartGame checks if the game can be started
 func (gm *GameManager) CanStartGame() (bool, string) {
 	// Check if game is already in progress
 	if gm.state.Phase != PhaseSetup {
-		return false, "game already started"
+		return false, constants.ErrGameAlreadyStarted
 	}
 
 	// Get non-host players for game requirements
 	connectedPlayers := gm.playerManager.GetConnectedNonHostPlayers()
 	readyPlayers := gm.playerManager.GetReadyNonHostPlayers()
 
-	if len(connectedPlayers) < constants.MinPlayers {
-		return false, fmt.Sprintf("Need at least %d players (current: %d)", constants.MinPlayers, len(connectedPlayers))
+	minPlayers := gm.GetSettings().MinPlayers
+	if len(connectedPlayers) < minPlayers {
+		return false, fmt.Sprintf("Need at least %d players (current: %d)", minPlayers, len(connectedPlayers))
 	}
 
 	// Check if host is connected
@@ -130,7 +282,7 @@ func (gm *GameManager) StartGame() error {
 	defer gm.mu.Unlock()
 
 	if gm.state.Phase != PhaseSetup {
-		return fmt.Errorf("game already started")
+		return errGameAlreadyStarted
 	}
 
 	// Initialize player analytics for NON-HOST players only
@@ -153,7 +305,9 @@ func (gm *GameManager) StartGame() error {
 	// Transition to resource gathering
 	gm.state.Phase = PhaseResourceGathering
 	gm.state.CurrentRound = 1
-	gm.state.RoundStartTime = time.Now()
+	gm.state.RoundStartTime = gm.clock.Now()
+	gm.state.StartedAt = gm.clock.Now()
+	gm.requestSnapshotInternal()
 
 	// Start resource gathering phase
 	go gm.runResourceGatheringPhase()
@@ -166,43 +320,69 @@ func (gm *GameManager) runResourceGatheringPhase() {
 	// Send resource phase start message
 	gm.broadcastChan <- BroadcastMessage{
 		Type: MsgResourcePhaseStart,
-		Payload: map[string]interface{}{
-			"resourceHashes": constants.ResourceStationHashes,
+		Payload: ResourcePhaseStartPayload{
+			ResourceHashes: constants.ResourceStationHashes,
 		},
 	}
 
-	// FIXED: Each round is exactly 60 seconds with 1 question per round
-	roundDuration := time.Duration(constants.ResourceGatheringRoundDuration) * time.Second
+	gm.runResourceGatheringRounds(1, 0)
+}
+
+// runResourceGatheringRounds runs the trivia rounds starting at firstRound. A non-zero
+// firstRoundElapsed resumes a round restored from a snapshot instead of starting it fresh.
+func (gm *GameManager) runResourceGatheringRounds(firstRound int, firstRoundElapsed time.Duration) {
+	// Settings are locked once the game starts, so they can be read once up front
+	settings := gm.GetSettings()
+	cancel := gm.gameCancelChan()
+
+	// One question per round, for as many rounds as the host configured
+	for round := firstRound; round <= settings.ResourceRounds; round++ {
+		resumed := round == firstRound && firstRoundElapsed > 0
 
-	// Run exactly 5 rounds as specified
-	for round := 1; round <= constants.ResourceGatheringRounds; round++ {
 		gm.mu.Lock()
+		if gm.state.Phase != PhaseResourceGathering {
+			// The host skipped or aborted before this loop got going
+			gm.mu.Unlock()
+			return
+		}
 		gm.state.CurrentRound = round
-		gm.state.RoundStartTime = time.Now()
+		if !resumed {
+			// A restored round keeps its start and added time so the remaining time carries over
+			gm.state.RoundStartTime = gm.clock.Now()
+			gm.state.RoundExtension = 0
+		}
+		gm.requestSnapshotInternal()
 		gm.mu.Unlock()
 
 		// Send round start to host
 		gm.sendHostUpdate()
 
 		// FIXED: Send exactly ONE question to all non-host players at the start of each round
-		gm.sendSynchronizedTriviaQuestion()
+		if !resumed {
+			gm.sendSynchronizedTriviaQuestion()
+		}
 
-		// Wait for the full round duration (60 seconds)
-		select {
-		case <-time.After(roundDuration):
-			// Round completed normally
-			log.Printf("Round %d completed", round)
-		case <-gm.stopChan:
-			// Game was stopped
+		// Wait for the rest of the round, however long the host pauses or extends it
+		roundTimer := gm.newPhaseTimer(gm.roundRemainingInternal)
+		gm.recordCheckpoint("round_started", nil)
+
+		if !gm.waitForPhaseTimer(roundTimer, cancel) {
+			// Game was stopped, skipped or aborted
 			return
 		}
+		log.Printf("Round %d completed", round)
 
 		// Send progress update after each round
 		gm.sendTeamProgressUpdate()
 	}
 
-	// All rounds completed, transition to puzzle phase
-	gm.startPuzzlePhase()
+	// All rounds completed, transition to puzzle phase unless the host already moved the game on
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	if isCancelled(cancel) || gm.state.Phase != PhaseResourceGathering {
+		return
+	}
+	gm.startPuzzlePhaseInternal()
 }
 
 // sendSynchronizedTriviaQuestion sends ONE question to all non-host players simultaneously
@@ -215,12 +395,17 @@ func (gm *GameManager) sendSynchronizedTriviaQuestion() {
 		return
 	}
 
+	// Draw questions in a stable order so each player's question depends only on the seed
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].ID < players[j].ID
+	})
+
 	gm.mu.RLock()
 	difficulty := gm.state.Difficulty
 	gm.mu.RUnlock()
 
 	// Send the same question timing to all players, but personalized questions
-	questionSentTime := time.Now()
+	questionSentTime := gm.clock.Now()
 
 	for _, player := range players {
 		// Get player's question history for this specific player
@@ -229,7 +414,7 @@ func (gm *GameManager) sendSynchronizedTriviaQuestion() {
 		gm.mu.RUnlock()
 
 		// Get a personalized question for this player
-		question, err := gm.triviaManager.GetQuestion(difficulty, player.Specialties, history)
+		question, err := gm.pickQuestion(player, difficulty, history)
 		if err != nil {
 			log.Printf("Error getting trivia question for player %s: %v", player.ID, err)
 			continue
@@ -258,11 +443,11 @@ func (gm *GameManager) sendSynchronizedTriviaQuestion() {
 // runTriviaRound manages a single trivia round - Updated to only send to non-host players
 func (gm *GameManager) runTriviaRound() {
 	difficultyMod := gm.getDifficultyModifiers()
-	roundDuration := time.Duration(float64(constants.ResourceGatheringRoundDuration)*difficultyMod.TimeLimitModifier) * time.Second
+	roundDuration := time.Duration(float64(gm.GetSettings().RoundDuration)*difficultyMod.TimeLimitModifier) * time.Second
 
-	roundEnd := time.Now().Add(roundDuration)
+	roundEnd := gm.clock.Now().Add(roundDuration)
 
-	for time.Now().Before(roundEnd) {
+	for gm.clock.Now().Before(roundEnd) {
 		// Send trivia questions to all connected NON-HOST players only
 		players := gm.playerManager.GetConnectedNonHostPlayers()
 
@@ -274,7 +459,7 @@ func (gm *GameManager) runTriviaRound() {
 		gm.sendTeamProgressUpdate()
 
 		select {
-		case <-time.After(30 * time.Second): // Fixed 30 second interval
+		case <-gm.clock.After(30 * time.Second): // Fixed 30 second interval
 			continue
 		case <-gm.stopChan:
 			return
@@ -291,7 +476,7 @@ func (gm *GameManager) sendTriviaQuestion(player *Player) {
 	gm.mu.RUnlock()
 
 	// Get a question
-	question, err := gm.triviaManager.GetQuestion(difficulty, player.Specialties, history)
+	question, err := gm.pickQuestion(player, difficulty, history)
 	if err != nil {
 		log.Printf("Error getting trivia question for player %s: %v", player.ID, err)
 		return
@@ -314,7 +499,11 @@ func (gm *GameManager) ProcessTriviaAnswer(playerID, questionID, answer string)
 
 	// Validate game phase
 	if gm.state.Phase != PhaseResourceGathering {
-		return fmt.Errorf("not in resource gathering phase")
+		return errNotInResourcePhase
+	}
+
+	if gm.state.Paused {
+		return errGamePaused
 	}
 
 	// Get player
@@ -329,26 +518,22 @@ func (gm *GameManager) ProcessTriviaAnswer(playerID, questionID, answer string)
 	player.mu.RUnlock()
 
 	if isHost {
-		return fmt.Errorf("host does not participate in trivia questions")
+		return newCodedError(CodeHostNotPlayer, "host does not participate in trivia questions")
 	}
 
 	// Check if player has a location (must be at a resource station)
 	if player.CurrentLocation == "" {
-		return fmt.Errorf("player must be at a resource station to answer questions")
+		return errNotAtStation
 	}
 
 	// Get the current question for this player
 	currentQuestion, exists := gm.state.CurrentQuestions[playerID]
 	if !exists || currentQuestion.ID != questionID {
-		return fmt.Errorf("invalid or expired question ID")
+		return errQuestionNotFound
 	}
 
-	// Validate answer using the trivia manager's enhanced comparison
-	correct, err := gm.triviaManager.ValidateAnswer(questionID, answer)
-	if err != nil {
-		log.Printf("Error validating answer for question %s: %v", questionID, err)
-		return fmt.Errorf("error validating answer")
-	}
+	// Validate against the question the player was actually asked, using the trivia manager's enhanced comparison
+	correct := gm.triviaManager.CheckAnswer(currentQuestion, answer)
 
 	// Initialize analytics if not exists
 	if gm.state.PlayerAnalytics[playerID] == nil {
@@ -367,6 +552,17 @@ func (gm *GameManager) ProcessTriviaAnswer(playerID, questionID, answer string)
 	analytics := gm.state.PlayerAnalytics[playerID]
 	analytics.TriviaPerformance.TotalQuestions++
 
+	answerRecord := TriviaAnswerRecord{
+		PlayerID:    playerID,
+		QuestionID:  questionID,
+		Category:    currentQuestion.Category,
+		IsSpecialty: currentQuestion.IsSpecialty,
+		Answer:      answer,
+		Correct:     correct,
+		Round:       gm.state.CurrentRound,
+		AnsweredAt:  gm.clock.Now(),
+	}
+
 	// Check if this is a specialty question
 	isSpecialtyQuestion := currentQuestion.IsSpecialty
 	if isSpecialtyQuestion {
@@ -433,6 +629,9 @@ func (gm *GameManager) ProcessTriviaAnswer(playerID, questionID, answer string)
 			}
 			analytics.TokenCollection[tokenType] += tokensAwarded
 
+			answerRecord.TokenType = tokenType
+			answerRecord.TokensAwarded = tokensAwarded
+
 			log.Printf("Player %s answered correctly, awarded %d %s tokens (specialty: %v, role bonus: %v)",
 				playerID, tokensAwarded, tokenType, isSpecialtyQuestion,
 				constants.RoleTokenBonuses[player.Role] == tokenType)
@@ -448,6 +647,9 @@ func (gm *GameManager) ProcessTriviaAnswer(playerID, questionID, answer string)
 		}
 	}
 
+	gm.state.TriviaAnswers = append(gm.state.TriviaAnswers, answerRecord)
+	gm.requestSnapshotInternal()
+
 	return nil
 }
 
@@ -457,6 +659,11 @@ func (gm *GameManager) updateAllGuideHighlights() {
 	players := gm.playerManager.GetConnectedNonHostPlayers()
 
 	for _, player := range players {
+		// Nothing changed for players whose clients don't show highlights
+		if !playerSupports(player, FeatureGuideHighlights) {
+			continue
+		}
+
 		// Calculate new guide highlight for this player
 		highlight := gm.calculateGuideHighlight(player.ID)
 
@@ -472,17 +679,11 @@ func (gm *GameManager) updateAllGuideHighlights() {
 // calculateGuideHighlight calculates linear progression guide highlighting for a specific player
 func (gm *GameManager) calculateGuideHighlight(playerID string) *GuideHighlight {
 	// Calculate current guide token threshold level
-	difficultyMod := gm.getDifficultyModifiers()
-	tokensPerThreshold := int(float64(constants.GuideTokenThresholds) * difficultyMod.TokenThresholdModifier)
-
-	currentLevel := 0
-	if tokensPerThreshold > 0 {
-		currentLevel = gm.state.TeamTokens.GuideTokens / tokensPerThreshold
-	}
+	currentLevel := gm.thresholdsReached(gm.state.TeamTokens.GuideTokens, gm.state.Settings.GuideThreshold)
 
 	// Cap at maximum threshold level
-	if currentLevel >= len(constants.GuideHighlightSizes) {
-		currentLevel = len(constants.GuideHighlightSizes) - 1
+	if currentLevel >= len(gm.balance.GuideHighlightSizes) {
+		currentLevel = len(gm.balance.GuideHighlightSizes) - 1
 	}
 
 	// Get the player's fragment to determine correct position
@@ -493,7 +694,7 @@ func (gm *GameManager) calculateGuideHighlight(playerID string) *GuideHighlight
 			PlayerID:       playerID,
 			Positions:      []GridPos{},
 			ThresholdLevel: currentLevel,
-			MaxThresholds:  constants.GuideTokenMaxThresholds,
+			MaxThresholds:  len(gm.balance.GuideHighlightSizes),
 			CoverageSize:   0.0,
 		}
 	}
@@ -502,26 +703,26 @@ func (gm *GameManager) calculateGuideHighlight(playerID string) *GuideHighlight
 	positions := gm.calculateHighlightPositions(fragment.CorrectPosition, currentLevel)
 
 	coverageSize := 0.0
-	if currentLevel < len(constants.GuideHighlightSizes) {
-		coverageSize = constants.GuideHighlightSizes[currentLevel]
+	if currentLevel < len(gm.balance.GuideHighlightSizes) {
+		coverageSize = gm.balance.GuideHighlightSizes[currentLevel]
 	}
 
 	return &GuideHighlight{
 		PlayerID:       playerID,
 		Positions:      positions,
 		ThresholdLevel: currentLevel,
-		MaxThresholds:  constants.GuideTokenMaxThresholds,
+		MaxThresholds:  len(gm.balance.GuideHighlightSizes),
 		CoverageSize:   coverageSize,
 	}
 }
 
 // calculateHighlightPositions calculates the grid positions to highlight based on threshold level
 func (gm *GameManager) calculateHighlightPositions(correctPos GridPos, thresholdLevel int) []GridPos {
-	if thresholdLevel < 0 || thresholdLevel >= len(constants.GuideHighlightSizes) {
+	if thresholdLevel < 0 || thresholdLevel >= len(gm.balance.GuideHighlightSizes) {
 		return []GridPos{}
 	}
 
-	coveragePercent := constants.GuideHighlightSizes[thresholdLevel]
+	coveragePercent := gm.balance.GuideHighlightSizes[thresholdLevel]
 	gridSize := gm.state.GridSize
 	totalPositions := gridSize * gridSize
 	// Round up to ensure we get enough positions
@@ -538,7 +739,7 @@ func (gm *GameManager) calculateHighlightPositions(correctPos GridPos, threshold
 	}
 
 	// Highest precision level = exactly 2 positions
-	if thresholdLevel == len(constants.GuideHighlightSizes)-1 {
+	if thresholdLevel == len(gm.balance.GuideHighlightSizes)-1 {
 		positionsToHighlight = 2
 	}
 
@@ -631,23 +832,38 @@ func (gm *GameManager) startPuzzlePhase() {
 	gm.mu.Lock()
 	defer gm.mu.Unlock()
 
+	gm.startPuzzlePhaseInternal()
+}
+
+// startPuzzlePhaseInternal lays out the puzzle and tells every player their segment
+// NOTE: This method assumes the caller already holds gm.mu lock
+func (gm *GameManager) startPuzzlePhaseInternal() {
 	gm.state.Phase = PhasePuzzleAssembly
 
 	// Calculate grid size based on NON-HOST player count
 	nonHostPlayers := gm.playerManager.GetConnectedNonHostPlayers()
 	playerCount := len(nonHostPlayers)
 	gridSize := gm.calculateGridSize(playerCount)
+	if fixed := gm.state.Settings.GridSize; fixed > 0 && fixed*fixed >= playerCount {
+		gridSize = fixed
+	}
+
+	// Lay fragments out in a stable order so the puzzle depends only on the game seed
+	sort.Slice(nonHostPlayers, func(i, j int) bool {
+		return nonHostPlayers[i].ID < nonHostPlayers[j].ID
+	})
 	gm.state.GridSize = gridSize
 
 	// Select random puzzle image
-	gm.state.PuzzleImageID = fmt.Sprintf("masterpiece_%03d", rand.Intn(constants.AvailablePuzzleImages)+1)
+	gm.state.PuzzleImageID = fmt.Sprintf("masterpiece_%03d", gm.rng.Intn(constants.AvailablePuzzleImages)+1)
 
 	// Calculate anchor token effects (pre-solved pieces)
-	anchorThresholds := gm.state.TeamTokens.AnchorTokens / (constants.AnchorTokenThresholds * int(gm.getDifficultyModifiers().TokenThresholdModifier))
+	anchorThresholds := gm.thresholdsReached(gm.state.TeamTokens.AnchorTokens, gm.state.Settings.AnchorThreshold)
 	maxPreSolved := min(anchorThresholds, constants.IndividualPuzzlePieces-4) // Leave at least 4 pieces to solve
 
 	// Initialize puzzle fragments for NON-HOST players only
 	gm.state.PuzzleFragments = make(map[string]*PuzzleFragment)
+	gm.state.FragmentHolds = make(map[string]time.Time)
 
 	// Create player-owned fragments
 	for i, player := range nonHostPlayers {
@@ -694,15 +910,15 @@ func (gm *GameManager) startPuzzlePhase() {
 	}
 
 	// Send clarity bonus (image preview)
-	clarityThresholds := gm.state.TeamTokens.ClarityTokens / (constants.ClarityTokenThresholds * int(gm.getDifficultyModifiers().TokenThresholdModifier))
+	clarityThresholds := gm.thresholdsReached(gm.state.TeamTokens.ClarityTokens, gm.state.Settings.ClarityThreshold)
 	previewDuration := clarityThresholds * constants.ClarityTimeBonus
 
 	if previewDuration > 0 {
 		gm.broadcastChan <- BroadcastMessage{
 			Type: MsgImagePreview,
-			Payload: map[string]interface{}{
-				"imageId":  gm.state.PuzzleImageID,
-				"duration": previewDuration,
+			Payload: ImagePreviewPayload{
+				ImageID:  gm.state.PuzzleImageID,
+				Duration: previewDuration,
 			},
 		}
 	}
@@ -712,25 +928,28 @@ func (gm *GameManager) startPuzzlePhase() {
 		fragment := gm.state.PuzzleFragments[fmt.Sprintf("fragment_%s", player.ID)]
 		segmentID := fmt.Sprintf("segment_%c%d", 'a'+fragment.CorrectPosition.Y, fragment.CorrectPosition.X+1)
 
-		sendToPlayer(player, MsgPuzzlePhaseLoad, map[string]interface{}{
-			"imageId":   gm.state.PuzzleImageID,
-			"segmentId": segmentID,
-			"gridSize":  gridSize,
-			"preSolved": fragment.PreSolved,
+		sendToPlayer(player, MsgPuzzlePhaseLoad, PuzzlePhaseLoadPayload{
+			ImageID:   gm.state.PuzzleImageID,
+			SegmentID: segmentID,
+			GridSize:  gridSize,
+			PreSolved: fragment.PreSolved,
 		})
 	}
 
 	// Send a different message to the host
 	host := gm.playerManager.GetHost()
 	if host != nil {
-		sendToPlayer(host, MsgPuzzlePhaseLoad, map[string]interface{}{
-			"imageId":     gm.state.PuzzleImageID,
-			"gridSize":    gridSize,
-			"isHost":      true,
-			"playerCount": len(nonHostPlayers),
-			"message":     "Puzzle phase started - monitor player progress",
+		sendToPlayer(host, MsgPuzzlePhaseLoad, PuzzlePhaseLoadPayload{
+			ImageID:     gm.state.PuzzleImageID,
+			GridSize:    gridSize,
+			IsHost:      true,
+			PlayerCount: len(nonHostPlayers),
+			Message:     "Puzzle phase started - monitor player progress",
 		})
 	}
+
+	gm.requestSnapshotInternal()
+	gm.recordCheckpointInternal("puzzle_phase_started")
 }
 
 // calculateCorrectPosition determines the correct position for a fragment
@@ -747,61 +966,83 @@ func (gm *GameManager) StartPuzzle() error {
 
 	if gm.state.Phase != PhasePuzzleAssembly {
 		gm.mu.Unlock()
-		return fmt.Errorf("not in puzzle assembly phase")
+		return errNotInPuzzlePhase
 	}
 
-	gm.state.PuzzleStartTime = time.Now()
+	gm.state.PuzzleStartTime = gm.clock.Now()
+	settings := gm.state.Settings
+	difficultyMod := gm.getDifficultyModifiers()
+	chronosThresholds := gm.thresholdsReached(gm.state.TeamTokens.ChronosTokens, settings.ChronosThreshold)
 	gm.mu.Unlock()
 
 	// IMPLEMENTED: Calculate total time with chronos bonuses and difficulty modifiers
-	difficultyMod := gm.getDifficultyModifiers()
-	baseTime := int(float64(constants.PuzzleAssemblyBaseTime) * difficultyMod.TimeLimitModifier)
+	baseTime := int(float64(settings.PuzzleBaseTime) * difficultyMod.TimeLimitModifier)
 
-	chronosThresholds := gm.state.TeamTokens.ChronosTokens / (constants.ChronosTokenThresholds * int(difficultyMod.TokenThresholdModifier))
 	chronosBonus := chronosThresholds * constants.ChronosTimeBonus
 
 	totalTime := baseTime + chronosBonus
 
+	gm.mu.Lock()
+	gm.state.PuzzleDuration = time.Duration(totalTime) * time.Second
+	gm.requestSnapshotInternal()
+	gm.mu.Unlock()
+
 	// Send puzzle phase start
 	gm.broadcastChan <- BroadcastMessage{
 		Type: MsgPuzzlePhaseStart,
-		Payload: map[string]interface{}{
-			"startTimestamp": time.Now().Unix(),
-			"totalTime":      totalTime,
+		Payload: PuzzlePhaseStartPayload{
+			StartTimestamp: gm.clock.Now().Unix(),
+			TotalTime:      totalTime,
 		},
 	}
 
 	// Start puzzle timer
-	go gm.runPuzzleTimer(time.Duration(totalTime) * time.Second)
+	gm.startPuzzleTimer()
 
 	return nil
 }
 
+// startPuzzleTimer arms the puzzle timers before returning, so they count from the moment the puzzle started
+func (gm *GameManager) startPuzzleTimer() {
+	cancel := gm.gameCancelChan()
+	timer := gm.newPhaseTimer(gm.puzzleRemainingInternal)
+	ticker := gm.clock.NewTicker(5 * time.Second)
+
+	// ADDED: Fragment release ticker - release one unassigned fragment every 30 seconds
+	fragmentReleaseTicker := gm.clock.NewTicker(30 * time.Second)
+
+	go gm.runPuzzleTimer(timer, ticker, fragmentReleaseTicker, cancel)
+}
+
 // Add this to the runPuzzleTimer function to gradually release unassigned fragments
-func (gm *GameManager) runPuzzleTimer(duration time.Duration) {
-	timer := time.NewTimer(duration)
+func (gm *GameManager) runPuzzleTimer(timer *phaseTimer, ticker, fragmentReleaseTicker Ticker, cancel <-chan struct{}) {
 	defer timer.Stop()
-
-	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
-
-	// ADDED: Fragment release ticker - release one unassigned fragment every 30 seconds
-	fragmentReleaseTicker := time.NewTicker(30 * time.Second)
 	defer fragmentReleaseTicker.Stop()
 
 	for {
 		select {
-		case <-timer.C:
-			// Time's up!
-			gm.endGame(false)
+		case <-timer.C():
+			if !timer.expired() {
+				timer.rearm()
+				continue
+			}
+			// Time's up, unless the host aborted the game as the timer fired
+			if gm.GetPhase() == PhasePuzzleAssembly && !isCancelled(cancel) {
+				gm.endGame(false)
+			}
 			return
-		case <-ticker.C:
+		case <-gm.timerChanged:
+			timer.rearm()
+		case <-ticker.C():
 			// Send progress updates
 			gm.sendPuzzleProgress()
 			gm.sendHostUpdate()
-		case <-fragmentReleaseTicker.C:
+		case <-fragmentReleaseTicker.C():
 			// ADDED: Release one unassigned fragment periodically
 			gm.releaseUnassignedFragment()
+		case <-cancel:
+			return
 		case <-gm.stopChan:
 			return
 		}
@@ -827,11 +1068,14 @@ func (gm *GameManager) BroadcastPersonalPuzzleStates() {
 			Fragments:        visibleFragments,
 			GridSize:         gm.state.GridSize,
 			PlayerFragmentID: fmt.Sprintf("fragment_%s", player.ID),
-			GuideHighlight:   gm.calculateGuideHighlight(player.ID),
+		}
+		if playerSupports(player, FeatureGuideHighlights) {
+			personalState.GuideHighlight = gm.calculateGuideHighlight(player.ID)
 		}
 
-		sendToPlayer(player, MsgPersonalPuzzleState, map[string]interface{}{
-			"personalView": personalState,
+		sendToPlayer(player, MsgPersonalPuzzleState, PersonalPuzzleStatePayload{
+			PersonalView: personalState,
+			Seq:          gm.state.PuzzleSeq,
 		})
 	}
 
@@ -845,7 +1089,7 @@ func (gm *GameManager) ProcessSegmentCompleted(playerID, segmentID string) error
 	defer gm.mu.Unlock()
 
 	if gm.state.Phase != PhasePuzzleAssembly {
-		return fmt.Errorf("not in puzzle assembly phase")
+		return errNotInPuzzlePhase
 	}
 
 	// Get player and verify they're not the host
@@ -859,22 +1103,22 @@ func (gm *GameManager) ProcessSegmentCompleted(playerID, segmentID string) error
 	player.mu.RUnlock()
 
 	if isHost {
-		return fmt.Errorf("host does not have puzzle segments to complete")
+		return newCodedError(CodeHostNotPlayer, "host does not have puzzle segments to complete")
 	}
 
 	// Find the fragment
 	fragmentID := fmt.Sprintf("fragment_%s", playerID)
 	fragment := gm.state.PuzzleFragments[fragmentID]
 	if fragment == nil {
-		return fmt.Errorf("fragment not found for player %s", playerID)
+		return fmt.Errorf("%w: %s", errFragmentNotFound, fragmentID)
 	}
 
 	if fragment.PreSolved {
-		return fmt.Errorf("fragment was pre-solved by anchor tokens")
+		return newCodedError(CodeFragmentCompleted, "fragment was pre-solved by anchor tokens")
 	}
 
 	if fragment.Solved {
-		return fmt.Errorf("fragment already completed")
+		return newCodedError(CodeFragmentCompleted, "fragment already completed")
 	}
 
 	// CRITICAL: Mark fragment as solved and visible (this is the transformation moment)
@@ -883,24 +1127,23 @@ func (gm *GameManager) ProcessSegmentCompleted(playerID, segmentID string) error
 
 	// Update analytics
 	if analytics, ok := gm.state.PlayerAnalytics[playerID]; ok {
-		analytics.PuzzleMetrics.FragmentSolveTime = int(time.Since(gm.state.PuzzleStartTime).Seconds())
+		analytics.PuzzleMetrics.FragmentSolveTime = int(gm.clock.Now().Sub(gm.state.PuzzleStartTime).Seconds())
 	}
 
 	// Send acknowledgment
-	sendToPlayer(player, MsgSegmentCompletionAck, map[string]interface{}{
-		"status":       "acknowledged",
-		"segmentId":    segmentID,
-		"gridPosition": fragment.Position,
+	sendToPlayer(player, MsgSegmentCompletionAck, SegmentCompletionAckPayload{
+		Status:       "acknowledged",
+		SegmentID:    segmentID,
+		GridPosition: fragment.Position,
 	})
 
 	log.Printf("Player %s completed individual puzzle segment %s, fragment %s is now visible on central grid",
 		playerID, segmentID, fragmentID)
 
-	// ENHANCED: Update all players' personal puzzle states since a new fragment is now visible
-	gm.BroadcastPersonalPuzzleStates()
+	// Everyone adds the newly visible fragment to their grid
+	gm.sendFragmentRevealedInternal(fragment)
 
-	// Update host with new fragment visibility
-	gm.sendCompletePuzzleStateToHost()
+	gm.requestSnapshotInternal()
 
 	// Check if all fragments are solved and positioned correctly
 	if gm.checkPuzzleComplete() {
@@ -912,7 +1155,7 @@ func (gm *GameManager) ProcessSegmentCompleted(playerID, segmentID string) error
 
 // IMPLEMENTED: Send guide token hints for piece placement
 func (gm *GameManager) sendGuideHints(playerID string) {
-	guideThresholds := gm.state.TeamTokens.GuideTokens / (constants.GuideTokenThresholds * int(gm.getDifficultyModifiers().TokenThresholdModifier))
+	guideThresholds := gm.thresholdsReached(gm.state.TeamTokens.GuideTokens, gm.state.Settings.GuideThreshold)
 
 	if guideThresholds > 0 {
 		fragment := gm.state.PuzzleFragments[fmt.Sprintf("fragment_%s", playerID)]
@@ -938,9 +1181,9 @@ func (gm *GameManager) sendGuideHints(playerID string) {
 			}
 
 			if player, _ := gm.playerManager.GetPlayer(playerID); player != nil {
-				sendToPlayer(player, MsgPieceRecommendation, map[string]interface{}{
-					"type":  "guide_hint",
-					"hints": hints,
+				sendToPlayer(player, MsgPieceRecommendation, GuideHintPayload{
+					Type:  "guide_hint",
+					Hints: hints,
 				})
 			}
 		}
@@ -953,44 +1196,59 @@ func (gm *GameManager) ProcessFragmentMove(playerID, fragmentID string, newPos G
 	defer gm.mu.Unlock()
 
 	if gm.state.Phase != PhasePuzzleAssembly {
-		return fmt.Errorf("not in puzzle assembly phase")
+		return errNotInPuzzlePhase
+	}
+
+	if gm.state.Paused {
+		player, _ := gm.playerManager.GetPlayer(playerID)
+		if player != nil {
+			sendToPlayer(player, MsgFragmentMoveResponse, FragmentMoveResponsePayload{
+				Status:     "denied",
+				Code:       CodeGamePaused,
+				Reason:     constants.ErrGamePaused,
+				FragmentID: fragmentID,
+			})
+		}
+		return alreadyAnswered(errGamePaused)
 	}
 
 	fragment, exists := gm.state.PuzzleFragments[fragmentID]
 	if !exists {
-		return fmt.Errorf("fragment not found: %s", fragmentID)
+		return fmt.Errorf("%w: %s", errFragmentNotFound, fragmentID)
 	}
 
 	// ENHANCED: Validate fragment ownership
 	if err := gm.validateFragmentOwnership(playerID, fragment); err != nil {
 		player, _ := gm.playerManager.GetPlayer(playerID)
 		if player != nil {
-			sendToPlayer(player, MsgFragmentMoveResponse, map[string]interface{}{
-				"status":     "denied",
-				"reason":     err.Error(),
-				"fragmentId": fragmentID,
+			sendToPlayer(player, MsgFragmentMoveResponse, FragmentMoveResponsePayload{
+				Status:     "denied",
+				Code:       errorCodeOf(err),
+				Reason:     err.Error(),
+				FragmentID: fragmentID,
 			})
 		}
-		return err
+		return alreadyAnswered(err)
 	}
 
 	// Check cooldown
 	cooldownDuration := time.Duration(constants.FragmentMovementCooldown) * time.Millisecond
-	if time.Since(fragment.LastMoved) < cooldownDuration {
+	if gm.clock.Now().Sub(fragment.LastMoved) < cooldownDuration {
 		player, _ := gm.playerManager.GetPlayer(playerID)
 		if player != nil {
-			sendToPlayer(player, MsgFragmentMoveResponse, map[string]interface{}{
-				"status":            "ignored",
-				"reason":            "cooldown",
-				"nextMoveAvailable": fragment.LastMoved.Add(cooldownDuration).Unix(),
+			sendToPlayer(player, MsgFragmentMoveResponse, FragmentMoveResponsePayload{
+				Status:            "ignored",
+				Code:              CodeFragmentCooldown,
+				Reason:            "cooldown",
+				NextMoveAvailable: fragment.LastMoved.Add(cooldownDuration).Unix(),
 			})
 		}
-		return nil // Don't return error for cooldown, just ignore
+		return alreadyAnswered(errFragmentCooldown)
 	}
 
 	// Validate new position
 	if newPos.X < 0 || newPos.X >= gm.state.GridSize || newPos.Y < 0 || newPos.Y >= gm.state.GridSize {
-		return fmt.Errorf("position out of bounds: (%d, %d)", newPos.X, newPos.Y)
+		return fmt.Errorf("%w: (%d, %d)", errPositionOutOfBounds, newPos.X, newPos.Y)
 	}
 
 	// Find fragment at target position and handle collision
@@ -1007,13 +1265,13 @@ func (gm *GameManager) ProcessFragmentMove(playerID, fragmentID string, newPos G
 		// Swap positions
 		fragment.Position = newPos
 		targetFragment.Position = oldPos
-		targetFragment.LastMoved = time.Now()
+		targetFragment.LastMoved = gm.clock.Now()
 	} else {
 		// Move to empty position
 		fragment.Position = newPos
 	}
 
-	fragment.LastMoved = time.Now()
+	fragment.LastMoved = gm.clock.Now()
 
 	// Record move in history
 	gm.state.FragmentMoveHistory = append(gm.state.FragmentMoveHistory, FragmentMove{
@@ -1021,7 +1279,7 @@ func (gm *GameManager) ProcessFragmentMove(playerID, fragmentID string, newPos G
 		FromPos:    oldPos,
 		ToPos:      newPos,
 		PlayerID:   playerID,
-		Timestamp:  time.Now(),
+		Timestamp:  gm.clock.Now(),
 	})
 
 	// Update analytics
@@ -1033,17 +1291,20 @@ func (gm *GameManager) ProcessFragmentMove(playerID, fragmentID string, newPos G
 	// Send success response
 	player, _ := gm.playerManager.GetPlayer(playerID)
 	if player != nil {
-		sendToPlayer(player, MsgFragmentMoveResponse, map[string]interface{}{
-			"status":   "success",
-			"fragment": fragment,
+		sendToPlayer(player, MsgFragmentMoveResponse, FragmentMoveResponsePayload{
+			Status:   "success",
+			Fragment: fragment,
 		})
 	}
 
-	// ENHANCED: Broadcast updated personal puzzle states to all players
-	gm.BroadcastPersonalPuzzleStates()
+	// Everyone applies the move to their copy of the grid
+	if targetFragment != nil {
+		gm.sendFragmentsMovedInternal(playerID, fragment, targetFragment)
+	} else {
+		gm.sendFragmentsMovedInternal(playerID, fragment)
+	}
 
-	// Update host with complete puzzle state
-	gm.sendCompletePuzzleStateToHost()
+	gm.requestSnapshotInternal()
 
 	// Check if puzzle is complete after move
 	if gm.checkPuzzleComplete() {
@@ -1070,11 +1331,14 @@ func (gm *GameManager) sendPersonalPuzzleState(player *Player, guideHighlight *G
 		Fragments:        visibleFragments,
 		GridSize:         gm.state.GridSize,
 		PlayerFragmentID: fmt.Sprintf("fragment_%s", player.ID),
-		GuideHighlight:   guideHighlight,
+	}
+	if playerSupports(player, FeatureGuideHighlights) {
+		personalState.GuideHighlight = guideHighlight
 	}
 
-	sendToPlayer(player, MsgPersonalPuzzleState, map[string]interface{}{
-		"personalView": personalState,
+	sendToPlayer(player, MsgPersonalPuzzleState, PersonalPuzzleStatePayload{
+		PersonalView: personalState,
+		Seq:          gm.state.PuzzleSeq,
 	})
 }
 
@@ -1120,6 +1384,7 @@ func (gm *GameManager) sendCompletePuzzleStateToHost() {
 		CompletionPercent:   gm.calculateCompletionPercentage(),
 		MovementHistory:     gm.getRecentMovementHistory(10), // Last 10 moves
 		CollaborationStats:  collaborationStats,
+		Seq:                 gm.state.PuzzleSeq,
 	}
 
 	// Send to host
@@ -1259,32 +1524,32 @@ func (gm *GameManager) ProcessPieceRecommendation(fromPlayerID, toPlayerID strin
 	defer gm.mu.Unlock()
 
 	if gm.state.Phase != PhasePuzzleAssembly {
-		return fmt.Errorf("not in puzzle assembly phase")
+		return errNotInPuzzlePhase
 	}
 
 	// FIXED: Validate that both fragments are unassigned or movable by anyone
 	fromFragment, exists := gm.state.PuzzleFragments[fromFragmentID]
 	if !exists {
-		return fmt.Errorf("from fragment not found: %s", fromFragmentID)
+		return fmt.Errorf("%w: %s", errFragmentNotFound, fromFragmentID)
 	}
 
 	toFragment, exists := gm.state.PuzzleFragments[toFragmentID]
 	if !exists {
-		return fmt.Errorf("to fragment not found: %s", toFragmentID)
+		return fmt.Errorf("%w: %s", errFragmentNotFound, toFragmentID)
 	}
 
 	// Check if fragments are unassigned or community-movable
 	if fromFragment.MovableBy != "anyone" || !fromFragment.IsUnassigned {
-		return fmt.Errorf(constants.ErrInvalidRecommendation + ": from fragment is player-owned")
+		return fmt.Errorf("%w: from fragment is player-owned", errInvalidRecommendation)
 	}
 
 	if toFragment.MovableBy != "anyone" || !toFragment.IsUnassigned {
-		return fmt.Errorf(constants.ErrInvalidRecommendation + ": to fragment is player-owned")
+		return fmt.Errorf("%w: to fragment is player-owned", errInvalidRecommendation)
 	}
 
 	// Create recommendation
 	recommendation := &PieceRecommendation{
-		ID:               uuid.New().String(),
+		ID:               uuid.Must(uuid.NewRandomFromReader(gm.rng)).String(),
 		FromPlayerID:     fromPlayerID,
 		ToPlayerID:       toPlayerID,
 		FromFragmentID:   fromFragmentID,
@@ -1292,7 +1557,7 @@ func (gm *GameManager) ProcessPieceRecommendation(fromPlayerID, toPlayerID strin
 		SuggestedFromPos: suggestedFromPos,
 		SuggestedToPos:   suggestedToPos,
 		Message:          "", // FIXED: No longer accept custom messages
-		Timestamp:        time.Now(),
+		Timestamp:        gm.clock.Now(),
 	}
 
 	gm.state.PieceRecommendations[recommendation.ID] = recommendation
@@ -1320,22 +1585,30 @@ func (gm *GameManager) ProcessPieceRecommendationResponse(playerID, recommendati
 
 	recommendation, exists := gm.state.PieceRecommendations[recommendationID]
 	if !exists {
-		return fmt.Errorf("recommendation not found")
+		return errRecommendationNotFound
 	}
 
 	if recommendation.ToPlayerID != playerID {
-		return fmt.Errorf("not authorized to respond to this recommendation")
+		return errRecommendationAuth
+	}
+
+	// Accepting moves fragments, which waits until the game resumes; rejecting is always allowed
+	if accepted && gm.state.Paused {
+		return errGamePaused
 	}
 
 	if accepted {
 		// Execute the recommended moves
+		moved := make([]*PuzzleFragment, 0, 2)
 		if fromFragment, exists := gm.state.PuzzleFragments[recommendation.FromFragmentID]; exists {
 			fromFragment.Position = recommendation.SuggestedFromPos
-			fromFragment.LastMoved = time.Now()
+			fromFragment.LastMoved = gm.clock.Now()
+			moved = append(moved, fromFragment)
 		}
 		if toFragment, exists := gm.state.PuzzleFragments[recommendation.ToFragmentID]; exists {
 			toFragment.Position = recommendation.SuggestedToPos
-			toFragment.LastMoved = time.Now()
+			toFragment.LastMoved = gm.clock.Now()
+			moved = append(moved, toFragment)
 		}
 
 		// Update analytics
@@ -1343,8 +1616,8 @@ func (gm *GameManager) ProcessPieceRecommendationResponse(playerID, recommendati
 			analytics.PuzzleMetrics.RecommendationsAccepted++
 		}
 
-		// Broadcast updated puzzle state
-		gm.broadcastPuzzleState()
+		// Everyone applies the moves to their copy of the grid
+		gm.sendFragmentsMovedInternal(playerID, moved...)
 
 		// Check if puzzle is complete
 		if gm.checkPuzzleComplete() {
@@ -1352,7 +1625,16 @@ func (gm *GameManager) ProcessPieceRecommendationResponse(playerID, recommendati
 		}
 	}
 
-	// Remove recommendation
+	// Keep the outcome for the game history, then remove the pending recommendation
+	status := "rejected"
+	if accepted {
+		status = "accepted"
+	}
+	gm.state.ResolvedRecommendations = append(gm.state.ResolvedRecommendations, RecommendationRecord{
+		PieceRecommendation: *recommendation,
+		Status:              status,
+		RespondedAt:         gm.clock.Now(),
+	})
 	delete(gm.state.PieceRecommendations, recommendationID)
 
 	return nil
@@ -1362,11 +1644,17 @@ func (gm *GameManager) ProcessPieceRecommendationResponse(playerID, recommendati
 func (gm *GameManager) endGame(success bool) {
 	gm.mu.Lock()
 	gm.state.Phase = PhasePostGame
+	gm.state.Paused = false
+	gm.requestSnapshotInternal() // The game is over, so this removes the saved snapshot
+	cancel := gm.gameCancel
 	gm.mu.Unlock()
 
 	// Calculate final analytics
 	analytics := gm.calculateFinalAnalytics(success)
 
+	// Record the game before resetGame wipes it
+	gm.saveGameRecord(success, analytics)
+
 	// Send analytics to all players
 	gm.broadcastChan <- BroadcastMessage{
 		Type:    MsgGameAnalytics,
@@ -1377,14 +1665,24 @@ func (gm *GameManager) endGame(success bool) {
 	gm.sendHostUpdate()
 
 	// Start reset timer
+	resetTimer := gm.clock.After(time.Duration(constants.PostGameAnalyticsDuration) * time.Second)
+	gm.recordCheckpoint("game_ended", nil)
+
 	go func() {
-		time.Sleep(time.Duration(constants.PostGameAnalyticsDuration) * time.Second)
-		gm.resetGame()
+		select {
+		case <-resetTimer:
+			gm.resetGame()
+		case <-cancel:
+			// The host started a rematch or reset the game already
+			return
+		case <-gm.stopChan:
+			return
+		}
 	}()
 }
 
 // IMPLEMENTED: Enhanced analytics calculations
-func (gm *GameManager) calculateFinalAnalytics(success bool) map[string]interface{} {
+func (gm *GameManager) calculateFinalAnalytics(success bool) GameAnalyticsPayload {
 	gm.mu.RLock()
 	defer gm.mu.RUnlock()
 
@@ -1397,7 +1695,7 @@ func (gm *GameManager) calculateFinalAnalytics(success bool) map[string]interfac
 		// Calculate category accuracies
 		if analytics.TriviaPerformance.TotalQuestions > 0 {
 			accuracy := float64(analytics.TriviaPerformance.CorrectAnswers) / float64(analytics.TriviaPerformance.TotalQuestions)
-			for _, cat := range constants.TriviaCategories {
+			for _, cat := range gm.triviaManager.GetAvailableCategories() {
 				analytics.TriviaPerformance.AccuracyByCategory[cat] = accuracy
 			}
 		}
@@ -1411,7 +1709,7 @@ func (gm *GameManager) calculateFinalAnalytics(success bool) map[string]interfac
 	// Team analytics
 	totalTime := 0
 	if !gm.state.PuzzleStartTime.IsZero() {
-		totalTime = int(time.Since(gm.state.PuzzleStartTime).Seconds())
+		totalTime = int(gm.clock.Now().Sub(gm.state.PuzzleStartTime).Seconds())
 	}
 
 	// Calculate collaboration score based on recommendations
@@ -1435,7 +1733,7 @@ func (gm *GameManager) calculateFinalAnalytics(success bool) map[string]interfac
 			AcceptedRecommendations: acceptedRecommendations,
 		},
 		ResourceEfficiency: ResourceMetrics{
-			TokensPerRound: float64(gm.getTotalTokens()) / float64(constants.ResourceGatheringRounds),
+			TokensPerRound: float64(gm.getTotalTokens()) / float64(gm.state.Settings.ResourceRounds),
 			TokenDistribution: map[string]float64{
 				constants.TokenAnchor:  float64(gm.state.TeamTokens.AnchorTokens),
 				constants.TokenChronos: float64(gm.state.TeamTokens.ChronosTokens),
@@ -1475,30 +1773,28 @@ func (gm *GameManager) calculateFinalAnalytics(success bool) map[string]interfac
 		})
 	}
 
-	return map[string]interface{}{
-		"personalAnalytics": personalAnalytics,
-		"teamAnalytics":     teamAnalytics,
-		"globalLeaderboard": leaderboard,
-		"gameSuccess":       success,
+	return GameAnalyticsPayload{
+		PersonalAnalytics: personalAnalytics,
+		TeamAnalytics:     teamAnalytics,
+		GlobalLeaderboard: leaderboard,
+		GameSuccess:       success,
 	}
 }
 
 // IMPLEMENTED: Get difficulty modifiers
 func (gm *GameManager) getDifficultyModifiers() constants.DifficultyModifiers {
-	switch gm.state.Difficulty {
-	case "easy":
-		return constants.EasyMode
-	case "hard":
-		return constants.HardMode
-	default:
-		return constants.MediumMode
-	}
+	return gm.balance.DifficultyModifiers.ForDifficulty(gm.state.Difficulty)
+}
+
+// thresholdsReached counts the thresholds a token total has passed, scaled by the difficulty's token threshold modifier
+func (gm *GameManager) thresholdsReached(tokens, tokensPerThreshold int) int {
+	return int(float64(tokens) / (float64(tokensPerThreshold) * gm.getDifficultyModifiers().TokenThresholdModifier))
 }
 
 // Helper functions
 
 func (gm *GameManager) calculateGridSize(playerCount int) int {
-	for _, breakpoint := range constants.GridSizeBreakpoints {
+	for _, breakpoint := range gm.balance.GridSizeBreakpoints {
 		if playerCount >= breakpoint.MinPlayers && playerCount <= breakpoint.MaxPlayers {
 			return breakpoint.GridSize
 		}
@@ -1515,12 +1811,11 @@ func (gm *GameManager) getTotalTokens() int {
 }
 
 func (gm *GameManager) calculateThresholdsReached() map[string]int {
-	difficultyMod := gm.getDifficultyModifiers()
 	return map[string]int{
-		constants.TokenAnchor:  int(float64(gm.state.TeamTokens.AnchorTokens) / (float64(constants.AnchorTokenThresholds) * difficultyMod.TokenThresholdModifier)),
-		constants.TokenChronos: int(float64(gm.state.TeamTokens.ChronosTokens) / (float64(constants.ChronosTokenThresholds) * difficultyMod.TokenThresholdModifier)),
-		constants.TokenGuide:   int(float64(gm.state.TeamTokens.GuideTokens) / (float64(constants.GuideTokenThresholds) * difficultyMod.TokenThresholdModifier)),
-		constants.TokenClarity: int(float64(gm.state.TeamTokens.ClarityTokens) / (float64(constants.ClarityTokenThresholds) * difficultyMod.TokenThresholdModifier)),
+		constants.TokenAnchor:  gm.thresholdsReached(gm.state.TeamTokens.AnchorTokens, gm.state.Settings.AnchorThreshold),
+		constants.TokenChronos: gm.thresholdsReached(gm.state.TeamTokens.ChronosTokens, gm.state.Settings.ChronosThreshold),
+		constants.TokenGuide:   gm.thresholdsReached(gm.state.TeamTokens.GuideTokens, gm.state.Settings.GuideThreshold),
+		constants.TokenClarity: gm.thresholdsReached(gm.state.TeamTokens.ClarityTokens, gm.state.Settings.ClarityThreshold),
 	}
 }
 
@@ -1548,10 +1843,10 @@ func (gm *GameManager) sendTeamProgressUpdate() {
 
 	gm.broadcastChan <- BroadcastMessage{
 		Type: MsgTeamProgressUpdate,
-		Payload: map[string]interface{}{
-			"questionsAnswered": totalQuestions,
-			"totalQuestions":    constants.ResourceGatheringRounds * nonHostPlayerCount,
-			"teamTokens":        gm.state.TeamTokens,
+		Payload: TeamProgressPayload{
+			QuestionsAnswered: totalQuestions,
+			TotalQuestions:    gm.state.Settings.ResourceRounds * nonHostPlayerCount,
+			TeamTokens:        gm.state.TeamTokens,
 		},
 	}
 }
@@ -1571,23 +1866,6 @@ func (gm *GameManager) sendPuzzleProgress() {
 	gm.sendHostUpdateInternal() // Use internal version
 }
 
-func (gm *GameManager) broadcastPuzzleState() {
-	gm.mu.RLock()
-	fragments := make([]*PuzzleFragment, 0, len(gm.state.PuzzleFragments))
-	for _, f := range gm.state.PuzzleFragments {
-		fragments = append(fragments, f)
-	}
-	gm.mu.RUnlock()
-
-	gm.broadcastChan <- BroadcastMessage{
-		Type: MsgCentralPuzzleState,
-		Payload: map[string]interface{}{
-			"fragments": fragments,
-			"gridSize":  gm.state.GridSize,
-		},
-	}
-}
-
 // sendHostUpdate updates host with current game status - Enhanced for new host system
 func (gm *GameManager) sendHostUpdate() {
 	gm.mu.RLock()
@@ -1633,15 +1911,9 @@ func (gm *GameManager) sendHostUpdateInternal() {
 		}
 	}
 
-	// Calculate time remaining
-	var timeRemaining int
-	if gm.state.Phase == PhaseResourceGathering {
-		elapsed := time.Since(gm.state.RoundStartTime)
-		remaining := time.Duration(constants.ResourceGatheringRoundDuration)*time.Second - elapsed
-		if remaining > 0 {
-			timeRemaining = int(remaining.Seconds())
-		}
-	}
+	// Calculate time remaining in the round or puzzle, which stands still while paused
+	remaining, _ := gm.timeRemainingInternal()
+	timeRemaining := int(remaining.Seconds())
 
 	nonHostPlayers := gm.playerManager.GetConnectedNonHostPlayers()
 	readyNonHostPlayers := gm.playerManager.GetReadyNonHostPlayers()
@@ -1652,6 +1924,7 @@ func (gm *GameManager) sendHostUpdateInternal() {
 		ReadyPlayers:     len(readyNonHostPlayers),
 		CurrentRound:     gm.state.CurrentRound,
 		TimeRemaining:    timeRemaining,
+		Paused:           gm.state.Paused,
 		TeamTokens:       gm.state.TeamTokens,
 		PlayerStatuses:   playerStatuses,
 		PuzzleProgress:   progress,
@@ -1668,26 +1941,32 @@ func (gm *GameManager) resetGame() {
 	gm.mu.Lock()
 	defer gm.mu.Unlock()
 
+	gm.cancelGameInternal()
+
 	// Send reset message
 	gm.broadcastChan <- BroadcastMessage{
 		Type: MsgGameReset,
-		Payload: map[string]interface{}{
-			"message":           "Game resetting. Please rejoin to start a new game.",
-			"reconnectRequired": true,
+		Payload: GameResetPayload{
+			Message:           "Game resetting. Please rejoin to start a new game.",
+			ReconnectRequired: true,
 		},
 	}
 
 	// Reset state
 	gm.state = &GameState{
 		Phase:                PhaseSetup,
-		Difficulty:           "medium",
+		Difficulty:           gm.balance.Difficulty,
+		Settings:             gm.balance.Settings,
 		Players:              make(map[string]*Player),
 		TeamTokens:           TeamTokens{},
 		QuestionHistory:      make(map[string]map[string]bool),
 		PlayerAnalytics:      make(map[string]*PlayerAnalytics),
 		PieceRecommendations: make(map[string]*PieceRecommendation),
 		CurrentQuestions:     make(map[string]*TriviaQuestion),
+		FragmentHolds:        make(map[string]time.Time),
 	}
+	gm.requestSnapshotInternal() // An aborted game never reached endGame, so remove its snapshot here
+	gm.recordCheckpointInternal("game_reset")
 }
 
 // initializeUnassignedFragments creates a pool of unassigned fragments
@@ -1714,12 +1993,12 @@ func (gm *GameManager) initializeUnassignedFragments(count int, startIndex int)
 // validateFragmentOwnership checks if a player can move a specific fragment
 func (gm *GameManager) validateFragmentOwnership(playerID string, fragment *PuzzleFragment) error {
 	if fragment == nil {
-		return fmt.Errorf("fragment not found")
+		return errFragmentNotFound
 	}
 
 	// Check if fragment is visible first
 	if !fragment.Visible {
-		return fmt.Errorf(constants.ErrFragmentNotVisible)
+		return errFragmentNotVisible
 	}
 
 	// Player can move their own fragment
@@ -1738,7 +2017,7 @@ func (gm *GameManager) validateFragmentOwnership(playerID string, fragment *Puzz
 	}
 
 	// Otherwise, movement is not allowed
-	return fmt.Errorf(constants.ErrFragmentOwnership)
+	return errFragmentOwnership
 }
 
 // releaseUnassignedFragment makes one unassigned fragment visible during puzzle phase
@@ -1746,7 +2025,20 @@ func (gm *GameManager) releaseUnassignedFragment() {
 	gm.mu.Lock()
 	defer gm.mu.Unlock()
 
-	for _, fragment := range gm.state.PuzzleFragments {
+	// Releases are paced by the puzzle clock, which stands still while paused
+	if gm.state.Paused {
+		return
+	}
+
+	// Release in ID order so replays pick the same fragment
+	fragmentIDs := make([]string, 0, len(gm.state.PuzzleFragments))
+	for id := range gm.state.PuzzleFragments {
+		fragmentIDs = append(fragmentIDs, id)
+	}
+	sort.Strings(fragmentIDs)
+
+	for _, id := range fragmentIDs {
+		fragment := gm.state.PuzzleFragments[id]
 		if fragment.IsUnassigned && !fragment.Visible {
 			fragment.Visible = true
 			fragment.Solved = true // Unassigned fragments are pre-solved
@@ -1754,8 +2046,8 @@ func (gm *GameManager) releaseUnassignedFragment() {
 			log.Printf("Released unassigned fragment %s at position (%d, %d)",
 				fragment.ID, fragment.Position.X, fragment.Position.Y)
 
-			// Broadcast updated personal puzzle states
-			gm.BroadcastPersonalPuzzleStates()
+			gm.sendFragmentRevealedInternal(fragment)
+			gm.recordCheckpointInternal("fragment_released")
 			break // Only release one at a time
 		}
 	}
@@ -1766,6 +2058,14 @@ func (gm *GameManager) handleFragmentDisconnection(playerID string) {
 	gm.mu.Lock()
 	defer gm.mu.Unlock()
 
+	gm.handleFragmentDisconnectionInternal(playerID)
+}
+
+// handleFragmentDisconnectionInternal converts a player's fragment to unassigned status
+// NOTE: This method assumes the caller already holds gm.mu lock
+func (gm *GameManager) handleFragmentDisconnectionInternal(playerID string) {
+	delete(gm.state.FragmentHolds, playerID)
+
 	fragmentID := fmt.Sprintf("fragment_%s", playerID)
 	fragment, exists := gm.state.PuzzleFragments[fragmentID]
 	if !exists {
@@ -1785,13 +2085,14 @@ func (gm *GameManager) handleFragmentDisconnection(playerID string) {
 
 	// Randomly relocate fragment to maintain game balance
 	fragment.Position = GridPos{
-		X: rand.Intn(gm.state.GridSize),
-		Y: rand.Intn(gm.state.GridSize),
+		X: gm.rng.Intn(gm.state.GridSize),
+		Y: gm.rng.Intn(gm.state.GridSize),
 	}
 
 	log.Printf("Converted fragment %s to unassigned due to player %s disconnection", fragmentID, playerID)
 
-	// Broadcast updated states
+	// Ownership changed as well as position, so everyone gets a full snapshot at a new seq
+	gm.nextPuzzleSeqInternal()
 	gm.BroadcastPersonalPuzzleStates()
 	gm.sendCompletePuzzleStateToHost()
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -9,10 +10,16 @@ import (
 )
 
 func createTestGameManager() (*GameManager, *PlayerManager, *TriviaManager, chan BroadcastMessage) {
+	return createSeededTestGameManager(realClock{}, testSeed)
+}
+
+// createSeededTestGameManager is createTestGameManager with a chosen clock and seed; pass a
+// FakeClock to run rounds and timers without sleeping
+func createSeededTestGameManager(clock Clock, seed int64) (*GameManager, *PlayerManager, *TriviaManager, chan BroadcastMessage) {
 	playerMgr := NewPlayerManager()
-	triviaMgr := NewTriviaManager()
+	triviaMgr := NewTriviaManager(clock, seed)
 	broadcastChan := make(chan BroadcastMessage, 10000) // Very large buffer to prevent blocking
-	gameMgr := NewGameManager(playerMgr, triviaMgr, broadcastChan)
+	gameMgr := NewGameManager(playerMgr, triviaMgr, broadcastChan, clock, seed)
 
 	// Start a goroutine to continuously drain the channel
 	go func() {
@@ -318,9 +325,10 @@ func TestProcessTriviaAnswer(t *testing.T) {
 	assert.Error(t, err)
 }
 
-// Temporarily disabled due to timeout issues - will need further investigation
-func testFragmentMovement(t *testing.T) {
-	gm, pm, _, _ := createTestGameManager()
+func TestFragmentMovement(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	gm, pm, tm, _ := createSeededTestGameManager(clock, testSeed)
+	defer cleanupTestGameManager(tm)
 
 	// Create player
 	player := pm.CreatePlayer(nil, false)
@@ -337,8 +345,8 @@ func testFragmentMovement(t *testing.T) {
 		Position:        GridPos{X: 0, Y: 0},
 		CorrectPosition: GridPos{X: 2, Y: 2},
 		Visible:         true,
-		Solved:          true,                             // Mark as solved (individual puzzle completed)
-		LastMoved:       time.Now().Add(-2 * time.Second), // Past cooldown
+		Solved:          true,                              // Mark as solved (individual puzzle completed)
+		LastMoved:       clock.Now().Add(-2 * time.Second), // Past cooldown
 	}
 	gm.state.PuzzleFragments["fragment-1"] = fragment
 
@@ -359,6 +367,7 @@ func testFragmentMovement(t *testing.T) {
 		// Log error but don't fail - fragment movement may have complex validation
 		t.Logf("Fragment move validation error (expected in some cases): %v", err)
 	}
+	clock.Advance(2 * time.Second) // Past the move cooldown
 
 	// Test move out of bounds
 	err = gm.ProcessFragmentMove(player.ID, "fragment-1", GridPos{X: 4, Y: 4})
@@ -754,3 +763,268 @@ func TestTokenThresholdEffects(t *testing.T) {
 
 	assert.GreaterOrEqual(t, clarityThresholds, 0, "Clarity thresholds should be non-negative")
 }
+
+func TestResourceRoundsRunOnFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	gm, pm, tm, _ := createSeededTestGameManager(clock, testSeed)
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	for i := 1; i <= 4; i++ {
+		pm.createPlayer(fmt.Sprintf("player-%d", i), nil, false)
+	}
+	SimulateGamePhase(gm, PhaseResourceGathering)
+
+	// Five 60 second rounds finish without the test sleeping through any of them
+	go gm.runResourceGatheringRounds(1, 0)
+	for round := 1; round <= constants.ResourceGatheringRounds; round++ {
+		WaitForCondition(t, func() bool {
+			gm.mu.RLock()
+			defer gm.mu.RUnlock()
+			// The trivia manager's cleanup ticker is always pending; the round timer is the second
+			return gm.state.CurrentRound == round && clock.PendingTimers() == 2
+		}, time.Second, fmt.Sprintf("round %d timer", round))
+
+		gm.mu.RLock()
+		assert.Equal(t, clock.Now(), gm.state.RoundStartTime)
+		assert.Len(t, gm.state.CurrentQuestions, 4)
+		gm.mu.RUnlock()
+
+		clock.Advance(time.Duration(constants.ResourceGatheringRoundDuration) * time.Second)
+	}
+
+	WaitForCondition(t, func() bool {
+		gm.mu.RLock()
+		defer gm.mu.RUnlock()
+		return gm.state.Phase == PhasePuzzleAssembly
+	}, time.Second, "puzzle phase")
+}
+
+func TestSeededGamesAreReproducible(t *testing.T) {
+	type layout struct {
+		imageID   string
+		questions map[string]string
+		fragments map[string]GridPos
+	}
+
+	play := func(seed int64) layout {
+		clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+		gm, pm, tm, _ := createSeededTestGameManager(clock, seed)
+		defer cleanupTestGameManager(tm)
+		defer gm.Stop()
+
+		for i := 1; i <= 4; i++ {
+			pm.createPlayer(fmt.Sprintf("player-%d", i), nil, false)
+		}
+		SimulateGamePhase(gm, PhaseResourceGathering)
+		gm.sendSynchronizedTriviaQuestion()
+		gm.startPuzzlePhase()
+
+		// Disconnecting a player relocates their fragment at random
+		gm.handleFragmentDisconnection("player-2")
+
+		gm.mu.RLock()
+		defer gm.mu.RUnlock()
+		result := layout{
+			imageID:   gm.state.PuzzleImageID,
+			questions: make(map[string]string),
+			fragments: make(map[string]GridPos),
+		}
+		for playerID, question := range gm.state.CurrentQuestions {
+			result.questions[playerID] = question.ID
+		}
+		for fragmentID, fragment := range gm.state.PuzzleFragments {
+			result.fragments[fragmentID] = fragment.Position
+		}
+		return result
+	}
+
+	first := play(testSeed)
+	assert.NotEmpty(t, first.imageID)
+	assert.Len(t, first.questions, 4)
+	assert.NotEmpty(t, first.fragments)
+	assert.Equal(t, first, play(testSeed))
+}
+
+func TestUpdateSettings(t *testing.T) {
+	gm, _, tm, _ := createTestGameManager()
+	defer cleanupTestGameManager(tm)
+
+	assert.Equal(t, DefaultGameSettings(), gm.GetSettings())
+
+	// Omitted fields keep their value
+	rounds, gridSize := 3, 4
+	settings, err := gm.UpdateSettings(GameSettingsUpdate{ResourceRounds: &rounds, GridSize: &gridSize})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, settings.ResourceRounds)
+	assert.Equal(t, 4, settings.GridSize)
+	assert.Equal(t, constants.PuzzleAssemblyBaseTime, settings.PuzzleBaseTime)
+	assert.Equal(t, settings, gm.GetSettings())
+
+	// A 4x4 grid has no room for 20 players; nothing is applied
+	minPlayers, difficulty := 20, "hard"
+	_, err = gm.UpdateSettings(GameSettingsUpdate{MinPlayers: &minPlayers, Difficulty: &difficulty})
+	assert.EqualError(t, err, constants.ErrGridTooSmall)
+	assert.Equal(t, settings, gm.GetSettings())
+	assert.Equal(t, "medium", gm.state.Difficulty)
+
+	gridSize = 0
+	_, err = gm.UpdateSettings(GameSettingsUpdate{MinPlayers: &minPlayers, GridSize: &gridSize, Difficulty: &difficulty})
+	assert.NoError(t, err)
+	assert.Equal(t, "hard", gm.state.Difficulty)
+
+	SimulateGamePhase(gm, PhaseResourceGathering)
+	_, err = gm.UpdateSettings(GameSettingsUpdate{ResourceRounds: &rounds})
+	assert.EqualError(t, err, constants.ErrSettingsLocked)
+
+	// A new game starts from the defaults again
+	gm.resetGame()
+	assert.Equal(t, DefaultGameSettings(), gm.GetSettings())
+}
+
+func TestUpdateSettingsWithPreset(t *testing.T) {
+	gm, _, tm, _ := createTestGameManager()
+	defer cleanupTestGameManager(tm)
+
+	// A preset starts from the defaults, so earlier changes it doesn't mention are undone
+	gridSize := 4
+	_, err := gm.UpdateSettings(GameSettingsUpdate{GridSize: &gridSize})
+	assert.NoError(t, err)
+
+	preset := "classroom"
+	settings, err := gm.UpdateSettings(GameSettingsUpdate{Preset: &preset})
+	assert.NoError(t, err)
+	assert.Equal(t, "easy", gm.state.Difficulty)
+	assert.Equal(t, 4, settings.ResourceRounds)
+	assert.Equal(t, 90, settings.RoundDuration)
+	assert.Equal(t, 0, settings.GridSize)
+	assert.Equal(t, constants.AnchorTokenThresholds, settings.AnchorThreshold)
+
+	// Fields sent with a preset are applied on top of it
+	preset, rounds := "speed-round", 5
+	settings, err = gm.UpdateSettings(GameSettingsUpdate{Preset: &preset, ResourceRounds: &rounds})
+	assert.NoError(t, err)
+	assert.Equal(t, "medium", gm.state.Difficulty)
+	assert.Equal(t, 5, settings.ResourceRounds)
+	assert.Equal(t, 30, settings.RoundDuration)
+
+	preset = "marathon"
+	_, err = gm.UpdateSettings(GameSettingsUpdate{Preset: &preset})
+	assert.EqualError(t, err, constants.ErrUnknownPreset)
+	assert.Equal(t, settings, gm.GetSettings())
+
+	// Easy lowers token thresholds below one per token without dividing by zero
+	assert.Equal(t, 10, gm.thresholdsReached(50, 5)) // 50 / (5 * 1.0) at medium
+	easy := "easy"
+	_, err = gm.UpdateSettings(GameSettingsUpdate{Difficulty: &easy})
+	assert.NoError(t, err)
+	assert.Equal(t, 12, gm.thresholdsReached(50, 5)) // 50 / (5 * 0.8) = 12.5 -> 12
+}
+
+func TestThresholdsReachedByDifficulty(t *testing.T) {
+	gm, _, tm, _ := createTestGameManager()
+	defer cleanupTestGameManager(tm)
+
+	// 50 tokens at 5 tokens per threshold, scaled by each difficulty's token threshold modifier
+	for difficulty, want := range map[string]int{
+		"easy":   12, // 50 / (5 * 0.8) = 12.5
+		"medium": 10, // 50 / (5 * 1.0)
+		"hard":   7,  // 50 / (5 * 1.3) = 7.7; hard mode needs more tokens, not medium's 10
+	} {
+		gm.state.Difficulty = difficulty
+		assert.Equal(t, want, gm.thresholdsReached(50, 5), difficulty)
+	}
+
+	// The game's effects and the final analytics count the same thresholds
+	gm.state.Difficulty = "hard"
+	gm.state.TeamTokens = TeamTokens{AnchorTokens: 13, ChronosTokens: 12, GuideTokens: 26, ClarityTokens: 0}
+	assert.Equal(t, map[string]int{
+		constants.TokenAnchor:  2, // 13 / 6.5
+		constants.TokenChronos: 1, // 12 / 6.5
+		constants.TokenGuide:   4, // 26 / 6.5
+		constants.TokenClarity: 0,
+	}, gm.calculateThresholdsReached())
+}
+
+func TestAnchorTokensPreSolveByDifficulty(t *testing.T) {
+	// 12 anchor tokens at 5 tokens per threshold pre-solve one piece per threshold
+	for difficulty, want := range map[string]int{
+		"easy":   3, // 12 / (5 * 0.8) = 3
+		"medium": 2, // 12 / (5 * 1.0) = 2.4
+		"hard":   1, // 12 / (5 * 1.3) = 1.8
+	} {
+		gm, pm, tm, _ := createTestGameManager()
+		for i := 1; i <= 4; i++ {
+			pm.createPlayer(fmt.Sprintf("player-%d", i), nil, false)
+		}
+		gm.state.Difficulty = difficulty
+		gm.state.Settings.AnchorThreshold = 5
+		gm.state.TeamTokens.AnchorTokens = 12
+		gm.startPuzzlePhase()
+
+		preSolved := 0
+		for _, fragment := range gm.state.PuzzleFragments {
+			if fragment.PreSolved {
+				preSolved++
+			}
+		}
+		assert.Equal(t, want, preSolved, difficulty)
+		cleanupTestGameManager(tm)
+	}
+}
+
+func TestSettingsShapeTheGame(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	gm, pm, tm, _ := createSeededTestGameManager(clock, testSeed)
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	rounds, duration, baseTime, gridSize, minPlayers := 2, 20, 120, 4, 2
+	_, err := gm.UpdateSettings(GameSettingsUpdate{
+		ResourceRounds: &rounds,
+		RoundDuration:  &duration,
+		PuzzleBaseTime: &baseTime,
+		GridSize:       &gridSize,
+		MinPlayers:     &minPlayers,
+	})
+	assert.NoError(t, err)
+
+	pm.createPlayer("host", nil, true)
+	pm.createPlayer("player-1", nil, false)
+	canStart, reason := gm.CanStartGame()
+	assert.False(t, canStart)
+	assert.Contains(t, reason, "Need at least 2 players")
+
+	pm.createPlayer("player-2", nil, false)
+	for i, id := range []string{"player-1", "player-2"} {
+		assert.NoError(t, pm.SetPlayerRole(id, []string{constants.RoleDetective, constants.RoleTourist}[i]))
+		assert.NoError(t, pm.SetPlayerSpecialties(id, []string{"science"}))
+		assert.NoError(t, pm.SetPlayerReady(id, true))
+	}
+	canStart, reason = gm.CanStartGame()
+	assert.True(t, canStart, reason)
+
+	// Two 20 second rounds, then the puzzle on a fixed 4x4 grid
+	SimulateGamePhase(gm, PhaseResourceGathering)
+	go gm.runResourceGatheringRounds(1, 0)
+	for round := 1; round <= rounds; round++ {
+		WaitForCondition(t, func() bool {
+			gm.mu.RLock()
+			defer gm.mu.RUnlock()
+			return gm.state.CurrentRound == round && clock.PendingTimers() == 2
+		}, time.Second, fmt.Sprintf("round %d timer", round))
+		clock.Advance(time.Duration(duration) * time.Second)
+	}
+
+	WaitForCondition(t, func() bool {
+		gm.mu.RLock()
+		defer gm.mu.RUnlock()
+		return gm.state.Phase == PhasePuzzleAssembly
+	}, time.Second, "puzzle phase")
+
+	assert.NoError(t, gm.StartPuzzle())
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	assert.Equal(t, 4, gm.state.GridSize)
+	assert.Equal(t, time.Duration(baseTime)*time.Second, gm.state.PuzzleDuration)
+}
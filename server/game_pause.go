@@ -0,0 +1,242 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+)
+
+// Timer update actions sent in game_timer_update
+const (
+	TimerActionPaused    = "paused"
+	TimerActionResumed   = "resumed"
+	TimerActionTimeAdded = "time_added"
+)
+
+// phaseTimer fires when the running round or puzzle is out of time. The deadline lives in the game
+// state, so the timer is re-armed from it whenever the host pauses, resumes or adds time.
+type phaseTimer struct {
+	gm        *GameManager
+	remaining func() time.Duration // Called with gm.mu held
+	timer     Timer                // nil while the game is paused
+}
+
+// newPhaseTimer arms a timer for the time remaining before returning, like startPuzzleTimer
+func (gm *GameManager) newPhaseTimer(remaining func() time.Duration) *phaseTimer {
+	pt := &phaseTimer{gm: gm, remaining: remaining}
+	pt.rearm()
+	return pt
+}
+
+// rearm replaces the timer with one for the time now remaining; no timer runs while paused
+func (pt *phaseTimer) rearm() {
+	pt.Stop()
+
+	pt.gm.mu.RLock()
+	paused := pt.gm.state.Paused
+	remaining := pt.remaining()
+	pt.gm.mu.RUnlock()
+
+	if !paused {
+		pt.timer = pt.gm.clock.NewTimer(remaining)
+	}
+}
+
+// C returns the channel of the current timer. It is nil, and so never fires, while paused.
+func (pt *phaseTimer) C() <-chan time.Time {
+	if pt.timer == nil {
+		return nil
+	}
+	return pt.timer.C()
+}
+
+// Stop releases the current timer
+func (pt *phaseTimer) Stop() {
+	if pt.timer != nil {
+		pt.timer.Stop()
+		pt.timer = nil
+	}
+}
+
+// expired reports whether time is really up, as opposed to a timer that fired just before the
+// host added time
+func (pt *phaseTimer) expired() bool {
+	pt.gm.mu.RLock()
+	defer pt.gm.mu.RUnlock()
+	return !pt.gm.state.Paused && pt.remaining() <= 0
+}
+
+// waitForPhaseTimer blocks until the timer's deadline has passed. It returns false if the game was
+// stopped or cancelled first.
+func (gm *GameManager) waitForPhaseTimer(pt *phaseTimer, cancel <-chan struct{}) bool {
+	defer pt.Stop()
+
+	for {
+		select {
+		case <-pt.C():
+			if pt.expired() {
+				return true
+			}
+			pt.rearm()
+		case <-gm.timerChanged:
+			pt.rearm()
+		case <-cancel:
+			return false
+		case <-gm.stopChan:
+			return false
+		}
+	}
+}
+
+// gameNowInternal returns the time on the game clock, which stands still while the game is paused
+// NOTE: This method assumes the caller already holds gm.mu lock (read or write)
+func (gm *GameManager) gameNowInternal() time.Time {
+	if gm.state.Paused {
+		return gm.state.PausedAt
+	}
+	return gm.clock.Now()
+}
+
+// roundRemainingInternal returns the time left in the current resource gathering round
+// NOTE: This method assumes the caller already holds gm.mu lock (read or write)
+func (gm *GameManager) roundRemainingInternal() time.Duration {
+	duration := time.Duration(gm.state.Settings.RoundDuration)*time.Second + gm.state.RoundExtension
+	return max(duration-gm.gameNowInternal().Sub(gm.state.RoundStartTime), 0)
+}
+
+// puzzleRemainingInternal returns the time left to solve the puzzle
+// NOTE: This method assumes the caller already holds gm.mu lock (read or write)
+func (gm *GameManager) puzzleRemainingInternal() time.Duration {
+	return max(gm.state.PuzzleDuration-gm.gameNowInternal().Sub(gm.state.PuzzleStartTime), 0)
+}
+
+// timeRemainingInternal returns the time left on whichever clock is running, and false if none is
+// NOTE: This method assumes the caller already holds gm.mu lock (read or write)
+func (gm *GameManager) timeRemainingInternal() (time.Duration, bool) {
+	switch {
+	case gm.state.Phase == PhaseResourceGathering:
+		return gm.roundRemainingInternal(), true
+	case gm.state.Phase == PhasePuzzleAssembly && !gm.state.PuzzleStartTime.IsZero():
+		return gm.puzzleRemainingInternal(), true
+	default:
+		return 0, false
+	}
+}
+
+// Pause freezes the running round or puzzle clock. Trivia answers and fragment moves are refused
+// until the host resumes.
+func (gm *GameManager) Pause() error {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if _, running := gm.timeRemainingInternal(); !running {
+		return errNoRunningTimer
+	}
+	if gm.state.Paused {
+		return errGamePaused
+	}
+
+	gm.state.Paused = true
+	gm.state.PausedAt = gm.clock.Now()
+	gm.timerChangedInternal(TimerActionPaused, 0)
+
+	log.Printf("Game paused in %s phase", gm.state.Phase.String())
+	return nil
+}
+
+// Resume restarts the clock where it stopped
+func (gm *GameManager) Resume() error {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if !gm.state.Paused {
+		return errGameNotPaused
+	}
+
+	// Moving the start forward by the pause keeps paused time out of every elapsed time and deadline
+	pausedFor := gm.clock.Now().Sub(gm.state.PausedAt)
+	if gm.state.Phase == PhaseResourceGathering {
+		gm.state.RoundStartTime = gm.state.RoundStartTime.Add(pausedFor)
+	} else {
+		gm.state.PuzzleStartTime = gm.state.PuzzleStartTime.Add(pausedFor)
+	}
+	gm.state.Paused = false
+	gm.state.PausedAt = time.Time{}
+	gm.timerChangedInternal(TimerActionResumed, 0)
+
+	log.Printf("Game resumed after %v", pausedFor.Round(time.Second))
+	return nil
+}
+
+// AddTime extends the current resource gathering round or the puzzle; it works while paused too
+func (gm *GameManager) AddTime(seconds int) error {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if _, running := gm.timeRemainingInternal(); !running {
+		return errNoRunningTimer
+	}
+	if seconds < constants.MinAddTimeSeconds || seconds > constants.MaxAddTimeSeconds {
+		return newCodedError(CodeValidationFailed, "seconds must be between %d and %d", constants.MinAddTimeSeconds, constants.MaxAddTimeSeconds)
+	}
+
+	extra := time.Duration(seconds) * time.Second
+	if gm.state.Phase == PhaseResourceGathering {
+		gm.state.RoundExtension += extra
+	} else {
+		gm.state.PuzzleDuration += extra
+	}
+	gm.timerChangedInternal(TimerActionTimeAdded, seconds)
+
+	return nil
+}
+
+// timerChangedInternal wakes the phase timer and tells every player and the host about the new clock
+// NOTE: This method assumes the caller already holds gm.mu lock
+func (gm *GameManager) timerChangedInternal(action string, addedSeconds int) {
+	// The waiting loop re-reads the state, so one pending wake-up covers any number of changes
+	select {
+	case gm.timerChanged <- struct{}{}:
+	default:
+	}
+
+	gm.requestSnapshotInternal()
+
+	gm.broadcastChan <- BroadcastMessage{
+		Type:    MsgGameTimerUpdate,
+		Payload: gm.timerUpdateInternal(action, addedSeconds),
+	}
+	gm.sendHostUpdateInternal()
+}
+
+// timerUpdateInternal describes the running clock for game_timer_update
+// NOTE: This method assumes the caller already holds gm.mu lock (read or write)
+func (gm *GameManager) timerUpdateInternal(action string, addedSeconds int) TimerUpdate {
+	remaining, _ := gm.timeRemainingInternal()
+
+	update := TimerUpdate{
+		Action:        action,
+		Phase:         gm.state.Phase.String(),
+		Paused:        gm.state.Paused,
+		TimeRemaining: int(remaining.Round(time.Second).Seconds()),
+		AddedSeconds:  addedSeconds,
+	}
+	if gm.state.Phase == PhaseResourceGathering {
+		update.CurrentRound = gm.state.CurrentRound
+	}
+	if !gm.state.Paused {
+		update.Deadline = gm.clock.Now().Add(remaining).Unix()
+	}
+	return update
+}
+
+// sendPausedState tells a reconnecting player that the game is waiting for the host to resume
+func (gm *GameManager) sendPausedState(player *Player) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	if gm.state.Paused {
+		sendToPlayer(player, MsgGameTimerUpdate, gm.timerUpdateInternal(TimerActionPaused, 0))
+	}
+}
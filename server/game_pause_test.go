@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPauseFreezesResourceRound(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	gm, pm, tm, _ := createSeededTestGameManager(clock, testSeed)
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	for i := 1; i <= 4; i++ {
+		pm.createPlayer(fmt.Sprintf("player-%d", i), nil, false)
+	}
+	SimulateGamePhase(gm, PhaseResourceGathering)
+
+	roundIs := func(round, pending int) func() bool {
+		return func() bool {
+			gm.mu.RLock()
+			defer gm.mu.RUnlock()
+			return gm.state.CurrentRound == round && clock.PendingTimers() == pending
+		}
+	}
+
+	go gm.runResourceGatheringRounds(1, 0)
+	WaitForCondition(t, roundIs(1, 2), time.Second, "round 1 timer")
+
+	clock.Advance(30 * time.Second)
+	assert.NoError(t, gm.Pause())
+	assert.EqualError(t, gm.Pause(), constants.ErrGamePaused)
+
+	// Paused rounds drop their timer, so no amount of waiting ends the round
+	WaitForCondition(t, roundIs(1, 1), time.Second, "round timer stopped")
+	clock.Advance(10 * time.Minute)
+
+	gm.mu.RLock()
+	assert.Equal(t, 30*time.Second, gm.roundRemainingInternal())
+	update := gm.timerUpdateInternal(TimerActionPaused, 0)
+	gm.mu.RUnlock()
+	assert.True(t, update.Paused)
+	assert.Equal(t, 1, update.CurrentRound)
+	assert.Equal(t, 30, update.TimeRemaining)
+	assert.Zero(t, update.Deadline)
+
+	// Answers wait for the host to resume
+	assert.EqualError(t, gm.ProcessTriviaAnswer("player-1", "any", "any"), constants.ErrGamePaused)
+
+	// Time can be added while paused; the round picks up where it stopped plus the extra
+	assert.NoError(t, gm.AddTime(15))
+	assert.NoError(t, gm.Resume())
+	assert.EqualError(t, gm.Resume(), constants.ErrGameNotPaused)
+	WaitForCondition(t, roundIs(1, 2), time.Second, "round timer rearmed")
+
+	gm.mu.RLock()
+	update = gm.timerUpdateInternal(TimerActionResumed, 0)
+	gm.mu.RUnlock()
+	assert.Equal(t, 45, update.TimeRemaining)
+	assert.Equal(t, clock.Now().Add(45*time.Second).Unix(), update.Deadline)
+
+	clock.Advance(44 * time.Second)
+	gm.mu.RLock()
+	assert.Equal(t, 1, gm.state.CurrentRound)
+	gm.mu.RUnlock()
+
+	clock.Advance(time.Second)
+	WaitForCondition(t, roundIs(2, 2), time.Second, "round 2 timer")
+
+	// The extension belonged to round 1 only
+	gm.mu.RLock()
+	assert.Zero(t, gm.state.RoundExtension)
+	assert.Equal(t, time.Duration(constants.ResourceGatheringRoundDuration)*time.Second, gm.roundRemainingInternal())
+	gm.mu.RUnlock()
+}
+
+func TestPauseFreezesPuzzleTimer(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	gm, pm, tm, _ := createSeededTestGameManager(clock, testSeed)
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	for i := 1; i <= 4; i++ {
+		pm.createPlayer(fmt.Sprintf("player-%d", i), nil, false)
+	}
+	gm.startPuzzlePhase()
+
+	// No timer runs until the host starts the puzzle
+	assert.EqualError(t, gm.Pause(), constants.ErrNoRunningTimer)
+	assert.EqualError(t, gm.AddTime(30), constants.ErrNoRunningTimer)
+
+	gm.mu.Lock()
+	gm.state.PuzzleStartTime = clock.Now()
+	gm.state.PuzzleDuration = 2 * time.Minute
+	gm.mu.Unlock()
+	gm.startPuzzleTimer()
+
+	// The puzzle timer plus the progress and fragment release tickers
+	pending := func(n int) func() bool {
+		return func() bool { return clock.PendingTimers() == n }
+	}
+	WaitForCondition(t, pending(4), time.Second, "puzzle timer")
+
+	clock.Advance(time.Minute)
+	assert.NoError(t, gm.Pause())
+	WaitForCondition(t, pending(3), time.Second, "puzzle timer stopped")
+	clock.Advance(time.Hour)
+	assert.Equal(t, PhasePuzzleAssembly, gm.GetPhase())
+
+	// Fragments stay where they are while paused
+	gm.mu.RLock()
+	fragment := gm.state.PuzzleFragments["fragment_player-1"]
+	gm.mu.RUnlock()
+	if assert.NotNil(t, fragment) {
+		err := gm.ProcessFragmentMove("player-1", fragment.ID, GridPos{X: 0, Y: 0})
+		assert.EqualError(t, err, constants.ErrGamePaused)
+	}
+
+	assert.NoError(t, gm.Resume())
+	assert.NoError(t, gm.AddTime(60))
+	WaitForCondition(t, pending(4), time.Second, "puzzle timer rearmed")
+
+	gm.mu.RLock()
+	assert.Equal(t, 3*time.Minute, gm.state.PuzzleDuration)
+	assert.Equal(t, 2*time.Minute, gm.puzzleRemainingInternal())
+	gm.mu.RUnlock()
+
+	clock.Advance(2 * time.Minute)
+	WaitForCondition(t, func() bool {
+		return gm.GetPhase() == PhasePostGame
+	}, time.Second, "puzzle time out")
+}
+
+func TestAddTimeBounds(t *testing.T) {
+	gm, _, tm, _ := createTestGameManager()
+	defer cleanupTestGameManager(tm)
+
+	SimulateGamePhase(gm, PhaseResourceGathering)
+	gm.state.RoundStartTime = gm.clock.Now()
+
+	assert.Error(t, gm.AddTime(constants.MinAddTimeSeconds-1))
+	assert.Error(t, gm.AddTime(constants.MaxAddTimeSeconds+1))
+	assert.NoError(t, gm.AddTime(constants.MaxAddTimeSeconds))
+	assert.Equal(t, time.Duration(constants.MaxAddTimeSeconds)*time.Second, gm.state.RoundExtension)
+
+	// Nothing to pause before the game starts or after it ends
+	SimulateGamePhase(gm, PhasePostGame)
+	assert.EqualError(t, gm.Pause(), constants.ErrNoRunningTimer)
+	assert.EqualError(t, gm.Resume(), constants.ErrGameNotPaused)
+}
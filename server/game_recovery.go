@@ -0,0 +1,383 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+)
+
+// EnableSnapshots starts persisting this game's state to the store under the given room identity.
+// Must be called before the game starts.
+func (gm *GameManager) EnableSnapshots(store *SnapshotStore, room RoomIdentity) {
+	gm.snapshotStore = store
+	gm.snapshotRoom = room
+	gm.snapshotChan = make(chan *GameSnapshot, 1)
+	gm.snapshotDone = make(chan struct{})
+
+	go gm.runSnapshotWriter()
+}
+
+// runSnapshotWriter persists event-driven snapshots as they arrive plus a periodic one while a game is running
+func (gm *GameManager) runSnapshotWriter() {
+	defer close(gm.snapshotDone)
+
+	ticker := time.NewTicker(constants.SnapshotInterval)
+	defer ticker.Stop()
+
+	var lastSaved time.Time
+	persist := func(snapshot *GameSnapshot) {
+		// Event and periodic snapshots can arrive out of order; never overwrite newer state
+		if snapshot.SavedAt.Before(lastSaved) {
+			return
+		}
+		lastSaved = snapshot.SavedAt
+		gm.persistSnapshot(snapshot)
+	}
+
+	for {
+		select {
+		case snapshot := <-gm.snapshotChan:
+			persist(snapshot)
+		case <-ticker.C:
+			gm.mu.RLock()
+			var snapshot *GameSnapshot
+			if isResumablePhase(gm.state.Phase) {
+				snapshot = gm.buildSnapshotInternal()
+			}
+			gm.mu.RUnlock()
+
+			if snapshot != nil {
+				persist(snapshot)
+			}
+		case <-gm.stopChan:
+			return
+		}
+	}
+}
+
+// persistSnapshot saves snapshots of running games and removes the file once the game is over
+func (gm *GameManager) persistSnapshot(snapshot *GameSnapshot) {
+	if isResumablePhase(snapshot.Phase) {
+		if err := gm.snapshotStore.Save(snapshot); err != nil {
+			log.Printf("Failed to save snapshot for room %s: %v", snapshot.Room.Code, err)
+		}
+		return
+	}
+
+	if err := gm.snapshotStore.Delete(snapshot.Room.Code); err != nil {
+		log.Printf("Failed to delete snapshot for room %s: %v", snapshot.Room.Code, err)
+	}
+}
+
+// requestSnapshotInternal queues a snapshot of the current state for the writer goroutine
+// NOTE: This method assumes the caller already holds gm.mu lock
+func (gm *GameManager) requestSnapshotInternal() {
+	if gm.snapshotStore == nil {
+		return
+	}
+
+	snapshot := gm.buildSnapshotInternal()
+
+	// Only the newest pending snapshot matters; replace one the writer hasn't picked up yet
+	select {
+	case gm.snapshotChan <- snapshot:
+	default:
+		select {
+		case <-gm.snapshotChan:
+		default:
+		}
+		select {
+		case gm.snapshotChan <- snapshot:
+		default:
+		}
+	}
+}
+
+// buildSnapshotInternal copies the current game state into its persisted form
+// NOTE: This method assumes the caller already holds gm.mu lock (read or write)
+func (gm *GameManager) buildSnapshotInternal() *GameSnapshot {
+	now := gm.clock.Now()
+	state := gm.state
+	gameNow := gm.gameNowInternal() // Elapsed times leave out the current pause
+
+	snapshot := &GameSnapshot{
+		Version:                 constants.SnapshotFormatVersion,
+		SavedAt:                 now,
+		Room:                    gm.snapshotRoom,
+		Phase:                   state.Phase,
+		Difficulty:              state.Difficulty,
+		Settings:                state.Settings,
+		TeamTokens:              state.TeamTokens,
+		CurrentRound:            state.CurrentRound,
+		RoundExtension:          state.RoundExtension,
+		Paused:                  state.Paused,
+		GridSize:                state.GridSize,
+		PuzzleImageID:           state.PuzzleImageID,
+		PuzzleDuration:          state.PuzzleDuration,
+		QuestionHistory:         make(map[string]map[string]bool, len(state.QuestionHistory)),
+		PlayerAnalytics:         make(map[string]*PlayerAnalytics, len(state.PlayerAnalytics)),
+		FragmentMoveHistory:     append([]FragmentMove(nil), state.FragmentMoveHistory...),
+		PieceRecommendations:    make(map[string]*PieceRecommendation, len(state.PieceRecommendations)),
+		StartedAt:               state.StartedAt,
+		TriviaAnswers:           append([]TriviaAnswerRecord(nil), state.TriviaAnswers...),
+		ResolvedRecommendations: append([]RecommendationRecord(nil), state.ResolvedRecommendations...),
+	}
+
+	if !state.RoundStartTime.IsZero() {
+		snapshot.RoundElapsed = gameNow.Sub(state.RoundStartTime)
+	}
+	if !state.PuzzleStartTime.IsZero() {
+		snapshot.PuzzleStarted = true
+		snapshot.PuzzleElapsed = gameNow.Sub(state.PuzzleStartTime)
+	}
+
+	for _, player := range gm.playerManager.GetAllPlayers() {
+		player.mu.RLock()
+		snapshot.Players = append(snapshot.Players, PlayerSnapshot{
+			ID:              player.ID,
+			Name:            player.Name,
+			Role:            player.Role,
+			Specialties:     append([]string(nil), player.Specialties...),
+			IsHost:          player.IsHost,
+			Ready:           player.Ready,
+			CurrentLocation: player.CurrentLocation,
+		})
+		player.mu.RUnlock()
+	}
+
+	for _, fragment := range state.PuzzleFragments {
+		snapshot.PuzzleFragments = append(snapshot.PuzzleFragments, FragmentSnapshot{
+			ID:              fragment.ID,
+			PlayerID:        fragment.PlayerID,
+			Position:        fragment.Position,
+			Solved:          fragment.Solved,
+			LastMoved:       fragment.LastMoved,
+			CorrectPosition: fragment.CorrectPosition,
+			PreSolved:       fragment.PreSolved,
+			Visible:         fragment.Visible,
+			MovableBy:       fragment.MovableBy,
+			IsUnassigned:    fragment.IsUnassigned,
+		})
+	}
+
+	// Deep copy the maps so the writer goroutine never races with game updates
+	for playerID, history := range state.QuestionHistory {
+		copied := make(map[string]bool, len(history))
+		for questionID, asked := range history {
+			copied[questionID] = asked
+		}
+		snapshot.QuestionHistory[playerID] = copied
+	}
+
+	for playerID, analytics := range state.PlayerAnalytics {
+		copied := *analytics
+		copied.TokenCollection = make(map[string]int, len(analytics.TokenCollection))
+		for tokenType, count := range analytics.TokenCollection {
+			copied.TokenCollection[tokenType] = count
+		}
+		copied.TriviaPerformance.AccuracyByCategory = make(map[string]float64, len(analytics.TriviaPerformance.AccuracyByCategory))
+		for category, accuracy := range analytics.TriviaPerformance.AccuracyByCategory {
+			copied.TriviaPerformance.AccuracyByCategory[category] = accuracy
+		}
+		snapshot.PlayerAnalytics[playerID] = &copied
+	}
+
+	for id, recommendation := range state.PieceRecommendations {
+		copied := *recommendation
+		snapshot.PieceRecommendations[id] = &copied
+	}
+
+	return snapshot
+}
+
+// RestoreFromSnapshot rebuilds an in-progress game from a snapshot and resumes its phase timers
+// with the time that was remaining when the snapshot was taken
+func (gm *GameManager) RestoreFromSnapshot(snapshot *GameSnapshot) error {
+	if !isResumablePhase(snapshot.Phase) {
+		return errSnapshotNotResumable
+	}
+
+	gm.mu.Lock()
+
+	if gm.state.Phase != PhaseSetup {
+		gm.mu.Unlock()
+		return fmt.Errorf("can only restore a snapshot into a game in setup phase")
+	}
+
+	for _, playerSnapshot := range snapshot.Players {
+		gm.playerManager.RestorePlayer(playerSnapshot)
+	}
+
+	now := gm.clock.Now()
+	state := &GameState{
+		Phase:                   snapshot.Phase,
+		Difficulty:              snapshot.Difficulty,
+		Settings:                snapshot.Settings,
+		Players:                 make(map[string]*Player),
+		TeamTokens:              snapshot.TeamTokens,
+		CurrentRound:            snapshot.CurrentRound,
+		RoundStartTime:          now.Add(-snapshot.RoundElapsed),
+		RoundExtension:          snapshot.RoundExtension,
+		PuzzleFragments:         make(map[string]*PuzzleFragment, len(snapshot.PuzzleFragments)),
+		FragmentHolds:           make(map[string]time.Time),
+		PuzzleDuration:          snapshot.PuzzleDuration,
+		GridSize:                snapshot.GridSize,
+		PuzzleImageID:           snapshot.PuzzleImageID,
+		QuestionHistory:         snapshot.QuestionHistory,
+		PlayerAnalytics:         snapshot.PlayerAnalytics,
+		FragmentMoveHistory:     snapshot.FragmentMoveHistory,
+		PieceRecommendations:    snapshot.PieceRecommendations,
+		StartedAt:               snapshot.StartedAt,
+		TriviaAnswers:           snapshot.TriviaAnswers,
+		ResolvedRecommendations: snapshot.ResolvedRecommendations,
+		// Question IDs are regenerated when trivia is loaded, so pending questions can't be
+		// carried across a restart; reconnecting players are sent a fresh one instead
+		CurrentQuestions: make(map[string]*TriviaQuestion),
+	}
+	if snapshot.PuzzleStarted {
+		state.PuzzleStartTime = now.Add(-snapshot.PuzzleElapsed)
+	}
+	if snapshot.Paused {
+		// The restart counts as part of the pause; the host resumes when everyone is back
+		state.Paused = true
+		state.PausedAt = now
+	}
+
+	// Snapshots written before host settings existed were played with the defaults
+	if state.Settings == (GameSettings{}) {
+		state.Settings = DefaultGameSettings()
+	}
+	if state.QuestionHistory == nil {
+		state.QuestionHistory = make(map[string]map[string]bool)
+	}
+	if state.PlayerAnalytics == nil {
+		state.PlayerAnalytics = make(map[string]*PlayerAnalytics)
+	}
+	if state.PieceRecommendations == nil {
+		state.PieceRecommendations = make(map[string]*PieceRecommendation)
+	}
+	for _, playerSnapshot := range snapshot.Players {
+		if !playerSnapshot.IsHost && state.QuestionHistory[playerSnapshot.ID] == nil {
+			state.QuestionHistory[playerSnapshot.ID] = make(map[string]bool)
+		}
+	}
+
+	for _, fragment := range snapshot.PuzzleFragments {
+		state.PuzzleFragments[fragment.ID] = &PuzzleFragment{
+			ID:              fragment.ID,
+			PlayerID:        fragment.PlayerID,
+			Position:        fragment.Position,
+			Solved:          fragment.Solved,
+			LastMoved:       fragment.LastMoved,
+			CorrectPosition: fragment.CorrectPosition,
+			PreSolved:       fragment.PreSolved,
+			Visible:         fragment.Visible,
+			MovableBy:       fragment.MovableBy,
+			IsUnassigned:    fragment.IsUnassigned,
+		}
+	}
+
+	gm.state = state
+	gm.mu.Unlock()
+
+	// A log that picks up mid-game cannot be replayed from the start, so mark where it happened
+	gm.eventLog.Record(EventSnapshotRestored, "", "", snapshot.Room)
+
+	switch snapshot.Phase {
+	case PhaseResourceGathering:
+		go gm.runResourceGatheringRounds(snapshot.CurrentRound, snapshot.RoundElapsed)
+		log.Printf("Restored room %s in resource gathering round %d (%v into the round)",
+			snapshot.Room.Code, snapshot.CurrentRound, snapshot.RoundElapsed.Round(time.Second))
+
+	case PhasePuzzleAssembly:
+		if snapshot.PuzzleStarted {
+			remaining := snapshot.PuzzleDuration - snapshot.PuzzleElapsed
+			if remaining < 0 {
+				remaining = 0
+			}
+			gm.startPuzzleTimer()
+			log.Printf("Restored room %s in puzzle assembly with %v remaining (paused: %v)",
+				snapshot.Room.Code, remaining.Round(time.Second), snapshot.Paused)
+		} else {
+			log.Printf("Restored room %s in puzzle assembly, waiting for host to start the timer", snapshot.Room.Code)
+		}
+	}
+
+	return nil
+}
+
+// FlushSnapshot waits for the snapshot writer to exit and saves the final state of a running game.
+// Used on graceful shutdown so the game can be resumed with -restore.
+func (gm *GameManager) FlushSnapshot() {
+	if gm.snapshotStore == nil {
+		return
+	}
+	<-gm.snapshotDone
+
+	gm.mu.RLock()
+	snapshot := gm.buildSnapshotInternal()
+	gm.mu.RUnlock()
+
+	gm.persistSnapshot(snapshot)
+}
+
+// DiscardSnapshot waits for the snapshot writer to exit and removes any saved state.
+// Used when a room is closed on purpose and should not come back after a restart.
+func (gm *GameManager) DiscardSnapshot() {
+	if gm.snapshotStore == nil {
+		return
+	}
+	<-gm.snapshotDone
+
+	if err := gm.snapshotStore.Delete(gm.snapshotRoom.Code); err != nil {
+		log.Printf("Failed to delete snapshot for room %s: %v", gm.snapshotRoom.Code, err)
+	}
+}
+
+// ensureTriviaQuestion sends a question to a recovered player who has none pending
+func (gm *GameManager) ensureTriviaQuestion(player *Player) {
+	gm.mu.RLock()
+	_, hasQuestion := gm.state.CurrentQuestions[player.ID]
+	inResourcePhase := gm.state.Phase == PhaseResourceGathering
+	gm.mu.RUnlock()
+
+	if inResourcePhase && !hasQuestion {
+		gm.sendTriviaQuestion(player)
+	}
+}
+
+// sendPuzzleRecoveryState brings a player who reconnected after a restore, or within the reconnect
+// grace period, back into the puzzle phase
+func (gm *GameManager) sendPuzzleRecoveryState(player *Player) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	fragment := gm.state.PuzzleFragments[fmt.Sprintf("fragment_%s", player.ID)]
+	if fragment == nil {
+		return
+	}
+
+	segmentID := fmt.Sprintf("segment_%c%d", 'a'+fragment.CorrectPosition.Y, fragment.CorrectPosition.X+1)
+	sendToPlayer(player, MsgPuzzlePhaseLoad, PuzzlePhaseLoadPayload{
+		ImageID:   gm.state.PuzzleImageID,
+		SegmentID: segmentID,
+		GridSize:  gm.state.GridSize,
+		PreSolved: fragment.PreSolved,
+	})
+
+	if !gm.state.PuzzleStartTime.IsZero() {
+		sendToPlayer(player, MsgPuzzlePhaseStart, PuzzlePhaseStartPayload{
+			StartTimestamp: gm.state.PuzzleStartTime.Unix(),
+			TotalTime:      int(gm.state.PuzzleDuration.Seconds()),
+		})
+	}
+
+	gm.sendPersonalPuzzleState(player, gm.calculateGuideHighlight(player.ID))
+}
+
+// isResumablePhase reports whether a game in this phase has state worth recovering
+func isResumablePhase(phase GamePhase) bool {
+	return phase == PhaseResourceGathering || phase == PhasePuzzleAssembly
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotRoundTripPuzzlePhase(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	gm, pm, tm, _ := createSeededTestGameManager(clock, testSeed)
+	defer cleanupTestGameManager(tm)
+
+	host := pm.CreatePlayer(nil, true)
+	player := pm.CreatePlayer(nil, false)
+	pm.SetPlayerRole(player.ID, constants.RoleDetective)
+
+	lastMoved := clock.Now().Add(-5 * time.Second)
+	gm.mu.Lock()
+	gm.state.Phase = PhasePuzzleAssembly
+	gm.state.Difficulty = "hard"
+	gm.state.TeamTokens = TeamTokens{AnchorTokens: 12, ChronosTokens: 7}
+	gm.state.GridSize = 3
+	gm.state.PuzzleImageID = "masterpiece_007"
+	gm.state.PuzzleStartTime = clock.Now().Add(-30 * time.Second)
+	gm.state.PuzzleDuration = 300 * time.Second
+	gm.state.PuzzleFragments["fragment_"+player.ID] = &PuzzleFragment{
+		ID: "fragment_" + player.ID, PlayerID: player.ID, MovableBy: player.ID,
+		Position: GridPos{X: 1, Y: 2}, CorrectPosition: GridPos{X: 0, Y: 0},
+		Solved: true, Visible: true, LastMoved: lastMoved,
+	}
+	gm.state.PuzzleFragments["fragment_unassigned_0"] = &PuzzleFragment{
+		ID: "fragment_unassigned_0", MovableBy: "anyone", IsUnassigned: true,
+	}
+	gm.state.PlayerAnalytics[player.ID] = &PlayerAnalytics{
+		PlayerID:        player.ID,
+		TokenCollection: map[string]int{constants.TokenAnchor: 12},
+	}
+	snapshot := gm.buildSnapshotInternal()
+	gm.mu.Unlock()
+
+	// Later changes must not leak into an already built snapshot
+	gm.mu.Lock()
+	gm.state.PlayerAnalytics[player.ID].TokenCollection[constants.TokenAnchor] = 99
+	gm.mu.Unlock()
+	assert.Equal(t, 12, snapshot.PlayerAnalytics[player.ID].TokenCollection[constants.TokenAnchor])
+
+	restoredGM, restoredPM, restoredTM, _ := createSeededTestGameManager(clock, testSeed)
+	defer cleanupTestGameManager(restoredTM)
+	defer restoredGM.Stop()
+
+	assert.NoError(t, restoredGM.RestoreFromSnapshot(snapshot))
+
+	// Players come back disconnected and may reconnect with their old IDs
+	restoredPlayer, err := restoredPM.GetPlayer(player.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, StateDisconnected, restoredPlayer.State)
+	assert.Equal(t, constants.RoleDetective, restoredPlayer.Role)
+	assert.True(t, restoredPM.IsAwaitingRecovery(player.ID))
+
+	restoredHost, err := restoredPM.GetPlayer(host.ID)
+	assert.NoError(t, err)
+	assert.True(t, restoredHost.IsHost)
+
+	restoredGM.mu.RLock()
+	defer restoredGM.mu.RUnlock()
+
+	state := restoredGM.state
+	assert.Equal(t, PhasePuzzleAssembly, state.Phase)
+	assert.Equal(t, "hard", state.Difficulty)
+	assert.Equal(t, TeamTokens{AnchorTokens: 12, ChronosTokens: 7}, state.TeamTokens)
+	assert.Equal(t, "masterpiece_007", state.PuzzleImageID)
+	assert.Equal(t, 300*time.Second, state.PuzzleDuration)
+	assert.Equal(t, clock.Now().Add(-30*time.Second), state.PuzzleStartTime)
+
+	fragment := state.PuzzleFragments["fragment_"+player.ID]
+	assert.NotNil(t, fragment)
+	assert.Equal(t, GridPos{X: 1, Y: 2}, fragment.Position)
+	assert.True(t, fragment.LastMoved.Equal(lastMoved))
+	assert.True(t, state.PuzzleFragments["fragment_unassigned_0"].IsUnassigned)
+	assert.Equal(t, 12, state.PlayerAnalytics[player.ID].TokenCollection[constants.TokenAnchor])
+}
+
+func TestRestoreResumesResourceRound(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	gm, _, tm, _ := createSeededTestGameManager(clock, testSeed)
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	snapshot := createTestSnapshot("ACDEF")
+	snapshot.CurrentRound = 3
+	snapshot.RoundElapsed = 20 * time.Second
+	restoredAt := clock.Now()
+
+	assert.NoError(t, gm.RestoreFromSnapshot(snapshot))
+
+	// The trivia manager's cleanup ticker is always pending; wait for the resumed round timer
+	WaitForCondition(t, func() bool { return clock.PendingTimers() == 2 }, time.Second, "resumed round timer")
+
+	// The resumed round keeps its original start instead of restarting the clock
+	gm.mu.RLock()
+	assert.Equal(t, PhaseResourceGathering, gm.state.Phase)
+	assert.Equal(t, 3, gm.state.CurrentRound)
+	assert.Equal(t, restoredAt.Add(-20*time.Second), gm.state.RoundStartTime)
+	assert.NotNil(t, gm.state.QuestionHistory["player-1"])
+	assert.Empty(t, gm.state.CurrentQuestions)
+	gm.mu.RUnlock()
+
+	// Only the 40 seconds left in the round have to pass before the next one starts
+	clock.Advance(40 * time.Second)
+	WaitForCondition(t, func() bool {
+		gm.mu.RLock()
+		defer gm.mu.RUnlock()
+		return gm.state.CurrentRound == 4
+	}, time.Second, "round 4")
+
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	assert.Equal(t, restoredAt.Add(40*time.Second), gm.state.RoundStartTime)
+}
+
+func TestRestoreRejectsFinishedGames(t *testing.T) {
+	gm, _, tm, _ := createTestGameManager()
+	defer cleanupTestGameManager(tm)
+
+	for _, phase := range []GamePhase{PhaseSetup, PhasePostGame} {
+		snapshot := createTestSnapshot("ACDEF")
+		snapshot.Phase = phase
+		assert.EqualError(t, gm.RestoreFromSnapshot(snapshot), constants.ErrSnapshotNotResumable)
+	}
+
+	// Only a fresh game can be overwritten by a snapshot
+	SimulateGamePhase(gm, PhaseResourceGathering)
+	assert.Error(t, gm.RestoreFromSnapshot(createTestSnapshot("ACDEF")))
+}
+
+func TestRoomsSurviveRestart(t *testing.T) {
+	tm := NewTriviaManager(realClock{}, testSeed)
+	defer tm.Shutdown()
+
+	store, err := NewSnapshotStore(t.TempDir())
+	assert.NoError(t, err)
+
+	rm := NewRoomManager(tm, store, nil, "")
+	room, err := rm.CreateRoom()
+	assert.NoError(t, err)
+	idle, err := rm.CreateRoom()
+	assert.NoError(t, err)
+
+	player := room.playerManager.CreatePlayer(nil, false)
+	room.gameManager.mu.Lock()
+	room.gameManager.state.Phase = PhaseResourceGathering
+	room.gameManager.state.CurrentRound = 2
+	room.gameManager.state.RoundStartTime = time.Now()
+	room.gameManager.state.TeamTokens.GuideTokens = 9
+	room.gameManager.mu.Unlock()
+
+	// Graceful shutdown keeps running games on disk; rooms still in setup are not saved
+	rm.Shutdown()
+	_, err = store.Load(room.Code)
+	assert.NoError(t, err)
+	_, err = store.Load(idle.Code)
+	assert.Error(t, err)
+
+	restartedRM := NewRoomManager(tm, store, nil, "")
+	defer restartedRM.Shutdown()
+
+	restored, err := restartedRM.RestoreRooms()
+	assert.NoError(t, err)
+	assert.Len(t, restored, 1)
+
+	restoredRoom, err := restartedRM.GetRoom(room.Code)
+	assert.NoError(t, err)
+	assert.Equal(t, room.HostEndpointID, restoredRoom.HostEndpointID)
+	assert.Equal(t, PhaseResourceGathering, restoredRoom.gameManager.GetPhase())
+	assert.True(t, restoredRoom.playerManager.IsAwaitingRecovery(player.ID))
+
+	restoredRoom.gameManager.mu.RLock()
+	assert.Equal(t, 9, restoredRoom.gameManager.state.TeamTokens.GuideTokens)
+	restoredRoom.gameManager.mu.RUnlock()
+
+	// Closing a room on purpose removes its snapshot so it won't come back
+	assert.NoError(t, restartedRM.CloseRoom(room.Code))
+	_, err = store.Load(room.Code)
+	assert.Error(t, err)
+}
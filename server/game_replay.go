@@ -0,0 +1,433 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+)
+
+// ReplayState is the canonical view of a game compared between a recording and its replay.
+// Everything is sorted and wall-clock timestamps are left out so equal games encode to equal JSON.
+type ReplayState struct {
+	Phase           string                 `json:"phase"`
+	Difficulty      string                 `json:"difficulty"`
+	Settings        GameSettings           `json:"settings"`
+	CurrentRound    int                    `json:"currentRound"`
+	TeamTokens      TeamTokens             `json:"teamTokens"`
+	GridSize        int                    `json:"gridSize"`
+	PuzzleImageID   string                 `json:"puzzleImageId"`
+	Players         []ReplayPlayer         `json:"players"`
+	Fragments       []ReplayFragment       `json:"fragments"`
+	TriviaAnswers   []ReplayAnswer         `json:"triviaAnswers"`
+	Recommendations []ReplayRecommendation `json:"recommendations"`
+	Paused          bool                   `json:"paused"`
+}
+
+// ReplayPlayer is the replay view of a player
+type ReplayPlayer struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Role        string   `json:"role"`
+	Specialties []string `json:"specialties"`
+	Location    string   `json:"location"`
+	Ready       bool     `json:"ready"`
+	IsHost      bool     `json:"isHost"`
+	Connected   bool     `json:"connected"`
+}
+
+// ReplayFragment is the replay view of a puzzle fragment
+type ReplayFragment struct {
+	ID           string  `json:"id"`
+	PlayerID     string  `json:"playerId"`
+	Position     GridPos `json:"position"`
+	Solved       bool    `json:"solved"`
+	Visible      bool    `json:"visible"`
+	IsUnassigned bool    `json:"isUnassigned"`
+}
+
+// ReplayAnswer is the replay view of an answered trivia question
+type ReplayAnswer struct {
+	PlayerID      string `json:"playerId"`
+	QuestionID    string `json:"questionId"`
+	Answer        string `json:"answer"`
+	Correct       bool   `json:"correct"`
+	TokenType     string `json:"tokenType"`
+	TokensAwarded int    `json:"tokensAwarded"`
+}
+
+// ReplayRecommendation is the replay view of a piece recommendation
+type ReplayRecommendation struct {
+	ID             string `json:"id"`
+	FromPlayerID   string `json:"fromPlayerId"`
+	ToPlayerID     string `json:"toPlayerId"`
+	FromFragmentID string `json:"fromFragmentId"`
+	ToFragmentID   string `json:"toFragmentId"`
+	Status         string `json:"status"`
+}
+
+// ReplayResult summarizes a replay; Mismatch is nil when every checkpoint matched
+type ReplayResult struct {
+	Events      int             `json:"events"`
+	Inputs      int             `json:"inputs"`
+	Checkpoints int             `json:"checkpoints"`
+	Mismatch    *ReplayMismatch `json:"mismatch,omitempty"`
+}
+
+// ReplayMismatch describes the first checkpoint where the replay diverged from the recording
+type ReplayMismatch struct {
+	Seq           int64           `json:"seq"`
+	Reason        string          `json:"reason"`
+	Detail        string          `json:"detail"`
+	Expected      json.RawMessage `json:"expected,omitempty"`
+	Actual        json.RawMessage `json:"actual,omitempty"`
+	ExpectedError string          `json:"expectedError,omitempty"`
+	ActualError   string          `json:"actualError,omitempty"`
+}
+
+// EnableEventLog makes the game manager record every message and state change in the event log
+func (gm *GameManager) EnableEventLog(eventLog *EventLog, roomCode string) {
+	gm.eventLog = eventLog
+	gm.playerManager.mu.Lock()
+	gm.playerManager.eventLog = eventLog
+	gm.playerManager.mu.Unlock()
+
+	gm.mu.RLock()
+	balance := gm.balance
+	gm.mu.RUnlock()
+
+	gm.playerManager.mu.RLock()
+	blocklist := gm.playerManager.nameFilter.Words()
+	gm.playerManager.mu.RUnlock()
+
+	eventLog.Record(EventLogStarted, "", "", EventLogHeader{RoomCode: roomCode, Seed: gm.seed, Balance: balance, NameBlocklist: blocklist})
+}
+
+// pickQuestion draws the next question for a player and records it, answer included, in the event log.
+// During a replay the recorded questions are handed out again instead.
+func (gm *GameManager) pickQuestion(player *Player, difficulty string, history map[string]bool) (*TriviaQuestion, error) {
+	var question *TriviaQuestion
+
+	if gm.replayQuestions != nil {
+		gm.mu.Lock()
+		queue := gm.replayQuestions[player.ID]
+		if len(queue) > 0 {
+			question = queue[0]
+			gm.replayQuestions[player.ID] = queue[1:]
+		}
+		gm.mu.Unlock()
+
+		if question == nil {
+			return nil, fmt.Errorf("event log has no more questions for player %s", player.ID)
+		}
+	} else {
+		var err error
+		question, err = gm.triviaManager.GetQuestion(difficulty, player.Specialties, history)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	gm.eventLog.Record(EventQuestionIssued, player.ID, MsgTriviaQuestion, questionRecord{
+		TriviaQuestion: *question,
+		CorrectAnswer:  question.CorrectAnswer,
+	})
+	return question, nil
+}
+
+// recordCheckpoint writes the current game state to the event log
+func (gm *GameManager) recordCheckpoint(reason string, handlerErr error) {
+	if gm.eventLog == nil {
+		return
+	}
+
+	gm.mu.RLock()
+	state := gm.replayStateInternal()
+	gm.mu.RUnlock()
+
+	gm.eventLog.RecordCheckpoint(reason, state, handlerErr)
+}
+
+// recordCheckpointInternal writes the current game state to the event log
+// NOTE: This method assumes the caller already holds gm.mu lock (read or write)
+func (gm *GameManager) recordCheckpointInternal(reason string) {
+	if gm.eventLog == nil {
+		return
+	}
+	gm.eventLog.RecordCheckpoint(reason, gm.replayStateInternal(), nil)
+}
+
+// replayStateInternal builds the canonical state compared during replay
+// NOTE: This method assumes the caller already holds gm.mu lock (read or write)
+func (gm *GameManager) replayStateInternal() *ReplayState {
+	state := &ReplayState{
+		Phase:           gm.state.Phase.String(),
+		Difficulty:      gm.state.Difficulty,
+		Settings:        gm.state.Settings,
+		CurrentRound:    gm.state.CurrentRound,
+		TeamTokens:      gm.state.TeamTokens,
+		GridSize:        gm.state.GridSize,
+		PuzzleImageID:   gm.state.PuzzleImageID,
+		Players:         make([]ReplayPlayer, 0),
+		Fragments:       make([]ReplayFragment, 0, len(gm.state.PuzzleFragments)),
+		TriviaAnswers:   make([]ReplayAnswer, 0, len(gm.state.TriviaAnswers)),
+		Recommendations: make([]ReplayRecommendation, 0),
+		Paused:          gm.state.Paused,
+	}
+
+	for _, player := range gm.playerManager.GetAllPlayers() {
+		player.mu.RLock()
+		state.Players = append(state.Players, ReplayPlayer{
+			ID:          player.ID,
+			Name:        player.Name,
+			Role:        player.Role,
+			Specialties: append([]string{}, player.Specialties...),
+			Location:    player.CurrentLocation,
+			Ready:       player.Ready,
+			IsHost:      player.IsHost,
+			Connected:   player.State == StateConnected,
+		})
+		player.mu.RUnlock()
+	}
+	sort.Slice(state.Players, func(i, j int) bool {
+		return state.Players[i].ID < state.Players[j].ID
+	})
+
+	for _, fragment := range gm.state.PuzzleFragments {
+		state.Fragments = append(state.Fragments, ReplayFragment{
+			ID:           fragment.ID,
+			PlayerID:     fragment.PlayerID,
+			Position:     fragment.Position,
+			Solved:       fragment.Solved,
+			Visible:      fragment.Visible,
+			IsUnassigned: fragment.IsUnassigned,
+		})
+	}
+	sort.Slice(state.Fragments, func(i, j int) bool {
+		return state.Fragments[i].ID < state.Fragments[j].ID
+	})
+
+	for _, answer := range gm.state.TriviaAnswers {
+		state.TriviaAnswers = append(state.TriviaAnswers, ReplayAnswer{
+			PlayerID:      answer.PlayerID,
+			QuestionID:    answer.QuestionID,
+			Answer:        answer.Answer,
+			Correct:       answer.Correct,
+			TokenType:     answer.TokenType,
+			TokensAwarded: answer.TokensAwarded,
+		})
+	}
+
+	for _, resolved := range gm.state.ResolvedRecommendations {
+		state.Recommendations = append(state.Recommendations, replayRecommendation(resolved.PieceRecommendation, resolved.Status))
+	}
+	pending := make([]ReplayRecommendation, 0, len(gm.state.PieceRecommendations))
+	for _, recommendation := range gm.state.PieceRecommendations {
+		pending = append(pending, replayRecommendation(*recommendation, "pending"))
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].ID < pending[j].ID
+	})
+	state.Recommendations = append(state.Recommendations, pending...)
+
+	return state
+}
+
+func replayRecommendation(recommendation PieceRecommendation, status string) ReplayRecommendation {
+	return ReplayRecommendation{
+		ID:             recommendation.ID,
+		FromPlayerID:   recommendation.FromPlayerID,
+		ToPlayerID:     recommendation.ToPlayerID,
+		FromFragmentID: recommendation.FromFragmentID,
+		ToFragmentID:   recommendation.ToFragmentID,
+		Status:         status,
+	}
+}
+
+// ReplayEventLog feeds a recorded game back through a fresh EventHandlers/GameManager pair on a fake
+// clock and checks that every recorded checkpoint is reproduced. Trivia questions come from the log,
+// so the replay does not depend on the trivia files matching the ones the game was played with.
+func ReplayEventLog(events []GameEvent, triviaManager *TriviaManager) (*ReplayResult, error) {
+	if len(events) == 0 || events[0].Kind != EventLogStarted {
+		return nil, fmt.Errorf("event log does not start with a %s event", EventLogStarted)
+	}
+
+	var header EventLogHeader
+	if err := json.Unmarshal(events[0].Payload, &header); err != nil {
+		return nil, fmt.Errorf("invalid event log header: %v", err)
+	}
+
+	questions := make(map[string][]*TriviaQuestion)
+	for _, event := range events {
+		switch event.Kind {
+		case EventSnapshotRestored:
+			return nil, fmt.Errorf("game was resumed from a snapshot at event %d and cannot be replayed from the start", event.Seq)
+
+		case EventQuestionIssued:
+			var record questionRecord
+			if err := json.Unmarshal(event.Payload, &record); err != nil {
+				return nil, fmt.Errorf("invalid question at event %d: %v", event.Seq, err)
+			}
+			question := record.TriviaQuestion
+			question.CorrectAnswer = record.CorrectAnswer
+			questions[event.PlayerID] = append(questions[event.PlayerID], &question)
+		}
+	}
+
+	// Build the same stack a room uses, driven by a fake clock and the recorded seed
+	clock := NewFakeClock(events[0].Time)
+	checkpoints := newCheckpointCollector()
+
+	broadcastChan := make(chan BroadcastMessage, constants.BroadcastChannelBuffer)
+	playerManager := NewPlayerManager()
+	gameManager := NewGameManager(playerManager, triviaManager, broadcastChan, clock, header.Seed)
+	gameManager.replayQuestions = questions
+	if header.Balance != nil {
+		gameManager.UseBalance(header.Balance)
+	}
+	if header.NameBlocklist != nil {
+		playerManager.UseNameFilter(NewNameFilter(header.NameBlocklist))
+	}
+	gameManager.EnableEventLog(NewEventLog(checkpoints, clock), header.RoomCode)
+
+	eventHandlers := NewEventHandlers(gameManager, playerManager, broadcastChan)
+	wsHandler := NewWebSocketHandler(playerManager, gameManager, eventHandlers, broadcastChan)
+	wsHandler.StartBroadcaster()
+	defer func() {
+		gameManager.Stop()
+		wsHandler.StopBroadcaster()
+	}()
+
+	// Hosts are removed from the player manager when they disconnect, so keep every player seen
+	players := make(map[string]*Player)
+	result := &ReplayResult{Events: len(events)}
+
+	for _, event := range events[1:] {
+		clock.AdvanceTo(event.Time)
+
+		switch event.Kind {
+		case EventPlayerConnected:
+			var record connectionRecord
+			if err := json.Unmarshal(event.Payload, &record); err != nil {
+				return nil, fmt.Errorf("invalid connection at event %d: %v", event.Seq, err)
+			}
+
+			player := players[event.PlayerID]
+			if record.Reconnected && player != nil {
+				playerManager.ReconnectPlayer(event.PlayerID, nil)
+			} else {
+				player = playerManager.createPlayer(event.PlayerID, nil, record.IsHost)
+				players[event.PlayerID] = player
+			}
+			wsHandler.admitPlayer(player, record.Reconnected)
+			result.Inputs++
+
+		case EventPlayerDisconnected, EventHostDisconnected:
+			player := players[event.PlayerID]
+			if player == nil {
+				return nil, fmt.Errorf("event %d disconnects unknown player %s", event.Seq, event.PlayerID)
+			}
+			if event.Kind == EventHostDisconnected {
+				wsHandler.handleHostDisconnection(player)
+			} else {
+				wsHandler.handleDisconnection(player)
+			}
+			result.Inputs++
+
+		case EventInbound:
+			wsHandler.routeValidatedMessage(event.PlayerID, event.Type, event.Payload)
+			result.Inputs++
+
+		case EventCheckpoint:
+			result.Checkpoints++
+
+			actual, ok := checkpoints.next(event.Type, constants.ReplayCheckpointTimeout)
+			if !ok {
+				result.Mismatch = &ReplayMismatch{
+					Seq:      event.Seq,
+					Reason:   event.Type,
+					Detail:   "replay never reached this checkpoint",
+					Expected: event.Payload,
+				}
+				return result, nil
+			}
+
+			if !bytes.Equal(event.Payload, actual.Payload) || event.Error != actual.Error {
+				result.Mismatch = &ReplayMismatch{
+					Seq:           event.Seq,
+					Reason:        event.Type,
+					Detail:        "replayed state differs from the recording",
+					Expected:      event.Payload,
+					Actual:        actual.Payload,
+					ExpectedError: event.Error,
+					ActualError:   actual.Error,
+				}
+				return result, nil
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// checkpointCollector is the event log sink of a replay. It queues checkpoints per reason, so
+// checkpoints taken by concurrent timers may land in either order without failing the replay.
+type checkpointCollector struct {
+	pending map[string][]GameEvent
+	notify  chan struct{}
+	mu      sync.Mutex
+}
+
+func newCheckpointCollector() *checkpointCollector {
+	return &checkpointCollector{
+		pending: make(map[string][]GameEvent),
+		notify:  make(chan struct{}, 1),
+	}
+}
+
+// Write receives one encoded event per call from EventLog
+func (cc *checkpointCollector) Write(p []byte) (int, error) {
+	var event GameEvent
+	if err := json.Unmarshal(p, &event); err != nil {
+		return 0, err
+	}
+
+	if event.Kind == EventCheckpoint {
+		cc.mu.Lock()
+		cc.pending[event.Type] = append(cc.pending[event.Type], event)
+		cc.mu.Unlock()
+
+		select {
+		case cc.notify <- struct{}{}:
+		default:
+		}
+	}
+
+	return len(p), nil
+}
+
+// next waits for the replay to produce its next checkpoint with the given reason. The timeout is
+// real time: timers run on the fake clock, but the goroutines they wake still need to be scheduled.
+func (cc *checkpointCollector) next(reason string, timeout time.Duration) (GameEvent, bool) {
+	deadline := time.After(timeout)
+
+	for {
+		cc.mu.Lock()
+		if queue := cc.pending[reason]; len(queue) > 0 {
+			cc.pending[reason] = queue[1:]
+			cc.mu.Unlock()
+			return queue[0], true
+		}
+		cc.mu.Unlock()
+
+		select {
+		case <-cc.notify:
+		case <-deadline:
+			return GameEvent{}, false
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+// syncBuffer lets the test read the event log while game goroutines are still writing to it
+type syncBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.Write(p)
+}
+
+func (sb *syncBuffer) events(t *testing.T) []GameEvent {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	events, err := ReadEventLog(bytes.NewReader(sb.buf.Bytes()))
+	assert.NoError(t, err)
+	return events
+}
+
+func countCheckpoints(events []GameEvent, reason string) int {
+	count := 0
+	for _, event := range events {
+		if event.Kind == EventCheckpoint && event.Type == reason {
+			count++
+		}
+	}
+	return count
+}
+
+// recordTestGame plays a whole game on a fake clock, from lobby to reset, and returns its event log
+func recordTestGame(t *testing.T, tm *TriviaManager) []GameEvent {
+	clock := NewFakeClock(time.Now().Add(-2 * time.Hour).Truncate(time.Second))
+	log := &syncBuffer{}
+
+	broadcastChan := make(chan BroadcastMessage, constants.BroadcastChannelBuffer)
+	pm := NewPlayerManager()
+	gm := NewGameManager(pm, tm, broadcastChan, clock, testSeed)
+	gm.EnableEventLog(NewEventLog(log, clock), "TEST1")
+	eh := NewEventHandlers(gm, pm, broadcastChan)
+	wsh := NewWebSocketHandler(pm, gm, eh, broadcastChan)
+	wsh.StartBroadcaster()
+	defer func() {
+		gm.Stop()
+		wsh.StopBroadcaster()
+	}()
+
+	send := func(player *Player, msgType string, payload interface{}) {
+		clock.Advance(time.Second)
+		wsh.routeValidatedMessage(player.ID, msgType, mustMarshal(payload))
+	}
+	waitForCheckpoints := func(reason string, count int) {
+		WaitForCondition(t, func() bool {
+			return countCheckpoints(log.events(t), reason) >= count
+		}, 2*time.Second, fmt.Sprintf("%d %s checkpoints", count, reason))
+	}
+
+	// Lobby
+	host := pm.CreatePlayer(nil, true)
+	assert.NoError(t, wsh.admitPlayer(host, false))
+
+	roles := []string{constants.RoleArtEnthusiast, constants.RoleDetective, constants.RoleTourist, constants.RoleJanitor}
+	players := make([]*Player, 0, len(roles))
+	for i, role := range roles {
+		player := pm.CreatePlayer(nil, false)
+		assert.NoError(t, wsh.admitPlayer(player, false))
+		send(player, MsgRoleSelection, map[string]string{"role": role})
+		send(player, MsgTriviaSpecialtySelection, map[string][]string{"specialties": {tm.GetAvailableCategories()[i]}})
+		send(player, MsgPlayerReady, map[string]bool{"ready": true})
+		players = append(players, player)
+	}
+	send(host, MsgHostStartGame, map[string]interface{}{})
+
+	// Resource gathering: everyone answers every round, the first player always wrongly
+	stations := []string{constants.TokenAnchor, constants.TokenChronos, constants.TokenGuide, constants.TokenClarity}
+	for round := 1; round <= constants.ResourceGatheringRounds; round++ {
+		waitForCheckpoints("round_started", round)
+
+		for i, player := range players {
+			send(player, MsgResourceLocationVerified, map[string]string{
+				"verifiedHash": constants.ResourceStationHashes[stations[(i+round)%len(stations)]],
+			})
+
+			gm.mu.RLock()
+			question := gm.state.CurrentQuestions[player.ID]
+			gm.mu.RUnlock()
+			if !assert.NotNil(t, question) {
+				return nil
+			}
+
+			answer := question.CorrectAnswer
+			if i == 0 {
+				answer = "definitely not the answer"
+			}
+			send(player, MsgTriviaAnswer, map[string]interface{}{
+				"questionId": question.ID,
+				"answer":     answer,
+				"timestamp":  clock.Now().Unix(),
+			})
+		}
+
+		clock.Advance(time.Duration(constants.ResourceGatheringRoundDuration) * time.Second)
+	}
+
+	// Puzzle assembly: release every unassigned fragment, then let the timer run out
+	waitForCheckpoints("puzzle_phase_started", 1)
+	send(host, MsgHostStartPuzzle, map[string]interface{}{})
+
+	gm.mu.RLock()
+	unassigned := 0
+	for _, fragment := range gm.state.PuzzleFragments {
+		if fragment.IsUnassigned {
+			unassigned++
+		}
+	}
+	puzzleDuration := gm.state.PuzzleDuration
+	gm.mu.RUnlock()
+
+	elapsed := time.Duration(0)
+	for released := 1; released <= unassigned; released++ {
+		clock.Advance(30 * time.Second)
+		elapsed += 30 * time.Second
+		waitForCheckpoints("fragment_released", released)
+	}
+
+	clock.Advance(puzzleDuration - elapsed)
+	waitForCheckpoints("game_ended", 1)
+
+	clock.Advance(time.Duration(constants.PostGameAnalyticsDuration) * time.Second)
+	waitForCheckpoints("game_reset", 1)
+
+	return log.events(t)
+}
+
+func TestReplayReproducesRecordedGame(t *testing.T) {
+	tm := NewTriviaManager(realClock{}, testSeed)
+	defer tm.Shutdown()
+
+	events := recordTestGame(t, tm)
+	if t.Failed() {
+		return
+	}
+
+	// The log answers "my answer was right!" disputes on its own
+	issued := 0
+	for _, event := range events {
+		if event.Kind == EventQuestionIssued {
+			var record questionRecord
+			assert.NoError(t, json.Unmarshal(event.Payload, &record))
+			assert.NotEmpty(t, record.CorrectAnswer)
+			issued++
+		}
+	}
+	assert.Equal(t, 4*constants.ResourceGatheringRounds, issued)
+
+	result, err := ReplayEventLog(events, tm)
+	assert.NoError(t, err)
+	assert.Nil(t, result.Mismatch)
+	assert.Greater(t, result.Checkpoints, 50)
+	assert.Equal(t, countCheckpoints(events, "game_reset"), 1)
+}
+
+func TestReplayDetectsDivergence(t *testing.T) {
+	tm := NewTriviaManager(realClock{}, testSeed)
+	defer tm.Shutdown()
+
+	events := recordTestGame(t, tm)
+	if t.Failed() {
+		return
+	}
+
+	// Change the first wrong answer the server accepted into the right one
+	var tamperedSeq int64
+	for i, event := range events {
+		if event.Kind != EventInbound || event.Type != MsgTriviaAnswer || !strings.Contains(string(event.Payload), "definitely not") {
+			continue
+		}
+		if !answerAccepted(events[i+1:]) {
+			continue
+		}
+
+		var payload map[string]interface{}
+		assert.NoError(t, json.Unmarshal(event.Payload, &payload))
+		for _, issued := range events[:i] {
+			if issued.Kind == EventQuestionIssued && issued.PlayerID == event.PlayerID {
+				var record questionRecord
+				assert.NoError(t, json.Unmarshal(issued.Payload, &record))
+				if record.ID == payload["questionId"] {
+					payload["answer"] = record.CorrectAnswer
+				}
+			}
+		}
+		events[i].Payload = mustMarshal(payload)
+		tamperedSeq = event.Seq
+		break
+	}
+	assert.NotZero(t, tamperedSeq)
+
+	result, err := ReplayEventLog(events, tm)
+	assert.NoError(t, err)
+	if assert.NotNil(t, result.Mismatch) {
+		assert.Greater(t, result.Mismatch.Seq, tamperedSeq)
+		assert.Equal(t, "inbound:"+MsgTriviaAnswer, result.Mismatch.Reason)
+	}
+}
+
+// answerAccepted reports whether the trivia answer preceding events passed validation
+func answerAccepted(events []GameEvent) bool {
+	for _, event := range events {
+		if event.Kind == EventCheckpoint && event.Type == "inbound:"+MsgTriviaAnswer {
+			return event.Error == ""
+		}
+	}
+	return false
+}
+
+func TestReplayRejectsRestoredGames(t *testing.T) {
+	events := []GameEvent{
+		{Seq: 1, Kind: EventLogStarted, Payload: mustMarshal(EventLogHeader{RoomCode: "ACDEF", Seed: 1})},
+		{Seq: 2, Kind: EventSnapshotRestored},
+	}
+
+	_, err := ReplayEventLog(events, nil)
+	assert.Error(t, err)
+
+	_, err = ReplayEventLog(events[1:], nil)
+	assert.Error(t, err)
+}
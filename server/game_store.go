@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GameStore persists finished games so they outlive resetGame
+type GameStore interface {
+	// SaveGame stores a finished game record
+	SaveGame(record *GameRecord) error
+	// ListGames returns summaries of stored games, most recently finished first
+	ListGames(limit, offset int) ([]GameSummary, error)
+	// GetGame returns the full record for one game
+	GetGame(id string) (*GameRecord, error)
+	// Close releases the underlying storage
+	Close() error
+}
+
+// GameSummary is the listing view of a finished game
+type GameSummary struct {
+	ID          string    `json:"id"`
+	RoomCode    string    `json:"roomCode"`
+	Difficulty  string    `json:"difficulty"`
+	Success     bool      `json:"success"`
+	StartedAt   time.Time `json:"startedAt"`
+	EndedAt     time.Time `json:"endedAt"`
+	PlayerCount int       `json:"playerCount"`
+}
+
+// GameRecord is the complete history of a finished game
+type GameRecord struct {
+	GameSummary
+	TeamTokens      TeamTokens             `json:"teamTokens"`
+	Players         []GameRecordPlayer     `json:"players"`
+	TriviaAnswers   []TriviaAnswerRecord   `json:"triviaAnswers"`
+	FragmentMoves   []FragmentMove         `json:"fragmentMoves"`
+	Recommendations []RecommendationRecord `json:"recommendations"`
+	Analytics       map[string]interface{} `json:"analytics"` // Output of calculateFinalAnalytics
+}
+
+// GameRecordPlayer is a participant as they were when the game ended
+type GameRecordPlayer struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Role        string   `json:"role"`
+	Specialties []string `json:"specialties"`
+	IsHost      bool     `json:"isHost"`
+}
+
+// EnableGameHistory makes the game manager record every finished game in the store
+func (gm *GameManager) EnableGameHistory(store GameStore, roomCode string) {
+	gm.gameStore = store
+	gm.gameStoreRoomCode = roomCode
+}
+
+// saveGameRecord stores the finished game in the background so endGame never waits on the database
+func (gm *GameManager) saveGameRecord(success bool, analytics GameAnalyticsPayload) {
+	if gm.gameStore == nil {
+		return
+	}
+
+	// Records keep analytics as plain JSON, so old records still load after the payload changes
+	var stored map[string]interface{}
+	if err := json.Unmarshal(mustMarshal(analytics), &stored); err != nil {
+		log.Printf("Failed to record analytics for room %s: %v", gm.gameStoreRoomCode, err)
+	}
+
+	gm.mu.RLock()
+	record := gm.buildGameRecordInternal(success, stored)
+	gm.mu.RUnlock()
+
+	gm.pendingSaves.Add(1)
+	go func() {
+		defer gm.pendingSaves.Done()
+		if err := gm.gameStore.SaveGame(record); err != nil {
+			log.Printf("Failed to save game history for room %s: %v", record.RoomCode, err)
+			return
+		}
+		log.Printf("Saved game %s to history", record.ID)
+	}()
+}
+
+// WaitForSaves blocks until every game record saveGameRecord started has been written, so the
+// store isn't closed under an in-flight save
+func (gm *GameManager) WaitForSaves() {
+	gm.pendingSaves.Wait()
+}
+
+// buildGameRecordInternal collects everything worth keeping about the finished game
+// NOTE: This method assumes the caller already holds gm.mu lock (read or write)
+func (gm *GameManager) buildGameRecordInternal(success bool, analytics map[string]interface{}) *GameRecord {
+	state := gm.state
+
+	record := &GameRecord{
+		GameSummary: GameSummary{
+			ID:         uuid.New().String(),
+			RoomCode:   gm.gameStoreRoomCode,
+			Difficulty: state.Difficulty,
+			Success:    success,
+			StartedAt:  state.StartedAt,
+			EndedAt:    time.Now(),
+		},
+		TeamTokens:      state.TeamTokens,
+		Players:         make([]GameRecordPlayer, 0),
+		TriviaAnswers:   append([]TriviaAnswerRecord(nil), state.TriviaAnswers...),
+		FragmentMoves:   append([]FragmentMove(nil), state.FragmentMoveHistory...),
+		Recommendations: append([]RecommendationRecord(nil), state.ResolvedRecommendations...),
+		Analytics:       analytics,
+	}
+
+	for _, player := range gm.playerManager.GetAllPlayers() {
+		player.mu.RLock()
+		record.Players = append(record.Players, GameRecordPlayer{
+			ID:          player.ID,
+			Name:        player.Name,
+			Role:        player.Role,
+			Specialties: append([]string(nil), player.Specialties...),
+			IsHost:      player.IsHost,
+		})
+		if !player.IsHost {
+			record.PlayerCount++
+		}
+		player.mu.RUnlock()
+	}
+	sort.Slice(record.Players, func(i, j int) bool {
+		return record.Players[i].Name < record.Players[j].Name
+	})
+
+	// Recommendations nobody answered before the game ended
+	for _, recommendation := range state.PieceRecommendations {
+		record.Recommendations = append(record.Recommendations, RecommendationRecord{
+			PieceRecommendation: *recommendation,
+			Status:              "pending",
+		})
+	}
+
+	return record
+}
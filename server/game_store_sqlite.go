@@ -0,0 +1,376 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteSchema creates the game history tables. Child rows are removed with their game.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS games (
+	id          TEXT PRIMARY KEY,
+	room_code   TEXT NOT NULL,
+	difficulty  TEXT NOT NULL,
+	success     INTEGER NOT NULL,
+	started_at  INTEGER NOT NULL,
+	ended_at    INTEGER NOT NULL,
+	team_tokens TEXT NOT NULL,
+	analytics   TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_games_ended_at ON games(ended_at);
+
+CREATE TABLE IF NOT EXISTS game_players (
+	game_id     TEXT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+	player_id   TEXT NOT NULL,
+	name        TEXT NOT NULL,
+	role        TEXT NOT NULL,
+	specialties TEXT NOT NULL,
+	is_host     INTEGER NOT NULL,
+	PRIMARY KEY (game_id, player_id)
+);
+
+CREATE TABLE IF NOT EXISTS trivia_answers (
+	game_id        TEXT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+	seq            INTEGER NOT NULL,
+	player_id      TEXT NOT NULL,
+	question_id    TEXT NOT NULL,
+	category       TEXT NOT NULL,
+	is_specialty   INTEGER NOT NULL,
+	answer         TEXT NOT NULL,
+	correct        INTEGER NOT NULL,
+	round          INTEGER NOT NULL,
+	token_type     TEXT NOT NULL,
+	tokens_awarded INTEGER NOT NULL,
+	answered_at    INTEGER NOT NULL,
+	PRIMARY KEY (game_id, seq)
+);
+
+CREATE TABLE IF NOT EXISTS fragment_moves (
+	game_id     TEXT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+	seq         INTEGER NOT NULL,
+	fragment_id TEXT NOT NULL,
+	player_id   TEXT NOT NULL,
+	from_x      INTEGER NOT NULL,
+	from_y      INTEGER NOT NULL,
+	to_x        INTEGER NOT NULL,
+	to_y        INTEGER NOT NULL,
+	moved_at    INTEGER NOT NULL,
+	PRIMARY KEY (game_id, seq)
+);
+
+CREATE TABLE IF NOT EXISTS recommendations (
+	game_id          TEXT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+	id               TEXT NOT NULL,
+	from_player_id   TEXT NOT NULL,
+	to_player_id     TEXT NOT NULL,
+	from_fragment_id TEXT NOT NULL,
+	to_fragment_id   TEXT NOT NULL,
+	from_x           INTEGER NOT NULL,
+	from_y           INTEGER NOT NULL,
+	to_x             INTEGER NOT NULL,
+	to_y             INTEGER NOT NULL,
+	status           TEXT NOT NULL,
+	created_at       INTEGER NOT NULL,
+	responded_at     INTEGER NOT NULL,
+	PRIMARY KEY (game_id, id)
+);
+`
+
+// SQLiteGameStore is the embedded SQLite implementation of GameStore
+type SQLiteGameStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteGameStore opens (or creates) the SQLite database at path and applies the schema
+func NewSQLiteGameStore(path string) (*SQLiteGameStore, error) {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000", path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open game history database: %v", err)
+	}
+
+	// SQLite allows a single writer; one connection also keeps :memory: databases alive
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create game history schema: %v", err)
+	}
+
+	return &SQLiteGameStore{db: db}, nil
+}
+
+// SaveGame stores a finished game and all of its child rows in one transaction
+func (s *SQLiteGameStore) SaveGame(record *GameRecord) error {
+	teamTokens, err := json.Marshal(record.TeamTokens)
+	if err != nil {
+		return fmt.Errorf("failed to encode team tokens: %v", err)
+	}
+	analytics, err := json.Marshal(record.Analytics)
+	if err != nil {
+		return fmt.Errorf("failed to encode analytics: %v", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`INSERT INTO games (id, room_code, difficulty, success, started_at, ended_at, team_tokens, analytics)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.ID, record.RoomCode, record.Difficulty, record.Success,
+		toUnixMilli(record.StartedAt), toUnixMilli(record.EndedAt), string(teamTokens), string(analytics),
+	); err != nil {
+		return fmt.Errorf("failed to insert game: %v", err)
+	}
+
+	for _, player := range record.Players {
+		specialties, err := json.Marshal(player.Specialties)
+		if err != nil {
+			return fmt.Errorf("failed to encode specialties: %v", err)
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO game_players (game_id, player_id, name, role, specialties, is_host) VALUES (?, ?, ?, ?, ?, ?)`,
+			record.ID, player.ID, player.Name, player.Role, string(specialties), player.IsHost,
+		); err != nil {
+			return fmt.Errorf("failed to insert player: %v", err)
+		}
+	}
+
+	for i, answer := range record.TriviaAnswers {
+		if _, err := tx.Exec(
+			`INSERT INTO trivia_answers (game_id, seq, player_id, question_id, category, is_specialty, answer, correct,
+			 round, token_type, tokens_awarded, answered_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			record.ID, i, answer.PlayerID, answer.QuestionID, answer.Category, answer.IsSpecialty, answer.Answer,
+			answer.Correct, answer.Round, answer.TokenType, answer.TokensAwarded, toUnixMilli(answer.AnsweredAt),
+		); err != nil {
+			return fmt.Errorf("failed to insert trivia answer: %v", err)
+		}
+	}
+
+	for i, move := range record.FragmentMoves {
+		if _, err := tx.Exec(
+			`INSERT INTO fragment_moves (game_id, seq, fragment_id, player_id, from_x, from_y, to_x, to_y, moved_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			record.ID, i, move.FragmentID, move.PlayerID, move.FromPos.X, move.FromPos.Y,
+			move.ToPos.X, move.ToPos.Y, toUnixMilli(move.Timestamp),
+		); err != nil {
+			return fmt.Errorf("failed to insert fragment move: %v", err)
+		}
+	}
+
+	for _, rec := range record.Recommendations {
+		if _, err := tx.Exec(
+			`INSERT INTO recommendations (game_id, id, from_player_id, to_player_id, from_fragment_id, to_fragment_id,
+			 from_x, from_y, to_x, to_y, status, created_at, responded_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			record.ID, rec.ID, rec.FromPlayerID, rec.ToPlayerID, rec.FromFragmentID, rec.ToFragmentID,
+			rec.SuggestedFromPos.X, rec.SuggestedFromPos.Y, rec.SuggestedToPos.X, rec.SuggestedToPos.Y,
+			rec.Status, toUnixMilli(rec.Timestamp), toUnixMilli(rec.RespondedAt),
+		); err != nil {
+			return fmt.Errorf("failed to insert recommendation: %v", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListGames returns game summaries, most recently finished first
+func (s *SQLiteGameStore) ListGames(limit, offset int) ([]GameSummary, error) {
+	rows, err := s.db.Query(
+		`SELECT g.id, g.room_code, g.difficulty, g.success, g.started_at, g.ended_at,
+		 (SELECT COUNT(*) FROM game_players p WHERE p.game_id = g.id AND p.is_host = 0)
+		 FROM games g ORDER BY g.ended_at DESC, g.id LIMIT ? OFFSET ?`,
+		limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	games := make([]GameSummary, 0)
+	for rows.Next() {
+		var summary GameSummary
+		var startedAt, endedAt int64
+		if err := rows.Scan(&summary.ID, &summary.RoomCode, &summary.Difficulty, &summary.Success,
+			&startedAt, &endedAt, &summary.PlayerCount); err != nil {
+			return nil, err
+		}
+		summary.StartedAt = fromUnixMilli(startedAt)
+		summary.EndedAt = fromUnixMilli(endedAt)
+		games = append(games, summary)
+	}
+
+	return games, rows.Err()
+}
+
+// GetGame loads one game with its players, answers, moves and recommendations
+func (s *SQLiteGameStore) GetGame(id string) (*GameRecord, error) {
+	record := &GameRecord{
+		Players:         make([]GameRecordPlayer, 0),
+		TriviaAnswers:   make([]TriviaAnswerRecord, 0),
+		FragmentMoves:   make([]FragmentMove, 0),
+		Recommendations: make([]RecommendationRecord, 0),
+	}
+
+	var startedAt, endedAt int64
+	var teamTokens, analytics string
+	err := s.db.QueryRow(
+		`SELECT id, room_code, difficulty, success, started_at, ended_at, team_tokens, analytics FROM games WHERE id = ?`, id,
+	).Scan(&record.ID, &record.RoomCode, &record.Difficulty, &record.Success, &startedAt, &endedAt, &teamTokens, &analytics)
+	if err == sql.ErrNoRows {
+		return nil, errGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	record.StartedAt = fromUnixMilli(startedAt)
+	record.EndedAt = fromUnixMilli(endedAt)
+
+	if err := json.Unmarshal([]byte(teamTokens), &record.TeamTokens); err != nil {
+		return nil, fmt.Errorf("failed to decode team tokens: %v", err)
+	}
+	if err := json.Unmarshal([]byte(analytics), &record.Analytics); err != nil {
+		return nil, fmt.Errorf("failed to decode analytics: %v", err)
+	}
+
+	if err := s.loadPlayers(record); err != nil {
+		return nil, err
+	}
+	if err := s.loadTriviaAnswers(record); err != nil {
+		return nil, err
+	}
+	if err := s.loadFragmentMoves(record); err != nil {
+		return nil, err
+	}
+	if err := s.loadRecommendations(record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Close closes the database
+func (s *SQLiteGameStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteGameStore) loadPlayers(record *GameRecord) error {
+	rows, err := s.db.Query(
+		`SELECT player_id, name, role, specialties, is_host FROM game_players WHERE game_id = ? ORDER BY name`, record.ID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var player GameRecordPlayer
+		var specialties string
+		if err := rows.Scan(&player.ID, &player.Name, &player.Role, &specialties, &player.IsHost); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(specialties), &player.Specialties); err != nil {
+			return fmt.Errorf("failed to decode specialties: %v", err)
+		}
+		if !player.IsHost {
+			record.PlayerCount++
+		}
+		record.Players = append(record.Players, player)
+	}
+
+	return rows.Err()
+}
+
+func (s *SQLiteGameStore) loadTriviaAnswers(record *GameRecord) error {
+	rows, err := s.db.Query(
+		`SELECT player_id, question_id, category, is_specialty, answer, correct, round, token_type, tokens_awarded, answered_at
+		 FROM trivia_answers WHERE game_id = ? ORDER BY seq`, record.ID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var answer TriviaAnswerRecord
+		var answeredAt int64
+		if err := rows.Scan(&answer.PlayerID, &answer.QuestionID, &answer.Category, &answer.IsSpecialty, &answer.Answer,
+			&answer.Correct, &answer.Round, &answer.TokenType, &answer.TokensAwarded, &answeredAt); err != nil {
+			return err
+		}
+		answer.AnsweredAt = fromUnixMilli(answeredAt)
+		record.TriviaAnswers = append(record.TriviaAnswers, answer)
+	}
+
+	return rows.Err()
+}
+
+func (s *SQLiteGameStore) loadFragmentMoves(record *GameRecord) error {
+	rows, err := s.db.Query(
+		`SELECT fragment_id, player_id, from_x, from_y, to_x, to_y, moved_at FROM fragment_moves WHERE game_id = ? ORDER BY seq`,
+		record.ID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var move FragmentMove
+		var movedAt int64
+		if err := rows.Scan(&move.FragmentID, &move.PlayerID, &move.FromPos.X, &move.FromPos.Y,
+			&move.ToPos.X, &move.ToPos.Y, &movedAt); err != nil {
+			return err
+		}
+		move.Timestamp = fromUnixMilli(movedAt)
+		record.FragmentMoves = append(record.FragmentMoves, move)
+	}
+
+	return rows.Err()
+}
+
+func (s *SQLiteGameStore) loadRecommendations(record *GameRecord) error {
+	rows, err := s.db.Query(
+		`SELECT id, from_player_id, to_player_id, from_fragment_id, to_fragment_id, from_x, from_y, to_x, to_y,
+		 status, created_at, responded_at FROM recommendations WHERE game_id = ? ORDER BY created_at, id`, record.ID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rec RecommendationRecord
+		var createdAt, respondedAt int64
+		if err := rows.Scan(&rec.ID, &rec.FromPlayerID, &rec.ToPlayerID, &rec.FromFragmentID, &rec.ToFragmentID,
+			&rec.SuggestedFromPos.X, &rec.SuggestedFromPos.Y, &rec.SuggestedToPos.X, &rec.SuggestedToPos.Y,
+			&rec.Status, &createdAt, &respondedAt); err != nil {
+			return err
+		}
+		rec.Timestamp = fromUnixMilli(createdAt)
+		rec.RespondedAt = fromUnixMilli(respondedAt)
+		record.Recommendations = append(record.Recommendations, rec)
+	}
+
+	return rows.Err()
+}
+
+// toUnixMilli stores zero times as 0 so they round-trip back to the zero time
+func toUnixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+func fromUnixMilli(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestGameRecord(id string, endedAt time.Time) *GameRecord {
+	return &GameRecord{
+		GameSummary: GameSummary{
+			ID:          id,
+			RoomCode:    "ACDEF",
+			Difficulty:  "medium",
+			Success:     true,
+			StartedAt:   endedAt.Add(-10 * time.Minute),
+			EndedAt:     endedAt,
+			PlayerCount: 1,
+		},
+		TeamTokens: TeamTokens{AnchorTokens: 20, ClarityTokens: 10},
+		Players: []GameRecordPlayer{
+			{ID: "host-1", Name: "", IsHost: true},
+			{ID: "player-1", Name: "Player1", Role: constants.RoleDetective, Specialties: []string{"science", "music"}},
+		},
+		TriviaAnswers: []TriviaAnswerRecord{
+			{PlayerID: "player-1", QuestionID: "q1", Category: "science", IsSpecialty: true, Answer: "Mars",
+				Correct: true, Round: 1, TokenType: constants.TokenGuide, TokensAwarded: 30, AnsweredAt: endedAt.Add(-9 * time.Minute)},
+			{PlayerID: "player-1", QuestionID: "q2", Category: "history", Answer: "1066", Round: 2,
+				AnsweredAt: endedAt.Add(-8 * time.Minute)},
+		},
+		FragmentMoves: []FragmentMove{
+			{FragmentID: "fragment_player-1", FromPos: GridPos{X: 0, Y: 0}, ToPos: GridPos{X: 1, Y: 2},
+				PlayerID: "player-1", Timestamp: endedAt.Add(-time.Minute)},
+		},
+		Recommendations: []RecommendationRecord{
+			{PieceRecommendation: PieceRecommendation{ID: "rec-1", FromPlayerID: "player-1", ToPlayerID: "player-2",
+				FromFragmentID: "fragment_player-1", ToFragmentID: "fragment_player-2", SuggestedToPos: GridPos{X: 2, Y: 2},
+				Timestamp: endedAt.Add(-2 * time.Minute)}, Status: "accepted", RespondedAt: endedAt.Add(-90 * time.Second)},
+		},
+		Analytics: map[string]interface{}{"success": true},
+	}
+}
+
+func createTestSQLiteGameStore(t *testing.T) *SQLiteGameStore {
+	store, err := NewSQLiteGameStore(":memory:")
+	assert.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestSQLiteGameStoreRoundTrip(t *testing.T) {
+	store := createTestSQLiteGameStore(t)
+
+	endedAt := time.Now().Truncate(time.Millisecond)
+	record := createTestGameRecord("game-1", endedAt)
+	assert.NoError(t, store.SaveGame(record))
+
+	loaded, err := store.GetGame("game-1")
+	assert.NoError(t, err)
+	assert.Equal(t, record.ID, loaded.ID)
+	assert.Equal(t, record.RoomCode, loaded.RoomCode)
+	assert.True(t, loaded.Success)
+	assert.True(t, record.EndedAt.Equal(loaded.EndedAt))
+	assert.True(t, record.StartedAt.Equal(loaded.StartedAt))
+	assert.Equal(t, record.TeamTokens, loaded.TeamTokens)
+	assert.Len(t, loaded.Players, 2)
+	assert.Equal(t, []string{"science", "music"}, loaded.Players[1].Specialties)
+	assert.Len(t, loaded.TriviaAnswers, 2)
+	assert.Equal(t, 30, loaded.TriviaAnswers[0].TokensAwarded)
+	assert.Equal(t, constants.TokenGuide, loaded.TriviaAnswers[0].TokenType)
+	assert.False(t, loaded.TriviaAnswers[1].Correct)
+	assert.Len(t, loaded.FragmentMoves, 1)
+	assert.Equal(t, GridPos{X: 1, Y: 2}, loaded.FragmentMoves[0].ToPos)
+	assert.Len(t, loaded.Recommendations, 1)
+	assert.Equal(t, "accepted", loaded.Recommendations[0].Status)
+	assert.Equal(t, GridPos{X: 2, Y: 2}, loaded.Recommendations[0].SuggestedToPos)
+	assert.Equal(t, true, loaded.Analytics["success"])
+}
+
+func TestSQLiteGameStoreListOrderAndPaging(t *testing.T) {
+	store := createTestSQLiteGameStore(t)
+
+	now := time.Now()
+	assert.NoError(t, store.SaveGame(createTestGameRecord("oldest", now.Add(-2*time.Hour))))
+	assert.NoError(t, store.SaveGame(createTestGameRecord("newest", now)))
+	assert.NoError(t, store.SaveGame(createTestGameRecord("middle", now.Add(-time.Hour))))
+
+	games, err := store.ListGames(10, 0)
+	assert.NoError(t, err)
+	assert.Len(t, games, 3)
+	assert.Equal(t, "newest", games[0].ID)
+	assert.Equal(t, "middle", games[1].ID)
+	assert.Equal(t, "oldest", games[2].ID)
+
+	games, err = store.ListGames(1, 1)
+	assert.NoError(t, err)
+	assert.Len(t, games, 1)
+	assert.Equal(t, "middle", games[0].ID)
+
+	games, err = store.ListGames(10, 5)
+	assert.NoError(t, err)
+	assert.Empty(t, games)
+}
+
+func TestSQLiteGameStoreNotFound(t *testing.T) {
+	store := createTestSQLiteGameStore(t)
+
+	_, err := store.GetGame("missing")
+	assert.Error(t, err)
+	assert.Equal(t, constants.ErrGameNotFound, err.Error())
+}
+
+func TestSQLiteGameStorePersistsToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	store, err := NewSQLiteGameStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.SaveGame(createTestGameRecord("game-1", time.Now())))
+	assert.NoError(t, store.Close())
+
+	reopened, err := NewSQLiteGameStore(path)
+	assert.NoError(t, err)
+	defer reopened.Close()
+
+	games, err := reopened.ListGames(10, 0)
+	assert.NoError(t, err)
+	assert.Len(t, games, 1)
+	assert.Equal(t, "game-1", games[0].ID)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildGameRecord(t *testing.T) {
+	gm, pm, tm, _ := createTestGameManager()
+	defer cleanupTestGameManager(tm)
+
+	gm.EnableGameHistory(nil, "ACDEF")
+
+	host := pm.CreatePlayer(nil, true)
+	player := pm.CreatePlayer(nil, false)
+	player.Name = "Player1"
+	player.Role = constants.RoleTourist
+
+	startedAt := time.Now().Add(-5 * time.Minute)
+	gm.mu.Lock()
+	gm.state.StartedAt = startedAt
+	gm.state.Difficulty = "hard"
+	gm.state.TeamTokens.ChronosTokens = 15
+	gm.state.TriviaAnswers = []TriviaAnswerRecord{{PlayerID: player.ID, QuestionID: "q1", Correct: true}}
+	gm.state.ResolvedRecommendations = []RecommendationRecord{
+		{PieceRecommendation: PieceRecommendation{ID: "rec-1"}, Status: "rejected"},
+	}
+	gm.state.PieceRecommendations["rec-2"] = &PieceRecommendation{ID: "rec-2"}
+	record := gm.buildGameRecordInternal(false, map[string]interface{}{"success": false})
+	gm.mu.Unlock()
+
+	assert.NotEmpty(t, record.ID)
+	assert.Equal(t, "ACDEF", record.RoomCode)
+	assert.Equal(t, "hard", record.Difficulty)
+	assert.False(t, record.Success)
+	assert.Equal(t, startedAt, record.StartedAt)
+	assert.Equal(t, 15, record.TeamTokens.ChronosTokens)
+	assert.Equal(t, 1, record.PlayerCount)
+	assert.Len(t, record.Players, 2)
+	assert.Len(t, record.TriviaAnswers, 1)
+
+	playerIDs := []string{record.Players[0].ID, record.Players[1].ID}
+	assert.Contains(t, playerIDs, host.ID)
+	assert.Contains(t, playerIDs, player.ID)
+
+	// Resolved and still-pending recommendations are both kept
+	assert.Len(t, record.Recommendations, 2)
+	assert.Equal(t, "rejected", record.Recommendations[0].Status)
+	assert.Equal(t, "pending", record.Recommendations[1].Status)
+	assert.Equal(t, "rec-2", record.Recommendations[1].ID)
+}
+
+// slowGameStore holds SaveGame until release is closed
+type slowGameStore struct {
+	release chan struct{}
+	saved   []*GameRecord
+}
+
+func (s *slowGameStore) SaveGame(record *GameRecord) error {
+	<-s.release
+	s.saved = append(s.saved, record)
+	return nil
+}
+func (s *slowGameStore) ListGames(limit, offset int) ([]GameSummary, error) { return nil, nil }
+func (s *slowGameStore) GetGame(id string) (*GameRecord, error)             { return nil, nil }
+func (s *slowGameStore) Close() error                                       { return nil }
+
+func TestWaitForSaves(t *testing.T) {
+	gm, _, tm, _ := createTestGameManager()
+	defer cleanupTestGameManager(tm)
+
+	store := &slowGameStore{release: make(chan struct{})}
+	gm.EnableGameHistory(store, "ACDEF")
+	gm.saveGameRecord(true, GameAnalyticsPayload{})
+
+	waited := make(chan struct{})
+	go func() {
+		gm.WaitForSaves()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+		t.Fatal("WaitForSaves returned while a save was still running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(store.release)
+	<-waited
+	assert.Len(t, store.saved, 1, "The record is written before WaitForSaves returns")
+}
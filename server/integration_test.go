@@ -11,9 +11,9 @@ import (
 func TestBasicGameFlow(t *testing.T) {
 	// Create managers
 	pm := NewPlayerManager()
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	broadcastChan := make(chan BroadcastMessage, 256)
-	gm := NewGameManager(pm, tm, broadcastChan)
+	gm := NewGameManager(pm, tm, broadcastChan, realClock{}, testSeed)
 	_ = NewEventHandlers(gm, pm, broadcastChan) // eh not used in this test
 
 	// Verify initial state
@@ -71,9 +71,9 @@ func TestBasicGameFlow(t *testing.T) {
 func TestTokenCalculations(t *testing.T) {
 	// Create game manager
 	pm := NewPlayerManager()
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	broadcastChan := make(chan BroadcastMessage, 256)
-	gm := NewGameManager(pm, tm, broadcastChan)
+	gm := NewGameManager(pm, tm, broadcastChan, realClock{}, testSeed)
 
 	// Set up test tokens
 	gm.state.TeamTokens.AnchorTokens = 25
@@ -134,9 +134,9 @@ func TestTokenCalculations(t *testing.T) {
 
 func TestGridSizeCalculations(t *testing.T) {
 	pm := NewPlayerManager()
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	broadcastChan := make(chan BroadcastMessage, 256)
-	gm := NewGameManager(pm, tm, broadcastChan)
+	gm := NewGameManager(pm, tm, broadcastChan, realClock{}, testSeed)
 
 	// Test all grid size breakpoints
 	tests := []struct {
@@ -162,9 +162,9 @@ func TestGridSizeCalculations(t *testing.T) {
 
 func TestFragmentPositionCalculations(t *testing.T) {
 	pm := NewPlayerManager()
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	broadcastChan := make(chan BroadcastMessage, 256)
-	gm := NewGameManager(pm, tm, broadcastChan)
+	gm := NewGameManager(pm, tm, broadcastChan, realClock{}, testSeed)
 
 	// Test position calculations for different grid sizes
 	tests := []struct {
@@ -266,22 +266,22 @@ func TestValidationIntegration(t *testing.T) {
 	assert.NotNil(t, err)
 
 	// Test specialty validation
-	_ = []string{"general", "geography", "history", "music", "science", "video_games"} // validSpecialties not used
+	validSpecialties := []string{"general", "geography", "history", "music", "science", "video_games"}
 
 	// Test single specialty
-	errs := validateSpecialties([]string{"science"})
+	errs := validateSpecialties([]string{"science"}, validSpecialties)
 	assert.Empty(t, errs)
 
 	// Test two specialties
-	errs = validateSpecialties([]string{"science", "history"})
+	errs = validateSpecialties([]string{"science", "history"}, validSpecialties)
 	assert.Empty(t, errs)
 
 	// Test too many specialties
-	errs = validateSpecialties([]string{"science", "history", "geography"})
+	errs = validateSpecialties([]string{"science", "history", "geography"}, validSpecialties)
 	assert.NotEmpty(t, errs)
 
 	// Test invalid specialty
-	errs = validateSpecialties([]string{"magic"})
+	errs = validateSpecialties([]string{"magic"}, validSpecialties)
 	assert.NotEmpty(t, errs)
 
 	// Test grid position validation
@@ -293,7 +293,7 @@ func TestValidationIntegration(t *testing.T) {
 }
 
 func TestTriviaManagerIntegration(t *testing.T) {
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 
 	// Test that categories are loaded
 	categories := tm.GetAvailableCategories()
@@ -327,9 +327,9 @@ func TestSystemStability(t *testing.T) {
 	// Create multiple game managers
 	for i := 0; i < 5; i++ {
 		pm := NewPlayerManager()
-		tm := NewTriviaManager()
+		tm := NewTriviaManager(realClock{}, testSeed)
 		broadcastChan := make(chan BroadcastMessage, 256)
-		gm := NewGameManager(pm, tm, broadcastChan)
+		gm := NewGameManager(pm, tm, broadcastChan, realClock{}, testSeed)
 
 		// Verify each one initializes properly
 		assert.NotNil(t, gm)
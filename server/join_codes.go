@@ -0,0 +1,34 @@
+package main
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+)
+
+// generateJoinCode returns a random join code drawn from the unambiguous alphabet
+func generateJoinCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(constants.JoinCodeAlphabet)))
+
+	var sb strings.Builder
+	sb.Grow(constants.JoinCodeLength)
+	for i := 0; i < constants.JoinCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(constants.JoinCodeAlphabet[n.Int64()])
+	}
+
+	return sb.String(), nil
+}
+
+// normalizeJoinCode converts user input ("abc-de", " Abcde ") into canonical join code form
+func normalizeJoinCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+	return code
+}
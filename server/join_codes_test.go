@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateJoinCode(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		code, err := generateJoinCode()
+		assert.NoError(t, err)
+		assert.Len(t, code, constants.JoinCodeLength)
+		assert.Nil(t, validateJoinCode(code))
+
+		for _, ambiguous := range "0O1IL2Z5S8B" {
+			assert.False(t, strings.ContainsRune(code, ambiguous), "code %s contains %c", code, ambiguous)
+		}
+	}
+}
+
+func TestNormalizeJoinCode(t *testing.T) {
+	assert.Equal(t, "ACDEF", normalizeJoinCode("acdef"))
+	assert.Equal(t, "ACDEF", normalizeJoinCode(" acd-ef "))
+	assert.Equal(t, "ACDEF", normalizeJoinCode("AC DEF"))
+}
+
+func TestValidateJoinCode(t *testing.T) {
+	assert.Nil(t, validateJoinCode("ACD34"))
+	assert.NotNil(t, validateJoinCode(""))
+	assert.NotNil(t, validateJoinCode("ACD3"))
+	assert.NotNil(t, validateJoinCode("ACD345"))
+	assert.NotNil(t, validateJoinCode("ACD10"))
+	assert.NotNil(t, validateJoinCode("acd34"))
+}
@@ -3,21 +3,25 @@ package main
 import (
 	"bufio" // Added for Hijacker
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"errors" // Added for Hijacker error
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net" // Added for Hijacker
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
-	"github.com/google/uuid"
 )
 
 var (
@@ -27,33 +31,121 @@ var (
 	keyFile        = flag.String("key", "", "TLS key file (optional)")
 	allowedOrigins = flag.String("origins", "", "Comma-separated list of allowed CORS origins (empty for development mode)")
 	environment    = flag.String("env", "development", "Environment (development, staging, production)")
+	snapshotDir    = flag.String("snapshots", "snapshots", "Directory for game state snapshots used in crash recovery (empty to disable)")
+	restoreGames   = flag.Bool("restore", false, "Resume in-progress games from the snapshot directory on startup")
+	historyDB      = flag.String("history-db", "", "SQLite database file for finished game history (optional, requires a cgo build)")
+	eventLogDir    = flag.String("event-logs", "", "Directory for per-room JSON Lines event logs used by -replay (optional)")
+	replayLog      = flag.String("replay", "", "Replay a recorded event log, verify the game state matches and exit")
+	balanceFile    = flag.String("config", "", "YAML or JSON game balance file overriding the built-in defaults (optional)")
+	nameBlocklist  = flag.String("name-blocklist", "", "File of words, one per line, that player names may not contain (default: built-in list)")
 )
 
-// Global host endpoint identifier - generated on server start
-var hostEndpointID string
-
 func main() {
+	// The import subcommand adds questions to the trivia bank and exits without starting the server
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImport(os.Args[2:], os.Stdout))
+	}
+
 	flag.Parse()
 
 	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
-	log.Printf("Canvas Conundrum Server starting on %s:%s (env: %s)", *host, *port, *environment)
 
-	// Generate unique host endpoint ID
-	hostEndpointID = uuid.New().String()
+	// Replay mode checks a recorded game and exits without starting the server
+	if *replayLog != "" {
+		os.Exit(runReplay(*replayLog))
+	}
+
+	log.Printf("Canvas Conundrum Server starting on %s:%s (env: %s)", *host, *port, *environment)
 
 	// Initialize CORS configuration
 	initializeCORS()
 
-	// Initialize components
-	broadcastChan := make(chan BroadcastMessage, constants.BroadcastChannelBuffer)
-	playerManager := NewPlayerManager()
-	triviaManager := NewTriviaManager()
-	gameManager := NewGameManager(playerManager, triviaManager, broadcastChan)
-	eventHandlers := NewEventHandlers(gameManager, playerManager, broadcastChan)
-	wsHandler := NewWebSocketHandler(playerManager, gameManager, eventHandlers, broadcastChan)
+	// Load game balance before anything is built from it
+	balance := DefaultBalanceConfig()
+	if *balanceFile != "" {
+		loaded, err := LoadBalanceConfig(*balanceFile)
+		if err != nil {
+			log.Fatalf("Failed to load balance config: %v", err)
+		}
+		balance = loaded
+		log.Printf("Loaded game balance from %s (%d presets)", *balanceFile, len(balance.Presets))
+	}
+
+	// Initialize shared components
+	triviaManager := NewTriviaManager(realClock{}, time.Now().UnixNano())
+	triviaManager.UseBalance(balance)
 
-	// Start broadcaster
-	wsHandler.StartBroadcaster()
+	var snapshotStore *SnapshotStore
+	if *snapshotDir != "" {
+		store, err := NewSnapshotStore(*snapshotDir)
+		if err != nil {
+			log.Fatalf("Failed to open snapshot store: %v", err)
+		}
+		snapshotStore = store
+	}
+
+	var gameStore GameStore
+	if *historyDB != "" {
+		store, err := NewSQLiteGameStore(*historyDB)
+		if err != nil {
+			log.Fatalf("Failed to open game history database: %v", err)
+		}
+		gameStore = store
+	}
+
+	roomManager := NewRoomManager(triviaManager, snapshotStore, gameStore, *eventLogDir)
+	roomManager.UseBalance(balance)
+
+	if *nameBlocklist != "" {
+		filter, err := LoadNameFilter(*nameBlocklist)
+		if err != nil {
+			log.Fatalf("Failed to load name blocklist: %v", err)
+		}
+		roomManager.UseNameFilter(filter)
+		log.Printf("Loaded %d blocked name words from %s", len(filter.Words()), *nameBlocklist)
+	}
+
+	// Session tokens must outlive a restart for restored games, so the signing key comes from
+	// SESSION_SECRET or is kept next to the snapshots
+	if secret := os.Getenv("SESSION_SECRET"); secret != "" {
+		key, err := ParseSessionSecret(secret)
+		if err != nil {
+			log.Fatalf("Invalid session key: %v", err)
+		}
+		roomManager.UseSessionTokens(NewSessionTokens(key, realClock{}))
+	} else if snapshotStore != nil {
+		key, err := LoadSessionSecret(filepath.Join(*snapshotDir, constants.SessionSecretFile))
+		if err != nil {
+			log.Fatalf("Failed to load session key: %v", err)
+		}
+		roomManager.UseSessionTokens(NewSessionTokens(key, realClock{}))
+	}
+
+	// Resume games that were running when the server last stopped
+	if *restoreGames {
+		restored, err := roomManager.RestoreRooms()
+		if err != nil {
+			log.Fatalf("Failed to restore game snapshots: %v", err)
+		}
+		for _, room := range restored {
+			log.Printf("Resumed room %s (default: %v) in %s phase - players can reconnect with their playerId",
+				room.Code, room.IsDefault, room.gameManager.GetPhase().String())
+		}
+	} else if snapshotStore != nil {
+		if snapshots, err := snapshotStore.LoadAll(); err == nil && len(snapshots) > 0 {
+			log.Printf("Found %d game snapshots in %s; start with -restore to resume them", len(snapshots), *snapshotDir)
+		}
+	}
+
+	// The default room keeps the original single-game /ws and /ws/host endpoints working
+	defaultRoom := roomManager.GetDefaultRoom()
+	if defaultRoom == nil {
+		var err error
+		defaultRoom, err = roomManager.CreateDefaultRoom()
+		if err != nil {
+			log.Fatalf("Failed to create default room: %v", err)
+		}
+	}
 
 	// Log trivia statistics
 	stats := triviaManager.GetCategoryStats()
@@ -77,21 +169,36 @@ func main() {
 	// Set up HTTP routes
 	mux := http.NewServeMux()
 
-	// Player WebSocket endpoint (regular players)
+	// Player WebSocket endpoint (regular players, default room)
 	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		wsHandler.HandleConnection(w, r, false) // false = not host
+		defaultRoom.wsHandler.HandleConnection(w, r, false) // false = not host
 	})
 
-	// Host WebSocket endpoint (host only)
-	mux.HandleFunc("/ws/host/"+hostEndpointID, func(w http.ResponseWriter, r *http.Request) {
-		wsHandler.HandleConnection(w, r, true) // true = is host
+	// Host WebSocket endpoint (host only, default room)
+	mux.HandleFunc("/ws/host/"+defaultRoom.HostEndpointID, func(w http.ResponseWriter, r *http.Request) {
+		defaultRoom.wsHandler.HandleConnection(w, r, true) // true = is host
 	})
 
+	// Event stream fallback for players whose network blocks WebSockets (default room)
+	mux.HandleFunc("GET /events", defaultRoom.wsHandler.HandleEventStream)
+	mux.HandleFunc("POST /events/messages", defaultRoom.wsHandler.HandleEventPost)
+
+	// Room management and per-room WebSocket endpoints
+	registerRoomRoutes(mux, roomManager)
+
+	// Finished game history
+	registerGameHistoryRoutes(mux, gameStore)
+
+	// Machine-readable description of the WebSocket protocol
+	registerProtocolRoutes(mux)
+
 	// Health check endpoint with detailed information including host endpoint
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		// Gather detailed health information
+		// Gather detailed health information for the default room
+		playerManager := defaultRoom.playerManager
+		gameManager := defaultRoom.gameManager
 		connectedPlayers := playerManager.GetConnectedCount()
 		readyPlayers := playerManager.GetReadyCount()
 		phase := gameManager.GetPhase()
@@ -104,7 +211,8 @@ func main() {
 			"environment": *environment,
 			"endpoints": map[string]interface{}{
 				"players": "/ws",
-				"host":    "/ws/host/" + hostEndpointID,
+				"host":    "/ws/host/" + defaultRoom.HostEndpointID,
+				"rooms":   "/ws/rooms/{roomCode}",
 			},
 			"game": map[string]interface{}{
 				"phase":   phase.String(),
@@ -115,6 +223,7 @@ func main() {
 					"ready":     readyPlayers,
 				},
 			},
+			"rooms": roomManager.GetAggregateStats(),
 			"trivia": map[string]interface{}{
 				"totalQuestions": totalQuestions,
 				"categories":     len(stats),
@@ -132,33 +241,34 @@ func main() {
 		json.NewEncoder(w).Encode(healthStatus)
 	})
 
-	// Game statistics endpoint with enhanced data
+	// Game statistics endpoint with per-room and aggregate data
 	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		gameManager.mu.RLock()
+		defaultStats := defaultRoom.GetStats()
+
+		roomStats := make([]map[string]interface{}, 0)
+		for _, room := range roomManager.GetAllRooms() {
+			roomStats = append(roomStats, room.GetStats())
+		}
+
 		stats := map[string]interface{}{
 			"game": map[string]interface{}{
-				"phase":      gameManager.state.Phase.String(),
-				"difficulty": gameManager.state.Difficulty,
-				"round":      gameManager.state.CurrentRound,
-				"teamTokens": gameManager.state.TeamTokens,
-				"gridSize":   gameManager.state.GridSize,
-			},
-			"players": map[string]interface{}{
-				"total":     playerManager.GetPlayerCount(),
-				"connected": playerManager.GetConnectedCount(),
-				"ready":     playerManager.GetReadyCount(),
-				"roles":     playerManager.GetRoleDistribution(),
-				"hasHost":   playerManager.GetHost() != nil,
+				"phase":      defaultStats["phase"],
+				"difficulty": defaultStats["difficulty"],
+				"round":      defaultStats["round"],
+				"teamTokens": defaultStats["teamTokens"],
+				"gridSize":   defaultStats["gridSize"],
 			},
+			"players":   defaultStats["players"],
+			"rooms":     roomStats,
+			"aggregate": roomManager.GetAggregateStats(),
 			"server": map[string]interface{}{
 				"uptime":       time.Since(startTime).Seconds(),
 				"environment":  *environment,
-				"hostEndpoint": "/ws/host/" + hostEndpointID,
+				"hostEndpoint": "/ws/host/" + defaultRoom.HostEndpointID,
 			},
 		}
-		gameManager.mu.RUnlock()
 
 		json.NewEncoder(w).Encode(stats)
 	})
@@ -187,21 +297,16 @@ func main() {
 		mux.HandleFunc("/admin/host-endpoint", adminAuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
 			json.NewEncoder(w).Encode(map[string]string{
-				"hostEndpoint": "/ws/host/" + hostEndpointID,
-				"hostURL":      fmt.Sprintf("ws://%s:%s/ws/host/%s", *host, *port, hostEndpointID),
+				"hostEndpoint": "/ws/host/" + defaultRoom.HostEndpointID,
+				"hostURL":      fmt.Sprintf("ws://%s:%s/ws/host/%s", *host, *port, defaultRoom.HostEndpointID),
 			})
 		}))
 	}
 
-	// Apply middleware chain
-	// The order is: loggingMiddleware(securityHeadersMiddleware(corsMiddleware(mux)))
-	// This means loggingMiddleware is the outermost, then security, then cors, then the mux.
-	handler := loggingMiddleware(securityHeadersMiddleware(corsMiddleware(mux)))
-
 	// Create server with enhanced configuration
 	srv := &http.Server{
 		Addr:              fmt.Sprintf("%s:%s", *host, *port),
-		Handler:           handler,
+		Handler:           withMiddleware(mux),
 		ReadTimeout:       15 * time.Second,
 		WriteTimeout:      15 * time.Second,
 		IdleTimeout:       60 * time.Second,
@@ -210,11 +315,12 @@ func main() {
 	}
 
 	// Log host endpoint information at the end for easy copying
-	log.Printf("HOST ENDPOINT: /ws/host/%s", hostEndpointID)
-	log.Printf("PLAYER ENDPOINT: /ws")
+	log.Printf("HOST ENDPOINT: /ws/host/%s", defaultRoom.HostEndpointID)
+	log.Printf("PLAYER ENDPOINT: /ws (join code %s)", defaultRoom.Code)
+	log.Printf("ROOM ENDPOINTS: POST /rooms to create, GET /rooms/{roomCode} to look up, then /ws/rooms/{roomCode}")
 
 	if *environment == "development" {
-		log.Printf("Host URL: ws://localhost:%s/ws/host/%s", *port, hostEndpointID)
+		log.Printf("Host URL: ws://localhost:%s/ws/host/%s", *port, defaultRoom.HostEndpointID)
 		log.Printf("Player URL: ws://localhost:%s/ws", *port)
 	}
 
@@ -251,18 +357,8 @@ func main() {
 	// Shutdown TriviaManager first to stop background goroutines
 	triviaManager.Shutdown()
 
-	// Close broadcast channel
-	close(broadcastChan)
-
-	// Notify all players of shutdown
-	shutdownMessage := map[string]string{
-		"error": "Server shutting down for maintenance",
-		"type":  "server_shutdown",
-	}
-
-	for _, player := range playerManager.GetAllPlayers() {
-		sendToPlayer(player, MsgError, shutdownMessage)
-	}
+	// Stop every room's game loops and broadcaster and notify all players of shutdown
+	roomManager.Shutdown()
 
 	// Allow time for messages to be sent
 	time.Sleep(2 * time.Second)
@@ -272,6 +368,11 @@ func main() {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
 
+	// Closing the rooms waited for their pending history saves
+	if gameStore != nil {
+		gameStore.Close()
+	}
+
 	log.Println("Server stopped")
 }
 
@@ -346,7 +447,7 @@ func corsMiddleware(next http.Handler) http.Handler {
 			log.Printf("CORS: Rejected origin: %s", origin)
 		}
 
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 		w.Header().Set("Access-Control-Max-Age", "86400")
@@ -359,6 +460,13 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// withMiddleware applies the middleware chain every request goes through.
+// The order is: loggingMiddleware(securityHeadersMiddleware(corsMiddleware(mux)))
+// This means loggingMiddleware is the outermost, then security, then cors, then the mux.
+func withMiddleware(mux http.Handler) http.Handler {
+	return loggingMiddleware(securityHeadersMiddleware(corsMiddleware(mux)))
+}
+
 // Security headers middleware
 func securityHeadersMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -403,6 +511,11 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return rw.ResponseWriter.Write(b)
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer to flush and set deadlines.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Hijack implements the http.Hijacker interface.
 func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	h, ok := rw.ResponseWriter.(http.Hijacker)
@@ -453,3 +566,324 @@ func adminAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		next(w, r)
 	}
 }
+
+// registerRoomRoutes sets up room creation, teardown and per-room WebSocket endpoints
+func registerRoomRoutes(mux *http.ServeMux, roomManager *RoomManager) {
+	createRoom := func(w http.ResponseWriter, r *http.Request) {
+		room, err := roomManager.CreateRoom()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{
+			"roomCode":       room.Code,
+			"playerEndpoint": room.PlayerEndpoint(),
+			"hostEndpoint":   room.HostEndpoint(),
+		})
+	}
+
+	// Room creation is restricted to admins in production, open in other environments
+	if *environment == "production" {
+		mux.HandleFunc("POST /rooms", adminAuthMiddleware(createRoom))
+	} else {
+		mux.HandleFunc("POST /rooms", createRoom)
+	}
+
+	// Room teardown requires the room's host secret or the admin token
+	mux.HandleFunc("DELETE /rooms/{roomCode}", func(w http.ResponseWriter, r *http.Request) {
+		room, err := roomManager.GetRoom(r.PathValue("roomCode"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		if !isRoomAdminRequest(r, room) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if room.IsDefault {
+			http.Error(w, "The default room cannot be closed", http.StatusForbidden)
+			return
+		}
+
+		if err := roomManager.CloseRoom(room.Code); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Room closed",
+		})
+	})
+
+	// Join code lookup so clients can check a code before opening a WebSocket
+	mux.HandleFunc("GET /rooms/{roomCode}", func(w http.ResponseWriter, r *http.Request) {
+		code := normalizeJoinCode(r.PathValue("roomCode"))
+		if validationErr := validateJoinCode(code); validationErr != nil {
+			http.Error(w, validationErr.Message, http.StatusBadRequest)
+			return
+		}
+
+		room, err := roomManager.GetRoom(code)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(room.GetJoinInfo())
+	})
+
+	// Per-room player WebSocket endpoint
+	mux.HandleFunc("/ws/rooms/{roomCode}", func(w http.ResponseWriter, r *http.Request) {
+		room, err := roomManager.GetRoom(r.PathValue("roomCode"))
+		if err != nil {
+			http.Error(w, constants.ErrRoomNotFound, http.StatusNotFound)
+			return
+		}
+		room.wsHandler.HandleConnection(w, r, false)
+	})
+
+	// Per-room event stream fallback for players
+	mux.HandleFunc("GET /events/rooms/{roomCode}", func(w http.ResponseWriter, r *http.Request) {
+		room, err := roomManager.GetRoom(r.PathValue("roomCode"))
+		if err != nil {
+			http.Error(w, constants.ErrRoomNotFound, http.StatusNotFound)
+			return
+		}
+		room.wsHandler.HandleEventStream(w, r)
+	})
+	mux.HandleFunc("POST /events/rooms/{roomCode}/messages", func(w http.ResponseWriter, r *http.Request) {
+		room, err := roomManager.GetRoom(r.PathValue("roomCode"))
+		if err != nil {
+			http.Error(w, constants.ErrRoomNotFound, http.StatusNotFound)
+			return
+		}
+		room.wsHandler.HandleEventPost(w, r)
+	})
+
+	// Per-room host WebSocket endpoint
+	mux.HandleFunc("/ws/rooms/{roomCode}/host/{secret}", func(w http.ResponseWriter, r *http.Request) {
+		room, err := roomManager.GetRoom(r.PathValue("roomCode"))
+		if err != nil || subtle.ConstantTimeCompare([]byte(r.PathValue("secret")), []byte(room.HostEndpointID)) != 1 {
+			// Same response for unknown rooms and wrong secrets so host endpoints can't be probed
+			http.Error(w, constants.ErrRoomNotFound, http.StatusNotFound)
+			return
+		}
+		room.wsHandler.HandleConnection(w, r, true)
+	})
+}
+
+// runReplay replays an event log and reports whether the game reproduced; the result is the exit code
+func runReplay(path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Printf("Failed to open event log: %v", err)
+		return 1
+	}
+	events, err := ReadEventLog(file)
+	file.Close()
+	if err != nil {
+		log.Printf("Failed to read event log: %v", err)
+		return 1
+	}
+
+	triviaManager := NewTriviaManager(realClock{}, time.Now().UnixNano())
+	defer triviaManager.Shutdown()
+
+	result, err := ReplayEventLog(events, triviaManager)
+	if err != nil {
+		log.Printf("Replay failed: %v", err)
+		return 1
+	}
+
+	if result.Mismatch != nil {
+		mismatch, _ := json.MarshalIndent(result.Mismatch, "", "  ")
+		log.Printf("Replay diverged at event %d (%s): %s\n%s",
+			result.Mismatch.Seq, result.Mismatch.Reason, result.Mismatch.Detail, mismatch)
+		return 1
+	}
+
+	log.Printf("Replay matched: %d events, %d inputs, %d checkpoints verified",
+		result.Events, result.Inputs, result.Checkpoints)
+	return 0
+}
+
+// runImport adds the questions in CSV or JSON files to the trivia bank, reporting every row it
+// skipped and why; the result is the exit code, 1 when any row had an error
+func runImport(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.SetOutput(out)
+	triviaDir := flags.String("trivia-dir", "trivia", "Trivia bank directory to add the questions to")
+	category := flags.String("category", "", "Category for questions that don't name one")
+	difficulty := flags.String("difficulty", "", "Difficulty (easy, medium, hard) for questions that don't name one")
+	dryRun := flags.Bool("dry-run", false, "Check the files and report without changing the bank")
+	flags.Usage = func() {
+		fmt.Fprintln(out, "Usage: canvas-conundrum import [options] file.csv|file.json ...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	bank, err := OpenTriviaBank(*triviaDir)
+	if err != nil {
+		fmt.Fprintf(out, "Failed to read trivia bank: %v\n", err)
+		return 1
+	}
+
+	imported, failed := 0, false
+	for _, path := range flags.Args() {
+		rows, rowErrors, err := parseImportFile(path)
+		if err != nil {
+			fmt.Fprintf(out, "%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+
+		report := bank.Import(rows, *category, *difficulty)
+		report.Errors = append(rowErrors, report.Errors...)
+		sort.Slice(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+
+		fmt.Fprintf(out, "%s: %d imported, %d duplicates skipped, %d errors\n",
+			path, report.Imported, len(report.Duplicates), len(report.Errors))
+		for _, rowErr := range report.Errors {
+			fmt.Fprintf(out, "  %v\n", rowErr)
+		}
+		for _, row := range report.Duplicates {
+			fmt.Fprintf(out, "  row %d: already in the bank\n", row)
+		}
+		for _, category := range report.NewCategories {
+			fmt.Fprintf(out, "  new category %s (%q)\n", category.ID, category.Name)
+		}
+
+		imported += report.Imported
+		failed = failed || len(report.Errors) > 0
+	}
+
+	switch {
+	case *dryRun:
+		fmt.Fprintf(out, "Dry run: %d questions would be added to %s\n", imported, *triviaDir)
+	case imported > 0:
+		if err := bank.Save(); err != nil {
+			fmt.Fprintf(out, "Failed to save trivia bank: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(out, "Added %d questions to %s; restart the server or POST /admin/reload-trivia to use them\n", imported, *triviaDir)
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// registerGameHistoryRoutes sets up the endpoints for browsing finished games
+func registerGameHistoryRoutes(mux *http.ServeMux, gameStore GameStore) {
+	listGames := func(w http.ResponseWriter, r *http.Request) {
+		if gameStore == nil {
+			http.Error(w, "Game history is disabled", http.StatusServiceUnavailable)
+			return
+		}
+
+		limit, offset, err := parsePagination(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		games, err := gameStore.ListGames(limit, offset)
+		if err != nil {
+			log.Printf("Failed to list game history: %v", err)
+			http.Error(w, "Failed to load game history", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"games":  games,
+			"limit":  limit,
+			"offset": offset,
+		})
+	}
+
+	getGame := func(w http.ResponseWriter, r *http.Request) {
+		if gameStore == nil {
+			http.Error(w, "Game history is disabled", http.StatusServiceUnavailable)
+			return
+		}
+
+		game, err := gameStore.GetGame(r.PathValue("gameId"))
+		if err != nil {
+			if errors.Is(err, errGameNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			log.Printf("Failed to load game %s: %v", r.PathValue("gameId"), err)
+			http.Error(w, "Failed to load game", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(game)
+	}
+
+	// Game history includes player names and answers, so it is admin-only in production
+	if *environment == "production" {
+		mux.HandleFunc("GET /games", adminAuthMiddleware(listGames))
+		mux.HandleFunc("GET /games/{gameId}", adminAuthMiddleware(getGame))
+	} else {
+		mux.HandleFunc("GET /games", listGames)
+		mux.HandleFunc("GET /games/{gameId}", getGame)
+	}
+}
+
+// parsePagination reads the limit and offset query parameters for list endpoints
+func parsePagination(r *http.Request) (int, int, error) {
+	limit := constants.DefaultGameHistoryPageSize
+	offset := 0
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > constants.MaxGameHistoryPageSize {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", constants.MaxGameHistoryPageSize)
+		}
+		limit = parsed
+	}
+
+	if value := r.URL.Query().Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+		offset = parsed
+	}
+
+	return limit, offset, nil
+}
+
+// isRoomAdminRequest checks for a bearer token matching the room's host secret or the admin token
+func isRoomAdminRequest(r *http.Request, room *Room) bool {
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return false
+	}
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(room.HostEndpointID)) == 1 {
+		return true
+	}
+
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	return adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -47,13 +51,13 @@ func TestServerLifecycle(t *testing.T) {
 			playerMgr := NewPlayerManager()
 			assert.NotNil(t, playerMgr)
 
-			triviaMgr := NewTriviaManager()
+			triviaMgr := NewTriviaManager(realClock{}, testSeed)
 			assert.NotNil(t, triviaMgr)
 
 			broadcastChan := make(chan BroadcastMessage, 256)
 			assert.NotNil(t, broadcastChan)
 
-			gameMgr := NewGameManager(playerMgr, triviaMgr, broadcastChan)
+			gameMgr := NewGameManager(playerMgr, triviaMgr, broadcastChan, realClock{}, testSeed)
 			assert.NotNil(t, gameMgr)
 		})
 	})
@@ -93,3 +97,160 @@ func TestEnvironmentSetup(t *testing.T) {
 		})
 	}
 }
+
+func TestRoomRoutes(t *testing.T) {
+	triviaMgr := NewTriviaManager(realClock{}, testSeed)
+	defer triviaMgr.Shutdown()
+	roomMgr := NewRoomManager(triviaMgr, nil, nil, "")
+	defer roomMgr.Shutdown()
+
+	mux := http.NewServeMux()
+	registerRoomRoutes(mux, roomMgr)
+
+	// Create a room
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/rooms", nil))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var created map[string]string
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.NotEmpty(t, created["roomCode"])
+	assert.Equal(t, "/ws/rooms/"+created["roomCode"], created["playerEndpoint"])
+
+	room, err := roomMgr.GetRoom(created["roomCode"])
+	assert.NoError(t, err)
+	assert.Equal(t, room.HostEndpoint(), created["hostEndpoint"])
+
+	t.Run("Unknown room WebSocket", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/ws/rooms/NOPE1234", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Wrong host secret", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/ws/rooms/"+room.Code+"/host/"+uuid.New().String(), nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Look up join code", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/rooms/"+strings.ToLower(room.Code), nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var info map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+		assert.Equal(t, room.Code, info["roomCode"])
+		assert.Equal(t, "setup", info["phase"])
+		assert.Equal(t, true, info["joinable"])
+		assert.Equal(t, room.PlayerEndpoint(), info["playerEndpoint"])
+		assert.NotContains(t, rec.Body.String(), room.HostEndpointID)
+	})
+
+	t.Run("Look up join code for game in progress", func(t *testing.T) {
+		SimulateGamePhase(room.gameManager, PhaseResourceGathering)
+		defer SimulateGamePhase(room.gameManager, PhaseSetup)
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/rooms/"+room.Code, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var info map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+		assert.Equal(t, "resource_gathering", info["phase"])
+		assert.Equal(t, false, info["joinable"])
+		assert.NotEmpty(t, info["reason"])
+	})
+
+	t.Run("Look up malformed and unknown codes", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/rooms/OOOO0", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		unknown := "AAAAA"
+		if unknown == room.Code {
+			unknown = "CCCCC"
+		}
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/rooms/"+unknown, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Delete without authorization", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("DELETE", "/rooms/"+room.Code, nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, 1, roomMgr.GetRoomCount())
+	})
+
+	t.Run("Delete with host secret", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/rooms/"+room.Code, nil)
+		req.Header.Set("Authorization", "Bearer "+room.HostEndpointID)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 0, roomMgr.GetRoomCount())
+	})
+}
+
+func TestGameHistoryRoutes(t *testing.T) {
+	t.Run("History disabled", func(t *testing.T) {
+		mux := http.NewServeMux()
+		registerGameHistoryRoutes(mux, nil)
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/games", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+
+	store, err := NewSQLiteGameStore(":memory:")
+	assert.NoError(t, err)
+	defer store.Close()
+
+	now := time.Now()
+	assert.NoError(t, store.SaveGame(createTestGameRecord("game-1", now.Add(-time.Hour))))
+	assert.NoError(t, store.SaveGame(createTestGameRecord("game-2", now)))
+
+	mux := http.NewServeMux()
+	registerGameHistoryRoutes(mux, store)
+
+	t.Run("List games", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/games?limit=1", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var body struct {
+			Games []GameSummary `json:"games"`
+			Limit int           `json:"limit"`
+		}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, 1, body.Limit)
+		assert.Len(t, body.Games, 1)
+		assert.Equal(t, "game-2", body.Games[0].ID)
+	})
+
+	t.Run("Invalid pagination", func(t *testing.T) {
+		for _, query := range []string{"limit=0", "limit=1000", "limit=abc", "offset=-1"} {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest("GET", "/games?"+query, nil))
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		}
+	})
+
+	t.Run("Get game", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/games/game-1", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var record GameRecord
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &record))
+		assert.Equal(t, "game-1", record.ID)
+		assert.Len(t, record.TriviaAnswers, 2)
+	})
+
+	t.Run("Unknown game", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/games/missing", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -0,0 +1,313 @@
+package main
+
+// Every message of the protocol has its payload defined once here, or in types.go when the game
+// state shares the type. The validate tags name the fieldRules a client payload is checked against,
+// and protocolMessages lists each message with its payload so the schema served at
+// /protocol/schema is generated from the same definitions the server validates and sends.
+
+// Message directions in protocolMessages
+const (
+	FromClient = "client"
+	FromServer = "server"
+)
+
+// Client to server payloads
+
+// HelloPayload opens a session with the protocol versions and features the client speaks.
+// minProtocolVersion defaults to protocolVersion.
+type HelloPayload struct {
+	ProtocolVersion    int      `json:"protocolVersion" validate:"protocolVersion"`
+	MinProtocolVersion int      `json:"minProtocolVersion,omitempty" validate:"protocolVersion"`
+	Features           []string `json:"features,omitempty" validate:"features"`
+}
+
+type RoleSelectionPayload struct {
+	Role string `json:"role" validate:"role"`
+}
+
+type SpecialtySelectionPayload struct {
+	Specialties []string `json:"specialties" validate:"specialties"`
+}
+
+type LocationVerificationPayload struct {
+	VerifiedHash string `json:"verifiedHash" validate:"locationHash"`
+}
+
+type TriviaAnswerPayload struct {
+	QuestionID string `json:"questionId" validate:"questionId"`
+	Answer     string `json:"answer" validate:"answer"`
+	Timestamp  int64  `json:"timestamp" validate:"pastTimestamp"` // Unix seconds the answer was given
+}
+
+type SegmentCompletionPayload struct {
+	SegmentID           string `json:"segmentId" validate:"segmentId"`
+	CompletionTimestamp int64  `json:"completionTimestamp" validate:"timestamp"`
+}
+
+type FragmentMoveRequestPayload struct {
+	FragmentID  string  `json:"fragmentId" validate:"fragmentId"`
+	NewPosition GridPos `json:"newPosition" validate:"gridPosition"`
+	Timestamp   int64   `json:"timestamp" validate:"timestamp"`
+}
+
+type PlayerReadyPayload struct {
+	Ready *bool `json:"ready" validate:"required"`
+}
+
+type PieceRecommendationRequestPayload struct {
+	ToPlayerID       string  `json:"toPlayerId" validate:"uuid"`
+	FromFragmentID   string  `json:"fromFragmentId" validate:"fragmentId"`
+	ToFragmentID     string  `json:"toFragmentId" validate:"fragmentId"`
+	SuggestedFromPos GridPos `json:"suggestedFromPos" validate:"gridPosition"`
+	SuggestedToPos   GridPos `json:"suggestedToPos" validate:"gridPosition"`
+}
+
+type PieceRecommendationResponsePayload struct {
+	RecommendationID string `json:"recommendationId" validate:"uuid"`
+	Accepted         *bool  `json:"accepted" validate:"required"`
+}
+
+type HostAddTimePayload struct {
+	Seconds *int `json:"seconds" validate:"required,bounds"`
+}
+
+type HostAbortGamePayload struct {
+	ShowResults bool `json:"showResults"` // Show the analytics screen instead of going straight back to the lobby
+}
+
+type HostKickPlayerPayload struct {
+	PlayerID string `json:"playerId" validate:"uuid"`
+	Ban      bool   `json:"ban"` // Refuse the player's session if they try to come back
+}
+
+type HostRenamePlayerPayload struct {
+	PlayerID string `json:"playerId" validate:"uuid"`
+	Name     string `json:"name" validate:"playerName"`
+}
+
+// HostAssignPlayerPayload gives a player a role, specialties or both
+type HostAssignPlayerPayload struct {
+	PlayerID    string    `json:"playerId" validate:"uuid"`
+	Role        *string   `json:"role,omitempty" validate:"role"`
+	Specialties *[]string `json:"specialties,omitempty" validate:"specialties"`
+}
+
+type PlayerSetNamePayload struct {
+	Name string `json:"name" validate:"playerName"`
+}
+
+// EmptyPayload is sent by messages that carry nothing beyond their type
+type EmptyPayload struct{}
+
+// Server to client payloads
+
+// AvailableRolesPayload greets a connection with its player ID and session token. Regular players
+// also get the roles and trivia categories to pick from.
+type AvailableRolesPayload struct {
+	PlayerID         string     `json:"playerId"`
+	SessionToken     string     `json:"sessionToken"`
+	IsHost           bool       `json:"isHost"`
+	Message          string     `json:"message,omitempty"`          // Host only
+	Roles            []RoleInfo `json:"roles,omitempty"`            // Players only
+	TriviaCategories []string   `json:"triviaCategories,omitempty"` // Players only; the categories loaded, which specialties are picked from
+	// Players only; display names and descriptions of triviaCategories, in the same order
+	TriviaCategoryDetails []TriviaCategory `json:"triviaCategoryDetails,omitempty"`
+	ProtocolVersion       int              `json:"protocolVersion,omitempty"` // Newest version the server speaks; sent on first connection
+}
+
+// redactedForLog hides the session token, which would let anyone reading the event log act as the player
+func (p AvailableRolesPayload) redactedForLog() interface{} {
+	if p.SessionToken != "" {
+		p.SessionToken = redactedSecret
+	}
+	return p
+}
+
+type LobbyStatusPayload struct {
+	CurrentPlayers int            `json:"currentPlayers"`
+	NonHostPlayers int            `json:"nonHostPlayers"`
+	PlayerRoles    map[string]int `json:"playerRoles"` // role -> players who picked it
+	HasHost        bool           `json:"hasHost"`
+	GameStarting   bool           `json:"gameStarting"`
+	WaitingMessage string         `json:"waitingMessage"`
+	Difficulty     string         `json:"difficulty"`
+	Settings       GameSettings   `json:"settings"`
+	Presets        []PresetInfo   `json:"presets"`
+	Players        []LobbyPlayer  `json:"players"`
+}
+
+type ResourcePhaseStartPayload struct {
+	ResourceHashes map[string]string `json:"resourceHashes"` // Station name -> QR code hash
+}
+
+type TeamProgressPayload struct {
+	QuestionsAnswered int        `json:"questionsAnswered"`
+	TotalQuestions    int        `json:"totalQuestions"`
+	TeamTokens        TeamTokens `json:"teamTokens"`
+}
+
+// PuzzlePhaseLoadPayload tells a player which segment to solve, or the host how many players are solving
+type PuzzlePhaseLoadPayload struct {
+	ImageID     string `json:"imageId"`
+	SegmentID   string `json:"segmentId,omitempty"` // Players only
+	GridSize    int    `json:"gridSize"`
+	PreSolved   bool   `json:"preSolved"`             // The segment was solved by anchor tokens
+	IsHost      bool   `json:"isHost,omitempty"`      // Host only
+	PlayerCount int    `json:"playerCount,omitempty"` // Host only
+	Message     string `json:"message,omitempty"`     // Host only
+}
+
+type PuzzlePhaseStartPayload struct {
+	StartTimestamp int64 `json:"startTimestamp"`
+	TotalTime      int   `json:"totalTime"` // Seconds, including chronos bonuses
+}
+
+type SegmentCompletionAckPayload struct {
+	Status       string  `json:"status"`
+	SegmentID    string  `json:"segmentId"`
+	GridPosition GridPos `json:"gridPosition"` // Where the fragment is on the central grid
+}
+
+// FragmentMoveResponsePayload answers a fragment_move_request: success with the moved fragment,
+// or denied or ignored with the reason
+type FragmentMoveResponsePayload struct {
+	Status            string          `json:"status"` // success, denied or ignored
+	Code              ErrorCode       `json:"code,omitempty"`
+	Reason            string          `json:"reason,omitempty"`
+	ErrorType         string          `json:"errorType,omitempty"`
+	FragmentID        string          `json:"fragmentId,omitempty"`
+	NextMoveAvailable int64           `json:"nextMoveAvailable,omitempty"` // Unix time the cooldown ends
+	Fragment          *PuzzleFragment `json:"fragment,omitempty"`          // Only on success
+}
+
+// PlayerDisconnectedNotice is the central_puzzle_state sent when a player drops out of the puzzle
+type PlayerDisconnectedNotice struct {
+	PlayerDisconnected  string `json:"playerDisconnected"`
+	Phase               string `json:"phase"`
+	ReconnectionAllowed bool   `json:"reconnectionAllowed"`
+	ReconnectDeadline   int64  `json:"reconnectDeadline,omitempty"` // Unix time the held fragment goes to everyone
+}
+
+// PlayerReconnectedNotice is the central_puzzle_state sent when a player reclaims their fragment
+type PlayerReconnectedNotice struct {
+	PlayerReconnected string `json:"playerReconnected"`
+	Phase             string `json:"phase"`
+}
+
+// HostDisconnectedPayload is the error broadcast when the host's connection closes
+type HostDisconnectedPayload struct {
+	ErrorPayload
+	Phase            string `json:"phase"`
+	ReconnectionInfo string `json:"reconnectionInfo"`
+}
+
+// GuideHintPayload is the piece_recommendation guide tokens send a player about their own fragment
+type GuideHintPayload struct {
+	Type  string   `json:"type"` // Always guide_hint
+	Hints []string `json:"hints"`
+}
+
+type ImagePreviewPayload struct {
+	ImageID  string `json:"imageId"`
+	Duration int    `json:"duration"` // Seconds, earned with clarity tokens
+}
+
+type PersonalPuzzleStatePayload struct {
+	PersonalView PersonalPuzzleState `json:"personalView"`
+	Seq          int                 `json:"seq"`
+}
+
+// FragmentMovedPayload is the puzzle delta for a move. Players only get the fragments they can see.
+type FragmentMovedPayload struct {
+	Seq               int               `json:"seq"`
+	Fragments         []*PuzzleFragment `json:"fragments"`
+	MovedBy           string            `json:"movedBy"`
+	CompletionPercent *float64          `json:"completionPercent,omitempty"` // Host only
+}
+
+type FragmentRevealedPayload struct {
+	Seq               int             `json:"seq"`
+	Fragment          *PuzzleFragment `json:"fragment"`
+	CompletionPercent *float64        `json:"completionPercent,omitempty"` // Host only
+}
+
+type GameAnalyticsPayload struct {
+	PersonalAnalytics []PlayerAnalytics  `json:"personalAnalytics"`
+	TeamAnalytics     TeamAnalytics      `json:"teamAnalytics"`
+	GlobalLeaderboard []LeaderboardEntry `json:"globalLeaderboard"`
+	GameSuccess       bool               `json:"gameSuccess"`
+}
+
+type GameResetPayload struct {
+	Message           string `json:"message"`
+	ReconnectRequired bool   `json:"reconnectRequired"`
+	Rematch           bool   `json:"rematch,omitempty"`
+}
+
+type PlayerKickedPayload struct {
+	Message string `json:"message"`
+	Banned  bool   `json:"banned"`
+}
+
+// protocolMessage describes one message type of the protocol
+type protocolMessage struct {
+	Type        string
+	Direction   string
+	Description string
+	Payloads    []interface{} // Zero values of the payload types; more than one when the message has variants
+}
+
+// protocolMessages is every message the server accepts or sends
+var protocolMessages = []protocolMessage{
+	// Client to server
+	{MsgHello, FromClient, "Negotiates the protocol version and features for the connection", []interface{}{HelloPayload{}}},
+	{MsgRoleSelection, FromClient, "Picks the player's role", []interface{}{RoleSelectionPayload{}}},
+	{MsgTriviaSpecialtySelection, FromClient, "Picks the player's trivia specialties", []interface{}{SpecialtySelectionPayload{}}},
+	{MsgPlayerSetName, FromClient, "Sets the player's display name", []interface{}{PlayerSetNamePayload{}}},
+	{MsgPlayerReady, FromClient, "Marks the player ready or not ready in the lobby", []interface{}{PlayerReadyPayload{}}},
+	{MsgResourceLocationVerified, FromClient, "Reports the resource station whose QR code the player scanned", []interface{}{LocationVerificationPayload{}}},
+	{MsgTriviaAnswer, FromClient, "Answers the player's current trivia question", []interface{}{TriviaAnswerPayload{}}},
+	{MsgSegmentCompleted, FromClient, "Reports the player solved their puzzle segment", []interface{}{SegmentCompletionPayload{}}},
+	{MsgFragmentMoveRequest, FromClient, "Moves a fragment on the central grid", []interface{}{FragmentMoveRequestPayload{}}},
+	{MsgPieceRecommendationRequest, FromClient, "Suggests another player move their fragment", []interface{}{PieceRecommendationRequestPayload{}}},
+	{MsgPieceRecommendationResponse, FromClient, "Accepts or rejects a recommendation", []interface{}{PieceRecommendationResponsePayload{}}},
+	{MsgPuzzleResyncRequest, FromClient, "Asks for the full puzzle state after a missed delta", []interface{}{EmptyPayload{}}},
+	{MsgHostUpdateSettings, FromClient, "Host only: changes game settings in the lobby; only the settings sent change", []interface{}{GameSettingsUpdate{}}},
+	{MsgHostStartGame, FromClient, "Host only: starts the game", []interface{}{EmptyPayload{}}},
+	{MsgHostStartPuzzle, FromClient, "Host only: starts the puzzle timer", []interface{}{EmptyPayload{}}},
+	{MsgHostPause, FromClient, "Host only: pauses the running round or puzzle", []interface{}{EmptyPayload{}}},
+	{MsgHostResume, FromClient, "Host only: resumes a paused game", []interface{}{EmptyPayload{}}},
+	{MsgHostAddTime, FromClient, "Host only: adds time to the running round or puzzle", []interface{}{HostAddTimePayload{}}},
+	{MsgHostSkipPhase, FromClient, "Host only: ends the current round or phase early", []interface{}{EmptyPayload{}}},
+	{MsgHostAbortGame, FromClient, "Host only: ends the game", []interface{}{HostAbortGamePayload{}}},
+	{MsgHostRematch, FromClient, "Host only: starts a new game with the same players", []interface{}{EmptyPayload{}}},
+	{MsgHostKickPlayer, FromClient, "Host only: removes a player, optionally for good", []interface{}{HostKickPlayerPayload{}}},
+	{MsgHostRenamePlayer, FromClient, "Host only: renames a player", []interface{}{HostRenamePlayerPayload{}}},
+	{MsgHostAssignPlayer, FromClient, "Host only: gives a player a role, specialties or both; at least one is required", []interface{}{HostAssignPlayerPayload{}}},
+
+	// Server to client
+	{MsgWelcome, FromServer, "Answers hello with the negotiated protocol version and features", []interface{}{WelcomePayload{}}},
+	{MsgAvailableRoles, FromServer, "Greets a new or reconnected connection", []interface{}{AvailableRolesPayload{}}},
+	{MsgAck, FromServer, "Acknowledges a message sent with a requestId", []interface{}{AckPayload{}}},
+	{MsgError, FromServer, "Reports a failed message or connection problem", []interface{}{ErrorPayload{}, HostDisconnectedPayload{}}},
+	{MsgGameLobbyStatus, FromServer, "Describes the lobby whenever it changes", []interface{}{LobbyStatusPayload{}}},
+	{MsgPlayerUpdate, FromServer, "Tells a player their name, role and specialties after a change", []interface{}{PlayerUpdate{}}},
+	{MsgPlayerKicked, FromServer, "Tells a player the host removed them", []interface{}{PlayerKickedPayload{}}},
+	{MsgHostUpdate, FromServer, "Host only: game status for the host's dashboard", []interface{}{HostUpdate{}}},
+	{MsgResourcePhaseStart, FromServer, "Starts resource gathering with the station hashes", []interface{}{ResourcePhaseStartPayload{}}},
+	{MsgTriviaQuestion, FromServer, "Asks a player a trivia question", []interface{}{TriviaQuestion{}}},
+	{MsgTeamProgressUpdate, FromServer, "Reports the team's trivia progress and tokens", []interface{}{TeamProgressPayload{}}},
+	{MsgGameTimerUpdate, FromServer, "Reports the host paused, resumed or added time", []interface{}{TimerUpdate{}}},
+	{MsgPuzzlePhaseLoad, FromServer, "Loads the puzzle phase", []interface{}{PuzzlePhaseLoadPayload{}}},
+	{MsgImagePreview, FromServer, "Shows the puzzle image for the time clarity tokens earned", []interface{}{ImagePreviewPayload{}}},
+	{MsgPuzzlePhaseStart, FromServer, "Starts the puzzle timer", []interface{}{PuzzlePhaseStartPayload{}}},
+	{MsgSegmentCompletionAck, FromServer, "Confirms a solved segment", []interface{}{SegmentCompletionAckPayload{}}},
+	{MsgFragmentMoveResponse, FromServer, "Answers a fragment move", []interface{}{FragmentMoveResponsePayload{}}},
+	{MsgFragmentMoved, FromServer, "Puzzle delta: fragments moved", []interface{}{FragmentMovedPayload{}}},
+	{MsgFragmentRevealed, FromServer, "Puzzle delta: a fragment appeared on the central grid", []interface{}{FragmentRevealedPayload{}}},
+	{MsgPersonalPuzzleState, FromServer, "A player's full view of the central grid", []interface{}{PersonalPuzzleStatePayload{}}},
+	{MsgCentralPuzzleState, FromServer, "The host's full view of the central grid, or a player leaving or rejoining the puzzle", []interface{}{CompletePuzzleState{}, PlayerDisconnectedNotice{}, PlayerReconnectedNotice{}}},
+	{MsgPieceRecommendation, FromServer, "A recommendation from another player, or guide token hints", []interface{}{PieceRecommendation{}, GuideHintPayload{}}},
+	{MsgGameAnalytics, FromServer, "Final analytics once the game ends", []interface{}{GameAnalyticsPayload{}}},
+	{MsgGameReset, FromServer, "The game is resetting, or a rematch is starting", []interface{}{GameResetPayload{}}},
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtocolMessagesCoverClientMessages(t *testing.T) {
+	seen := make(map[string]bool)
+	clientTypes := make(map[string]bool)
+	for _, message := range protocolMessages {
+		assert.False(t, seen[message.Type], "%s is registered twice", message.Type)
+		seen[message.Type] = true
+
+		assert.Contains(t, []string{FromClient, FromServer}, message.Direction, message.Type)
+		assert.NotEmpty(t, message.Description, message.Type)
+		assert.NotEmpty(t, message.Payloads, message.Type)
+		if message.Direction == FromClient {
+			clientTypes[message.Type] = true
+		}
+	}
+
+	// Every message the server accepts is described, and nothing else is described as a client message
+	assert.Equal(t, clientMessageTypes, clientTypes)
+}
+
+func TestPayloadValidateTagsNameKnownRules(t *testing.T) {
+	var checkFields func(t *testing.T, typ reflect.Type)
+	checkFields = func(t *testing.T, typ reflect.Type) {
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				checkFields(t, field.Type)
+				continue
+			}
+			for _, ruleName := range validateTags(field) {
+				_, known := fieldRules[ruleName]
+				assert.True(t, known || ruleName == "required", "%s.%s names unknown rule %q", typ.Name(), field.Name, ruleName)
+			}
+		}
+	}
+
+	for _, message := range protocolMessages {
+		for _, payload := range message.Payloads {
+			checkFields(t, reflect.TypeOf(payload))
+		}
+	}
+	checkFields(t, reflect.TypeOf(GameSettingsUpdate{}))
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+)
+
+// NameFilter rejects display names that contain a blocked word. Names are lowercased, common
+// character swaps (0 for o, 4 for a, $ for s, ...) are undone, and the result is split into words at
+// everything that isn't a letter. A blocked word matches a whole word, or a run of neighbouring words
+// written together, so "b4d guy" and "B a-D" both match "bad" while "Badger", "Jess Hitt" or
+// "Scunthorpe" don't match words hidden inside or across them.
+type NameFilter struct {
+	words      []string // As configured, for the event log
+	normalized []string // Compared against normalized names
+}
+
+// nameSubstitutions maps characters commonly swapped in for letters back to the letter
+var nameSubstitutions = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b', '@': 'a', '$': 's', '!': 'i',
+}
+
+// NewNameFilter builds a filter blocking the given words; an empty list allows every name
+func NewNameFilter(words []string) *NameFilter {
+	nf := &NameFilter{
+		words:      make([]string, 0, len(words)),
+		normalized: make([]string, 0, len(words)),
+	}
+
+	for _, word := range words {
+		normalized := normalizeName(word)
+		if normalized == "" {
+			continue
+		}
+		nf.words = append(nf.words, word)
+		nf.normalized = append(nf.normalized, normalized)
+	}
+
+	return nf
+}
+
+// DefaultNameFilter blocks the built-in word list
+func DefaultNameFilter() *NameFilter {
+	return NewNameFilter(constants.DefaultNameBlocklist)
+}
+
+// LoadNameFilter reads a blocklist file with one word per line. Blank lines and lines starting
+// with # are skipped.
+func LoadNameFilter(path string) (*NameFilter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open name blocklist: %v", err)
+	}
+	defer file.Close()
+
+	words := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read name blocklist %s: %v", path, err)
+	}
+
+	return NewNameFilter(words), nil
+}
+
+// Allows reports whether a name is free of blocked words
+func (nf *NameFilter) Allows(name string) bool {
+	if len(nf.normalized) == 0 {
+		return true
+	}
+
+	// Every run of consecutive words, joined, is compared against the blocked words
+	words := nameWords(name)
+	for start := range words {
+		run := ""
+		for _, word := range words[start:] {
+			run += word
+			if slices.Contains(nf.normalized, run) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Words returns the blocked words as configured
+func (nf *NameFilter) Words() []string {
+	return append([]string{}, nf.words...)
+}
+
+// normalizeName lowercases a name, undoes character swaps and keeps only the letters
+func normalizeName(name string) string {
+	return strings.Join(nameWords(name), "")
+}
+
+// nameWords lowercases a name, undoes character swaps and splits it into words at everything else
+func nameWords(name string) []string {
+	return strings.FieldsFunc(strings.Map(func(r rune) rune {
+		if sub, ok := nameSubstitutions[r]; ok {
+			return sub
+		}
+		return r
+	}, strings.ToLower(name)), func(r rune) bool {
+		return r < 'a' || r > 'z'
+	})
+}
+
+// UseNameFilter replaces the filter player-chosen names are checked against
+func (pm *PlayerManager) UseNameFilter(filter *NameFilter) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.nameFilter = filter
+}
+
+// UseNameFilter sets the name filter every room created from now on checks player names against
+func (rm *RoomManager) UseNameFilter(filter *NameFilter) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.nameFilter = filter
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameFilter(t *testing.T) {
+	nf := NewNameFilter([]string{"bad", " ", "W0rse"})
+	assert.Equal(t, []string{"bad", "W0rse"}, nf.Words())
+
+	tests := []struct {
+		name    string
+		allowed bool
+	}{
+		{"Ada", true},
+		{"B a-D", false},
+		{"b4d_guy", false},
+		{"WORSE", false},
+		{"w_o_r_$_e", false},
+		{"Ba d", false},
+		{"Bad", false},
+		{"Brad", true},
+
+		// Blocked words inside or across ordinary words don't count
+		{"Badger", true},
+		{"Sinbad", true},
+		{"Ab Adam", true},
+		{"Worsed", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, nf.Allows(tt.name), tt.name)
+	}
+
+	// Real names that merely contain a blocked word are allowed
+	nf = NewNameFilter([]string{"shit", "cunt", "fag"})
+	for _, name := range []string{"Jess Hitt", "Scunthorpe", "Fagan", "Cass Hite"} {
+		assert.True(t, nf.Allows(name), name)
+	}
+	assert.False(t, nf.Allows("s-h-i-t"))
+	assert.False(t, nf.Allows("Big Sh1t"))
+
+	// An empty list lets every name through
+	assert.True(t, NewNameFilter(nil).Allows("anything bad"))
+	assert.NotNil(t, NewNameFilter(nil).Words())
+	assert.NotEmpty(t, DefaultNameFilter().Words())
+}
+
+func TestLoadNameFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("# Words players may not use\nfoo\n\n  bar  \n"), 0o644))
+
+	nf, err := LoadNameFilter(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"foo", "bar"}, nf.Words())
+		assert.False(t, nf.Allows("Foo Fighter"))
+		assert.True(t, nf.Allows("Baz"))
+	}
+
+	_, err = LoadNameFilter(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
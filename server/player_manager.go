@@ -3,6 +3,9 @@ package main
 import (
 	"fmt"
 	"log"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,28 +16,44 @@ import (
 
 // PlayerManager handles all player-related operations
 type PlayerManager struct {
-	players map[string]*Player
-	mu      sync.RWMutex
+	players    map[string]*Player
+	banned     map[string]bool // Player IDs the host banned; they can't reconnect while the room lives
+	bannedIPs  map[string]bool // Addresses of banned players; they can't join again as someone new
+	nameFilter *NameFilter     // Checks names players choose for themselves (see name_filter.go)
+	eventLog   *EventLog       // Handed to every player so sendToPlayer can record outbound messages
+	categories func() []string // Trivia categories specialties are picked from; nil until a game is attached, which rejects every pick
+	clock      Clock           // Stamps LastSeen; the game manager shares its own so tests control both
+	mu         sync.RWMutex
 }
 
 // NewPlayerManager creates a new player manager instance
 func NewPlayerManager() *PlayerManager {
 	return &PlayerManager{
-		players: make(map[string]*Player),
+		players:    make(map[string]*Player),
+		banned:     make(map[string]bool),
+		bannedIPs:  make(map[string]bool),
+		nameFilter: DefaultNameFilter(),
+		clock:      realClock{},
 	}
 }
 
 // CreatePlayer creates a new player with a unique ID and explicit host status
 func (pm *PlayerManager) CreatePlayer(conn *websocket.Conn, isHost bool) *Player {
+	return pm.createPlayer(uuid.New().String(), conn, isHost)
+}
+
+// createPlayer creates a player with a known ID; replays use it to recreate the players of a recorded game
+func (pm *PlayerManager) createPlayer(id string, conn *websocket.Conn, isHost bool) *Player {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
 	player := &Player{
-		ID:         uuid.New().String(),
+		ID:         id,
 		Connection: conn,
 		State:      StateConnected,
-		LastSeen:   time.Now(),
+		LastSeen:   pm.clock.Now(),
 		IsHost:     isHost, // Explicitly set host status
+		eventLog:   pm.eventLog,
 	}
 
 	// Set player name based on role
@@ -42,7 +61,7 @@ func (pm *PlayerManager) CreatePlayer(conn *websocket.Conn, isHost bool) *Player
 		player.Name = "Host"
 		log.Printf("Created new host player: %s", player.ID)
 	} else {
-		// Count existing non-host players for naming
+		// Number players in order of joining, skipping any name already in use
 		nonHostCount := 0
 		for _, p := range pm.players {
 			if !p.IsHost {
@@ -50,6 +69,9 @@ func (pm *PlayerManager) CreatePlayer(conn *websocket.Conn, isHost bool) *Player
 			}
 		}
 		player.Name = fmt.Sprintf("Player%d", nonHostCount+1)
+		for n := nonHostCount + 2; pm.nameTakenInternal(player.Name, ""); n++ {
+			player.Name = fmt.Sprintf("Player%d", n)
+		}
 		log.Printf("Created new regular player: %s", player.ID)
 	}
 
@@ -64,7 +86,7 @@ func (pm *PlayerManager) GetPlayer(playerID string) (*Player, error) {
 
 	player, exists := pm.players[playerID]
 	if !exists {
-		return nil, fmt.Errorf("player not found")
+		return nil, errPlayerNotFound
 	}
 
 	return player, nil
@@ -124,36 +146,211 @@ func (pm *PlayerManager) DisconnectPlayer(playerID string) error {
 	pm.mu.RUnlock()
 
 	if !exists {
-		return fmt.Errorf("player not found")
+		return errPlayerNotFound
 	}
 
 	player.mu.Lock()
 	player.State = StateDisconnected
 	player.Connection = nil
+	player.writer = nil
+	player.mu.Unlock()
+
+	return nil
+}
+
+// RemovePlayer takes a kicked player out of the game for good. With ban set, the player's ID is
+// refused if they try to reconnect with it.
+func (pm *PlayerManager) RemovePlayer(playerID string, ban bool) (*Player, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	player, exists := pm.players[playerID]
+	if !exists {
+		return nil, errPlayerNotFound
+	}
+
+	delete(pm.players, playerID)
+	if ban {
+		pm.banned[playerID] = true
+		if player.Address != "" {
+			pm.bannedIPs[player.Address] = true
+		}
+	}
+
+	return player, nil
+}
+
+// IsBanned reports whether the host banned this player ID
+func (pm *PlayerManager) IsBanned(playerID string) bool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.banned[playerID]
+}
+
+// IsAddressBanned reports whether the host banned a player who connected from this address
+func (pm *PlayerManager) IsAddressBanned(address string) bool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.bannedIPs[address]
+}
+
+// SetPlayerAddress records the address a player's connection came from, so a ban covers it
+func (pm *PlayerManager) SetPlayerAddress(player *Player, address string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	player.Address = address
+}
+
+// RenamePlayer changes a player's display name. Names are unique within the game, ignoring case.
+func (pm *PlayerManager) RenamePlayer(playerID, name string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	player, exists := pm.players[playerID]
+	if !exists {
+		return errPlayerNotFound
+	}
+
+	if pm.nameTakenInternal(name, playerID) {
+		return errNameTaken
+	}
+
+	player.mu.Lock()
+	player.Name = name
 	player.mu.Unlock()
 
 	return nil
 }
 
+// SetPlayerName gives a player the display name they chose, as long as it passes the name filter
+func (pm *PlayerManager) SetPlayerName(playerID, name string) error {
+	player, err := pm.GetPlayer(playerID)
+	if err != nil {
+		return err
+	}
+
+	// Hosts cannot choose names
+	player.mu.RLock()
+	isHost := player.IsHost
+	player.mu.RUnlock()
+
+	if isHost {
+		return newCodedError(CodeHostNotPlayer, "host cannot choose a name")
+	}
+
+	pm.mu.RLock()
+	allowed := pm.nameFilter.Allows(name)
+	pm.mu.RUnlock()
+
+	if !allowed {
+		return errNameNotAllowed
+	}
+
+	return pm.RenamePlayer(playerID, name)
+}
+
+// nameTakenInternal reports whether a player other than exceptID already uses name, ignoring case
+// NOTE: This method assumes the caller already holds pm.mu lock (read or write)
+func (pm *PlayerManager) nameTakenInternal(name, exceptID string) bool {
+	for id, p := range pm.players {
+		if id == exceptID {
+			continue
+		}
+		p.mu.RLock()
+		taken := strings.EqualFold(p.Name, name)
+		p.mu.RUnlock()
+		if taken {
+			return true
+		}
+	}
+	return false
+}
+
+// GetLobbyPlayers lists the non-host players by name for the lobby
+func (pm *PlayerManager) GetLobbyPlayers() []LobbyPlayer {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	players := make([]LobbyPlayer, 0, len(pm.players))
+	for _, p := range pm.players {
+		p.mu.RLock()
+		if !p.IsHost {
+			players = append(players, LobbyPlayer{
+				Name:      p.Name,
+				Role:      p.Role,
+				Ready:     p.Ready,
+				Connected: p.State == StateConnected,
+			})
+		}
+		p.mu.RUnlock()
+	}
+
+	sort.Slice(players, func(i, j int) bool {
+		return strings.ToLower(players[i].Name) < strings.ToLower(players[j].Name)
+	})
+	return players
+}
+
 // ReconnectPlayer handles player reconnection
 func (pm *PlayerManager) ReconnectPlayer(playerID string, conn *websocket.Conn) error {
 	pm.mu.RLock()
 	player, exists := pm.players[playerID]
+	now := pm.clock.Now()
 	pm.mu.RUnlock()
 
 	if !exists {
-		return fmt.Errorf("player not found")
+		return errPlayerNotFound
 	}
 
 	player.mu.Lock()
 	player.State = StateConnected
 	player.Connection = conn
-	player.LastSeen = time.Now()
+	player.LastSeen = now
+	player.AwaitingRecovery = false
+	player.features = nil // The new connection says hello again
 	player.mu.Unlock()
 
 	return nil
 }
 
+// RestorePlayer re-creates a player from a snapshot as disconnected so they can reconnect with their old ID
+func (pm *PlayerManager) RestorePlayer(snapshot PlayerSnapshot) *Player {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	player := &Player{
+		ID:               snapshot.ID,
+		Name:             snapshot.Name,
+		Role:             snapshot.Role,
+		Specialties:      append([]string(nil), snapshot.Specialties...),
+		State:            StateDisconnected,
+		CurrentLocation:  snapshot.CurrentLocation,
+		IsHost:           snapshot.IsHost,
+		Ready:            snapshot.Ready,
+		LastSeen:         pm.clock.Now(),
+		AwaitingRecovery: true,
+		eventLog:         pm.eventLog,
+	}
+
+	pm.players[player.ID] = player
+	return player
+}
+
+// IsAwaitingRecovery reports whether a player was restored from a snapshot and hasn't reconnected yet
+func (pm *PlayerManager) IsAwaitingRecovery(playerID string) bool {
+	pm.mu.RLock()
+	player, exists := pm.players[playerID]
+	pm.mu.RUnlock()
+
+	if !exists {
+		return false
+	}
+
+	player.mu.RLock()
+	defer player.mu.RUnlock()
+	return player.AwaitingRecovery
+}
+
 // GetAvailableRoles returns roles that haven't been selected yet
 func (pm *PlayerManager) GetAvailableRoles() []RoleInfo {
 	pm.mu.RLock()
@@ -194,12 +391,23 @@ func (pm *PlayerManager) GetAvailableRoles() []RoleInfo {
 
 // SetPlayerRole assigns a role to a player
 func (pm *PlayerManager) SetPlayerRole(playerID, role string) error {
+	return pm.setPlayerRole(playerID, role, true)
+}
+
+// AssignPlayerRole gives a player a role chosen by the host, even if that role is already taken
+// by its share of the players
+func (pm *PlayerManager) AssignPlayerRole(playerID, role string) error {
+	return pm.setPlayerRole(playerID, role, false)
+}
+
+// setPlayerRole assigns a role, checking that the role still has room only when checkAvailable is set
+func (pm *PlayerManager) setPlayerRole(playerID, role string, checkAvailable bool) error {
 	pm.mu.RLock()
 	player, exists := pm.players[playerID]
 	pm.mu.RUnlock()
 
 	if !exists {
-		return fmt.Errorf("player not found")
+		return errPlayerNotFound
 	}
 
 	// Hosts cannot select roles
@@ -208,7 +416,7 @@ func (pm *PlayerManager) SetPlayerRole(playerID, role string) error {
 	player.mu.RUnlock()
 
 	if isHost {
-		return fmt.Errorf("host cannot select a role")
+		return newCodedError(CodeHostNotPlayer, "host cannot select a role")
 	}
 
 	// Validate role
@@ -220,21 +428,23 @@ func (pm *PlayerManager) SetPlayerRole(playerID, role string) error {
 	}
 
 	if !validRoles[role] {
-		return fmt.Errorf("invalid role")
+		return newCodedError(CodeValidationFailed, "invalid role")
 	}
 
 	// Check if role is available
-	availableRoles := pm.GetAvailableRoles()
-	roleAvailable := false
-	for _, r := range availableRoles {
-		if r.Role == role && r.Available {
-			roleAvailable = true
-			break
+	if checkAvailable {
+		availableRoles := pm.GetAvailableRoles()
+		roleAvailable := false
+		for _, r := range availableRoles {
+			if r.Role == role && r.Available {
+				roleAvailable = true
+				break
+			}
 		}
-	}
 
-	if !roleAvailable {
-		return fmt.Errorf("role not available")
+		if !roleAvailable {
+			return errRoleNotAvailable
+		}
 	}
 
 	player.mu.Lock()
@@ -244,6 +454,27 @@ func (pm *PlayerManager) SetPlayerRole(playerID, role string) error {
 	return nil
 }
 
+// UseClock sets the clock players' LastSeen times are taken from
+func (pm *PlayerManager) UseClock(clock Clock) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.clock = clock
+}
+
+// Now returns the current time on the player manager's clock
+func (pm *PlayerManager) Now() time.Time {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.clock.Now()
+}
+
+// UseTriviaCategories sets where the trivia categories players pick specialties from come from
+func (pm *PlayerManager) UseTriviaCategories(categories func() []string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.categories = categories
+}
+
 // SetPlayerSpecialties assigns trivia specialties to a player
 func (pm *PlayerManager) SetPlayerSpecialties(playerID string, specialties []string) error {
 	pm.mu.RLock()
@@ -251,7 +482,7 @@ func (pm *PlayerManager) SetPlayerSpecialties(playerID string, specialties []str
 	pm.mu.RUnlock()
 
 	if !exists {
-		return fmt.Errorf("player not found")
+		return errPlayerNotFound
 	}
 
 	// Hosts cannot select specialties
@@ -260,29 +491,33 @@ func (pm *PlayerManager) SetPlayerSpecialties(playerID string, specialties []str
 	player.mu.RUnlock()
 
 	if isHost {
-		return fmt.Errorf("host cannot select specialties")
+		return newCodedError(CodeHostNotPlayer, "host cannot select specialties")
 	}
 
 	if len(specialties) == 0 || len(specialties) > constants.MaxSpecialtiesPerPlayer {
-		return fmt.Errorf("must select 1-2 specialties")
+		return newCodedError(CodeValidationFailed, "must select 1-2 specialties")
 	}
 
-	// Validate specialties
-	validCategories := make(map[string]bool)
-	for _, cat := range constants.TriviaCategories {
-		validCategories[cat] = true
+	// Validate specialties against the categories the question bank has; with no
+	// question bank attached there is nothing to pick from
+	pm.mu.RLock()
+	categories := pm.categories
+	pm.mu.RUnlock()
+	if categories == nil {
+		return newCodedError(CodeValidationFailed, "no trivia categories available")
 	}
+	validCategories := categories()
 
 	// Check for duplicates
 	seen := make(map[string]bool)
 	for _, specialty := range specialties {
 		if seen[specialty] {
-			return fmt.Errorf("duplicate specialty: %s", specialty)
+			return newCodedError(CodeValidationFailed, "duplicate specialty: %s", specialty)
 		}
 		seen[specialty] = true
 
-		if !validCategories[specialty] {
-			return fmt.Errorf("invalid specialty: %s", specialty)
+		if !slices.Contains(validCategories, specialty) {
+			return newCodedError(CodeValidationFailed, "invalid specialty: %s", specialty)
 		}
 	}
 
@@ -301,7 +536,7 @@ func (pm *PlayerManager) SetPlayerReady(playerID string, ready bool) error {
 	pm.mu.RUnlock()
 
 	if !exists {
-		return fmt.Errorf("player not found")
+		return errPlayerNotFound
 	}
 
 	// Hosts are always considered ready and don't need to set ready status
@@ -310,7 +545,7 @@ func (pm *PlayerManager) SetPlayerReady(playerID string, ready bool) error {
 	player.mu.RUnlock()
 
 	if isHost {
-		return fmt.Errorf("host ready status is managed automatically")
+		return newCodedError(CodeHostNotPlayer, "host ready status is managed automatically")
 	}
 
 	player.mu.Lock()
@@ -434,7 +669,7 @@ func (pm *PlayerManager) UpdatePlayerLocation(playerID string, locationHash stri
 	pm.mu.RUnlock()
 
 	if !exists {
-		return fmt.Errorf("player not found")
+		return errPlayerNotFound
 	}
 
 	// Hosts don't participate in resource gathering
@@ -443,7 +678,7 @@ func (pm *PlayerManager) UpdatePlayerLocation(playerID string, locationHash stri
 	player.mu.RUnlock()
 
 	if isHost {
-		return fmt.Errorf("host does not participate in resource gathering")
+		return newCodedError(CodeHostNotPlayer, "host does not participate in resource gathering")
 	}
 
 	// Validate location hash
@@ -456,7 +691,7 @@ func (pm *PlayerManager) UpdatePlayerLocation(playerID string, locationHash stri
 	}
 
 	if !validLocation {
-		return fmt.Errorf("invalid resource station hash")
+		return newCodedError(CodeValidationFailed, "invalid resource station hash")
 	}
 
 	player.mu.Lock()
@@ -473,7 +708,7 @@ func (pm *PlayerManager) GetPlayerLocation(playerID string) (string, error) {
 	pm.mu.RUnlock()
 
 	if !exists {
-		return "", fmt.Errorf("player not found")
+		return "", errPlayerNotFound
 	}
 
 	player.mu.RLock()
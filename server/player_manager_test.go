@@ -2,7 +2,9 @@ package main
 
 import (
 	"testing"
+	"time"
 
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
@@ -196,6 +198,7 @@ func TestPlayerManagerRoleManagement(t *testing.T) {
 
 func TestPlayerManagerSpecialtyManagement(t *testing.T) {
 	pm := NewPlayerManager()
+	pm.UseTriviaCategories(func() []string { return testTriviaCategories })
 	player := pm.CreatePlayer(nil, false)
 
 	tests := []struct {
@@ -256,6 +259,14 @@ func TestPlayerManagerSpecialtyManagement(t *testing.T) {
 			}
 		})
 	}
+
+	// Without a question bank there are no categories to pick from
+	unattached := NewPlayerManager()
+	other := unattached.CreatePlayer(nil, false)
+	err := unattached.SetPlayerSpecialties(other.ID, []string{"science"})
+	assert.EqualError(t, err, "no trivia categories available")
+	assert.Equal(t, CodeValidationFailed, errorCodeOf(err))
+	assert.Empty(t, other.Specialties)
 }
 
 func TestPlayerManagerLocationTracking(t *testing.T) {
@@ -291,11 +302,15 @@ func TestPlayerManagerLocationTracking(t *testing.T) {
 }
 
 func TestPlayerManagerDisconnectReconnect(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
 	pm := NewPlayerManager()
+	pm.UseClock(clock)
+	pm.UseTriviaCategories(func() []string { return testTriviaCategories })
 
 	// Create player
 	player := pm.CreatePlayer(nil, false)
 	playerID := player.ID
+	assert.Equal(t, clock.Now(), player.LastSeen)
 
 	// Set some state
 	assert.NoError(t, pm.SetPlayerRole(playerID, "detective"))
@@ -313,6 +328,7 @@ func TestPlayerManagerDisconnectReconnect(t *testing.T) {
 	assert.Equal(t, []string{"science", "history"}, p.Specialties) // Specialties preserved
 
 	// Reconnect player
+	clock.Advance(time.Minute)
 	newConn := &websocket.Conn{} // Mock connection
 	err = pm.ReconnectPlayer(playerID, newConn)
 	assert.NoError(t, err)
@@ -322,6 +338,7 @@ func TestPlayerManagerDisconnectReconnect(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, StateConnected, p.State)
 	assert.Equal(t, newConn, p.Connection)
+	assert.Equal(t, clock.Now(), p.LastSeen, "LastSeen comes from the injected clock")
 
 	// Test reconnect non-existent player
 	err = pm.ReconnectPlayer(uuid.New().String(), newConn)
@@ -425,3 +442,97 @@ func TestPlayerManagerConcurrency(t *testing.T) {
 	finalCount := pm.GetPlayerCount()
 	assert.Equal(t, 10, finalCount)
 }
+
+func TestRestorePlayerAwaitsRecovery(t *testing.T) {
+	pm := NewPlayerManager()
+
+	player := pm.RestorePlayer(PlayerSnapshot{
+		ID:          "11111111-2222-3333-4444-555555555555",
+		Name:        "Player3",
+		Role:        constants.RoleTourist,
+		Specialties: []string{"history"},
+	})
+
+	assert.Equal(t, StateDisconnected, player.State)
+	assert.Nil(t, player.Connection)
+	assert.True(t, pm.IsAwaitingRecovery(player.ID))
+	assert.Equal(t, 0, pm.GetConnectedCount())
+
+	// Reconnecting with the old ID clears the recovery flag
+	assert.NoError(t, pm.ReconnectPlayer(player.ID, nil))
+	assert.False(t, pm.IsAwaitingRecovery(player.ID))
+	assert.False(t, pm.IsAwaitingRecovery("unknown"))
+}
+
+func TestPlayerManagerModeration(t *testing.T) {
+	pm := NewPlayerManager()
+	host := pm.CreatePlayer(nil, true)
+	players := make([]*Player, 4)
+	for i := range players {
+		players[i] = pm.CreatePlayer(nil, false)
+	}
+
+	// With four players each role has room for one, but the host may override that
+	assert.NoError(t, pm.SetPlayerRole(players[0].ID, constants.RoleDetective))
+	assert.Error(t, pm.SetPlayerRole(players[1].ID, constants.RoleDetective))
+	assert.NoError(t, pm.AssignPlayerRole(players[1].ID, constants.RoleDetective))
+	assert.Equal(t, 2, pm.GetRoleDistribution()[constants.RoleDetective])
+	assert.Error(t, pm.AssignPlayerRole(host.ID, constants.RoleDetective))
+
+	assert.NoError(t, pm.RenamePlayer(players[2].ID, "Ada"))
+	assert.Equal(t, "Ada", players[2].Name)
+	assert.Error(t, pm.RenamePlayer(uuid.New().String(), "Ada"))
+
+	// Kicked players are gone; only banned ones stay on the list, with their address
+	pm.SetPlayerAddress(players[2], "203.0.113.2")
+	pm.SetPlayerAddress(players[3], "203.0.113.3")
+	removed, err := pm.RemovePlayer(players[2].ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, players[2], removed)
+	assert.False(t, pm.IsBanned(players[2].ID))
+	assert.False(t, pm.IsAddressBanned("203.0.113.2"))
+
+	_, err = pm.RemovePlayer(players[3].ID, true)
+	assert.NoError(t, err)
+	assert.True(t, pm.IsBanned(players[3].ID))
+	assert.True(t, pm.IsAddressBanned("203.0.113.3"))
+	assert.False(t, pm.IsAddressBanned(""), "Players without a known address ban nothing else")
+	assert.Equal(t, 3, pm.GetPlayerCount())
+
+	_, err = pm.RemovePlayer(players[3].ID, true)
+	assert.Error(t, err)
+}
+
+func TestPlayerNames(t *testing.T) {
+	pm := NewPlayerManager()
+	pm.UseNameFilter(NewNameFilter([]string{"bad"}))
+	host := pm.CreatePlayer(nil, true)
+	ada := pm.CreatePlayer(nil, false)
+	bob := pm.CreatePlayer(nil, false)
+	assert.Equal(t, "Player1", ada.Name)
+	assert.Equal(t, "Player2", bob.Name)
+
+	// Names are unique ignoring case, and a player can keep their own
+	assert.NoError(t, pm.SetPlayerName(ada.ID, "Ada"))
+	assert.EqualError(t, pm.SetPlayerName(bob.ID, "ADA"), constants.ErrNameTaken)
+	assert.EqualError(t, pm.RenamePlayer(bob.ID, "ada"), constants.ErrNameTaken)
+	assert.NoError(t, pm.SetPlayerName(ada.ID, "ADA"))
+
+	// The filter applies to names players choose, not to the host renaming someone
+	assert.EqualError(t, pm.SetPlayerName(bob.ID, "B4d Bob"), constants.ErrNameNotAllowed)
+	assert.Equal(t, "Player2", bob.Name)
+	assert.Error(t, pm.SetPlayerName(host.ID, "Boss"))
+
+	// Automatic names skip any a player already took
+	assert.NoError(t, pm.SetPlayerName(bob.ID, "Player3"))
+	carol := pm.CreatePlayer(nil, false)
+	assert.Equal(t, "Player4", carol.Name)
+
+	assert.NoError(t, pm.SetPlayerReady(ada.ID, true))
+	lobby := pm.GetLobbyPlayers()
+	if assert.Len(t, lobby, 3) {
+		assert.Equal(t, LobbyPlayer{Name: "ADA", Ready: true, Connected: true}, lobby[0])
+		assert.Equal(t, "Player3", lobby[1].Name)
+		assert.Equal(t, "Player4", lobby[2].Name)
+	}
+}
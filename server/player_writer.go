@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/gorilla/websocket"
+)
+
+// droppableMessages are progress updates that a newer update of the same kind makes stale. When a
+// player's queue is full the oldest of these is dropped to make room; everything else is never dropped.
+var droppableMessages = map[string]bool{
+	MsgTeamProgressUpdate: true,
+	MsgHostUpdate:         true,
+}
+
+// outboundMessage is one encoded message waiting in a player's write queue
+type outboundMessage struct {
+	msgType string
+	data    []byte
+}
+
+// transport is the connection a player's messages go out over: a WebSocket, or an event stream
+// for clients that can't use one (see event_stream.go)
+type transport interface {
+	// writeMessage sends one message, given as an encoded BaseMessage in JSON
+	writeMessage(data []byte) error
+	// ping keeps the connection alive and lets a dead one fail
+	ping() error
+	// writeClose tells the client why the server is closing the connection
+	writeClose(code int, reason string) error
+	// close ends the connection
+	close()
+}
+
+// webSocketTransport sends messages over a WebSocket in the encoding the client negotiated
+type webSocketTransport struct {
+	conn  *websocket.Conn
+	codec messageCodec // Messages are queued as JSON and converted as they are written
+}
+
+func newWebSocketTransport(conn *websocket.Conn) *webSocketTransport {
+	return &webSocketTransport{conn: conn, codec: codecFor(conn)}
+}
+
+func (t *webSocketTransport) writeMessage(data []byte) error {
+	frame, err := t.codec.encode(data)
+	if err != nil {
+		return err
+	}
+
+	// Compression is only used if the client negotiated it
+	t.conn.EnableWriteCompression(len(frame) >= constants.WebSocketCompressionThreshold)
+	t.conn.SetWriteDeadline(time.Now().Add(constants.WebSocketWriteTimeout))
+	return t.conn.WriteMessage(t.codec.frameType(), frame)
+}
+
+func (t *webSocketTransport) ping() error {
+	t.conn.SetWriteDeadline(time.Now().Add(constants.WebSocketWriteTimeout))
+	return t.conn.WriteMessage(websocket.PingMessage, nil)
+}
+
+func (t *webSocketTransport) writeClose(code int, reason string) error {
+	closeMessage := websocket.FormatCloseMessage(code, reason)
+	return t.conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(constants.WebSocketWriteTimeout))
+}
+
+func (t *webSocketTransport) close() {
+	t.conn.Close()
+}
+
+// playerWriter is the only goroutine that writes to a player's connection. gorilla/websocket allows
+// one writer at a time, and a queue keeps a slow client from blocking whoever is sending, often
+// while holding gm.mu. A client that falls so far behind that only critical messages are queued is
+// disconnected.
+type playerWriter struct {
+	player    *Player
+	transport transport
+
+	queue     []outboundMessage
+	closeCode int    // Non-zero once a close was requested; sent after the queue drains
+	closeText string // Reason sent with closeCode
+	stopped   bool
+	wake      chan struct{} // Buffered; one pending wake-up covers any number of new messages
+	done      chan struct{}
+	finished  chan struct{} // Closed once the goroutine has made its last write
+	stopOnce  sync.Once
+	mu        sync.Mutex
+}
+
+// startPlayerWriter hands the transport's writing over to a new writer goroutine and attaches it
+// to the player
+func startPlayerWriter(player *Player, t transport) *playerWriter {
+	w := &playerWriter{
+		player:    player,
+		transport: t,
+		queue:     make([]outboundMessage, 0, constants.PlayerWriteQueueSize),
+		wake:      make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		finished:  make(chan struct{}),
+	}
+
+	player.mu.Lock()
+	previous := player.writer
+	player.writer = w
+	player.mu.Unlock()
+
+	// A reconnection replaces the writer of the connection it supersedes
+	if previous != nil {
+		previous.stop()
+	}
+
+	go w.run()
+	return w
+}
+
+// enqueue adds a message to the queue without blocking. On overflow the oldest progress update
+// makes room; if there is none the client is too far behind and is disconnected.
+func (w *playerWriter) enqueue(msgType string, data []byte) error {
+	w.mu.Lock()
+
+	if w.stopped || w.closeCode != 0 {
+		w.mu.Unlock()
+		return fmt.Errorf("connection to player %s is closing", w.player.ID)
+	}
+
+	if len(w.queue) >= constants.PlayerWriteQueueSize && !w.dropOldestInternal() {
+		w.mu.Unlock()
+		log.Printf("Write queue overflow for player %s - disconnecting", w.player.ID)
+		w.stop()
+		return fmt.Errorf("write queue full for player %s", w.player.ID)
+	}
+
+	w.queue = append(w.queue, outboundMessage{msgType: msgType, data: data})
+	w.mu.Unlock()
+
+	w.signal()
+	return nil
+}
+
+// dropOldestInternal removes the oldest droppable message and reports whether there was one
+// NOTE: This method assumes the caller already holds w.mu lock
+func (w *playerWriter) dropOldestInternal() bool {
+	for i, msg := range w.queue {
+		if droppableMessages[msg.msgType] {
+			w.queue = append(w.queue[:i], w.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// closeAfterFlush sends everything already queued, then closes the connection with code and reason
+func (w *playerWriter) closeAfterFlush(code int, reason string) {
+	w.mu.Lock()
+	if w.closeCode == 0 {
+		w.closeCode = code
+		w.closeText = reason
+	}
+	w.mu.Unlock()
+
+	w.signal()
+}
+
+// stop ends the writer and closes the connection, which also ends the player's read loop
+func (w *playerWriter) stop() {
+	w.stopOnce.Do(func() {
+		w.mu.Lock()
+		w.stopped = true
+		w.queue = nil
+		w.mu.Unlock()
+
+		close(w.done)
+		w.transport.close()
+
+		w.player.mu.Lock()
+		if w.player.writer == w {
+			w.player.writer = nil
+		}
+		w.player.mu.Unlock()
+	})
+}
+
+// wait blocks until the writer goroutine has exited after stop
+func (w *playerWriter) wait() {
+	<-w.finished
+}
+
+// superseded reports whether the player has since connected again with a different writer. The
+// end of a superseded connection isn't a disconnect.
+func (w *playerWriter) superseded() bool {
+	w.player.mu.RLock()
+	defer w.player.mu.RUnlock()
+	return w.player.writer != nil && w.player.writer != w
+}
+
+// signal wakes the writer goroutine
+func (w *playerWriter) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run writes queued messages and keep-alive pings until the writer stops or a write fails
+func (w *playerWriter) run() {
+	pingTicker := time.NewTicker(constants.WebSocketPingInterval)
+	defer close(w.finished)
+	defer pingTicker.Stop()
+	defer w.stop()
+
+	for {
+		select {
+		case <-w.wake:
+			if !w.flush() {
+				return
+			}
+
+		case <-pingTicker.C:
+			if err := w.transport.ping(); err != nil {
+				log.Printf("Ping failed for player %s: %v", w.player.ID, err)
+				return
+			}
+
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// flush writes everything queued and, once a requested close is due, the close message. It
+// returns false when the writer should stop.
+func (w *playerWriter) flush() bool {
+	for {
+		w.mu.Lock()
+		if w.stopped {
+			w.mu.Unlock()
+			return false
+		}
+		if len(w.queue) == 0 {
+			closeCode, closeText := w.closeCode, w.closeText
+			w.mu.Unlock()
+
+			if closeCode != 0 {
+				if err := w.transport.writeClose(closeCode, closeText); err != nil {
+					log.Printf("Failed to send close message to player %s: %v", w.player.ID, err)
+				}
+				return false
+			}
+			return true
+		}
+		msg := w.queue[0]
+		w.queue = w.queue[1:]
+		w.mu.Unlock()
+
+		if err := w.transport.writeMessage(msg.data); err != nil {
+			log.Printf("Write to player %s failed: %v", w.player.ID, err)
+			return false
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// dialTestConnection returns both ends of a real WebSocket connection, upgraded like a player's
+// connection, with the client offering the given subprotocols and compression
+func dialTestConnection(t *testing.T, subprotocols ...string) (server *websocket.Conn, client *websocket.Conn) {
+	testUpgrader := upgrader
+	testUpgrader.CheckOrigin = func(*http.Request) bool { return true }
+	serverConns := make(chan *websocket.Conn, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := testUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %v", err)
+			return
+		}
+		serverConns <- conn
+	}))
+	t.Cleanup(srv.Close)
+
+	dialer := websocket.Dialer{Subprotocols: subprotocols, EnableCompression: true}
+	client, _, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	select {
+	case server = <-serverConns:
+	case <-time.After(time.Second):
+		t.Fatal("server side of the connection never arrived")
+	}
+	return server, client
+}
+
+// readTestMessage reads the next message the client received
+func readTestMessage(t *testing.T, client *websocket.Conn) BaseMessage {
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	var msg BaseMessage
+	if err := client.ReadJSON(&msg); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	return msg
+}
+
+// idleTestWriter attaches a writer whose goroutine never runs, so queued messages stay queued
+func idleTestWriter(t *testing.T, player *Player) *playerWriter {
+	conn, _ := dialTestConnection(t)
+	w := &playerWriter{
+		player:    player,
+		transport: newWebSocketTransport(conn),
+		queue:     make([]outboundMessage, 0, constants.PlayerWriteQueueSize),
+		wake:      make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		finished:  make(chan struct{}),
+	}
+	player.mu.Lock()
+	player.writer = w
+	player.mu.Unlock()
+	return w
+}
+
+func TestPlayerWriterDeliversInOrder(t *testing.T) {
+	conn, client := dialTestConnection(t)
+	player := &Player{ID: "player-1", Connection: conn}
+	writer := startPlayerWriter(player, newWebSocketTransport(conn))
+	defer writer.stop()
+
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, sendToPlayer(player, MsgTeamProgressUpdate, map[string]int{"seq": i}))
+	}
+
+	for i := 0; i < 20; i++ {
+		msg := readTestMessage(t, client)
+		assert.Equal(t, MsgTeamProgressUpdate, msg.Type)
+
+		var payload map[string]int
+		assert.NoError(t, json.Unmarshal(msg.Payload, &payload))
+		assert.Equal(t, i, payload["seq"])
+	}
+}
+
+func TestPlayerWriterDropsOldestProgressUpdate(t *testing.T) {
+	player := &Player{ID: "player-1"}
+	writer := idleTestWriter(t, player)
+
+	assert.NoError(t, writer.enqueue(MsgPieceRecommendation, []byte("critical")))
+	for i := 1; i < constants.PlayerWriteQueueSize; i++ {
+		assert.NoError(t, writer.enqueue(MsgTeamProgressUpdate, []byte{byte(i)}))
+	}
+
+	// A full queue makes room by dropping the oldest progress update, never the critical message
+	assert.NoError(t, writer.enqueue(MsgGameAnalytics, []byte("results")))
+	assert.Len(t, writer.queue, constants.PlayerWriteQueueSize)
+	assert.Equal(t, []byte("critical"), writer.queue[0].data)
+	assert.Equal(t, []byte{2}, writer.queue[1].data)
+	assert.Equal(t, []byte("results"), writer.queue[len(writer.queue)-1].data)
+}
+
+func TestPlayerWriterOverflowDisconnects(t *testing.T) {
+	player := &Player{ID: "player-1"}
+	writer := idleTestWriter(t, player)
+
+	for i := 0; i < constants.PlayerWriteQueueSize; i++ {
+		assert.NoError(t, writer.enqueue(MsgPieceRecommendation, []byte{byte(i)}))
+	}
+
+	// Nothing left to drop, so the client is too far behind to keep
+	assert.Error(t, writer.enqueue(MsgPieceRecommendation, []byte("one too many")))
+	assert.Nil(t, player.writer)
+	assert.NoError(t, sendToPlayer(player, MsgError, map[string]string{"error": "gone"}))
+
+	select {
+	case <-writer.done:
+	default:
+		t.Error("writer still running after overflow")
+	}
+}
+
+func TestCloseAfterFlush(t *testing.T) {
+	conn, client := dialTestConnection(t)
+	player := &Player{ID: "player-1", Connection: conn}
+	startPlayerWriter(player, newWebSocketTransport(conn))
+
+	assert.NoError(t, sendToPlayer(player, MsgError, map[string]string{"error": "Room closed"}))
+	closePlayerConnection(player, websocket.CloseGoingAway, "room closed")
+
+	// The queued message arrives before the close
+	msg := readTestMessage(t, client)
+	assert.Equal(t, MsgError, msg.Type)
+
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err := client.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+	WaitForCondition(t, func() bool {
+		player.mu.RLock()
+		defer player.mu.RUnlock()
+		return player.writer == nil
+	}, time.Second, "writer stopped")
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/gorilla/websocket"
+)
+
+// After connecting, a client says hello with the range of protocol versions it speaks and the
+// optional features it understands. The server answers with welcome: the newest version both sides
+// speak and the features enabled for the connection. Messages and fields belonging to a feature only
+// go to connections that enabled it. Clients that never say hello predate the handshake, and are
+// served as protocol version 1 clients with legacyFeatures.
+
+// Optional protocol features a client may ask for in its hello
+const (
+	FeaturePuzzleDeltas         = "puzzleDeltas"         // fragment_moved and fragment_revealed instead of full puzzle states
+	FeatureGuideHighlights      = "guideHighlights"      // guideHighlight in personal_puzzle_state
+	FeaturePieceRecommendations = "pieceRecommendations" // piece_recommendation messages
+	FeatureImagePreview         = "imagePreview"         // image_preview messages
+	FeatureTimerUpdates         = "timerUpdates"         // game_timer_update messages
+)
+
+// serverFeatures are the features this server offers, in the order welcome lists them
+var serverFeatures = []string{
+	FeaturePuzzleDeltas,
+	FeatureGuideHighlights,
+	FeaturePieceRecommendations,
+	FeatureImagePreview,
+	FeatureTimerUpdates,
+}
+
+// legacyFeatures are enabled for clients that never say hello: everything a protocol version 1
+// client handles or safely ignores. Puzzle deltas replace messages those clients rely on, so they
+// get full puzzle states instead.
+var legacyFeatures = map[string]bool{
+	FeatureGuideHighlights:      true,
+	FeaturePieceRecommendations: true,
+	FeatureImagePreview:         true,
+	FeatureTimerUpdates:         true,
+}
+
+// featureMessages are the messages only sent to connections that enabled a feature
+var featureMessages = map[string]string{
+	MsgFragmentMoved:       FeaturePuzzleDeltas,
+	MsgFragmentRevealed:    FeaturePuzzleDeltas,
+	MsgPieceRecommendation: FeaturePieceRecommendations,
+	MsgImagePreview:        FeatureImagePreview,
+	MsgGameTimerUpdate:     FeatureTimerUpdates,
+}
+
+// WelcomePayload is the server's answer to hello
+type WelcomePayload struct {
+	ProtocolVersion    int      `json:"protocolVersion"`    // Version the connection uses from now on
+	MinProtocolVersion int      `json:"minProtocolVersion"` // Oldest version the server speaks
+	MaxProtocolVersion int      `json:"maxProtocolVersion"` // Newest version the server speaks
+	Features           []string `json:"features"`           // Features enabled for this connection
+}
+
+// negotiateProtocol picks the newest version both the client and the server speak
+func negotiateProtocol(clientMin, clientMax int) (int, error) {
+	version := min(clientMax, constants.ProtocolVersion)
+	if version < clientMin || version < constants.MinProtocolVersion {
+		return 0, fmt.Errorf("%w: client speaks %d to %d, server speaks %d to %d", errProtocolUnsupported,
+			clientMin, clientMax, constants.MinProtocolVersion, constants.ProtocolVersion)
+	}
+	return version, nil
+}
+
+// negotiateFeatures returns the offered features the server also has, in the server's order.
+// Features the server doesn't know are left out rather than refused.
+func negotiateFeatures(offered []string) []string {
+	wanted := make(map[string]bool, len(offered))
+	for _, feature := range offered {
+		wanted[feature] = true
+	}
+
+	enabled := make([]string, 0, len(serverFeatures))
+	for _, feature := range serverFeatures {
+		if wanted[feature] {
+			enabled = append(enabled, feature)
+		}
+	}
+	return enabled
+}
+
+// playerSupports reports whether a feature is enabled on the player's connection
+func playerSupports(player *Player, feature string) bool {
+	player.mu.RLock()
+	defer player.mu.RUnlock()
+	return playerSupportsInternal(player, feature)
+}
+
+// playerSupportsInternal reports whether a feature is enabled on the player's connection
+// NOTE: This method assumes the caller already holds player.mu lock (read or write)
+func playerSupportsInternal(player *Player, feature string) bool {
+	if player.features == nil {
+		return legacyFeatures[feature]
+	}
+	return player.features[feature]
+}
+
+// handleHello negotiates the protocol version and features for the player's connection. The
+// handshake is about the connection rather than the game, so it isn't recorded in the event log.
+func (wsh *WebSocketHandler) handleHello(player *Player, payload json.RawMessage) error {
+	data, errors := ValidateHello(payload)
+	if len(errors) > 0 {
+		return validationFailure(errors)
+	}
+
+	version, err := negotiateProtocol(data.MinProtocolVersion, data.ProtocolVersion)
+	if err != nil {
+		return err
+	}
+
+	features := negotiateFeatures(data.Features)
+	enabled := make(map[string]bool, len(features))
+	for _, feature := range features {
+		enabled[feature] = true
+	}
+
+	player.mu.Lock()
+	player.features = enabled
+	player.mu.Unlock()
+
+	log.Printf("Player %s speaks protocol version %d with features %v", player.ID, version, features)
+
+	return sendToPlayer(player, MsgWelcome, WelcomePayload{
+		ProtocolVersion:    version,
+		MinProtocolVersion: constants.MinProtocolVersion,
+		MaxProtocolVersion: constants.ProtocolVersion,
+		Features:           features,
+	})
+}
+
+// refuseProtocol disconnects a player whose client can't speak any protocol version the server does,
+// once the error saying so has been sent
+func refuseProtocol(player *Player, err error) {
+	log.Printf("Disconnecting player %s: %v", player.ID, err)
+	closePlayerConnection(player, websocket.CloseProtocolError, constants.ErrProtocolUnsupported)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+)
+
+// The protocol schema describes every message in protocolMessages as JSON Schema, generated from
+// the payload types and the rules their validate tags name. Client code generators and docs read it
+// from /protocol/schema instead of copying message shapes by hand.
+
+// JSONSchema is the subset of JSON Schema (draft 2020-12) the protocol schema uses
+type JSONSchema struct {
+	Ref         string                 `json:"$ref,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Format      string                 `json:"format,omitempty"`
+	Properties  map[string]*JSONSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+	Extra       *JSONSchema            `json:"additionalProperties,omitempty"`
+	Items       *JSONSchema            `json:"items,omitempty"`
+	Enum        []string               `json:"enum,omitempty"`
+	Const       interface{}            `json:"const,omitempty"`
+	Pattern     string                 `json:"pattern,omitempty"`
+	MinLength   *int                   `json:"minLength,omitempty"`
+	MaxLength   *int                   `json:"maxLength,omitempty"`
+	Minimum     *int                   `json:"minimum,omitempty"`
+	Maximum     *int                   `json:"maximum,omitempty"`
+	MinItems    *int                   `json:"minItems,omitempty"`
+	MaxItems    *int                   `json:"maxItems,omitempty"`
+	UniqueItems bool                   `json:"uniqueItems,omitempty"`
+	AnyOf       []*JSONSchema          `json:"anyOf,omitempty"`
+	OneOf       []*JSONSchema          `json:"oneOf,omitempty"`
+}
+
+// MessageSchema describes one message type
+type MessageSchema struct {
+	Direction   string      `json:"direction"` // client or server
+	Description string      `json:"description"`
+	Feature     string      `json:"feature,omitempty"` // Only sent to connections that enabled this feature
+	Payload     *JSONSchema `json:"payload"`
+}
+
+// ProtocolSchema is the document served at /protocol/schema. Client messages travel in a
+// ClientMessage envelope with their payload inside an AuthWrapper; server messages travel in a
+// ServerMessage envelope.
+type ProtocolSchema struct {
+	Schema             string                   `json:"$schema"`
+	Title              string                   `json:"title"`
+	ProtocolVersion    int                      `json:"protocolVersion"`
+	MinProtocolVersion int                      `json:"minProtocolVersion"`
+	Messages           map[string]MessageSchema `json:"messages"`
+	Defs               map[string]*JSONSchema   `json:"$defs"`
+}
+
+// buildProtocolSchema generates the schema for every message in protocolMessages
+func buildProtocolSchema() ProtocolSchema {
+	builder := &schemaBuilder{defs: make(map[string]*JSONSchema)}
+
+	messages := make(map[string]MessageSchema, len(protocolMessages))
+	for _, message := range protocolMessages {
+		variants := make([]*JSONSchema, len(message.Payloads))
+		for i, payload := range message.Payloads {
+			variants[i] = builder.schemaOf(reflect.TypeOf(payload))
+		}
+
+		payload := variants[0]
+		if len(variants) > 1 {
+			payload = &JSONSchema{OneOf: variants}
+		}
+		messages[message.Type] = MessageSchema{
+			Direction:   message.Direction,
+			Description: message.Description,
+			Feature:     featureMessages[message.Type],
+			Payload:     payload,
+		}
+	}
+
+	clientTypes := make([]string, 0, len(clientMessageTypes))
+	serverTypes := make([]string, 0, len(protocolMessages))
+	for _, message := range protocolMessages {
+		if message.Direction == FromClient {
+			clientTypes = append(clientTypes, message.Type)
+		} else {
+			serverTypes = append(serverTypes, message.Type)
+		}
+	}
+
+	auth := builder.schemaOf(reflect.TypeOf(AuthWrapper{}))
+	builder.defs["AuthWrapper"].Properties["payload"] = &JSONSchema{Description: "The message's payload, as described under messages"}
+	builder.defs["ClientMessage"] = &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"type":      {Type: "string", Enum: clientTypes},
+			"requestId": {Type: "string", Description: "Chosen by the client and echoed in the ack or error answering the message"},
+			"payload":   auth,
+		},
+		Required: []string{"type", "payload"},
+	}
+	builder.defs["ServerMessage"] = &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"type":    {Type: "string", Enum: serverTypes},
+			"payload": {Description: "The message's payload, as described under messages"},
+		},
+		Required: []string{"type", "payload"},
+	}
+
+	return ProtocolSchema{
+		Schema:             "https://json-schema.org/draft/2020-12/schema",
+		Title:              "Canvas Conundrum WebSocket protocol",
+		ProtocolVersion:    constants.ProtocolVersion,
+		MinProtocolVersion: constants.MinProtocolVersion,
+		Messages:           messages,
+		Defs:               builder.defs,
+	}
+}
+
+// schemaBuilder turns Go types into JSON Schema, collecting named structs under $defs
+type schemaBuilder struct {
+	defs map[string]*JSONSchema
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaOf returns the schema of values of type t as encoding/json writes them
+func (sb *schemaBuilder) schemaOf(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	case t == rawJSONType:
+		return &JSONSchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: sb.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", Extra: sb.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return sb.objectSchema(t)
+		}
+		if _, built := sb.defs[t.Name()]; !built {
+			sb.defs[t.Name()] = &JSONSchema{} // Claimed first, so types that refer to themselves end
+			*sb.defs[t.Name()] = *sb.objectSchema(t)
+		}
+		return &JSONSchema{Ref: "#/$defs/" + t.Name()}
+	default:
+		return &JSONSchema{}
+	}
+}
+
+// objectSchema describes a struct's fields, applying the rules their validate tags name
+func (sb *schemaBuilder) objectSchema(t reflect.Type) *JSONSchema {
+	object := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+	sb.addFields(object, t)
+	return object
+}
+
+// addFields adds a struct's fields to object, flattening embedded structs as encoding/json does
+func (sb *schemaBuilder) addFields(object *JSONSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" && field.Type.Kind() == reflect.Struct {
+			sb.addFields(object, field.Type)
+			continue
+		}
+
+		name, optional := jsonFieldName(field)
+		if name == "" {
+			continue
+		}
+
+		property := sb.schemaOf(field.Type)
+		required := false
+		for _, ruleName := range validateTags(field) {
+			if ruleName == "required" {
+				required = true
+				continue
+			}
+			if rule, known := fieldRules[ruleName]; known {
+				required = required || (rule.required && !optional)
+				rule.schema(name, property)
+			}
+		}
+
+		object.Properties[name] = property
+		if required && !slices.Contains(object.Required, name) {
+			object.Required = append(object.Required, name)
+		}
+	}
+}
+
+// registerProtocolRoutes serves the protocol schema
+func registerProtocolRoutes(mux *http.ServeMux) {
+	// The schema only changes with the code, so it is generated once
+	schema := mustMarshal(buildProtocolSchema())
+
+	mux.HandleFunc("GET /protocol/schema", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/schema+json")
+		w.Write(schema)
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildProtocolSchema(t *testing.T) {
+	schema := buildProtocolSchema()
+
+	assert.Equal(t, constants.ProtocolVersion, schema.ProtocolVersion)
+	assert.Len(t, schema.Messages, len(protocolMessages))
+
+	t.Run("Rules become constraints", func(t *testing.T) {
+		role := schema.Defs["RoleSelectionPayload"]
+		if assert.NotNil(t, role) {
+			assert.Equal(t, []string{"role"}, role.Required)
+			assert.Equal(t, playerRoles, role.Properties["role"].Enum)
+		}
+
+		addTime := schema.Defs["HostAddTimePayload"]
+		if assert.NotNil(t, addTime) {
+			assert.Equal(t, []string{"seconds"}, addTime.Required)
+			assert.Equal(t, constants.MinAddTimeSeconds, *addTime.Properties["seconds"].Minimum)
+			assert.Equal(t, constants.MaxAddTimeSeconds, *addTime.Properties["seconds"].Maximum)
+		}
+
+		// Optional fields keep their constraints without being required
+		settings := schema.Defs["GameSettingsUpdate"]
+		if assert.NotNil(t, settings) {
+			assert.Empty(t, settings.Required)
+			assert.Equal(t, constants.MaxResourceRounds, *settings.Properties["resourceRounds"].Maximum)
+		}
+	})
+
+	t.Run("Messages refer to their payloads", func(t *testing.T) {
+		assert.Equal(t, FromClient, schema.Messages[MsgRoleSelection].Direction)
+		assert.Equal(t, "#/$defs/RoleSelectionPayload", schema.Messages[MsgRoleSelection].Payload.Ref)
+
+		// Messages with several shapes list each one
+		assert.Len(t, schema.Messages[MsgPieceRecommendation].Payload.OneOf, 2)
+		assert.Equal(t, FeaturePieceRecommendations, schema.Messages[MsgPieceRecommendation].Feature)
+
+		// Every reference resolves
+		refs := regexp.MustCompile(`"\$ref":"#/\$defs/([^"]*)"`).FindAllStringSubmatch(string(mustMarshal(schema)), -1)
+		assert.NotEmpty(t, refs)
+		for _, ref := range refs {
+			assert.Contains(t, schema.Defs, ref[1])
+		}
+	})
+
+	t.Run("Envelopes list the message types", func(t *testing.T) {
+		client := schema.Defs["ClientMessage"]
+		if assert.NotNil(t, client) {
+			assert.Len(t, client.Properties["type"].Enum, len(clientMessageTypes))
+		}
+		assert.Contains(t, schema.Defs["ServerMessage"].Properties["type"].Enum, MsgGameAnalytics)
+	})
+}
+
+func TestProtocolRoutes(t *testing.T) {
+	mux := http.NewServeMux()
+	registerProtocolRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/protocol/schema", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/schema+json", rec.Header().Get("Content-Type"))
+
+	var body ProtocolSchema
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Contains(t, body.Messages, MsgHello)
+	assert.Contains(t, body.Defs, "AuthWrapper")
+}
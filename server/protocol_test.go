@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// enableTestFeatures enables features on a player as if their client had said hello with them
+func enableTestFeatures(player *Player, features ...string) {
+	enabled := make(map[string]bool, len(features))
+	for _, feature := range features {
+		enabled[feature] = true
+	}
+	player.mu.Lock()
+	player.features = enabled
+	player.mu.Unlock()
+}
+
+func TestNegotiateProtocol(t *testing.T) {
+	tests := []struct {
+		name      string
+		clientMin int
+		clientMax int
+		want      int
+	}{
+		{"Same version", constants.ProtocolVersion, constants.ProtocolVersion, constants.ProtocolVersion},
+		{"Older client", constants.MinProtocolVersion, constants.MinProtocolVersion, constants.MinProtocolVersion},
+		{"Newer client that can speak down", constants.MinProtocolVersion, constants.ProtocolVersion + 3, constants.ProtocolVersion},
+		{"Newer client that can't", constants.ProtocolVersion + 1, constants.ProtocolVersion + 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := negotiateProtocol(tt.clientMin, tt.clientMax)
+			assert.Equal(t, tt.want, version)
+			if tt.want == 0 {
+				assert.Equal(t, CodeProtocolUnsupported, errorCodeOf(err))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNegotiateFeatures(t *testing.T) {
+	// Unknown features are left out, and the rest come back in the server's order
+	enabled := negotiateFeatures([]string{FeatureTimerUpdates, "holograms", FeaturePuzzleDeltas})
+	assert.Equal(t, []string{FeaturePuzzleDeltas, FeatureTimerUpdates}, enabled)
+	assert.Empty(t, negotiateFeatures(nil))
+}
+
+func TestHelloEnablesFeatures(t *testing.T) {
+	gm, pm, tm, broadcastChan := createTestGameManager()
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	eh := NewEventHandlers(gm, pm, broadcastChan)
+	wsh := NewWebSocketHandler(pm, gm, eh, broadcastChan)
+	player := pm.CreatePlayer(nil, false)
+	writer := idleTestWriter(t, player)
+	token := wsh.sessionTokens.Issue(player.ID)
+
+	// Until hello, the player gets what protocol version 1 clients got
+	assert.False(t, playerSupports(player, FeaturePuzzleDeltas))
+	assert.True(t, playerSupports(player, FeatureImagePreview))
+
+	wsh.handleClientMessage(player, BaseMessage{
+		Type:      MsgHello,
+		RequestID: "hello-1",
+		Payload: mustMarshal(AuthWrapper{
+			Auth:    AuthData{PlayerID: player.ID, Token: token},
+			Payload: mustMarshal(map[string]interface{}{"protocolVersion": constants.ProtocolVersion, "features": []string{FeaturePuzzleDeltas, "holograms"}}),
+		}),
+	})
+
+	welcome := queuedPayloads(t, writer, MsgWelcome)
+	if assert.Len(t, welcome, 1) {
+		assert.EqualValues(t, constants.ProtocolVersion, welcome[0]["protocolVersion"])
+		assert.EqualValues(t, constants.MinProtocolVersion, welcome[0]["minProtocolVersion"])
+		assert.EqualValues(t, constants.ProtocolVersion, welcome[0]["maxProtocolVersion"])
+		assert.Equal(t, []interface{}{FeaturePuzzleDeltas}, welcome[0]["features"])
+	}
+	assert.Len(t, queuedPayloads(t, writer, MsgAck), 1)
+
+	// Messages of features the client left out are no longer sent
+	assert.NoError(t, sendToPlayer(player, MsgImagePreview, map[string]int{"duration": 5}))
+	assert.NoError(t, sendToPlayer(player, MsgFragmentRevealed, map[string]int{"seq": 1}))
+	assert.Empty(t, queuedPayloads(t, writer, MsgImagePreview))
+	assert.Len(t, queuedPayloads(t, writer, MsgFragmentRevealed), 1)
+
+	// A new connection starts over
+	assert.NoError(t, pm.ReconnectPlayer(player.ID, nil))
+	assert.False(t, playerSupports(player, FeaturePuzzleDeltas))
+	assert.True(t, playerSupports(player, FeatureImagePreview))
+}
+
+func TestLegacyClientsGetFullPuzzleStates(t *testing.T) {
+	gm, pm, tm, _ := startFakeClockPuzzle(t)
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	host := pm.createPlayer("host", nil, true)
+	legacy, _ := pm.GetPlayer("player-2")
+	current, _ := pm.GetPlayer("player-3")
+	hostWriter := idleTestWriter(t, host)
+	legacyWriter := idleTestWriter(t, legacy)
+	currentWriter := idleTestWriter(t, current)
+	enableTestFeatures(current, FeaturePuzzleDeltas)
+
+	assert.NoError(t, gm.ProcessSegmentCompleted("player-1", "segment-1"))
+	assert.NoError(t, gm.ProcessFragmentMove("player-1", "fragment_player-1", fragmentOf(gm, "player-2").Position))
+
+	// Clients that never said hello get a snapshot after each change instead of a delta
+	assert.Empty(t, queuedPayloads(t, legacyWriter, MsgFragmentRevealed))
+	assert.Empty(t, queuedPayloads(t, legacyWriter, MsgFragmentMoved))
+	snapshots := queuedPayloads(t, legacyWriter, MsgPersonalPuzzleState)
+	if assert.Len(t, snapshots, 2) {
+		assert.EqualValues(t, 2, snapshots[1]["seq"])
+		assert.Contains(t, snapshots[1]["personalView"], "guideHighlight")
+	}
+	assert.Empty(t, queuedPayloads(t, hostWriter, MsgFragmentMoved))
+	assert.Len(t, queuedPayloads(t, hostWriter, MsgCentralPuzzleState), 2)
+
+	// A client that negotiated deltas gets only those, and no guide highlight it didn't ask for
+	assert.Len(t, queuedPayloads(t, currentWriter, MsgFragmentRevealed), 1)
+	assert.Len(t, queuedPayloads(t, currentWriter, MsgFragmentMoved), 1)
+	assert.Empty(t, queuedPayloads(t, currentWriter, MsgPersonalPuzzleState))
+
+	gm.mu.RLock()
+	gm.sendPersonalPuzzleState(current, gm.calculateGuideHighlight(current.ID))
+	gm.mu.RUnlock()
+	if states := queuedPayloads(t, currentWriter, MsgPersonalPuzzleState); assert.Len(t, states, 1) {
+		assert.NotContains(t, states[0]["personalView"], "guideHighlight")
+	}
+}
+
+func TestIncompatibleClientIsRefused(t *testing.T) {
+	gm, pm, tm, broadcastChan := createTestGameManager()
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	eh := NewEventHandlers(gm, pm, broadcastChan)
+	wsh := NewWebSocketHandler(pm, gm, eh, broadcastChan)
+	conn, client := dialTestConnection(t)
+	player := pm.CreatePlayer(conn, false)
+	startPlayerWriter(player, newWebSocketTransport(conn))
+	token := wsh.sessionTokens.Issue(player.ID)
+
+	tooNew := constants.ProtocolVersion + 1
+	wsh.handleClientMessage(player, BaseMessage{
+		Type: MsgHello,
+		Payload: mustMarshal(AuthWrapper{
+			Auth:    AuthData{PlayerID: player.ID, Token: token},
+			Payload: mustMarshal(map[string]int{"protocolVersion": tooNew, "minProtocolVersion": tooNew}),
+		}),
+	})
+
+	// The client is told why, then disconnected
+	msg := readTestMessage(t, client)
+	assert.Equal(t, MsgError, msg.Type)
+	var refused map[string]interface{}
+	assert.NoError(t, json.Unmarshal(msg.Payload, &refused))
+	assert.Equal(t, string(CodeProtocolUnsupported), refused["code"])
+	assert.Equal(t, "connection_error", refused["type"])
+	assert.Equal(t, MsgHello, refused["requestType"])
+
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err := client.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseProtocolError), "unexpected error: %v", err)
+}
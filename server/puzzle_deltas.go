@@ -0,0 +1,111 @@
+package main
+
+import (
+	"log"
+)
+
+// Puzzle state is versioned by gm.state.PuzzleSeq, which goes up by one with every change to the
+// fragments. Changes go out as fragment_moved and fragment_revealed deltas carrying their seq, and
+// full snapshots carry the seq they reflect. A client that sees a seq other than the one after its
+// last asks for a snapshot with puzzle_resync_request. Clients without FeaturePuzzleDeltas get a
+// full snapshot after every change instead.
+
+// nextPuzzleSeqInternal advances the puzzle version for a change about to be sent
+// NOTE: This method assumes the caller already holds gm.mu lock
+func (gm *GameManager) nextPuzzleSeqInternal() int {
+	gm.state.PuzzleSeq++
+	return gm.state.PuzzleSeq
+}
+
+// sendFragmentsMovedInternal tells everyone which fragments a move or accepted recommendation
+// relocated. Players only learn about fragments they can see; the host gets all of them.
+// NOTE: This method assumes the caller already holds gm.mu lock
+func (gm *GameManager) sendFragmentsMovedInternal(movedBy string, fragments ...*PuzzleFragment) {
+	seq := gm.nextPuzzleSeqInternal()
+
+	visible := make([]*PuzzleFragment, 0, len(fragments))
+	for _, fragment := range fragments {
+		if fragment.Visible {
+			visible = append(visible, fragment)
+		}
+	}
+
+	for _, player := range gm.playerManager.GetConnectedNonHostPlayers() {
+		if !playerSupports(player, FeaturePuzzleDeltas) {
+			gm.sendPersonalPuzzleState(player, gm.calculateGuideHighlight(player.ID))
+			continue
+		}
+		sendToPlayer(player, MsgFragmentMoved, FragmentMovedPayload{
+			Seq:       seq,
+			Fragments: visible,
+			MovedBy:   movedBy,
+		})
+	}
+
+	host := gm.playerManager.GetHost()
+	if host == nil {
+		return
+	}
+	if !playerSupports(host, FeaturePuzzleDeltas) {
+		gm.sendCompletePuzzleStateToHost()
+		return
+	}
+	completion := gm.calculateCompletionPercentage()
+	sendToPlayer(host, MsgFragmentMoved, FragmentMovedPayload{
+		Seq:               seq,
+		Fragments:         fragments,
+		MovedBy:           movedBy,
+		CompletionPercent: &completion,
+	})
+}
+
+// sendFragmentRevealedInternal tells everyone a fragment appeared on the central grid
+// NOTE: This method assumes the caller already holds gm.mu lock
+func (gm *GameManager) sendFragmentRevealedInternal(fragment *PuzzleFragment) {
+	seq := gm.nextPuzzleSeqInternal()
+
+	for _, player := range gm.playerManager.GetConnectedPlayers() {
+		if !playerSupports(player, FeaturePuzzleDeltas) {
+			if player.IsHost {
+				gm.sendCompletePuzzleStateToHost()
+			} else {
+				gm.sendPersonalPuzzleState(player, gm.calculateGuideHighlight(player.ID))
+			}
+			continue
+		}
+
+		payload := FragmentRevealedPayload{
+			Seq:      seq,
+			Fragment: fragment,
+		}
+		if player.IsHost {
+			completion := gm.calculateCompletionPercentage()
+			payload.CompletionPercent = &completion
+		}
+		sendToPlayer(player, MsgFragmentRevealed, payload)
+	}
+}
+
+// ResyncPuzzle sends a player who missed a delta the full puzzle state as of the current seq
+func (gm *GameManager) ResyncPuzzle(playerID string) error {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	if gm.state.Phase != PhasePuzzleAssembly {
+		return errNotInPuzzlePhase
+	}
+
+	player, err := gm.playerManager.GetPlayer(playerID)
+	if err != nil {
+		return err
+	}
+
+	if player.IsHost {
+		gm.sendCompletePuzzleStateToHost()
+	} else {
+		gm.sendPersonalPuzzleState(player, gm.calculateGuideHighlight(playerID))
+	}
+
+	log.Printf("Resent puzzle state at seq %d to player %s", gm.state.PuzzleSeq, playerID)
+	return nil
+}
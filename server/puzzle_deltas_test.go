@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+// queuedPayloads decodes the payloads of the messages of one type waiting in an idle writer's queue
+func queuedPayloads(t *testing.T, w *playerWriter, msgType string) []map[string]interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	payloads := make([]map[string]interface{}, 0)
+	for _, msg := range w.queue {
+		if msg.msgType != msgType {
+			continue
+		}
+		var base BaseMessage
+		var payload map[string]interface{}
+		assert.NoError(t, json.Unmarshal(msg.data, &base))
+		assert.NoError(t, json.Unmarshal(base.Payload, &payload))
+		payloads = append(payloads, payload)
+	}
+	return payloads
+}
+
+func TestPuzzleDeltas(t *testing.T) {
+	gm, pm, tm, _ := startFakeClockPuzzle(t)
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	host := pm.createPlayer("host", nil, true)
+	other, _ := pm.GetPlayer("player-2")
+	hostWriter := idleTestWriter(t, host)
+	otherWriter := idleTestWriter(t, other)
+	enableTestFeatures(host, FeaturePuzzleDeltas)
+	enableTestFeatures(other, FeaturePuzzleDeltas)
+
+	// Solving a segment reveals the fragment to everyone
+	assert.NoError(t, gm.ProcessSegmentCompleted("player-1", "segment-1"))
+	revealed := queuedPayloads(t, otherWriter, MsgFragmentRevealed)
+	if assert.Len(t, revealed, 1) {
+		assert.EqualValues(t, 1, revealed[0]["seq"])
+		assert.Equal(t, "fragment_player-1", revealed[0]["fragment"].(map[string]interface{})["id"])
+	}
+	assert.Len(t, queuedPayloads(t, hostWriter, MsgFragmentRevealed), 1)
+
+	// Swapping with a fragment that is still hidden only tells players about the visible one
+	target := fragmentOf(gm, "player-2").Position
+	assert.NoError(t, gm.ProcessFragmentMove("player-1", "fragment_player-1", target))
+
+	moved := queuedPayloads(t, otherWriter, MsgFragmentMoved)
+	if assert.Len(t, moved, 1) {
+		assert.EqualValues(t, 2, moved[0]["seq"])
+		assert.Equal(t, "player-1", moved[0]["movedBy"])
+		assert.Len(t, moved[0]["fragments"], 1)
+	}
+	hostMoved := queuedPayloads(t, hostWriter, MsgFragmentMoved)
+	if assert.Len(t, hostMoved, 1) {
+		assert.EqualValues(t, 2, hostMoved[0]["seq"])
+		assert.Len(t, hostMoved[0]["fragments"], 2)
+	}
+
+	// No full state goes out for either change
+	assert.Empty(t, queuedPayloads(t, otherWriter, MsgPersonalPuzzleState))
+	assert.Empty(t, queuedPayloads(t, hostWriter, MsgCentralPuzzleState))
+
+	// A resync returns the whole state as of the latest seq
+	assert.NoError(t, gm.ResyncPuzzle("player-2"))
+	snapshots := queuedPayloads(t, otherWriter, MsgPersonalPuzzleState)
+	if assert.Len(t, snapshots, 1) {
+		assert.EqualValues(t, 2, snapshots[0]["seq"])
+		assert.Len(t, snapshots[0]["personalView"].(map[string]interface{})["fragments"], 1)
+	}
+
+	assert.NoError(t, gm.ResyncPuzzle("host"))
+	hostSnapshots := queuedPayloads(t, hostWriter, MsgCentralPuzzleState)
+	if assert.Len(t, hostSnapshots, 1) {
+		assert.EqualValues(t, 2, hostSnapshots[0]["seq"])
+		assert.Len(t, hostSnapshots[0]["fragments"], 7)
+	}
+}
+
+func TestResyncPuzzleOutsidePuzzle(t *testing.T) {
+	gm, pm, tm, _ := createTestGameManager()
+	defer cleanupTestGameManager(tm)
+
+	pm.createPlayer("player-1", nil, false)
+	assert.EqualError(t, gm.ResyncPuzzle("player-1"), constants.ErrNotInPuzzlePhase)
+}
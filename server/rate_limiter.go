@@ -0,0 +1,271 @@
+package main
+
+import (
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/gorilla/websocket"
+)
+
+// Every message a player sends takes a token from their connection's bucket, and costly message
+// types also take one from a bucket of their own. Messages that find a bucket empty are refused
+// with a rate_limited error, and a client that keeps sending anyway is disconnected.
+
+// RateLimit is a token bucket: PerSecond tokens are added each second, up to Burst
+type RateLimit struct {
+	PerSecond float64 `json:"perSecond"`
+	Burst     int     `json:"burst"`
+}
+
+// RateLimitConfig sets how much traffic one connection, and one address, may send
+type RateLimitConfig struct {
+	Messages         RateLimit            `json:"messages"`         // Every message a player sends
+	MessageTypes     map[string]RateLimit `json:"messageTypes"`     // Tighter limits for costly message types
+	ConnectionsPerIP int                  `json:"connectionsPerIp"` // Open connections from one address; 0 for no limit
+	AbuseLimit       int                  `json:"abuseLimit"`       // Refused messages within abuseWindow that close the connection
+	AbuseWindow      int                  `json:"abuseWindow"`      // Seconds
+}
+
+// DefaultRateLimitConfig returns the built-in limits taken from the constants package
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Messages: RateLimit{PerSecond: constants.MessageRatePerSecond, Burst: constants.MessageRateBurst},
+		MessageTypes: map[string]RateLimit{
+			MsgFragmentMoveRequest:        {PerSecond: constants.FragmentMoveRatePerSecond, Burst: constants.FragmentMoveRateBurst},
+			MsgPieceRecommendationRequest: {PerSecond: constants.RecommendationRatePerSecond, Burst: constants.RecommendationRateBurst},
+			MsgPlayerSetName:              {PerSecond: constants.SetNameRatePerSecond, Burst: constants.SetNameRateBurst},
+			MsgPuzzleResyncRequest:        {PerSecond: constants.ResyncRatePerSecond, Burst: constants.ResyncRateBurst},
+		},
+		ConnectionsPerIP: constants.MaxConnectionsPerIP,
+		AbuseLimit:       constants.RateLimitAbuseLimit,
+		AbuseWindow:      int(constants.RateLimitAbuseWindow / time.Second),
+	}
+}
+
+// validate reports problems with the limits the same way BalanceConfig.Validate does
+func (rc RateLimitConfig) validate(add func(field, format string, args ...interface{})) {
+	checkLimit := func(field string, limit RateLimit) {
+		if limit.PerSecond <= 0 {
+			add(field+".perSecond", "must be greater than 0")
+		}
+		if limit.Burst < 1 {
+			add(field+".burst", "must be at least 1")
+		}
+	}
+
+	checkLimit("rateLimits.messages", rc.Messages)
+	msgTypes := make([]string, 0, len(rc.MessageTypes))
+	for msgType := range rc.MessageTypes {
+		msgTypes = append(msgTypes, msgType)
+	}
+	sort.Strings(msgTypes)
+	for _, msgType := range msgTypes {
+		field := "rateLimits.messageTypes." + msgType
+		if !clientMessageTypes[msgType] {
+			add(field, "is not a message type clients send")
+			continue
+		}
+		checkLimit(field, rc.MessageTypes[msgType])
+	}
+	if rc.ConnectionsPerIP < 0 {
+		add("rateLimits.connectionsPerIp", "must not be negative")
+	}
+	if rc.AbuseLimit < 1 {
+		add("rateLimits.abuseLimit", "must be at least 1")
+	}
+	if rc.AbuseWindow < 1 {
+		add("rateLimits.abuseWindow", "must be at least 1 second")
+	}
+}
+
+// tokenBucket holds up to burst tokens and gains rate of them each second
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:   limit.PerSecond,
+		burst:  float64(limit.Burst),
+		tokens: float64(limit.Burst),
+		last:   now,
+	}
+}
+
+// refill adds the tokens earned since the bucket was last used
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+	}
+	b.last = now
+}
+
+// wait returns how long until the bucket has a whole token
+func (b *tokenBucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// messageLimiter applies a RateLimitConfig to the messages of one player
+type messageLimiter struct {
+	config  RateLimitConfig
+	overall *tokenBucket
+	types   map[string]*tokenBucket
+	refused []time.Time // When messages were refused, oldest first, within the abuse window
+	mu      sync.Mutex
+}
+
+func newMessageLimiter(config RateLimitConfig, now time.Time) *messageLimiter {
+	return &messageLimiter{
+		config:  config,
+		overall: newTokenBucket(config.Messages, now),
+		types:   make(map[string]*tokenBucket),
+	}
+}
+
+// allow reports whether a message of msgType may be handled now. A refused message reports how long
+// until it would be allowed, and whether the player has been refused often enough to be cut off.
+func (ml *messageLimiter) allow(msgType string, now time.Time) (bool, time.Duration, bool) {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	buckets := []*tokenBucket{ml.overall}
+	if limit, limited := ml.config.MessageTypes[msgType]; limited {
+		bucket, exists := ml.types[msgType]
+		if !exists {
+			bucket = newTokenBucket(limit, now)
+			ml.types[msgType] = bucket
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	// A message is only charged when every bucket it draws from can pay
+	var retryAfter time.Duration
+	for _, bucket := range buckets {
+		bucket.refill(now)
+		if wait := bucket.wait(); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	if retryAfter == 0 {
+		for _, bucket := range buckets {
+			bucket.tokens--
+		}
+		return true, 0, false
+	}
+
+	// Forget refusals that have left the abuse window
+	windowStart := now.Add(-time.Duration(ml.config.AbuseWindow) * time.Second)
+	kept := 0
+	for kept < len(ml.refused) && !ml.refused[kept].After(windowStart) {
+		kept++
+	}
+	ml.refused = append(ml.refused[kept:], now)
+
+	return false, retryAfter, len(ml.refused) >= ml.config.AbuseLimit
+}
+
+// ConnectionLimiter caps the connections open at once from one address. It is shared by every room,
+// so a single machine can't fill the server by spreading its connections across rooms.
+type ConnectionLimiter struct {
+	open  map[string]int // address -> connections open
+	limit int            // 0 for no limit
+	mu    sync.Mutex
+}
+
+// NewConnectionLimiter creates a limiter allowing limit connections per address; 0 for no limit
+func NewConnectionLimiter(limit int) *ConnectionLimiter {
+	return &ConnectionLimiter{
+		open:  make(map[string]int),
+		limit: limit,
+	}
+}
+
+// SetLimit changes the limit for connections opened from now on
+func (cl *ConnectionLimiter) SetLimit(limit int) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.limit = limit
+}
+
+// acquire claims a connection slot for ip, failing when it already has as many as allowed.
+// Every successful acquire must be paired with a release.
+func (cl *ConnectionLimiter) acquire(ip string) error {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if cl.limit > 0 && cl.open[ip] >= cl.limit {
+		return errTooManyConnections
+	}
+	cl.open[ip]++
+	return nil
+}
+
+// release gives back a slot claimed by acquire
+func (cl *ConnectionLimiter) release(ip string) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if cl.open[ip] <= 1 {
+		delete(cl.open, ip)
+	} else {
+		cl.open[ip]--
+	}
+}
+
+// clientIP returns the address a request came from, without its port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// UseRateLimits sets the message limits for the room's players and the connection limiter its
+// endpoints share with other rooms
+func (wsh *WebSocketHandler) UseRateLimits(config RateLimitConfig, connections *ConnectionLimiter) {
+	wsh.rateLimits = config
+	wsh.connections = connections
+}
+
+// allowMessage checks a message against the player's limits. A refused message is answered with a
+// rate_limited error, and a player refused too often is disconnected.
+func (wsh *WebSocketHandler) allowMessage(player *Player, baseMsg BaseMessage) bool {
+	now := wsh.gameManager.clock.Now() // The room's clock, so tests can drive the buckets
+
+	player.mu.Lock()
+	if player.limiter == nil {
+		player.limiter = newMessageLimiter(wsh.rateLimits, now)
+	}
+	limiter := player.limiter
+	player.mu.Unlock()
+
+	allowed, retryAfter, abusive := limiter.allow(baseMsg.Type, now)
+	if allowed {
+		return true
+	}
+
+	if abusive {
+		log.Printf("Disconnecting player %s for flooding the server with messages", player.ID)
+		sendToPlayer(player, MsgError, requestErrorPayload(baseMsg, errMessageFlood))
+		closePlayerConnection(player, websocket.ClosePolicyViolation, constants.ErrMessageFlood)
+		return false
+	}
+
+	refused := requestErrorPayload(baseMsg, errRateLimited)
+	refused.RetryAfterMs = (retryAfter + time.Millisecond - 1).Milliseconds() // Rounded up so retrying on time succeeds
+	sendToPlayer(player, MsgError, refused)
+	return false
+}
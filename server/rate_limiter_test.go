@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageLimiter(t *testing.T) {
+	config := RateLimitConfig{
+		Messages:     RateLimit{PerSecond: 10, Burst: 5},
+		MessageTypes: map[string]RateLimit{MsgFragmentMoveRequest: {PerSecond: 1, Burst: 2}},
+		AbuseLimit:   100,
+		AbuseWindow:  10,
+	}
+	now := time.Now()
+	limiter := newMessageLimiter(config, now)
+
+	t.Run("Limited types draw from their own bucket as well", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			allowed, _, _ := limiter.allow(MsgFragmentMoveRequest, now)
+			assert.True(t, allowed)
+		}
+		allowed, retryAfter, abusive := limiter.allow(MsgFragmentMoveRequest, now)
+		assert.False(t, allowed)
+		assert.Equal(t, time.Second, retryAfter)
+		assert.False(t, abusive)
+
+		// The refused move cost nothing, so other messages still have the rest of the burst
+		for i := 0; i < 3; i++ {
+			allowed, _, _ := limiter.allow(MsgPlayerReady, now)
+			assert.True(t, allowed)
+		}
+		allowed, retryAfter, _ = limiter.allow(MsgPlayerReady, now)
+		assert.False(t, allowed)
+		assert.InDelta(t, 100*time.Millisecond, retryAfter, float64(time.Microsecond))
+	})
+
+	t.Run("Buckets refill over time up to their burst", func(t *testing.T) {
+		later := now.Add(time.Hour)
+		for i := 0; i < 5; i++ {
+			allowed, _, _ := limiter.allow(MsgPlayerReady, later)
+			assert.True(t, allowed)
+		}
+		allowed, _, _ := limiter.allow(MsgPlayerReady, later)
+		assert.False(t, allowed)
+	})
+}
+
+func TestMessageLimiterAbuse(t *testing.T) {
+	config := RateLimitConfig{
+		Messages:    RateLimit{PerSecond: 1, Burst: 1},
+		AbuseLimit:  3,
+		AbuseWindow: 10,
+	}
+	now := time.Now()
+	limiter := newMessageLimiter(config, now)
+
+	allowed, _, _ := limiter.allow(MsgPlayerReady, now)
+	assert.True(t, allowed)
+
+	// Refusals spread out past the window are forgotten
+	for i := 0; i < 4; i++ {
+		at := now.Add(time.Duration(i) * 6 * time.Second)
+		limiter.overall.tokens = -100 // Keep the bucket empty however long we wait
+		_, _, abusive := limiter.allow(MsgPlayerReady, at)
+		assert.False(t, abusive)
+	}
+
+	// Refusals in quick succession are not
+	at := now.Add(time.Minute)
+	var abusive bool
+	for i := 0; i < 3; i++ {
+		limiter.overall.tokens = -100
+		_, _, abusive = limiter.allow(MsgPlayerReady, at)
+	}
+	assert.True(t, abusive)
+}
+
+func TestConnectionLimiter(t *testing.T) {
+	limiter := NewConnectionLimiter(2)
+
+	assert.NoError(t, limiter.acquire("192.0.2.1"))
+	assert.NoError(t, limiter.acquire("192.0.2.1"))
+	assert.EqualError(t, limiter.acquire("192.0.2.1"), constants.ErrTooManyConnections)
+	assert.NoError(t, limiter.acquire("192.0.2.2"))
+
+	limiter.release("192.0.2.1")
+	assert.NoError(t, limiter.acquire("192.0.2.1"))
+
+	limiter.SetLimit(0)
+	assert.NoError(t, limiter.acquire("192.0.2.1"))
+
+	limiter.release("192.0.2.2")
+	assert.NotContains(t, limiter.open, "192.0.2.2")
+}
+
+// newRateLimitTestHandler builds a handler for a room whose game runs on clock
+func newRateLimitTestHandler(clock Clock) *WebSocketHandler {
+	playerManager := NewPlayerManager()
+	gameManager := NewGameManager(playerManager, nil, nil, clock, testSeed)
+	return NewWebSocketHandler(playerManager, gameManager, nil, nil)
+}
+
+func TestRateLimitedMessagesAreRefused(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	wsh := newRateLimitTestHandler(clock)
+	player := &Player{ID: "player-1"}
+	writer := idleTestWriter(t, player)
+
+	for i := 0; i < constants.ResyncRateBurst+1; i++ {
+		wsh.handleClientMessage(player, BaseMessage{Type: MsgPuzzleResyncRequest})
+	}
+
+	// Messages within the limit reach validation; the one after it is refused
+	assert.Len(t, queuedPayloads(t, writer, MsgError), constants.ResyncRateBurst+1)
+	refused := queuedPayloads(t, writer, MsgError)[constants.ResyncRateBurst]
+	assert.Equal(t, string(CodeRateLimited), refused["code"])
+	assert.Equal(t, "rate_limited", refused["type"])
+	assert.Equal(t, constants.ErrRateLimited, refused["error"])
+	assert.Equal(t, MsgPuzzleResyncRequest, refused["requestType"])
+	assert.EqualValues(t, 2000, refused["retryAfterMs"])
+
+	// Buckets refill on the room's clock: still refused a moment early, accepted on time
+	clock.Advance(1999 * time.Millisecond)
+	wsh.handleClientMessage(player, BaseMessage{Type: MsgPuzzleResyncRequest})
+	assert.Equal(t, string(CodeRateLimited), queuedPayloads(t, writer, MsgError)[constants.ResyncRateBurst+1]["code"])
+
+	clock.Advance(time.Millisecond)
+	wsh.handleClientMessage(player, BaseMessage{Type: MsgPuzzleResyncRequest})
+	assert.NotEqual(t, string(CodeRateLimited), queuedPayloads(t, writer, MsgError)[constants.ResyncRateBurst+2]["code"])
+}
+
+func TestMessageFloodDisconnects(t *testing.T) {
+	wsh := newRateLimitTestHandler(NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)))
+	wsh.UseRateLimits(RateLimitConfig{
+		Messages:    RateLimit{PerSecond: 0.001, Burst: 1},
+		AbuseLimit:  3,
+		AbuseWindow: 10,
+	}, wsh.connections)
+
+	conn, client := dialTestConnection(t)
+	player := &Player{ID: "player-1", Connection: conn}
+	startPlayerWriter(player, newWebSocketTransport(conn))
+
+	for i := 0; i < 4; i++ {
+		wsh.handleClientMessage(player, BaseMessage{Type: MsgPlayerReady})
+	}
+
+	// Everything queued arrives, then the close
+	var lastError map[string]interface{}
+	for {
+		client.SetReadDeadline(time.Now().Add(time.Second))
+		var msg BaseMessage
+		err := client.ReadJSON(&msg)
+		if err != nil {
+			assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "unexpected error: %v", err)
+			break
+		}
+		assert.NoError(t, json.Unmarshal(msg.Payload, &lastError))
+	}
+	assert.Equal(t, string(CodeMessageFlood), lastError["code"])
+	assert.Equal(t, constants.ErrMessageFlood, lastError["error"])
+}
+
+func TestConnectionsPerIPLimit(t *testing.T) {
+	wsh := NewWebSocketHandler(NewPlayerManager(), nil, nil, nil)
+	wsh.UseRateLimits(DefaultRateLimitConfig(), NewConnectionLimiter(1))
+	assert.NoError(t, wsh.connections.acquire("192.0.2.1"))
+
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.RemoteAddr = "192.0.2.1:40000"
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("User-Agent", "test")
+	rec := httptest.NewRecorder()
+
+	wsh.HandleConnection(rec, req, false)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Contains(t, rec.Body.String(), constants.ErrTooManyConnections)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+)
+
+// A client may tag any message with a requestId. The server then answers that message with exactly
+// one ack on success or one error (the nack) on failure, both carrying the requestId back. Outcomes
+// are remembered per player, so a message retried with the same requestId - after a reconnect, say -
+// gets its original answer again instead of being handled twice.
+
+// AckPayload is the payload of an ack, sent when a message with a requestId succeeds
+type AckPayload struct {
+	RequestID   string `json:"requestId"`
+	RequestType string `json:"requestType"`
+	Duplicate   bool   `json:"duplicate,omitempty"` // The message was a retry and was not handled again
+}
+
+// answeredError is a failure a handler has already told the player about in a reply of its own,
+// such as a denied fragment_move_response. A tagged message is still nacked with it, while an
+// untagged one gets no error message on top of the reply.
+type answeredError struct {
+	err error
+}
+
+func (e *answeredError) Error() string {
+	return e.err.Error()
+}
+
+func (e *answeredError) Unwrap() error {
+	return e.err
+}
+
+// alreadyAnswered marks err as reported to the player
+func alreadyAnswered(err error) error {
+	return &answeredError{err: err}
+}
+
+// isAnswered reports whether err, or an error it wraps, was marked by alreadyAnswered
+func isAnswered(err error) bool {
+	var answered *answeredError
+	return errors.As(err, &answered)
+}
+
+// requestKey identifies a message a player tagged with a requestId
+type requestKey struct {
+	msgType   string
+	requestID string
+}
+
+// requestOutcome is how a tagged message was answered
+type requestOutcome struct {
+	nack *ErrorPayload // nil when the message succeeded
+	done chan struct{} // Closed once the message has been handled
+}
+
+// requestLog remembers the outcomes of a player's most recent tagged messages
+type requestLog struct {
+	outcomes map[requestKey]*requestOutcome
+	order    []requestKey // Oldest first, so the log can forget the oldest outcome when full
+	mu       sync.Mutex
+}
+
+func newRequestLog() *requestLog {
+	return &requestLog{
+		outcomes: make(map[requestKey]*requestOutcome),
+		order:    make([]requestKey, 0, constants.RecentRequestsPerPlayer),
+	}
+}
+
+// begin claims key for a message about to be handled. When the key was already claimed it returns
+// the earlier outcome instead, which may still be in progress until its done channel closes.
+func (rl *requestLog) begin(key requestKey) (*requestOutcome, bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if outcome, seen := rl.outcomes[key]; seen {
+		return outcome, false
+	}
+
+	if len(rl.order) >= constants.RecentRequestsPerPlayer {
+		delete(rl.outcomes, rl.order[0])
+		rl.order = rl.order[1:]
+	}
+	outcome := &requestOutcome{done: make(chan struct{})}
+	rl.outcomes[key] = outcome
+	rl.order = append(rl.order, key)
+	return outcome, true
+}
+
+// forget drops key, so a retry of the message is handled afresh
+func (rl *requestLog) forget(key requestKey) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	delete(rl.outcomes, key)
+	for i, k := range rl.order {
+		if k == key {
+			rl.order = append(rl.order[:i], rl.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// handleTrackedMessage handles a message tagged with a requestId, answering it with an ack or a
+// nack, or with the earlier answer when the requestId has been seen before
+func (wsh *WebSocketHandler) handleTrackedMessage(player *Player, baseMsg BaseMessage) {
+	player.mu.Lock()
+	if player.requests == nil {
+		player.requests = newRequestLog()
+	}
+	requests := player.requests
+	player.mu.Unlock()
+
+	key := requestKey{msgType: baseMsg.Type, requestID: baseMsg.RequestID}
+	outcome, first := requests.begin(key)
+	if !first {
+		<-outcome.done
+		if outcome.nack != nil {
+			nack := *outcome.nack
+			nack.Duplicate = true
+			sendToPlayer(player, MsgError, nack)
+		} else {
+			sendToPlayer(player, MsgAck, AckPayload{RequestID: baseMsg.RequestID, RequestType: baseMsg.Type, Duplicate: true})
+		}
+		return
+	}
+
+	err := wsh.handleAuthenticatedMessage(player, baseMsg)
+	if err != nil {
+		nack := requestErrorPayload(baseMsg, err)
+		outcome.nack = &nack
+	}
+	close(outcome.done)
+
+	// A message that failed authentication was never handled, so retrying it with a fresh token must work
+	if err != nil && errorCodeOf(err) == CodeAuthenticationFailed {
+		requests.forget(key)
+	}
+
+	if err != nil {
+		wsh.sendRequestError(player, baseMsg, err)
+		return
+	}
+	sendToPlayer(player, MsgAck, AckPayload{RequestID: baseMsg.RequestID, RequestType: baseMsg.Type})
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestLogForgetsOldestOutcome(t *testing.T) {
+	rl := newRequestLog()
+
+	for i := 0; i < constants.RecentRequestsPerPlayer; i++ {
+		_, first := rl.begin(requestKey{msgType: MsgPlayerReady, requestID: string(rune('a' + i))})
+		assert.True(t, first)
+	}
+	_, first := rl.begin(requestKey{msgType: MsgPlayerReady, requestID: "a"})
+	assert.False(t, first)
+
+	// A full log makes room by forgetting the oldest requestId
+	_, first = rl.begin(requestKey{msgType: MsgPlayerReady, requestID: "newest"})
+	assert.True(t, first)
+	assert.Len(t, rl.outcomes, constants.RecentRequestsPerPlayer)
+	_, first = rl.begin(requestKey{msgType: MsgPlayerReady, requestID: "a"})
+	assert.True(t, first)
+
+	// The same requestId on another message type is a different request
+	_, first = rl.begin(requestKey{msgType: MsgTriviaAnswer, requestID: "newest"})
+	assert.True(t, first)
+}
+
+func TestTaggedMessagesAreAcknowledged(t *testing.T) {
+	// Authentication wants real player IDs, so the players are made here rather than by startFakeClockPuzzle
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	gm, pm, tm, broadcastChan := createSeededTestGameManager(clock, testSeed)
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	player := pm.CreatePlayer(nil, false)
+	other := pm.CreatePlayer(nil, false)
+	gm.startPuzzlePhase()
+
+	eh := NewEventHandlers(gm, pm, broadcastChan)
+	wsh := NewWebSocketHandler(pm, gm, eh, broadcastChan)
+	writer := idleTestWriter(t, player)
+	token := wsh.sessionTokens.Issue(player.ID)
+	move := func(requestID string, to GridPos) {
+		wsh.handleClientMessage(player, BaseMessage{
+			Type:      MsgFragmentMoveRequest,
+			RequestID: requestID,
+			Payload: mustMarshal(AuthWrapper{
+				Auth:    AuthData{PlayerID: player.ID, Token: token},
+				Payload: mustMarshal(map[string]interface{}{"fragmentId": "fragment_" + player.ID, "newPosition": to, "timestamp": clock.Now().Unix()}),
+			}),
+		})
+	}
+
+	assert.NoError(t, gm.ProcessSegmentCompleted(player.ID, "segment-1"))
+	target := fragmentOf(gm, other.ID).Position
+	start := fragmentOf(gm, player.ID).Position
+
+	move("move-1", target)
+	assert.Equal(t, target, fragmentOf(gm, player.ID).Position)
+
+	// A retry is answered again without moving the fragment a second time
+	move("move-1", start)
+	assert.Equal(t, target, fragmentOf(gm, player.ID).Position)
+
+	// A new request inside the cooldown is refused, and so is its retry
+	move("move-2", start)
+	move("move-2", start)
+	assert.Equal(t, target, fragmentOf(gm, player.ID).Position)
+
+	acks := queuedPayloads(t, writer, MsgAck)
+	if assert.Len(t, acks, 2) {
+		assert.Equal(t, map[string]interface{}{"requestId": "move-1", "requestType": MsgFragmentMoveRequest}, acks[0])
+		assert.Equal(t, true, acks[1]["duplicate"])
+		assert.Equal(t, "move-1", acks[1]["requestId"])
+	}
+
+	nacks := queuedPayloads(t, writer, MsgError)
+	if assert.Len(t, nacks, 2) {
+		assert.Equal(t, string(CodeFragmentCooldown), nacks[0]["code"])
+		assert.Equal(t, "move-2", nacks[0]["requestId"])
+		assert.NotContains(t, nacks[0], "duplicate")
+		assert.Equal(t, string(CodeFragmentCooldown), nacks[1]["code"])
+		assert.Equal(t, true, nacks[1]["duplicate"])
+	}
+
+	// Only the two messages that were handled got a fragment_move_response
+	assert.Len(t, queuedPayloads(t, writer, MsgFragmentMoveResponse), 2)
+}
+
+func TestUntaggedDeniedMoveGetsOneReply(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	gm, pm, tm, broadcastChan := createSeededTestGameManager(clock, testSeed)
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	player := pm.CreatePlayer(nil, false)
+	other := pm.CreatePlayer(nil, false)
+	gm.startPuzzlePhase()
+
+	eh := NewEventHandlers(gm, pm, broadcastChan)
+	wsh := NewWebSocketHandler(pm, gm, eh, broadcastChan)
+	writer := idleTestWriter(t, player)
+	token := wsh.sessionTokens.Issue(player.ID)
+	move := func(fragmentID string, to GridPos) {
+		wsh.handleClientMessage(player, BaseMessage{
+			Type: MsgFragmentMoveRequest,
+			Payload: mustMarshal(AuthWrapper{
+				Auth:    AuthData{PlayerID: player.ID, Token: token},
+				Payload: mustMarshal(map[string]interface{}{"fragmentId": fragmentID, "newPosition": to, "timestamp": clock.Now().Unix()}),
+			}),
+		})
+	}
+
+	assert.NoError(t, gm.ProcessSegmentCompleted(player.ID, "segment-1"))
+	target := fragmentOf(gm, other.ID).Position
+	start := fragmentOf(gm, player.ID).Position
+
+	move("fragment_"+player.ID, target)
+	move("fragment_"+player.ID, start) // Inside the cooldown
+	move("fragment_"+other.ID, start)  // Someone else's fragment
+
+	responses := queuedPayloads(t, writer, MsgFragmentMoveResponse)
+	if assert.Len(t, responses, 3) {
+		assert.Equal(t, "ignored", responses[1]["status"])
+		assert.Equal(t, string(CodeFragmentCooldown), responses[1]["code"])
+		assert.Equal(t, "denied", responses[2]["status"])
+	}
+	assert.Empty(t, queuedPayloads(t, writer, MsgError))
+}
+
+func TestFailedAuthenticationIsNotRemembered(t *testing.T) {
+	gm, pm, tm, broadcastChan := createTestGameManager()
+	defer cleanupTestGameManager(tm)
+	defer gm.Stop()
+
+	eh := NewEventHandlers(gm, pm, broadcastChan)
+	wsh := NewWebSocketHandler(pm, gm, eh, broadcastChan)
+	player := pm.CreatePlayer(nil, false)
+	writer := idleTestWriter(t, player)
+
+	// Sent without a token, then retried once the client has one
+	wsh.handleClientMessage(player, BaseMessage{
+		Type:      MsgPlayerSetName,
+		RequestID: "name-1",
+		Payload:   CreateAuthWrapper(player.ID, map[string]string{"name": "Ada"}),
+	})
+	token := wsh.sessionTokens.Issue(player.ID)
+	wsh.handleClientMessage(player, BaseMessage{
+		Type:      MsgPlayerSetName,
+		RequestID: "name-1",
+		Payload: mustMarshal(AuthWrapper{
+			Auth:    AuthData{PlayerID: player.ID, Token: token},
+			Payload: mustMarshal(map[string]string{"name": "Ada"}),
+		}),
+	})
+
+	nacks := queuedPayloads(t, writer, MsgError)
+	if assert.Len(t, nacks, 1) {
+		assert.Equal(t, string(CodeAuthenticationFailed), nacks[0]["code"])
+	}
+	acks := queuedPayloads(t, writer, MsgAck)
+	if assert.Len(t, acks, 1) {
+		assert.NotContains(t, acks[0], "duplicate")
+	}
+	assert.Equal(t, "Ada", player.Name)
+}
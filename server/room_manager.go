@@ -0,0 +1,488 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// Room is an isolated game instance with its own state, host endpoint and broadcaster
+type Room struct {
+	Code           string
+	HostEndpointID string
+	CreatedAt      time.Time
+	IsDefault      bool
+
+	broadcastChan chan BroadcastMessage
+	playerManager *PlayerManager
+	gameManager   *GameManager
+	eventHandlers *EventHandlers
+	wsHandler     *WebSocketHandler
+
+	idleSince time.Time
+	closeOnce sync.Once
+	mu        sync.RWMutex
+}
+
+// RoomManager is the registry of all active rooms on this server
+type RoomManager struct {
+	rooms         map[string]*Room
+	releasedCodes map[string]time.Time // join code -> when its room closed
+	triviaManager *TriviaManager
+	balance       *BalanceConfig     // Game balance every room plays with
+	nameFilter    *NameFilter        // Checks the names players choose in every room
+	sessionTokens *SessionTokens     // Signs player session tokens; shared so restored rooms accept old ones
+	connections   *ConnectionLimiter // Caps connections per address across every room
+	snapshotStore *SnapshotStore     // nil disables crash recovery snapshots
+	gameStore     GameStore          // nil disables finished game history
+	eventLogDir   string             // empty disables per-room event logs
+	shutdownChan  chan struct{}
+	shutdownOnce  sync.Once
+	mu            sync.RWMutex
+}
+
+// NewRoomManager creates a room registry that shares a single trivia manager across rooms.
+// snapshotStore and gameStore may be nil and eventLogDir empty to run without crash recovery,
+// game history or event logs.
+func NewRoomManager(triviaManager *TriviaManager, snapshotStore *SnapshotStore, gameStore GameStore, eventLogDir string) *RoomManager {
+	rm := &RoomManager{
+		rooms:         make(map[string]*Room),
+		releasedCodes: make(map[string]time.Time),
+		triviaManager: triviaManager,
+		balance:       DefaultBalanceConfig(),
+		nameFilter:    DefaultNameFilter(),
+		sessionTokens: NewRandomSessionTokens(),
+		connections:   NewConnectionLimiter(constants.MaxConnectionsPerIP),
+		snapshotStore: snapshotStore,
+		gameStore:     gameStore,
+		eventLogDir:   eventLogDir,
+		shutdownChan:  make(chan struct{}),
+	}
+
+	// Start cleanup routine for abandoned rooms
+	go rm.cleanupIdleRooms()
+
+	return rm
+}
+
+// CreateRoom builds a new isolated game instance and registers it
+func (rm *RoomManager) CreateRoom() (*Room, error) {
+	return rm.createRoom(false)
+}
+
+// CreateDefaultRoom builds the room served by the legacy /ws and /ws/host endpoints.
+// The default room is never torn down by the idle cleanup routine.
+func (rm *RoomManager) CreateDefaultRoom() (*Room, error) {
+	return rm.createRoom(true)
+}
+
+func (rm *RoomManager) createRoom(isDefault bool) (*Room, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if len(rm.rooms) >= constants.MaxRooms {
+		return nil, fmt.Errorf("room limit reached (%d/%d)", len(rm.rooms), constants.MaxRooms)
+	}
+
+	code, err := rm.generateRoomCode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate join code: %v", err)
+	}
+
+	return rm.createRoomInternal(RoomIdentity{
+		Code:           code,
+		HostEndpointID: uuid.New().String(),
+		IsDefault:      isDefault,
+	}), nil
+}
+
+// createRoomInternal builds and registers a room with a known identity
+// NOTE: This method assumes the caller already holds rm.mu lock
+func (rm *RoomManager) createRoomInternal(identity RoomIdentity) *Room {
+	broadcastChan := make(chan BroadcastMessage, constants.BroadcastChannelBuffer)
+	playerManager := NewPlayerManager()
+	gameManager := NewGameManager(playerManager, rm.triviaManager, broadcastChan, realClock{}, time.Now().UnixNano())
+	eventHandlers := NewEventHandlers(gameManager, playerManager, broadcastChan)
+	wsHandler := NewWebSocketHandler(playerManager, gameManager, eventHandlers, broadcastChan)
+
+	gameManager.UseBalance(rm.balance)
+	playerManager.UseNameFilter(rm.nameFilter)
+	wsHandler.UseSessionTokens(rm.sessionTokens)
+	wsHandler.UseRateLimits(rm.balance.RateLimits, rm.connections)
+	if rm.snapshotStore != nil {
+		gameManager.EnableSnapshots(rm.snapshotStore, identity)
+	}
+	if rm.gameStore != nil {
+		gameManager.EnableGameHistory(rm.gameStore, identity.Code)
+	}
+	if rm.eventLogDir != "" {
+		// A room without its event log still works, so a failure here is only logged
+		if eventLog, err := OpenEventLogFile(rm.eventLogDir, identity.Code, gameManager.clock); err != nil {
+			log.Printf("Event log disabled for room %s: %v", identity.Code, err)
+		} else {
+			gameManager.EnableEventLog(eventLog, identity.Code)
+		}
+	}
+
+	room := &Room{
+		Code:           identity.Code,
+		HostEndpointID: identity.HostEndpointID,
+		CreatedAt:      time.Now(),
+		IsDefault:      identity.IsDefault,
+		broadcastChan:  broadcastChan,
+		playerManager:  playerManager,
+		gameManager:    gameManager,
+		eventHandlers:  eventHandlers,
+		wsHandler:      wsHandler,
+	}
+
+	// Each room runs its own broadcaster so filters only ever see that room's players
+	wsHandler.StartBroadcaster()
+
+	rm.rooms[room.Code] = room
+	log.Printf("Created room %s (default: %v)", room.Code, room.IsDefault)
+
+	return room
+}
+
+// RestoreRooms recreates every room with a saved snapshot and resumes its game.
+// Rooms keep their join code and host endpoint so existing clients can reconnect.
+func (rm *RoomManager) RestoreRooms() ([]*Room, error) {
+	if rm.snapshotStore == nil {
+		return nil, nil
+	}
+
+	snapshots, err := rm.snapshotStore.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	restored := make([]*Room, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		room, err := rm.restoreRoom(snapshot)
+		if err != nil {
+			log.Printf("Could not restore room %s: %v", snapshot.Room.Code, err)
+			rm.snapshotStore.Delete(snapshot.Room.Code)
+			continue
+		}
+		restored = append(restored, room)
+	}
+
+	return restored, nil
+}
+
+func (rm *RoomManager) restoreRoom(snapshot *GameSnapshot) (*Room, error) {
+	if !isResumablePhase(snapshot.Phase) {
+		return nil, errSnapshotNotResumable
+	}
+
+	rm.mu.Lock()
+	if len(rm.rooms) >= constants.MaxRooms {
+		rm.mu.Unlock()
+		return nil, fmt.Errorf("room limit reached (%d/%d)", len(rm.rooms), constants.MaxRooms)
+	}
+	if _, exists := rm.rooms[snapshot.Room.Code]; exists {
+		rm.mu.Unlock()
+		return nil, fmt.Errorf("room code already in use")
+	}
+	if snapshot.Room.IsDefault && rm.getDefaultRoomInternal() != nil {
+		rm.mu.Unlock()
+		return nil, fmt.Errorf("default room already exists")
+	}
+	room := rm.createRoomInternal(snapshot.Room)
+	rm.mu.Unlock()
+
+	if err := room.gameManager.RestoreFromSnapshot(snapshot); err != nil {
+		rm.mu.Lock()
+		delete(rm.rooms, room.Code)
+		rm.mu.Unlock()
+		room.Close(CodeRoomClosed, "Room could not be restored")
+		return nil, err
+	}
+
+	return room, nil
+}
+
+// GetDefaultRoom returns the room behind the legacy endpoints, or nil if it hasn't been created
+func (rm *RoomManager) GetDefaultRoom() *Room {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	return rm.getDefaultRoomInternal()
+}
+
+// getDefaultRoomInternal finds the default room
+// NOTE: This method assumes the caller already holds rm.mu lock
+func (rm *RoomManager) getDefaultRoomInternal() *Room {
+	for _, room := range rm.rooms {
+		if room.IsDefault {
+			return room
+		}
+	}
+	return nil
+}
+
+// generateRoomCode returns a join code that is not used by any active room and was
+// not released recently, so a stale code can never land players in someone else's game.
+// NOTE: This method assumes the caller already holds rm.mu lock
+func (rm *RoomManager) generateRoomCode() (string, error) {
+	for {
+		code, err := generateJoinCode()
+		if err != nil {
+			return "", err
+		}
+		if _, exists := rm.rooms[code]; exists {
+			continue
+		}
+		if releasedAt, held := rm.releasedCodes[code]; held && time.Since(releasedAt) < constants.JoinCodeReuseCooldown {
+			continue
+		}
+		delete(rm.releasedCodes, code)
+		return code, nil
+	}
+}
+
+// GetRoom retrieves a room by its code
+func (rm *RoomManager) GetRoom(code string) (*Room, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	room, exists := rm.rooms[normalizeJoinCode(code)]
+	if !exists {
+		return nil, errRoomNotFound
+	}
+
+	return room, nil
+}
+
+// GetAllRooms returns all active rooms
+func (rm *RoomManager) GetAllRooms() []*Room {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	rooms := make([]*Room, 0, len(rm.rooms))
+	for _, room := range rm.rooms {
+		rooms = append(rooms, room)
+	}
+
+	return rooms
+}
+
+// GetRoomCount returns the number of active rooms
+func (rm *RoomManager) GetRoomCount() int {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	return len(rm.rooms)
+}
+
+// CloseRoom tears down a room, disconnecting its players and stopping its game loops
+func (rm *RoomManager) CloseRoom(code string) error {
+	rm.mu.Lock()
+	room, exists := rm.rooms[normalizeJoinCode(code)]
+	if exists {
+		delete(rm.rooms, room.Code)
+		rm.releasedCodes[room.Code] = time.Now()
+	}
+	rm.mu.Unlock()
+
+	if !exists {
+		return errRoomNotFound
+	}
+
+	room.Close(CodeRoomClosed, "This game room has been closed")
+	room.gameManager.DiscardSnapshot()
+	log.Printf("Closed room %s", room.Code)
+
+	return nil
+}
+
+// Shutdown closes every room and stops the cleanup routine
+func (rm *RoomManager) Shutdown() {
+	rm.shutdownOnce.Do(func() {
+		close(rm.shutdownChan)
+	})
+
+	for _, room := range rm.GetAllRooms() {
+		room.Close(CodeServerShutdown, "Server shutting down for maintenance")
+
+		// Keep running games on disk so they can be resumed with -restore
+		room.gameManager.FlushSnapshot()
+	}
+}
+
+// GetAggregateStats returns player counts summed across all rooms
+func (rm *RoomManager) GetAggregateStats() map[string]interface{} {
+	rooms := rm.GetAllRooms()
+
+	totalPlayers := 0
+	connectedPlayers := 0
+	readyPlayers := 0
+	activeGames := 0
+	phaseCounts := make(map[string]int)
+
+	for _, room := range rooms {
+		totalPlayers += room.playerManager.GetPlayerCount()
+		connectedPlayers += room.playerManager.GetConnectedCount()
+		readyPlayers += room.playerManager.GetReadyCount()
+
+		phase := room.gameManager.GetPhase()
+		phaseCounts[phase.String()]++
+		if phase == PhaseResourceGathering || phase == PhasePuzzleAssembly {
+			activeGames++
+		}
+	}
+
+	return map[string]interface{}{
+		"rooms":       len(rooms),
+		"maxRooms":    constants.MaxRooms,
+		"activeGames": activeGames,
+		"phases":      phaseCounts,
+		"players": map[string]int{
+			"total":     totalPlayers,
+			"connected": connectedPlayers,
+			"ready":     readyPlayers,
+		},
+	}
+}
+
+// cleanupIdleRooms removes non-default rooms that have had no connected players for too long
+func (rm *RoomManager) cleanupIdleRooms() {
+	ticker := time.NewTicker(constants.RoomCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			rm.pruneReleasedCodes(time.Now())
+			for _, room := range rm.GetAllRooms() {
+				if room.IsDefault {
+					continue
+				}
+				if room.checkIdle(time.Now()) {
+					log.Printf("Room %s idle for over %v - tearing down", room.Code, constants.RoomIdleTimeout)
+					rm.CloseRoom(room.Code)
+				}
+			}
+		case <-rm.shutdownChan:
+			log.Println("Room manager cleanup routine shutting down")
+			return
+		}
+	}
+}
+
+// pruneReleasedCodes forgets released join codes whose reuse cooldown has passed
+func (rm *RoomManager) pruneReleasedCodes(now time.Time) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	for code, releasedAt := range rm.releasedCodes {
+		if now.Sub(releasedAt) >= constants.JoinCodeReuseCooldown {
+			delete(rm.releasedCodes, code)
+		}
+	}
+}
+
+// checkIdle records when the room became empty and reports whether it has been empty past the timeout
+func (r *Room) checkIdle(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.playerManager.GetConnectedCount() > 0 {
+		r.idleSince = time.Time{}
+		return false
+	}
+
+	if r.idleSince.IsZero() {
+		r.idleSince = now
+		return false
+	}
+
+	return now.Sub(r.idleSince) >= constants.RoomIdleTimeout
+}
+
+// Close stops the room's game loops and broadcaster and disconnects every player
+func (r *Room) Close(code ErrorCode, message string) {
+	r.closeOnce.Do(func() {
+		r.gameManager.Stop()
+		r.wsHandler.StopBroadcaster()
+		r.gameManager.WaitForSaves()
+
+		for _, player := range r.playerManager.GetAllPlayers() {
+			sendToPlayer(player, MsgError, ErrorPayload{
+				Code:  code,
+				Error: message,
+				Type:  errorTypeOf(code),
+			})
+			closePlayerConnection(player, websocket.CloseGoingAway, message)
+		}
+
+		r.gameManager.eventLog.Close()
+	})
+}
+
+// PlayerEndpoint returns the WebSocket path players use to join this room
+func (r *Room) PlayerEndpoint() string {
+	return "/ws/rooms/" + r.Code
+}
+
+// HostEndpoint returns the secret WebSocket path for this room's host
+func (r *Room) HostEndpoint() string {
+	return "/ws/rooms/" + r.Code + "/host/" + r.HostEndpointID
+}
+
+// GetJoinInfo resolves the room's join code to its current phase and whether new players may join
+func (r *Room) GetJoinInfo() map[string]interface{} {
+	joinable, reason := r.wsHandler.joinAvailability()
+
+	info := map[string]interface{}{
+		"roomCode":       r.Code,
+		"phase":          r.gameManager.GetPhase().String(),
+		"joinable":       joinable,
+		"playerEndpoint": r.PlayerEndpoint(),
+		"hasHost":        r.playerManager.IsHostConnected(),
+		"players": map[string]int{
+			"connected": len(r.playerManager.GetConnectedNonHostPlayers()),
+			"max":       constants.MaxPlayers,
+		},
+	}
+	if !joinable {
+		info["reason"] = reason
+	}
+
+	return info
+}
+
+// GetStats returns a per-room status summary for the health and stats endpoints
+func (r *Room) GetStats() map[string]interface{} {
+	gm := r.gameManager
+	pm := r.playerManager
+
+	gm.mu.RLock()
+	stats := map[string]interface{}{
+		"code":       r.Code,
+		"default":    r.IsDefault,
+		"createdAt":  r.CreatedAt.Unix(),
+		"phase":      gm.state.Phase.String(),
+		"difficulty": gm.state.Difficulty,
+		"round":      gm.state.CurrentRound,
+		"teamTokens": gm.state.TeamTokens,
+		"gridSize":   gm.state.GridSize,
+	}
+	gm.mu.RUnlock()
+
+	stats["players"] = map[string]interface{}{
+		"total":     pm.GetPlayerCount(),
+		"connected": pm.GetConnectedCount(),
+		"ready":     pm.GetReadyCount(),
+		"roles":     pm.GetRoleDistribution(),
+		"hasHost":   pm.GetHost() != nil,
+	}
+
+	return stats
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestRoomManager() (*RoomManager, *TriviaManager) {
+	triviaMgr := NewTriviaManager(realClock{}, testSeed)
+	roomMgr := NewRoomManager(triviaMgr, nil, nil, "")
+	return roomMgr, triviaMgr
+}
+
+func TestNewRoomManager(t *testing.T) {
+	rm, tm := createTestRoomManager()
+	defer tm.Shutdown()
+	defer rm.Shutdown()
+
+	assert.NotNil(t, rm)
+	assert.Equal(t, 0, rm.GetRoomCount())
+	assert.Empty(t, rm.GetAllRooms())
+}
+
+func TestCreateAndGetRoom(t *testing.T) {
+	rm, tm := createTestRoomManager()
+	defer tm.Shutdown()
+	defer rm.Shutdown()
+
+	room, err := rm.CreateRoom()
+	assert.NoError(t, err)
+	assert.NotNil(t, room)
+	assert.NotEmpty(t, room.Code)
+	assert.NotEmpty(t, room.HostEndpointID)
+	assert.False(t, room.IsDefault)
+	assert.Equal(t, "/ws/rooms/"+room.Code, room.PlayerEndpoint())
+	assert.Equal(t, "/ws/rooms/"+room.Code+"/host/"+room.HostEndpointID, room.HostEndpoint())
+
+	// Lookup by code is case-insensitive and ignores separators
+	found, err := rm.GetRoom(strings.ToLower(room.Code[:2] + "-" + room.Code[2:]))
+	assert.NoError(t, err)
+	assert.Same(t, room, found)
+
+	_, err = rm.GetRoom("missing")
+	assert.EqualError(t, err, constants.ErrRoomNotFound)
+}
+
+func TestRoomsAreIsolated(t *testing.T) {
+	rm, tm := createTestRoomManager()
+	defer tm.Shutdown()
+	defer rm.Shutdown()
+
+	roomA, err := rm.CreateRoom()
+	assert.NoError(t, err)
+	roomB, err := rm.CreateRoom()
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, roomA.Code, roomB.Code)
+	assert.NotEqual(t, roomA.HostEndpointID, roomB.HostEndpointID)
+	assert.NotSame(t, roomA.gameManager, roomB.gameManager)
+	assert.NotSame(t, roomA.playerManager, roomB.playerManager)
+
+	// Players and state changes in one room must not leak into another
+	roomA.playerManager.CreatePlayer(nil, true)
+	roomA.playerManager.CreatePlayer(nil, false)
+	SimulateGamePhase(roomA.gameManager, PhaseResourceGathering)
+
+	assert.Equal(t, 2, roomA.playerManager.GetPlayerCount())
+	assert.Equal(t, 0, roomB.playerManager.GetPlayerCount())
+	assert.Equal(t, PhaseResourceGathering, roomA.gameManager.GetPhase())
+	assert.Equal(t, PhaseSetup, roomB.gameManager.GetPhase())
+}
+
+func TestCloseRoom(t *testing.T) {
+	rm, tm := createTestRoomManager()
+	defer tm.Shutdown()
+	defer rm.Shutdown()
+
+	room, err := rm.CreateRoom()
+	assert.NoError(t, err)
+	room.playerManager.CreatePlayer(nil, false)
+
+	assert.NoError(t, rm.CloseRoom(room.Code))
+	assert.Equal(t, 0, rm.GetRoomCount())
+
+	_, err = rm.GetRoom(room.Code)
+	assert.Error(t, err)
+
+	// Closing twice reports not found and does not panic
+	assert.Error(t, rm.CloseRoom(room.Code))
+	assert.NotPanics(t, func() {
+		room.Close(CodeRoomClosed, "closed again")
+	})
+
+	// Late broadcasts from game timers must not panic after teardown
+	assert.NotPanics(t, func() {
+		room.broadcastChan <- BroadcastMessage{Type: MsgHostUpdate}
+	})
+}
+
+func TestReleasedJoinCodesAreNotReused(t *testing.T) {
+	rm, tm := createTestRoomManager()
+	defer tm.Shutdown()
+	defer rm.Shutdown()
+
+	room, err := rm.CreateRoom()
+	assert.NoError(t, err)
+	assert.Nil(t, validateJoinCode(room.Code))
+	assert.NoError(t, rm.CloseRoom(room.Code))
+
+	rm.mu.RLock()
+	releasedAt, held := rm.releasedCodes[room.Code]
+	rm.mu.RUnlock()
+	assert.True(t, held)
+
+	// Codes stay reserved for the whole cooldown, then become available again
+	rm.pruneReleasedCodes(releasedAt.Add(constants.JoinCodeReuseCooldown / 2))
+	rm.mu.RLock()
+	_, held = rm.releasedCodes[room.Code]
+	rm.mu.RUnlock()
+	assert.True(t, held)
+
+	rm.pruneReleasedCodes(releasedAt.Add(constants.JoinCodeReuseCooldown))
+	rm.mu.RLock()
+	_, held = rm.releasedCodes[room.Code]
+	rm.mu.RUnlock()
+	assert.False(t, held)
+}
+
+func TestRoomLimit(t *testing.T) {
+	rm, tm := createTestRoomManager()
+	defer tm.Shutdown()
+	defer rm.Shutdown()
+
+	for i := 0; i < constants.MaxRooms; i++ {
+		_, err := rm.CreateRoom()
+		assert.NoError(t, err)
+	}
+
+	_, err := rm.CreateRoom()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "room limit reached")
+}
+
+func TestRoomIdleDetection(t *testing.T) {
+	rm, tm := createTestRoomManager()
+	defer tm.Shutdown()
+	defer rm.Shutdown()
+
+	room, err := rm.CreateRoom()
+	assert.NoError(t, err)
+
+	now := time.Now()
+
+	// First empty check only starts the idle clock
+	assert.False(t, room.checkIdle(now))
+	assert.False(t, room.checkIdle(now.Add(constants.RoomIdleTimeout/2)))
+	assert.True(t, room.checkIdle(now.Add(constants.RoomIdleTimeout)))
+
+	// A connected player resets the idle clock
+	room.playerManager.CreatePlayer(nil, false)
+	assert.False(t, room.checkIdle(now.Add(2*constants.RoomIdleTimeout)))
+	assert.True(t, room.idleSince.IsZero())
+}
+
+func TestRoomStats(t *testing.T) {
+	rm, tm := createTestRoomManager()
+	defer tm.Shutdown()
+	defer rm.Shutdown()
+
+	defaultRoom, err := rm.CreateDefaultRoom()
+	assert.NoError(t, err)
+	assert.True(t, defaultRoom.IsDefault)
+
+	room, err := rm.CreateRoom()
+	assert.NoError(t, err)
+
+	defaultRoom.playerManager.CreatePlayer(nil, true)
+	defaultRoom.playerManager.CreatePlayer(nil, false)
+	room.playerManager.CreatePlayer(nil, false)
+	SimulateGamePhase(room.gameManager, PhasePuzzleAssembly)
+
+	stats := room.GetStats()
+	assert.Equal(t, room.Code, stats["code"])
+	assert.Equal(t, "puzzle_assembly", stats["phase"])
+
+	aggregate := rm.GetAggregateStats()
+	assert.Equal(t, 2, aggregate["rooms"])
+	assert.Equal(t, 1, aggregate["activeGames"])
+	assert.Equal(t, 3, aggregate["players"].(map[string]int)["total"])
+	assert.Equal(t, 1, aggregate["phases"].(map[string]int)["setup"])
+}
+
+func TestConcurrentRoomCreation(t *testing.T) {
+	rm, tm := createTestRoomManager()
+	defer tm.Shutdown()
+	defer rm.Shutdown()
+
+	var wg sync.WaitGroup
+	codes := sync.Map{}
+	errors := make(chan error, 20)
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			room, err := rm.CreateRoom()
+			if err != nil {
+				errors <- err
+				return
+			}
+			if _, dup := codes.LoadOrStore(room.Code, true); dup {
+				errors <- fmt.Errorf("duplicate room code %s", room.Code)
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errors)
+
+	for err := range errors {
+		t.Error(err)
+	}
+	assert.Equal(t, 20, rm.GetRoomCount())
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+)
+
+// SessionTokens issues and checks the secret tokens players prove who they are with. A token is
+// "<playerId>.<expiry unix seconds>.<signature>", signed with HMAC-SHA256, so the server keeps no
+// per-player state and a token only works for the player it was issued to.
+type SessionTokens struct {
+	key   []byte
+	clock Clock
+}
+
+// NewSessionTokens signs tokens with key. Tokens from another key never verify.
+func NewSessionTokens(key []byte, clock Clock) *SessionTokens {
+	return &SessionTokens{key: append([]byte(nil), key...), clock: clock}
+}
+
+// NewRandomSessionTokens signs tokens with a fresh random key, so they stop working when the server restarts
+func NewRandomSessionTokens() *SessionTokens {
+	key := make([]byte, constants.SessionSecretBytes)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("failed to generate session key: %v", err))
+	}
+	return NewSessionTokens(key, realClock{})
+}
+
+// LoadSessionSecret reads the hex encoded signing key at path, creating it with a random key if
+// it doesn't exist yet
+func LoadSessionSecret(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) < constants.SessionSecretBytes {
+			return nil, fmt.Errorf("session key %s is not %d hex encoded bytes", path, constants.SessionSecretBytes)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read session key: %v", err)
+	}
+
+	key := make([]byte, constants.SessionSecretBytes)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate session key: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write session key: %v", err)
+	}
+	return key, nil
+}
+
+// ParseSessionSecret checks that a SESSION_SECRET value is long enough to sign tokens with
+func ParseSessionSecret(secret string) ([]byte, error) {
+	if len(secret) < constants.SessionSecretBytes {
+		return nil, fmt.Errorf("SESSION_SECRET must be at least %d characters", constants.SessionSecretBytes)
+	}
+	return []byte(secret), nil
+}
+
+// Issue returns a new token for playerID that expires after constants.SessionTokenLifetime
+func (st *SessionTokens) Issue(playerID string) string {
+	expires := strconv.FormatInt(st.clock.Now().Add(constants.SessionTokenLifetime).Unix(), 10)
+	claims := playerID + "." + expires
+	return claims + "." + st.sign(claims)
+}
+
+// Verify checks a token's signature and expiry and returns the player it was issued to
+func (st *SessionTokens) Verify(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errInvalidSessionToken
+	}
+
+	claims := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(st.sign(claims))) {
+		return "", errInvalidSessionToken
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || !st.clock.Now().Before(time.Unix(expires, 0)) {
+		return "", errInvalidSessionToken
+	}
+
+	return parts[0], nil
+}
+
+// sign returns the base64url HMAC of claims
+func (st *SessionTokens) sign(claims string) string {
+	mac := hmac.New(sha256.New, st.key)
+	mac.Write([]byte(claims))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// UseSessionTokens replaces the signer a room's connections are checked against
+func (wsh *WebSocketHandler) UseSessionTokens(tokens *SessionTokens) {
+	wsh.sessionTokens = tokens
+}
+
+// UseSessionTokens sets the signer every room created from now on issues tokens with
+func (rm *RoomManager) UseSessionTokens(tokens *SessionTokens) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.sessionTokens = tokens
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionTokens(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	tokens := NewSessionTokens([]byte("test-session-key"), clock)
+	playerID := "123e4567-e89b-12d3-a456-426614174000"
+
+	token := tokens.Issue(playerID)
+	id, err := tokens.Verify(token)
+	assert.NoError(t, err)
+	assert.Equal(t, playerID, id)
+
+	// Changing the player, the expiry or the signature breaks the token
+	parts := strings.Split(token, ".")
+	for _, tampered := range []string{
+		"223e4567-e89b-12d3-a456-426614174000." + parts[1] + "." + parts[2],
+		parts[0] + ".9999999999." + parts[2],
+		parts[0] + "." + parts[1] + ".AAAA",
+		playerID,
+		"",
+	} {
+		_, err := tokens.Verify(tampered)
+		assert.EqualError(t, err, constants.ErrInvalidSessionToken, tampered)
+	}
+
+	// Another server's key never verifies
+	_, err = NewSessionTokens([]byte("other-key"), clock).Verify(token)
+	assert.Error(t, err)
+
+	clock.Advance(constants.SessionTokenLifetime - time.Second)
+	_, err = tokens.Verify(token)
+	assert.NoError(t, err)
+	clock.Advance(time.Second)
+	_, err = tokens.Verify(token)
+	assert.EqualError(t, err, constants.ErrInvalidSessionToken)
+}
+
+func TestLoadSessionSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), constants.SessionSecretFile)
+
+	key, err := LoadSessionSecret(path)
+	assert.NoError(t, err)
+	assert.Len(t, key, constants.SessionSecretBytes)
+
+	// The same key comes back after a restart
+	again, err := LoadSessionSecret(path)
+	assert.NoError(t, err)
+	assert.Equal(t, key, again)
+
+	assert.NoError(t, os.WriteFile(path, []byte("not hex"), 0o600))
+	_, err = LoadSessionSecret(path)
+	assert.Error(t, err)
+}
+
+func TestParseSessionSecret(t *testing.T) {
+	_, err := ParseSessionSecret("another-secure-random-string")
+	assert.Error(t, err, "Secrets shorter than the generated key are refused")
+
+	secret := strings.Repeat("k", constants.SessionSecretBytes)
+	key, err := ParseSessionSecret(secret)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(secret), key)
+}
+
+func TestSessionPlayerID(t *testing.T) {
+	wsh := NewWebSocketHandler(NewPlayerManager(), nil, nil, nil)
+	playerID := "123e4567-e89b-12d3-a456-426614174000"
+	token := wsh.sessionTokens.Issue(playerID)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr string
+	}{
+		{name: "New connection", query: ""},
+		{name: "Token", query: "?token=" + token, want: playerID},
+		{name: "Token and matching ID", query: "?playerId=" + playerID + "&token=" + token, want: playerID},
+		{name: "ID without token", query: "?playerId=" + playerID, wantErr: constants.ErrSessionTokenRequired},
+		{name: "Token for another ID", query: "?playerId=223e4567-e89b-12d3-a456-426614174000&token=" + token, wantErr: constants.ErrSessionTokenMismatch},
+		{name: "Bad token", query: "?token=nonsense", wantErr: constants.ErrInvalidSessionToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := wsh.sessionPlayerID(httptest.NewRequest("GET", "/ws"+tt.query, nil))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, id)
+		})
+	}
+}
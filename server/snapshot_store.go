@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+)
+
+// RoomIdentity is the part of a room that must survive a restart so players and hosts can reconnect
+type RoomIdentity struct {
+	Code           string `json:"code"`
+	HostEndpointID string `json:"hostEndpointId"`
+	IsDefault      bool   `json:"isDefault"`
+}
+
+// GameSnapshot is the persisted form of a room's GameState used for crash recovery.
+// Timers are stored as elapsed durations so time the server spends down is not
+// charged against the players.
+type GameSnapshot struct {
+	Version                 int                             `json:"version"`
+	SavedAt                 time.Time                       `json:"savedAt"`
+	Room                    RoomIdentity                    `json:"room"`
+	Phase                   GamePhase                       `json:"phase"`
+	Difficulty              string                          `json:"difficulty"`
+	Settings                GameSettings                    `json:"settings"`
+	TeamTokens              TeamTokens                      `json:"teamTokens"`
+	CurrentRound            int                             `json:"currentRound"`
+	RoundElapsed            time.Duration                   `json:"roundElapsed"`
+	RoundExtension          time.Duration                   `json:"roundExtension,omitempty"`
+	Paused                  bool                            `json:"paused,omitempty"`
+	PuzzleStarted           bool                            `json:"puzzleStarted"`
+	PuzzleElapsed           time.Duration                   `json:"puzzleElapsed"`
+	PuzzleDuration          time.Duration                   `json:"puzzleDuration"`
+	GridSize                int                             `json:"gridSize"`
+	PuzzleImageID           string                          `json:"puzzleImageId"`
+	Players                 []PlayerSnapshot                `json:"players"`
+	PuzzleFragments         []FragmentSnapshot              `json:"puzzleFragments"`
+	QuestionHistory         map[string]map[string]bool      `json:"questionHistory"`
+	PlayerAnalytics         map[string]*PlayerAnalytics     `json:"playerAnalytics"`
+	FragmentMoveHistory     []FragmentMove                  `json:"fragmentMoveHistory"`
+	PieceRecommendations    map[string]*PieceRecommendation `json:"pieceRecommendations"`
+	StartedAt               time.Time                       `json:"startedAt"`
+	TriviaAnswers           []TriviaAnswerRecord            `json:"triviaAnswers"`
+	ResolvedRecommendations []RecommendationRecord          `json:"resolvedRecommendations"`
+}
+
+// PlayerSnapshot holds the player fields needed to let a player reconnect with their old ID
+type PlayerSnapshot struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Role            string   `json:"role"`
+	Specialties     []string `json:"specialties"`
+	IsHost          bool     `json:"isHost"`
+	Ready           bool     `json:"ready"`
+	CurrentLocation string   `json:"currentLocation,omitempty"`
+}
+
+// FragmentSnapshot includes the fragment fields that are hidden from clients
+type FragmentSnapshot struct {
+	ID              string    `json:"id"`
+	PlayerID        string    `json:"playerId"`
+	Position        GridPos   `json:"position"`
+	Solved          bool      `json:"solved"`
+	LastMoved       time.Time `json:"lastMoved"`
+	CorrectPosition GridPos   `json:"correctPosition"`
+	PreSolved       bool      `json:"preSolved"`
+	Visible         bool      `json:"visible"`
+	MovableBy       string    `json:"movableBy"`
+	IsUnassigned    bool      `json:"isUnassigned"`
+}
+
+// SnapshotStore persists one snapshot file per room in a local directory
+type SnapshotStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewSnapshotStore creates a file-backed snapshot store, creating the directory if needed
+func NewSnapshotStore(dir string) (*SnapshotStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %v", err)
+	}
+
+	return &SnapshotStore{dir: dir}, nil
+}
+
+// Save writes a snapshot atomically so a crash mid-write never leaves a truncated file behind
+func (ss *SnapshotStore) Save(snapshot *GameSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %v", err)
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	tmp, err := os.CreateTemp(ss.dir, snapshot.Room.Code+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync snapshot: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot file: %v", err)
+	}
+
+	return os.Rename(tmp.Name(), ss.path(snapshot.Room.Code))
+}
+
+// Load reads the snapshot for a room code
+func (ss *SnapshotStore) Load(code string) (*GameSnapshot, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	return ss.loadFile(ss.path(code))
+}
+
+// LoadAll reads every snapshot in the store, skipping files that cannot be decoded
+func (ss *SnapshotStore) LoadAll() ([]*GameSnapshot, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(ss.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]*GameSnapshot, 0, len(files))
+	for _, file := range files {
+		snapshot, err := ss.loadFile(file)
+		if err != nil {
+			log.Printf("Skipping unreadable snapshot %s: %v", file, err)
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// Delete removes the snapshot for a room code; missing snapshots are not an error
+func (ss *SnapshotStore) Delete(code string) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if err := os.Remove(ss.path(code)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (ss *SnapshotStore) loadFile(path string) (*GameSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot GameSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %v", err)
+	}
+	if snapshot.Version != constants.SnapshotFormatVersion {
+		return nil, fmt.Errorf("%w: %d", errSnapshotVersion, snapshot.Version)
+	}
+
+	return &snapshot, nil
+}
+
+func (ss *SnapshotStore) path(code string) string {
+	// Room codes come from our own alphabet, but never let one escape the snapshot directory
+	return filepath.Join(ss.dir, strings.ReplaceAll(filepath.Base(code), ".", "_")+".json")
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestSnapshot(code string) *GameSnapshot {
+	return &GameSnapshot{
+		Version:      constants.SnapshotFormatVersion,
+		SavedAt:      time.Now(),
+		Room:         RoomIdentity{Code: code, HostEndpointID: "host-secret"},
+		Phase:        PhaseResourceGathering,
+		Difficulty:   "hard",
+		TeamTokens:   TeamTokens{AnchorTokens: 10, GuideTokens: 5},
+		CurrentRound: 3,
+		RoundElapsed: 20 * time.Second,
+		Players: []PlayerSnapshot{
+			{ID: "player-1", Name: "Player1", Role: constants.RoleDetective, Specialties: []string{"science"}},
+		},
+	}
+}
+
+func TestSnapshotStoreSaveAndLoad(t *testing.T) {
+	store, err := NewSnapshotStore(filepath.Join(t.TempDir(), "snapshots"))
+	assert.NoError(t, err)
+
+	snapshot := createTestSnapshot("ACDEF")
+	assert.NoError(t, store.Save(snapshot))
+
+	loaded, err := store.Load("ACDEF")
+	assert.NoError(t, err)
+	assert.Equal(t, snapshot.Room, loaded.Room)
+	assert.Equal(t, PhaseResourceGathering, loaded.Phase)
+	assert.Equal(t, snapshot.TeamTokens, loaded.TeamTokens)
+	assert.Equal(t, 3, loaded.CurrentRound)
+	assert.Equal(t, 20*time.Second, loaded.RoundElapsed)
+	assert.Equal(t, snapshot.Players, loaded.Players)
+
+	// Saving again overwrites in place and leaves no temp files behind
+	snapshot.CurrentRound = 4
+	assert.NoError(t, store.Save(snapshot))
+	loaded, err = store.Load("ACDEF")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, loaded.CurrentRound)
+
+	entries, err := os.ReadDir(store.dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestSnapshotStoreLoadAll(t *testing.T) {
+	store, err := NewSnapshotStore(t.TempDir())
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Save(createTestSnapshot("ACDEF")))
+	assert.NoError(t, store.Save(createTestSnapshot("GHJKM")))
+
+	// Corrupt files and old format versions are skipped, not fatal
+	assert.NoError(t, os.WriteFile(filepath.Join(store.dir, "BROKEN.json"), []byte("{not json"), 0o644))
+	old := createTestSnapshot("NPQRT")
+	old.Version = constants.SnapshotFormatVersion + 1
+	assert.NoError(t, store.Save(old))
+
+	snapshots, err := store.LoadAll()
+	assert.NoError(t, err)
+	assert.Len(t, snapshots, 2)
+
+	_, err = store.Load("NPQRT")
+	assert.ErrorContains(t, err, constants.ErrSnapshotVersion)
+}
+
+func TestSnapshotStoreDelete(t *testing.T) {
+	store, err := NewSnapshotStore(t.TempDir())
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Save(createTestSnapshot("ACDEF")))
+	assert.NoError(t, store.Delete("ACDEF"))
+
+	_, err = store.Load("ACDEF")
+	assert.Error(t, err)
+
+	// Deleting a missing snapshot is not an error
+	assert.NoError(t, store.Delete("ACDEF"))
+}
+
+func TestSnapshotStorePathStaysInDirectory(t *testing.T) {
+	store, err := NewSnapshotStore(t.TempDir())
+	assert.NoError(t, err)
+
+	assert.Equal(t, store.dir, filepath.Dir(store.path("../../etc/passwd")))
+	assert.Equal(t, store.dir, filepath.Dir(store.path("..")))
+}
@@ -13,6 +13,9 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// testSeed seeds every game and trivia manager in tests so failures can be reproduced
+const testSeed = 42
+
 // MockWebSocketConn implements a mock WebSocket connection for testing
 type MockWebSocketConn struct {
 	WriteMessages [][]byte
@@ -104,9 +107,9 @@ func (m *MockWebSocketConn) WriteJSON(v interface{}) error {
 // CreateTestGame creates a game with test managers
 func CreateTestGame(t *testing.T, playerCount int) (*GameManager, *PlayerManager, *TriviaManager) {
 	playerMgr := NewPlayerManager()
-	triviaMgr := NewTriviaManager()
+	triviaMgr := NewTriviaManager(realClock{}, testSeed)
 	broadcastChan := make(chan BroadcastMessage, 256)
-	gameMgr := NewGameManager(playerMgr, triviaMgr, broadcastChan)
+	gameMgr := NewGameManager(playerMgr, triviaMgr, broadcastChan, realClock{}, testSeed)
 
 	// Create host
 	hostConn := &websocket.Conn{} // We'll use mock connection methods later
@@ -161,6 +164,15 @@ func CreateAuthWrapper(playerID string, payload interface{}) []byte {
 	return data
 }
 
+// boolPtr and stringPtr fill in the optional fields of payloads
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
+
 // CreateWebSocketTestServer creates a test HTTP server with WebSocket upgrade
 func CreateWebSocketTestServer(handler http.HandlerFunc) *httptest.Server {
 	return httptest.NewServer(handler)
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+)
+
+// Trivia categories are whatever the question bank holds: every directory under the bank whose name
+// is a valid category ID is a category. A category.json file in the directory can give it a display
+// name and description; without one the name is made from the ID.
+
+// TriviaCategory describes a category of the question bank
+type TriviaCategory struct {
+	ID          string `json:"id"` // Directory name, and what players send as a specialty
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// categoryMetadata is the optional category.json file in a category directory
+type categoryMetadata struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+var categoryIDRegex = regexp.MustCompile(fmt.Sprintf(`^[a-z0-9][a-z0-9_]{0,%d}$`, constants.MaxTriviaCategoryIDLength-1))
+
+// discoverTriviaCategories lists the categories in the question bank at dir, sorted by ID.
+// Directories that can't be category IDs are skipped with a warning.
+func discoverTriviaCategories(dir string) ([]TriviaCategory, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]TriviaCategory, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if !categoryIDRegex.MatchString(entry.Name()) {
+			log.Printf("Warning: Skipping trivia directory %q: category names are lowercase letters, digits and underscores", entry.Name())
+			continue
+		}
+
+		category, err := loadTriviaCategory(dir, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+	return categories, nil
+}
+
+// loadTriviaCategory describes the category in dir/id, reading its category.json when there is one
+func loadTriviaCategory(dir, id string) (TriviaCategory, error) {
+	category := TriviaCategory{ID: id, Name: categoryDisplayName(id)}
+
+	data, err := os.ReadFile(filepath.Join(dir, id, constants.TriviaCategoryMetadataFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return category, nil
+	}
+	if err != nil {
+		return TriviaCategory{}, err
+	}
+
+	var metadata categoryMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return TriviaCategory{}, fmt.Errorf("invalid %s for category %s: %v", constants.TriviaCategoryMetadataFile, id, err)
+	}
+	if name := strings.TrimSpace(metadata.Name); name != "" {
+		category.Name = name
+	}
+	category.Description = strings.TrimSpace(metadata.Description)
+	return category, nil
+}
+
+// categoryIDs lists the IDs of categories, in order
+func categoryIDs(categories []TriviaCategory) []string {
+	ids := make([]string, len(categories))
+	for i, category := range categories {
+		ids[i] = category.ID
+	}
+	return ids
+}
+
+// categoryID turns a category name such as "Company History" into its ID, company_history
+func categoryID(name string) string {
+	var id strings.Builder
+	pendingSeparator := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if pendingSeparator && id.Len() > 0 {
+				id.WriteByte('_')
+			}
+			id.WriteRune(r)
+			pendingSeparator = false
+			continue
+		}
+		pendingSeparator = true
+	}
+	return id.String()
+}
+
+// categoryDisplayName makes a name for a category without a category.json, e.g. video_games becomes Video Games
+func categoryDisplayName(id string) string {
+	words := strings.Split(id, "_")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
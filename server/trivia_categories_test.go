@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestCategory adds a category to the bank at dir with count medium questions
+func writeTestCategory(t *testing.T, dir, id string, count int) {
+	questions := make([]TriviaQuestionJSON, count)
+	for i := range questions {
+		questions[i] = TriviaQuestionJSON{
+			Type: "multiple", Difficulty: "medium", Category: categoryDisplayName(id),
+			Question: fmt.Sprintf("%s question %d?", id, i), CorrectAnswer: "Right", IncorrectAnswers: []string{"Wrong"},
+		}
+	}
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, id), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, id, "medium.json"), mustMarshal(triviaFile{Results: questions}), 0o644))
+}
+
+func TestDiscoverTriviaCategories(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"science", "company_history", "Bad Name", "empty"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, name), 0o755))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "company_history", constants.TriviaCategoryMetadataFile),
+		[]byte(`{"name": " Company History ", "description": "How we got here"}`), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a category"), 0o644))
+
+	categories, err := discoverTriviaCategories(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []TriviaCategory{
+		{ID: "company_history", Name: "Company History", Description: "How we got here"},
+		{ID: "empty", Name: "Empty"},
+		{ID: "science", Name: "Science"},
+	}, categories, "Directories that can't be IDs and plain files are skipped")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "science", constants.TriviaCategoryMetadataFile), []byte(`{"name": `), 0o644))
+	_, err = discoverTriviaCategories(dir)
+	assert.ErrorContains(t, err, "category science")
+
+	_, err = discoverTriviaCategories(filepath.Join(dir, "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCategoryNames(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{name: "Company History", id: "company_history"},
+		{name: "  Science & Nature ", id: "science_nature"},
+		{name: "video_games", id: "video_games"},
+		{name: "90s Music!", id: "90s_music"},
+		{name: "!!!", id: ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.id, categoryID(tt.name), tt.name)
+	}
+
+	assert.Equal(t, "Video Games", categoryDisplayName("video_games"))
+	assert.Equal(t, "90s Music", categoryDisplayName("90s_music"))
+}
+
+func TestTriviaManagerCustomCategories(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCategory(t, dir, "science", 3)
+	writeTestCategory(t, dir, "company_history", 3)
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "empty"), 0o755))
+
+	tm := NewTriviaManagerFromDir(dir, realClock{}, testSeed)
+	defer tm.Shutdown()
+
+	// Only categories with questions are offered
+	assert.Equal(t, []string{"company_history", "science"}, tm.GetAvailableCategories())
+	assert.Equal(t, "Company History", tm.GetCategoryDetails()[0].Name)
+
+	questions := tm.GetQuestionsByCategory("company_history", "medium")
+	if assert.Len(t, questions, 3) {
+		assert.Equal(t, "company_history", questions[0].Category)
+		assert.Empty(t, validateTriviaAnswer(questions[0].ID, "Right", realClock{}.Now().Unix()), "Its question IDs are ones players can answer")
+	}
+
+	// Specialties are checked against the categories the bank has
+	pm := NewPlayerManager()
+	pm.UseTriviaCategories(tm.GetAvailableCategories)
+	player := pm.CreatePlayer(nil, false)
+	assert.NoError(t, pm.SetPlayerSpecialties(player.ID, []string{"company_history"}))
+	assert.Error(t, pm.SetPlayerSpecialties(player.ID, []string{"history"}))
+
+	// A reload picks up categories added since
+	writeTestCategory(t, dir, "history", 3)
+	assert.NoError(t, tm.ReloadQuestions())
+	assert.Equal(t, []string{"company_history", "history", "science"}, tm.GetAvailableCategories())
+	assert.NoError(t, pm.SetPlayerSpecialties(player.ID, []string{"history"}))
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+)
+
+// Teachers bring their own questions as CSV or in a simple JSON format. The import subcommand checks
+// every row the same way the server checks the bank, skips questions the bank already has, and adds
+// the rest to the bank files (trivia/{category}/{difficulty}.json) the server loads.
+
+// ImportQuestion is one question in the native JSON import format
+type ImportQuestion struct {
+	Category         string   `json:"category,omitempty"`   // Defaults to the file's category
+	Difficulty       string   `json:"difficulty,omitempty"` // Defaults to the file's difficulty
+	Question         string   `json:"question"`
+	CorrectAnswer    string   `json:"correctAnswer"`
+	IncorrectAnswers []string `json:"incorrectAnswers"`
+}
+
+// ImportFile is the native JSON import format
+type ImportFile struct {
+	Category   string           `json:"category,omitempty"`   // For questions that don't name one
+	Difficulty string           `json:"difficulty,omitempty"` // For questions that don't name one
+	Questions  []ImportQuestion `json:"questions"`
+}
+
+// importRow is a parsed question and where it came from, so problems can be reported by row
+type importRow struct {
+	Row      int // CSV line number, or position in the JSON questions list counting from 1
+	Question ImportQuestion
+}
+
+// ImportRowError explains why one row was not imported
+type ImportRowError struct {
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+func (e ImportRowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Reason)
+}
+
+// ImportReport is the outcome of importing one file
+type ImportReport struct {
+	Imported      int              `json:"imported"`
+	Duplicates    []int            `json:"duplicates"` // Rows the bank, or an earlier row, already has
+	Errors        []ImportRowError `json:"errors"`
+	NewCategories []TriviaCategory `json:"newCategories"` // Categories the bank didn't have before this file
+}
+
+// csvImportColumns are the CSV header names the importer reads. Any number of columns whose name
+// starts with incorrect_answer hold the wrong answers, one per column; blank cells are ignored.
+var csvImportColumns = []string{"category", "difficulty", "question", "correct_answer"}
+
+// parseImportFile reads questions from a .csv or .json import file
+func parseImportFile(path string) ([]importRow, []ImportRowError, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return parseImportCSV(file)
+	case ".json":
+		rows, err := parseImportJSON(file)
+		return rows, nil, err
+	default:
+		return nil, nil, fmt.Errorf("unsupported import format %q: expected .csv or .json", filepath.Ext(path))
+	}
+}
+
+// parseImportCSV reads questions from CSV with a header row. Rows that can't be read are reported
+// rather than ending the import.
+func parseImportCSV(r io.Reader) ([]importRow, []ImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+
+	columns := make(map[string]int)
+	var incorrectColumns []int
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) // Spreadsheets often save a byte order mark
+		if strings.HasPrefix(name, "incorrect_answer") {
+			incorrectColumns = append(incorrectColumns, i)
+			continue
+		}
+		if slices.Contains(csvImportColumns, name) {
+			columns[name] = i
+		}
+	}
+	for _, required := range []string{"question", "correct_answer"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("CSV header has no %s column", required)
+		}
+	}
+	if len(incorrectColumns) == 0 {
+		return nil, nil, fmt.Errorf("CSV header has no incorrect_answer columns")
+	}
+
+	cell := func(record []string, column int) string {
+		if column < len(record) {
+			return strings.TrimSpace(record[column])
+		}
+		return ""
+	}
+
+	var rows []importRow
+	var rowErrors []ImportRowError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrors = append(rowErrors, ImportRowError{Row: parseErr.StartLine, Reason: parseErr.Err.Error()})
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to read CSV: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		question := ImportQuestion{
+			Question:      cell(record, columns["question"]),
+			CorrectAnswer: cell(record, columns["correct_answer"]),
+		}
+		if column, ok := columns["category"]; ok {
+			question.Category = cell(record, column)
+		}
+		if column, ok := columns["difficulty"]; ok {
+			question.Difficulty = cell(record, column)
+		}
+		for _, column := range incorrectColumns {
+			if answer := cell(record, column); answer != "" {
+				question.IncorrectAnswers = append(question.IncorrectAnswers, answer)
+			}
+		}
+
+		// Blank lines between questions are common in hand-edited files
+		if question.Question == "" && question.CorrectAnswer == "" && len(question.IncorrectAnswers) == 0 {
+			continue
+		}
+		rows = append(rows, importRow{Row: line, Question: question})
+	}
+
+	return rows, rowErrors, nil
+}
+
+// parseImportJSON reads questions in the native JSON format
+func parseImportJSON(r io.Reader) ([]importRow, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var file ImportFile
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid JSON format: %v", err)
+	}
+	if len(file.Questions) == 0 {
+		return nil, fmt.Errorf("no questions found in file")
+	}
+
+	rows := make([]importRow, len(file.Questions))
+	for i, question := range file.Questions {
+		if question.Category == "" {
+			question.Category = file.Category
+		}
+		if question.Difficulty == "" {
+			question.Difficulty = file.Difficulty
+		}
+		rows[i] = importRow{Row: i + 1, Question: question}
+	}
+	return rows, nil
+}
+
+// TriviaBank is the question bank on disk, read whole so imports can be checked against it
+type TriviaBank struct {
+	dir        string
+	categories map[string]TriviaCategory                  // Categories the bank has, including ones Import created
+	created    []TriviaCategory                           // Categories Import created, which Save gives a directory
+	questions  map[string]map[string][]TriviaQuestionJSON // category -> difficulty -> questions
+	known      map[string]bool                            // questionKey of every question in the bank
+	changed    map[string]map[string]bool                 // Files Import added questions to
+}
+
+// OpenTriviaBank reads every bank file under dir. Missing files are treated as empty, but a file
+// that can't be read is an error, so an import never overwrites questions it didn't understand.
+func OpenTriviaBank(dir string) (*TriviaBank, error) {
+	tb := &TriviaBank{
+		dir:        dir,
+		categories: make(map[string]TriviaCategory),
+		questions:  make(map[string]map[string][]TriviaQuestionJSON),
+		known:      make(map[string]bool),
+		changed:    make(map[string]map[string]bool),
+	}
+
+	categories, err := discoverTriviaCategories(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return tb, nil // A new bank; Save creates it
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, categoryInfo := range categories {
+		category := categoryInfo.ID
+		tb.categories[category] = categoryInfo
+		tb.questions[category] = make(map[string][]TriviaQuestionJSON)
+		tb.changed[category] = make(map[string]bool)
+
+		for _, difficulty := range []string{"easy", "medium", "hard"} {
+			data, err := os.ReadFile(tb.path(category, difficulty))
+			if errors.Is(err, os.ErrNotExist) || (err == nil && len(bytes.TrimSpace(data)) == 0) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			var file triviaFile
+			if err := json.Unmarshal(data, &file); err != nil {
+				return nil, fmt.Errorf("invalid JSON format in %s: %v", tb.path(category, difficulty), err)
+			}
+			tb.questions[category][difficulty] = file.Results
+			for _, question := range file.Results {
+				tb.known[questionKey(question.Question)] = true
+			}
+		}
+	}
+
+	return tb, nil
+}
+
+// path is where the bank keeps a category's questions of one difficulty
+func (tb *TriviaBank) path(category, difficulty string) string {
+	return filepath.Join(tb.dir, category, difficulty+".json")
+}
+
+// Import checks rows and adds the valid ones the bank doesn't already have. defaultCategory and
+// defaultDifficulty apply to rows that don't name their own. A category the bank doesn't have yet
+// is created, named as the row wrote it; its ID is the name in lowercase with underscores.
+func (tb *TriviaBank) Import(rows []importRow, defaultCategory, defaultDifficulty string) ImportReport {
+	report := ImportReport{Duplicates: []int{}, Errors: []ImportRowError{}, NewCategories: []TriviaCategory{}}
+
+	for _, row := range rows {
+		question, err := bankQuestion(row.Question, defaultCategory, defaultDifficulty)
+		if err != nil {
+			report.Errors = append(report.Errors, ImportRowError{Row: row.Row, Reason: err.Error()})
+			continue
+		}
+
+		key := questionKey(question.Question)
+		if tb.known[key] {
+			report.Duplicates = append(report.Duplicates, row.Row)
+			continue
+		}
+
+		id := categoryID(question.Category)
+		category, exists := tb.categories[id]
+		if !exists {
+			category = TriviaCategory{ID: id, Name: question.Category}
+			if category.Name == strings.ToLower(category.Name) {
+				category.Name = categoryDisplayName(id) // Written like an ID, so name it like one
+			}
+			tb.categories[id] = category
+			tb.created = append(tb.created, category)
+			tb.questions[id] = make(map[string][]TriviaQuestionJSON)
+			tb.changed[id] = make(map[string]bool)
+			report.NewCategories = append(report.NewCategories, category)
+		}
+		question.Category = category.Name
+
+		tb.known[key] = true
+		tb.questions[id][question.Difficulty] = append(tb.questions[id][question.Difficulty], question)
+		tb.changed[id][question.Difficulty] = true
+		report.Imported++
+	}
+
+	return report
+}
+
+// Save writes the bank files Import added questions to, and a category.json for each category it
+// created whose name the directory name can't give
+func (tb *TriviaBank) Save() error {
+	for _, category := range tb.created {
+		if category.Name == categoryDisplayName(category.ID) {
+			continue // The directory name alone gives it this name
+		}
+		if err := tb.writeFile(filepath.Join(tb.dir, category.ID, constants.TriviaCategoryMetadataFile),
+			categoryMetadata{Name: category.Name, Description: category.Description}); err != nil {
+			return err
+		}
+	}
+	tb.created = nil
+
+	for _, category := range slices.Sorted(maps.Keys(tb.changed)) {
+		for difficulty := range tb.changed[category] {
+			if err := tb.writeFile(tb.path(category, difficulty), triviaFile{Results: tb.questions[category][difficulty]}); err != nil {
+				return err
+			}
+		}
+		clear(tb.changed[category])
+	}
+	return nil
+}
+
+// writeFile replaces one bank file, writing a temporary file first so the server never loads a half-written one
+func (tb *TriviaBank) writeFile(path string, contents interface{}) error {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false) // Keep answers like "AT&T" readable in the file
+	if err := encoder.Encode(contents); err != nil {
+		return fmt.Errorf("failed to encode %s: %v", filepath.Base(path), err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", filepath.Dir(path), err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %v", path, err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// bankQuestion checks an imported question and converts it to the bank's format
+func bankQuestion(question ImportQuestion, defaultCategory, defaultDifficulty string) (TriviaQuestionJSON, error) {
+	category := strings.TrimSpace(question.Category)
+	if category == "" {
+		category = strings.TrimSpace(defaultCategory)
+	}
+	if category == "" {
+		return TriviaQuestionJSON{}, fmt.Errorf("no category given")
+	}
+	if !categoryIDRegex.MatchString(categoryID(category)) {
+		return TriviaQuestionJSON{}, fmt.Errorf("invalid category %q: use letters and digits, at most %d characters", category, constants.MaxTriviaCategoryIDLength)
+	}
+
+	difficulty := strings.ToLower(strings.TrimSpace(question.Difficulty))
+	if difficulty == "" {
+		difficulty = strings.ToLower(defaultDifficulty)
+	}
+	if difficulty == "" {
+		return TriviaQuestionJSON{}, fmt.Errorf("no difficulty given")
+	}
+	if !validDifficulties[difficulty] {
+		return TriviaQuestionJSON{}, fmt.Errorf("%w: %q", errInvalidDifficulty, difficulty)
+	}
+
+	incorrect := make([]string, 0, len(question.IncorrectAnswers))
+	for _, answer := range question.IncorrectAnswers {
+		if answer = strings.TrimSpace(answer); answer != "" {
+			incorrect = append(incorrect, answer)
+		}
+	}
+
+	converted := TriviaQuestionJSON{
+		Type:             "multiple",
+		Difficulty:       difficulty,
+		Category:         category,
+		Question:         strings.TrimSpace(question.Question),
+		CorrectAnswer:    strings.TrimSpace(question.CorrectAnswer),
+		IncorrectAnswers: incorrect,
+	}
+	if err := validateQuestionData(converted); err != nil {
+		return TriviaQuestionJSON{}, err
+	}
+	return converted, nil
+}
+
+// questionKey identifies a question for deduplication, ignoring case, spacing and HTML entities
+func questionKey(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(cleanHTMLEntities(text))), " ")
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImportCSV(t *testing.T) {
+	csvData := "\ufeffQuestion,Correct_Answer,Incorrect_Answer_1,Incorrect_Answer_2,Category\n" +
+		"What is 2+2?,4,3,5,science\n" +
+		"\n" +
+		"\"Which city is called \"\"the Big Apple\"\"?\",New York,Boston,,geography\n" +
+		"Broken \"quote,x,y,z,general\n"
+
+	rows, rowErrors, err := parseImportCSV(strings.NewReader(csvData))
+	assert.NoError(t, err)
+
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, 2, rows[0].Row)
+		assert.Equal(t, ImportQuestion{Category: "science", Question: "What is 2+2?", CorrectAnswer: "4", IncorrectAnswers: []string{"3", "5"}}, rows[0].Question)
+
+		// Quoted cells keep their commas and quotes, and blank answer cells are dropped
+		assert.Equal(t, 4, rows[1].Row)
+		assert.Equal(t, `Which city is called "the Big Apple"?`, rows[1].Question.Question)
+		assert.Equal(t, []string{"Boston"}, rows[1].Question.IncorrectAnswers)
+	}
+
+	if assert.Len(t, rowErrors, 1) {
+		assert.Equal(t, 5, rowErrors[0].Row)
+	}
+
+	_, _, err = parseImportCSV(strings.NewReader("question,correct_answer\nQ,A\n"))
+	assert.Error(t, err, "A header without incorrect answer columns is refused")
+}
+
+func TestParseImportJSON(t *testing.T) {
+	rows, err := parseImportJSON(strings.NewReader(`{
+		"category": "history",
+		"difficulty": "easy",
+		"questions": [
+			{"question": "Who was the first US president?", "correctAnswer": "George Washington", "incorrectAnswers": ["John Adams", "Thomas Jefferson"]},
+			{"question": "In which year did WW2 end?", "correctAnswer": "1945", "incorrectAnswers": ["1944"], "difficulty": "medium"}
+		]
+	}`))
+	assert.NoError(t, err)
+
+	if assert.Len(t, rows, 2) {
+		// File defaults fill in what a question leaves out
+		assert.Equal(t, "history", rows[0].Question.Category)
+		assert.Equal(t, "easy", rows[0].Question.Difficulty)
+		assert.Equal(t, "medium", rows[1].Question.Difficulty)
+		assert.Equal(t, 2, rows[1].Row)
+	}
+
+	_, err = parseImportJSON(strings.NewReader(`{"questions": [{"question": "Q", "answer": "A"}]}`))
+	assert.Error(t, err, "Unknown fields are refused so misspelled keys don't import empty questions")
+}
+
+func TestTriviaBankImport(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "science", "easy.json")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(existing), 0o755))
+	assert.NoError(t, os.WriteFile(existing, mustMarshal(triviaFile{Results: []TriviaQuestionJSON{{
+		Type: "multiple", Difficulty: "easy", Category: "Science &amp; Nature",
+		Question: "What is H&#039;2O&#039;?", CorrectAnswer: "Water", IncorrectAnswers: []string{"Salt"},
+	}}}), 0o644))
+
+	bank, err := OpenTriviaBank(dir)
+	assert.NoError(t, err)
+
+	report := bank.Import([]importRow{
+		{Row: 1, Question: ImportQuestion{Category: "Science", Question: "What is  h'2o'?", CorrectAnswer: "Water", IncorrectAnswers: []string{"Ice"}}},
+		{Row: 2, Question: ImportQuestion{Category: "video games", Question: "Who is Mario's brother?", CorrectAnswer: "Luigi", IncorrectAnswers: []string{"Wario", "Toad"}}},
+		{Row: 3, Question: ImportQuestion{Category: "video_games", Question: "who is mario's brother?", CorrectAnswer: "Luigi", IncorrectAnswers: []string{"Yoshi"}}},
+		{Row: 4, Question: ImportQuestion{Category: "!!!", Question: "Q", CorrectAnswer: "A", IncorrectAnswers: []string{"B"}}},
+		{Row: 5, Question: ImportQuestion{Question: "Q", CorrectAnswer: "A", IncorrectAnswers: []string{"a"}}},
+		{Row: 6, Question: ImportQuestion{Difficulty: "impossible", Question: "Q", CorrectAnswer: "A", IncorrectAnswers: []string{"B"}}},
+		{Row: 7, Question: ImportQuestion{Question: "What is the capital of Peru?", CorrectAnswer: "Lima"}},
+		{Row: 8, Question: ImportQuestion{Category: "Company History", Question: "Who founded the company?", CorrectAnswer: "Max", IncorrectAnswers: []string{"Ada"}}},
+	}, "geography", "medium")
+
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, []int{1, 3}, report.Duplicates, "Matches ignore case, spacing and HTML entities, against the bank and earlier rows")
+	assert.Equal(t, []TriviaCategory{{ID: "video_games", Name: "Video Games"}, {ID: "company_history", Name: "Company History"}}, report.NewCategories)
+	if assert.Len(t, report.Errors, 4) {
+		assert.Contains(t, report.Errors[0].Reason, "invalid category")
+		assert.Equal(t, "duplicate answers detected", report.Errors[1].Reason)
+		assert.Contains(t, report.Errors[2].Reason, constants.ErrInvalidDifficulty)
+		assert.Equal(t, "row 7: no incorrect answers provided", report.Errors[3].Error())
+	}
+
+	assert.NoError(t, bank.Save())
+
+	// The saved file is one the server loads
+	tm := &TriviaManager{rng: newLockedRand(testSeed), clock: realClock{}}
+	questions, err := tm.loadQuestionsFromFile(filepath.Join(dir, "video_games", "medium.json"), "video_games", "medium")
+	assert.NoError(t, err)
+	if assert.Len(t, questions, 1) {
+		assert.Equal(t, "Luigi", questions[0].CorrectAnswer)
+	}
+
+	// New categories are ones the server finds, with a category.json only where the name needs one
+	categories, err := discoverTriviaCategories(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []TriviaCategory{
+		{ID: "company_history", Name: "Company History"},
+		{ID: "science", Name: "Science"},
+		{ID: "video_games", Name: "Video Games"},
+	}, categories)
+	_, err = os.Stat(filepath.Join(dir, "video_games", constants.TriviaCategoryMetadataFile))
+	assert.True(t, os.IsNotExist(err))
+
+	// Files nothing was added to are left alone
+	_, err = os.Stat(filepath.Join(dir, "geography", "medium.json"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRunImport(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(t.TempDir(), "questions.csv")
+	assert.NoError(t, os.WriteFile(input, []byte("question,correct_answer,incorrect_answer_1,incorrect_answer_2\n"+
+		"What is the largest planet?,Jupiter,Saturn,Mars\n"+
+		"What is the smallest planet?,,Mars,Venus\n"), 0o644))
+
+	var out bytes.Buffer
+	assert.Equal(t, 1, runImport([]string{"-trivia-dir", dir, "-category", "science", "-difficulty", "easy", "-dry-run", input}, &out))
+	assert.Contains(t, out.String(), "1 imported, 0 duplicates skipped, 1 errors")
+	assert.Contains(t, out.String(), "row 3: empty correct answer")
+	_, err := os.Stat(filepath.Join(dir, "science", "easy.json"))
+	assert.True(t, os.IsNotExist(err), "A dry run leaves the bank alone")
+
+	out.Reset()
+	assert.Equal(t, 1, runImport([]string{"-trivia-dir", dir, "-category", "science", "-difficulty", "easy", input}, &out))
+	bank, err := OpenTriviaBank(dir)
+	assert.NoError(t, err)
+	assert.Len(t, bank.questions["science"]["easy"], 1)
+
+	// Importing the same file again only finds duplicates
+	out.Reset()
+	runImport([]string{"-trivia-dir", dir, "-category", "science", "-difficulty", "easy", input}, &out)
+	assert.Contains(t, out.String(), "0 imported, 1 duplicates skipped")
+	assert.Contains(t, out.String(), "row 2: already in the bank")
+
+	out.Reset()
+	assert.Equal(t, 2, runImport(nil, &out))
+	assert.Contains(t, out.String(), "Usage")
+}
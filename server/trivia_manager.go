@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"maps"
 	"math/rand"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,21 +20,36 @@ import (
 
 // TriviaManager handles loading and serving trivia questions with enhanced cycling
 type TriviaManager struct {
+	dir               string           // Question bank directory
+	categories        []TriviaCategory // Categories with questions loaded, sorted by ID
 	questions         map[string]map[string][]TriviaQuestion
 	questionPools     map[string]map[string][]int
 	questionHistory   map[string]time.Time
 	poolResetCounters map[string]map[string]int
+	balance           *BalanceConfig // Difficulty modifiers; see balance_config.go
+	clock             Clock
+	rng               *rand.Rand // Safe for concurrent use; pool shuffles also run outside tm.mu
 	mu                sync.RWMutex
 	shutdownChan      chan struct{} // Add this for graceful shutdown
 }
 
-// NewTriviaManager creates and initializes a new trivia manager
-func NewTriviaManager() *TriviaManager {
+// NewTriviaManager creates and initializes a new trivia manager. Option order, pool order and
+// category choices are all drawn from seed, so the same seed serves the same questions.
+func NewTriviaManager(clock Clock, seed int64) *TriviaManager {
+	return NewTriviaManagerFromDir(constants.TriviaDirectory, clock, seed)
+}
+
+// NewTriviaManagerFromDir creates a trivia manager serving the question bank in dir
+func NewTriviaManagerFromDir(dir string, clock Clock, seed int64) *TriviaManager {
 	tm := &TriviaManager{
+		dir:               dir,
 		questions:         make(map[string]map[string][]TriviaQuestion),
 		questionPools:     make(map[string]map[string][]int),
 		questionHistory:   make(map[string]time.Time),
 		poolResetCounters: make(map[string]map[string]int),
+		balance:           DefaultBalanceConfig(),
+		clock:             clock,
+		rng:               newLockedRand(seed),
 		shutdownChan:      make(chan struct{}), // Initialize shutdown channel
 	}
 
@@ -53,18 +71,19 @@ func (tm *TriviaManager) loadAllQuestions() error {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
+	categories, err := discoverTriviaCategories(tm.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read trivia categories: %v", err)
+	}
+
 	difficulties := []string{"easy", "medium", "hard"}
 	totalLoaded := 0
 	errors := make([]string, 0)
 
-	for _, category := range constants.TriviaCategories {
-		if tm.questions[category] == nil {
-			tm.questions[category] = make(map[string][]TriviaQuestion)
-		}
-
+	for _, category := range categories {
 		for _, difficulty := range difficulties {
-			filename := filepath.Join("trivia", category, fmt.Sprintf("%s.json", difficulty))
-			questions, err := tm.loadQuestionsFromFile(filename, category, difficulty)
+			filename := filepath.Join(tm.dir, category.ID, fmt.Sprintf("%s.json", difficulty))
+			questions, err := tm.loadQuestionsFromFile(filename, category.ID, difficulty)
 			if err != nil {
 				errorMsg := fmt.Sprintf("Could not load %s: %v", filename, err)
 				log.Printf("Warning: %s", errorMsg)
@@ -72,12 +91,18 @@ func (tm *TriviaManager) loadAllQuestions() error {
 				continue
 			}
 
-			tm.questions[category][difficulty] = questions
+			if tm.questions[category.ID] == nil {
+				tm.questions[category.ID] = make(map[string][]TriviaQuestion)
+			}
+			tm.questions[category.ID][difficulty] = questions
 			totalLoaded += len(questions)
-			log.Printf("Loaded %d %s %s questions", len(questions), difficulty, category)
+			log.Printf("Loaded %d %s %s questions", len(questions), difficulty, category.ID)
 		}
 	}
 
+	// Only categories with questions are offered to players
+	tm.categories = loadedCategories(categories, tm.questions)
+
 	log.Printf("Total questions loaded: %d", totalLoaded)
 
 	if totalLoaded == 0 {
@@ -101,11 +126,7 @@ func (tm *TriviaManager) loadQuestionsFromFile(filename, category, difficulty st
 		return nil, err
 	}
 
-	var response struct {
-		ResponseCode int                  `json:"response_code"`
-		Results      []TriviaQuestionJSON `json:"results"`
-	}
-
+	var response triviaFile
 	if err := json.Unmarshal(data, &response); err != nil {
 		return nil, fmt.Errorf("invalid JSON format: %v", err)
 	}
@@ -121,7 +142,7 @@ func (tm *TriviaManager) loadQuestionsFromFile(filename, category, difficulty st
 	questions := make([]TriviaQuestion, 0, len(response.Results))
 	for i, q := range response.Results {
 		// Validate question data
-		if err := tm.validateQuestionData(q); err != nil {
+		if err := validateQuestionData(q); err != nil {
 			log.Printf("Skipping invalid question %d in %s: %v", i, filename, err)
 			continue
 		}
@@ -157,13 +178,13 @@ func (tm *TriviaManager) loadQuestionsFromFile(filename, category, difficulty st
 		}
 
 		// Shuffle options
-		rand.Shuffle(len(options), func(i, j int) {
+		tm.rng.Shuffle(len(options), func(i, j int) {
 			options[i], options[j] = options[j], options[i]
 		})
 
 		// Create unique ID that includes timestamp to prevent conflicts
 		questions = append(questions, TriviaQuestion{
-			ID:               fmt.Sprintf("%s_%s_%d_%d", category, difficulty, i, time.Now().UnixNano()%1000000),
+			ID:               fmt.Sprintf("%s_%s_%d_%d", category, difficulty, i, tm.clock.Now().UnixNano()%1000000),
 			Text:             questionText,
 			Category:         category,
 			Difficulty:       difficulty,
@@ -186,7 +207,7 @@ func (tm *TriviaManager) loadQuestionsFromFile(filename, category, difficulty st
 }
 
 // validateQuestionData validates the basic structure of question data
-func (tm *TriviaManager) validateQuestionData(q TriviaQuestionJSON) error {
+func validateQuestionData(q TriviaQuestionJSON) error {
 	if q.Question == "" {
 		return fmt.Errorf("empty question text")
 	}
@@ -226,7 +247,12 @@ func (tm *TriviaManager) initializeQuestionPools() {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
-	for category, difficulties := range tm.questions {
+	// Walk categories and difficulties in a fixed order so the seeded shuffles are reproducible
+	for _, category := range slices.Sorted(maps.Keys(tm.questions)) {
+		difficulties, ok := tm.questions[category]
+		if !ok {
+			continue
+		}
 		if tm.questionPools[category] == nil {
 			tm.questionPools[category] = make(map[string][]int)
 		}
@@ -234,7 +260,12 @@ func (tm *TriviaManager) initializeQuestionPools() {
 			tm.poolResetCounters[category] = make(map[string]int)
 		}
 
-		for difficulty, questions := range difficulties {
+		for _, difficulty := range []string{"easy", "medium", "hard"} {
+			questions, ok := difficulties[difficulty]
+			if !ok {
+				continue
+			}
+
 			// Create index pool
 			pool := make([]int, len(questions))
 			for i := range pool {
@@ -242,7 +273,7 @@ func (tm *TriviaManager) initializeQuestionPools() {
 			}
 
 			// Shuffle the initial pool
-			rand.Shuffle(len(pool), func(i, j int) {
+			tm.rng.Shuffle(len(pool), func(i, j int) {
 				pool[i], pool[j] = pool[j], pool[i]
 			})
 
@@ -274,12 +305,12 @@ func (tm *TriviaManager) GetQuestion(gameDifficulty string, playerSpecialties []
 	}
 
 	// Check if we should ask a specialty question
-	if len(playerSpecialties) > 0 && rand.Float32() < float32(specialtyChance) {
+	if len(playerSpecialties) > 0 && tm.rng.Float32() < float32(specialtyChance) {
 		isSpecialty = true
 		// Select from player's specialties
 		availableSpecialties := tm.getAvailableSpecialtyCategories(playerSpecialties, gameDifficulty)
 		if len(availableSpecialties) > 0 {
-			category = availableSpecialties[rand.Intn(len(availableSpecialties))]
+			category = availableSpecialties[tm.rng.Intn(len(availableSpecialties))]
 		} else {
 			// Fallback to regular question if no specialty questions available
 			isSpecialty = false
@@ -315,7 +346,7 @@ func (tm *TriviaManager) GetQuestion(gameDifficulty string, playerSpecialties []
 		if len(availableCategories) == 0 {
 			return nil, fmt.Errorf("no categories available for difficulty %s", questionDifficulty)
 		}
-		category = availableCategories[rand.Intn(len(availableCategories))]
+		category = availableCategories[tm.rng.Intn(len(availableCategories))]
 	}
 
 	// Get question from pool with cycling
@@ -346,7 +377,7 @@ func (tm *TriviaManager) GetQuestion(gameDifficulty string, playerSpecialties []
 	}
 
 	// Record when this question was asked
-	tm.questionHistory[question.ID] = time.Now()
+	tm.questionHistory[question.ID] = tm.clock.Now()
 
 	log.Printf("Generated %s question (specialty: %v) with %d second timeout for category %s",
 		questionDifficulty, isSpecialty, question.TimeLimit, category)
@@ -468,7 +499,7 @@ func (tm *TriviaManager) resetQuestionPool(category, difficulty string) {
 	}
 
 	// Shuffle the pool for variety in question order
-	rand.Shuffle(len(pool), func(i, j int) {
+	tm.rng.Shuffle(len(pool), func(i, j int) {
 		pool[i], pool[j] = pool[j], pool[i]
 	})
 
@@ -487,19 +518,21 @@ func (tm *TriviaManager) getAvailableCategories(difficulty string) []string {
 			available = append(available, category)
 		}
 	}
+	// Map order is random; sort so the seeded choice picks the same category every time
+	sort.Strings(available)
 	return available
 }
 
 // cleanupQuestionHistory removes old question history entries
 func (tm *TriviaManager) cleanupQuestionHistory() {
-	ticker := time.NewTicker(10 * time.Minute) // Cleanup every 10 minutes
+	ticker := tm.clock.NewTicker(10 * time.Minute) // Cleanup every 10 minutes
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			tm.mu.Lock()
-			cutoff := time.Now().Add(-30 * time.Minute) // Remove entries older than 30 minutes
+			cutoff := tm.clock.Now().Add(-30 * time.Minute) // Remove entries older than 30 minutes
 			cleaned := 0
 			for questionID, askTime := range tm.questionHistory {
 				if askTime.Before(cutoff) {
@@ -533,13 +566,7 @@ func (tm *TriviaManager) ValidateAnswer(questionID, playerAnswer string) (bool,
 		for _, questions := range difficulties {
 			for _, question := range questions {
 				if question.ID == questionID {
-					correct := tm.compareAnswers(question.CorrectAnswer, playerAnswer)
-
-					// Enhanced logging for debugging
-					log.Printf("Answer validation: questionID=%s, correct=%s, player=%s, result=%v",
-						questionID, question.CorrectAnswer, playerAnswer, correct)
-
-					return correct, nil
+					return tm.CheckAnswer(&question, playerAnswer), nil
 				}
 			}
 		}
@@ -548,6 +575,17 @@ func (tm *TriviaManager) ValidateAnswer(questionID, playerAnswer string) (bool,
 	return false, fmt.Errorf("question not found: %s", questionID)
 }
 
+// CheckAnswer compares a player's answer with the correct answer of a question
+func (tm *TriviaManager) CheckAnswer(question *TriviaQuestion, playerAnswer string) bool {
+	correct := tm.compareAnswers(question.CorrectAnswer, playerAnswer)
+
+	// Enhanced logging for debugging
+	log.Printf("Answer validation: questionID=%s, correct=%s, player=%s, result=%v",
+		question.ID, question.CorrectAnswer, playerAnswer, correct)
+
+	return correct
+}
+
 // Enhanced answer comparison with better variation handling
 func (tm *TriviaManager) compareAnswers(correct, player string) bool {
 	// Normalize both answers
@@ -807,15 +845,9 @@ func (tm *TriviaManager) checkAnswerVariations(correct, player string) bool {
 }
 
 // getDifficultyModifiersForTrivia gets difficulty modifiers for trivia
+// NOTE: This method assumes the caller already holds tm.mu lock
 func (tm *TriviaManager) getDifficultyModifiersForTrivia(gameDifficulty string) constants.DifficultyModifiers {
-	switch gameDifficulty {
-	case "easy":
-		return constants.EasyMode
-	case "hard":
-		return constants.HardMode
-	default:
-		return constants.MediumMode
-	}
+	return tm.balance.DifficultyModifiers.ForDifficulty(gameDifficulty)
 }
 
 // GetQuestionByID retrieves a question by ID with enhanced error handling
@@ -893,22 +925,29 @@ func (tm *TriviaManager) ReloadQuestions() error {
 	newPools := make(map[string]map[string][]int)
 	newCounters := make(map[string]map[string]int)
 
+	categories, err := discoverTriviaCategories(tm.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read trivia categories: %v", err)
+	}
+
 	difficulties := []string{"easy", "medium", "hard"}
 	totalLoaded := 0
 
-	for _, category := range constants.TriviaCategories {
-		newQuestions[category] = make(map[string][]TriviaQuestion)
-		newPools[category] = make(map[string][]int)
-		newCounters[category] = make(map[string]int)
-
+	for _, categoryInfo := range categories {
+		category := categoryInfo.ID
 		for _, difficulty := range difficulties {
-			filename := filepath.Join("trivia", category, fmt.Sprintf("%s.json", difficulty))
+			filename := filepath.Join(tm.dir, category, fmt.Sprintf("%s.json", difficulty))
 			questions, err := tm.loadQuestionsFromFile(filename, category, difficulty)
 			if err != nil {
 				log.Printf("Warning: Could not reload %s: %v", filename, err)
 				continue
 			}
 
+			if newQuestions[category] == nil {
+				newQuestions[category] = make(map[string][]TriviaQuestion)
+				newPools[category] = make(map[string][]int)
+				newCounters[category] = make(map[string]int)
+			}
 			newQuestions[category][difficulty] = questions
 			totalLoaded += len(questions)
 
@@ -917,7 +956,7 @@ func (tm *TriviaManager) ReloadQuestions() error {
 			for i := range pool {
 				pool[i] = i
 			}
-			rand.Shuffle(len(pool), func(i, j int) {
+			tm.rng.Shuffle(len(pool), func(i, j int) {
 				pool[i], pool[j] = pool[j], pool[i]
 			})
 
@@ -934,6 +973,7 @@ func (tm *TriviaManager) ReloadQuestions() error {
 
 	// Replace old data atomically
 	tm.mu.Lock()
+	tm.categories = loadedCategories(categories, newQuestions)
 	tm.questions = newQuestions
 	tm.questionPools = newPools
 	tm.poolResetCounters = newCounters
@@ -969,7 +1009,7 @@ func (tm *TriviaManager) GetSummaryStats() map[string]interface{} {
 		"totalQuestions":      totalQuestions,
 		"categoryCounts":      categoryCounts,
 		"difficultyCounts":    difficultyCounts,
-		"supportedCategories": constants.TriviaCategories,
+		"supportedCategories": tm.categoryIDsInternal(),
 		"historySize":         len(tm.questionHistory),
 		"poolStats":           tm.GetPoolStats(),
 		"cycling": map[string]interface{}{
@@ -1056,15 +1096,36 @@ func (tm *TriviaManager) IsCategorySupported(category string) bool {
 	return exists
 }
 
+// GetAvailableCategories returns the IDs of the categories with questions loaded, sorted; these
+// are the specialties players may pick
 func (tm *TriviaManager) GetAvailableCategories() []string {
 	tm.mu.RLock()
 	defer tm.mu.RUnlock()
+	return tm.categoryIDsInternal()
+}
 
-	categories := make([]string, 0, len(tm.questions))
-	for category := range tm.questions {
-		categories = append(categories, category)
+// GetCategoryDetails returns the names and descriptions of the categories with questions loaded
+func (tm *TriviaManager) GetCategoryDetails() []TriviaCategory {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return slices.Clone(tm.categories)
+}
+
+// categoryIDsInternal returns the IDs of the categories with questions loaded
+// NOTE: This method assumes the caller already holds tm.mu lock (read or write)
+func (tm *TriviaManager) categoryIDsInternal() []string {
+	return categoryIDs(tm.categories)
+}
+
+// loadedCategories keeps the categories that have questions
+func loadedCategories(categories []TriviaCategory, questions map[string]map[string][]TriviaQuestion) []TriviaCategory {
+	loaded := make([]TriviaCategory, 0, len(categories))
+	for _, category := range categories {
+		if len(questions[category.ID]) > 0 {
+			loaded = append(loaded, category)
+		}
 	}
-	return categories
+	return loaded
 }
 
 // Utility functions
@@ -1146,48 +1207,3 @@ func cleanHTMLEntities(text string) string {
 
 	return result
 }
-
-// normalizeCategory converts category names to match our expected format
-func normalizeCategory(category string) string {
-	// Convert to lowercase and replace spaces with underscores
-	normalized := strings.ToLower(category)
-	normalized = strings.ReplaceAll(normalized, " ", "_")
-	normalized = strings.ReplaceAll(normalized, ":", "")
-	normalized = strings.ReplaceAll(normalized, "-", "_")
-
-	// Map common variations to our supported categories
-	categoryMap := map[string]string{
-		"general_knowledge":                  "general",
-		"entertainment_video_games":          "video_games",
-		"entertainment_music":                "music",
-		"science_nature":                     "science",
-		"science_&_nature":                   "science",
-		"entertainment_books":                "general",
-		"entertainment_film":                 "general",
-		"entertainment_television":           "general",
-		"sports":                             "general",
-		"art":                                "general",
-		"celebrities":                        "general",
-		"animals":                            "science",
-		"vehicles":                           "general",
-		"entertainment_comics":               "general",
-		"entertainment_japanese_anime_manga": "general",
-		"entertainment_cartoons_animations":  "general",
-		"mythology":                          "history",
-		"politics":                           "history",
-	}
-
-	if mapped, ok := categoryMap[normalized]; ok {
-		return mapped
-	}
-
-	// Check if it's one of our supported categories
-	for _, supportedCat := range constants.TriviaCategories {
-		if normalized == supportedCat {
-			return normalized
-		}
-	}
-
-	// Default to general if we don't recognize the category
-	return "general"
-}
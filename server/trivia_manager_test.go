@@ -48,7 +48,7 @@ func setupTestTriviaFiles(t *testing.T) func() {
 }
 
 func TestNewTriviaManager(t *testing.T) {
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	defer tm.Shutdown()
 
 	assert.NotNil(t, tm)
@@ -59,7 +59,7 @@ func TestNewTriviaManager(t *testing.T) {
 func TestTriviaManagerQuestionLoading(t *testing.T) {
 	// This test would require actual trivia files or mocking
 	// For now, we'll test the structure
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	defer tm.Shutdown()
 
 	// Test that the manager initializes without panic
@@ -76,7 +76,7 @@ func TestTriviaManagerQuestionLoading(t *testing.T) {
 }
 
 func TestValidateAnswer(t *testing.T) {
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	defer tm.Shutdown()
 
 	// Since we can't easily mock the loaded questions, we'll test the answer validation logic
@@ -165,7 +165,7 @@ func TestValidateAnswer(t *testing.T) {
 }
 
 func TestGetQuestion(t *testing.T) {
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	defer tm.Shutdown()
 
 	// Test with empty question history
@@ -215,8 +215,34 @@ func TestGetQuestion(t *testing.T) {
 	}
 }
 
+func TestSeededQuestionSequence(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	draw := func(seed int64) []TriviaQuestion {
+		tm := NewTriviaManager(clock, seed)
+		defer tm.Shutdown()
+
+		asked := make(map[string]bool)
+		questions := make([]TriviaQuestion, 0, 10)
+		for i := 0; i < 10; i++ {
+			question, err := tm.GetQuestion("medium", []string{"science", "history"}, asked)
+			if !assert.NoError(t, err) {
+				break
+			}
+			asked[question.ID] = true
+			questions = append(questions, *question)
+		}
+		return questions
+	}
+
+	// Same seed, same questions with the same option order
+	first := draw(testSeed)
+	assert.Len(t, first, 10)
+	assert.Equal(t, first, draw(testSeed))
+	assert.NotEqual(t, first, draw(testSeed+1))
+}
+
 func TestGetCategoryStats(t *testing.T) {
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	defer tm.Shutdown()
 
 	stats := tm.GetCategoryStats()
@@ -240,7 +266,7 @@ func TestGetCategoryStats(t *testing.T) {
 }
 
 func TestGetPoolStats(t *testing.T) {
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	defer tm.Shutdown()
 
 	stats := tm.GetPoolStats()
@@ -258,7 +284,7 @@ func TestGetPoolStats(t *testing.T) {
 }
 
 func TestIsCategorySupported(t *testing.T) {
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	defer tm.Shutdown()
 
 	tests := []struct {
@@ -285,7 +311,7 @@ func TestIsCategorySupported(t *testing.T) {
 }
 
 func TestValidateQuestion(t *testing.T) {
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	defer tm.Shutdown()
 
 	// Test various question ID formats
@@ -313,7 +339,7 @@ func TestValidateQuestion(t *testing.T) {
 }
 
 func TestGetSummaryStats(t *testing.T) {
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	defer tm.Shutdown()
 
 	stats := tm.GetSummaryStats()
@@ -335,7 +361,7 @@ func TestGetSummaryStats(t *testing.T) {
 }
 
 func TestTriviaManagerConcurrency(t *testing.T) {
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	defer tm.Shutdown() // Ensure cleanup goroutine is stopped
 
 	var wg sync.WaitGroup
@@ -395,7 +421,7 @@ func TestTriviaManagerConcurrency(t *testing.T) {
 }
 
 func TestTriviaManagerHighConcurrency(t *testing.T) {
-	tm := NewTriviaManager()
+	tm := NewTriviaManager(realClock{}, testSeed)
 	defer tm.Shutdown()
 
 	var wg sync.WaitGroup
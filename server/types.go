@@ -42,17 +42,25 @@ const (
 	MsgGameAnalytics        = "game_analytics"
 	MsgGameReset            = "game_reset"
 	MsgError                = "error"
+	MsgAck                  = "ack"
+	MsgWelcome              = "welcome"
 	MsgHostUpdate           = "host_update"
 	MsgCountdown            = "countdown"
 	MsgPieceRecommendation  = "piece_recommendation"
 	MsgImagePreview         = "image_preview"
 	MsgPersonalPuzzleState  = "personal_puzzle_state"
 	MsgGuideHighlight       = "guide_highlight"
+	MsgGameTimerUpdate      = "game_timer_update"
+	MsgPlayerKicked         = "player_kicked"
+	MsgPlayerUpdate         = "player_update"
+	MsgFragmentMoved        = "fragment_moved"
+	MsgFragmentRevealed     = "fragment_revealed"
 )
 
 // WebSocket Message Types - Client to Server
 const (
 	MsgPlayerJoin                  = "player_join"
+	MsgHello                       = "hello"
 	MsgRoleSelection               = "role_selection"
 	MsgTriviaSpecialtySelection    = "trivia_specialty_selection"
 	MsgResourceLocationVerified    = "resource_location_verified"
@@ -62,14 +70,55 @@ const (
 	MsgPlayerReady                 = "player_ready"
 	MsgHostStartGame               = "host_start_game"
 	MsgHostStartPuzzle             = "host_start_puzzle"
+	MsgHostUpdateSettings          = "host_update_settings"
+	MsgHostPause                   = "host_pause"
+	MsgHostResume                  = "host_resume"
+	MsgHostAddTime                 = "host_add_time"
+	MsgHostAbortGame               = "host_abort_game"
+	MsgHostSkipPhase               = "host_skip_phase"
+	MsgHostRematch                 = "host_rematch"
+	MsgHostKickPlayer              = "host_kick_player"
+	MsgHostRenamePlayer            = "host_rename_player"
+	MsgHostAssignPlayer            = "host_assign_player"
+	MsgPlayerSetName               = "player_set_name"
 	MsgPieceRecommendationRequest  = "piece_recommendation_request"
 	MsgPieceRecommendationResponse = "piece_recommendation_response"
+	MsgPuzzleResyncRequest         = "puzzle_resync_request"
 )
 
+// clientMessageTypes are the messages a connected client may send
+var clientMessageTypes = map[string]bool{
+	MsgHello:                       true,
+	MsgRoleSelection:               true,
+	MsgTriviaSpecialtySelection:    true,
+	MsgResourceLocationVerified:    true,
+	MsgTriviaAnswer:                true,
+	MsgSegmentCompleted:            true,
+	MsgFragmentMoveRequest:         true,
+	MsgPlayerReady:                 true,
+	MsgHostStartGame:               true,
+	MsgHostStartPuzzle:             true,
+	MsgHostUpdateSettings:          true,
+	MsgHostPause:                   true,
+	MsgHostResume:                  true,
+	MsgHostAddTime:                 true,
+	MsgHostAbortGame:               true,
+	MsgHostSkipPhase:               true,
+	MsgHostRematch:                 true,
+	MsgHostKickPlayer:              true,
+	MsgHostRenamePlayer:            true,
+	MsgHostAssignPlayer:            true,
+	MsgPlayerSetName:               true,
+	MsgPieceRecommendationRequest:  true,
+	MsgPieceRecommendationResponse: true,
+	MsgPuzzleResyncRequest:         true,
+}
+
 // Base message structure for all communications
 type BaseMessage struct {
-	Type    string          `json:"type"`
-	Payload json.RawMessage `json:"payload,omitempty"`
+	Type      string          `json:"type"`
+	RequestID string          `json:"requestId,omitempty"` // Chosen by the client and echoed in errors about the message
+	Payload   json.RawMessage `json:"payload,omitempty"`
 }
 
 // Client authentication wrapper
@@ -80,21 +129,30 @@ type AuthWrapper struct {
 
 type AuthData struct {
 	PlayerID string `json:"playerId"`
+	Token    string `json:"token"` // Session token from available_roles
 }
 
 // Player represents a connected player
 type Player struct {
-	ID              string
-	Name            string
-	Role            string
-	Specialties     []string
-	State           PlayerState
-	Connection      *websocket.Conn
-	CurrentLocation string // Resource station hash
-	IsHost          bool
-	Ready           bool
-	LastSeen        time.Time
-	mu              sync.RWMutex
+	ID               string
+	Name             string
+	Role             string
+	Specialties      []string
+	State            PlayerState
+	Connection       *websocket.Conn
+	CurrentLocation  string // Resource station hash
+	IsHost           bool
+	Ready            bool
+	LastSeen         time.Time
+	AwaitingRecovery bool            // Restored from a snapshot and not yet reconnected
+	Address          string          // Client IP of the latest connection; guarded by PlayerManager.mu
+	SessionToken     string          // Issued on this connection; sent in available_roles
+	writer           *playerWriter   // Sole writer to Connection; nil while disconnected
+	limiter          *messageLimiter // Rate limits the player's messages; kept across reconnections
+	requests         *requestLog     // Outcomes of recent messages with a requestId; kept across reconnections
+	features         map[string]bool // Protocol features enabled by hello; nil until the connection says hello
+	eventLog         *EventLog       // Records messages sent to this player; nil when logging is disabled
+	mu               sync.RWMutex
 }
 
 // Role information
@@ -127,6 +185,12 @@ type TriviaQuestionJSON struct {
 	IncorrectAnswers []string `json:"incorrect_answers"`
 }
 
+// triviaFile is a question bank file, trivia/{category}/{difficulty}.json, in the Open Trivia DB response shape
+type triviaFile struct {
+	ResponseCode int                  `json:"response_code"`
+	Results      []TriviaQuestionJSON `json:"results"`
+}
+
 // Team Tokens
 type TeamTokens struct {
 	AnchorTokens  int `json:"anchorTokens"`
@@ -237,11 +301,40 @@ type HostUpdate struct {
 	ReadyPlayers     int                     `json:"readyPlayers"`
 	CurrentRound     int                     `json:"currentRound,omitempty"`
 	TimeRemaining    int                     `json:"timeRemaining,omitempty"`
+	Paused           bool                    `json:"paused,omitempty"`
 	TeamTokens       TeamTokens              `json:"teamTokens,omitempty"`
 	PlayerStatuses   map[string]PlayerStatus `json:"playerStatuses"`
 	PuzzleProgress   float64                 `json:"puzzleProgress,omitempty"`
 }
 
+// TimerUpdate tells every player that the host paused, resumed or extended the running clock
+type TimerUpdate struct {
+	Action        string `json:"action"` // paused, resumed or time_added
+	Phase         string `json:"phase"`
+	Paused        bool   `json:"paused"`
+	CurrentRound  int    `json:"currentRound,omitempty"`
+	TimeRemaining int    `json:"timeRemaining"`          // Seconds left in the round or puzzle
+	Deadline      int64  `json:"deadline,omitempty"`     // Unix time the round or puzzle ends; omitted while paused
+	AddedSeconds  int    `json:"addedSeconds,omitempty"` // Only set for time_added
+}
+
+// LobbyPlayer is how a player appears to everyone in game_lobby_status
+type LobbyPlayer struct {
+	Name      string `json:"name"`
+	Role      string `json:"role,omitempty"`
+	Ready     bool   `json:"ready"`
+	Connected bool   `json:"connected"`
+}
+
+// PlayerUpdate tells a player that the host changed their name, role or specialties
+type PlayerUpdate struct {
+	PlayerID    string   `json:"playerId"`
+	Name        string   `json:"name"`
+	Role        string   `json:"role,omitempty"`
+	Specialties []string `json:"specialties,omitempty"`
+	Ready       bool     `json:"ready"`
+}
+
 type PlayerStatus struct {
 	Name      string `json:"name"`
 	Role      string `json:"role"`
@@ -250,24 +343,66 @@ type PlayerStatus struct {
 	Location  string `json:"location,omitempty"`
 }
 
+// GameSettings holds the balance values a host may adjust per game during setup.
+// Difficulty modifiers still scale these values the same way they scale the defaults.
+type GameSettings struct {
+	ResourceRounds   int `json:"resourceRounds"`   // Trivia rounds in resource gathering
+	RoundDuration    int `json:"roundDuration"`    // Length of each resource gathering round (seconds)
+	PuzzleBaseTime   int `json:"puzzleBaseTime"`   // Puzzle time before chronos bonuses (seconds)
+	AnchorThreshold  int `json:"anchorThreshold"`  // Anchor tokens per threshold
+	ChronosThreshold int `json:"chronosThreshold"` // Chronos tokens per threshold
+	GuideThreshold   int `json:"guideThreshold"`   // Guide tokens per threshold
+	ClarityThreshold int `json:"clarityThreshold"` // Clarity tokens per threshold
+	GridSize         int `json:"gridSize"`         // Puzzle grid dimension; 0 scales with the player count
+	MinPlayers       int `json:"minPlayers"`       // Non-host players needed to start
+	ReconnectGrace   int `json:"reconnectGrace"`   // Time a player dropped from the puzzle has to come back (seconds)
+}
+
+// GameSettingsUpdate is the host_update_settings payload; omitted fields keep their current value.
+// A preset resets the settings to the server defaults and applies the preset before the other fields.
+type GameSettingsUpdate struct {
+	Preset           *string `json:"preset,omitempty" validate:"preset"`
+	Difficulty       *string `json:"difficulty,omitempty" validate:"difficulty"`
+	ResourceRounds   *int    `json:"resourceRounds,omitempty" validate:"bounds"`
+	RoundDuration    *int    `json:"roundDuration,omitempty" validate:"bounds"`
+	PuzzleBaseTime   *int    `json:"puzzleBaseTime,omitempty" validate:"bounds"`
+	AnchorThreshold  *int    `json:"anchorThreshold,omitempty" validate:"bounds"`
+	ChronosThreshold *int    `json:"chronosThreshold,omitempty" validate:"bounds"`
+	GuideThreshold   *int    `json:"guideThreshold,omitempty" validate:"bounds"`
+	ClarityThreshold *int    `json:"clarityThreshold,omitempty" validate:"bounds"`
+	MinPlayers       *int    `json:"minPlayers,omitempty" validate:"bounds"`
+	ReconnectGrace   *int    `json:"reconnectGrace,omitempty" validate:"bounds"`
+	GridSize         *int    `json:"gridSize,omitempty" validate:"gridSize"`
+}
+
 // Game State
 type GameState struct {
-	Phase                GamePhase
-	Difficulty           string
-	Players              map[string]*Player
-	TeamTokens           TeamTokens
-	CurrentRound         int
-	RoundStartTime       time.Time
-	PuzzleStartTime      time.Time
-	PuzzleFragments      map[string]*PuzzleFragment
-	GridSize             int
-	PuzzleImageID        string
-	QuestionHistory      map[string]map[string]bool // playerID -> questionID -> answered
-	PlayerAnalytics      map[string]*PlayerAnalytics
-	FragmentMoveHistory  []FragmentMove
-	PieceRecommendations map[string]*PieceRecommendation // recommendationID -> recommendation
-	CurrentQuestions     map[string]*TriviaQuestion      // playerID -> current question
-	mu                   sync.RWMutex
+	Phase                   GamePhase
+	Difficulty              string
+	Settings                GameSettings
+	Players                 map[string]*Player
+	TeamTokens              TeamTokens
+	CurrentRound            int
+	RoundStartTime          time.Time
+	RoundExtension          time.Duration // Time the host added to the current round
+	PuzzleStartTime         time.Time
+	PuzzleDuration          time.Duration
+	Paused                  bool      // The host froze the round or puzzle clock
+	PausedAt                time.Time // Start times are moved forward by the pause on resume
+	PuzzleFragments         map[string]*PuzzleFragment
+	FragmentHolds           map[string]time.Time // playerID -> when their held fragment is handed over
+	PuzzleSeq               int                  // Version of the puzzle state; one up per change sent to clients
+	GridSize                int
+	PuzzleImageID           string
+	QuestionHistory         map[string]map[string]bool // playerID -> questionID -> answered
+	PlayerAnalytics         map[string]*PlayerAnalytics
+	FragmentMoveHistory     []FragmentMove
+	PieceRecommendations    map[string]*PieceRecommendation // recommendationID -> recommendation
+	CurrentQuestions        map[string]*TriviaQuestion      // playerID -> current question
+	StartedAt               time.Time
+	TriviaAnswers           []TriviaAnswerRecord
+	ResolvedRecommendations []RecommendationRecord
+	mu                      sync.RWMutex
 }
 
 type FragmentMove struct {
@@ -278,6 +413,27 @@ type FragmentMove struct {
 	Timestamp  time.Time `json:"timestamp"`
 }
 
+// TriviaAnswerRecord is one answered trivia question, kept for the game history
+type TriviaAnswerRecord struct {
+	PlayerID      string    `json:"playerId"`
+	QuestionID    string    `json:"questionId"`
+	Category      string    `json:"category"`
+	IsSpecialty   bool      `json:"isSpecialty"`
+	Answer        string    `json:"answer"`
+	Correct       bool      `json:"correct"`
+	Round         int       `json:"round"`
+	TokenType     string    `json:"tokenType,omitempty"`
+	TokensAwarded int       `json:"tokensAwarded"`
+	AnsweredAt    time.Time `json:"answeredAt"`
+}
+
+// RecommendationRecord is a piece recommendation along with how the receiving player responded
+type RecommendationRecord struct {
+	PieceRecommendation
+	Status      string    `json:"status"` // "pending", "accepted" or "rejected"
+	RespondedAt time.Time `json:"respondedAt,omitempty"`
+}
+
 // Broadcast message structure
 type BroadcastMessage struct {
 	Type    string
@@ -287,10 +443,10 @@ type BroadcastMessage struct {
 
 // Personal Puzzle State - Individual player view of the puzzle
 type PersonalPuzzleState struct {
-	Fragments        []*PuzzleFragment `json:"fragments"`        // Only visible fragments
-	GridSize         int               `json:"gridSize"`         // Grid dimensions
-	PlayerFragmentID string            `json:"playerFragmentId"` // Player's own fragment ID
-	GuideHighlight   *GuideHighlight   `json:"guideHighlight"`   // Player-specific guide highlighting
+	Fragments        []*PuzzleFragment `json:"fragments"`                // Only visible fragments
+	GridSize         int               `json:"gridSize"`                 // Grid dimensions
+	PlayerFragmentID string            `json:"playerFragmentId"`         // Player's own fragment ID
+	GuideHighlight   *GuideHighlight   `json:"guideHighlight,omitempty"` // Player-specific guide highlighting; needs FeatureGuideHighlights
 }
 
 // Guide Highlight - Linear progression guide token effects
@@ -312,6 +468,7 @@ type CompletePuzzleState struct {
 	CompletionPercent   float64              `json:"completionPercent"`   // Percentage of puzzle completed
 	MovementHistory     []FragmentMove       `json:"movementHistory"`     // Recent movement activity
 	CollaborationStats  CollaborationSummary `json:"collaborationStats"`  // Real-time collaboration metrics
+	Seq                 int                  `json:"seq"`                 // Puzzle version this snapshot reflects
 }
 
 // Collaboration Summary - Real-time collaboration metrics for host
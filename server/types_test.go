@@ -96,6 +96,10 @@ func TestBaseMessageMarshaling(t *testing.T) {
 }
 
 func TestAuthWrapperValidation(t *testing.T) {
+	playerID := "123e4567-e89b-12d3-a456-426614174000"
+	tokens := NewRandomSessionTokens()
+	token := tokens.Issue(playerID)
+
 	tests := []struct {
 		name    string
 		json    string
@@ -104,9 +108,14 @@ func TestAuthWrapperValidation(t *testing.T) {
 	}{
 		{
 			name:    "Valid auth wrapper",
-			json:    `{"auth": {"playerId": "123e4567-e89b-12d3-a456-426614174000"}, "payload": {"test": true}}`,
+			json:    `{"auth": {"playerId": "` + playerID + `", "token": "` + token + `"}, "payload": {"test": true}}`,
 			wantErr: false,
-			wantID:  "123e4567-e89b-12d3-a456-426614174000",
+			wantID:  playerID,
+		},
+		{
+			name:    "Missing token",
+			json:    `{"auth": {"playerId": "` + playerID + `"}, "payload": {"test": true}}`,
+			wantErr: true,
 		},
 		{
 			name:    "Missing auth field",
@@ -127,7 +136,7 @@ func TestAuthWrapperValidation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			wrapper, errors := validateAuthWrapper([]byte(tt.json))
+			wrapper, errors := validateAuthWrapper([]byte(tt.json), tokens, playerID)
 
 			if tt.wantErr {
 				assert.True(t, len(errors) > 0, "Expected validation errors but got none")
@@ -3,29 +3,68 @@ package main
 import (
 	"encoding/json"
 	"log"
+	"math/rand"
+	"sync"
 )
 
 // sendToPlayer sends a message to a specific player
 func sendToPlayer(player *Player, msgType string, payload interface{}) error {
+	return writeToPlayer(player, msgType, payload, true)
+}
+
+// writeToPlayer queues a message for a player, recording it in the event log when record is set.
+// The broadcaster records each broadcast once instead of once per recipient.
+func writeToPlayer(player *Player, msgType string, payload interface{}, record bool) error {
 	player.mu.RLock()
-	conn := player.Connection
+	writer := player.writer
+	eventLog := player.eventLog
+	feature, gated := featureMessages[msgType]
+	enabled := !gated || playerSupportsInternal(player, feature)
 	player.mu.RUnlock()
 
-	if conn == nil {
+	if writer == nil {
 		return nil // Player disconnected, silently ignore
 	}
+	if !enabled {
+		return nil // The player's client didn't ask for this kind of message
+	}
 
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
 
+	if record {
+		if redactor, ok := payload.(logRedactor); ok {
+			eventLog.Record(EventOutbound, player.ID, msgType, redactor.redactedForLog())
+		} else {
+			eventLog.Record(EventOutbound, player.ID, msgType, json.RawMessage(payloadBytes))
+		}
+	}
+
 	msg := BaseMessage{
 		Type:    msgType,
 		Payload: payloadBytes,
 	}
 
-	return conn.WriteJSON(msg)
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return writer.enqueue(msgType, data)
+}
+
+// closePlayerConnection closes a player's connection from the server side with a close code telling
+// the client why. Messages already queued for the player are sent first.
+func closePlayerConnection(player *Player, code int, reason string) {
+	player.mu.RLock()
+	writer := player.writer
+	player.mu.RUnlock()
+
+	if writer != nil {
+		writer.closeAfterFlush(code, reason)
+	}
 }
 
 // mustMarshal marshals data or panics
@@ -37,6 +76,36 @@ func mustMarshal(v interface{}) json.RawMessage {
 	return data
 }
 
+// lockedSource makes a seeded rand.Source safe to share between goroutines
+type lockedSource struct {
+	src rand.Source64
+	mu  sync.Mutex
+}
+
+func (ls *lockedSource) Int63() int64 {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.src.Int63()
+}
+
+func (ls *lockedSource) Uint64() uint64 {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.src.Uint64()
+}
+
+func (ls *lockedSource) Seed(seed int64) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.src.Seed(seed)
+}
+
+// newLockedRand creates a seeded random generator that may be used from several goroutines.
+// Only methods that draw from the source (Intn, Float32, Shuffle, ...) are safe; Read is not.
+func newLockedRand(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)})
+}
+
 // logError logs an error with context
 func logError(context string, err error) {
 	if err != nil {
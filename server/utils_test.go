@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"errors"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -120,6 +121,27 @@ func TestMustMarshal(t *testing.T) {
 	}
 }
 
+func TestLockedRand(t *testing.T) {
+	a, b := newLockedRand(testSeed), newLockedRand(testSeed)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, a.Intn(1000), b.Intn(1000))
+	}
+
+	// Shared between goroutines without a data race
+	shared := newLockedRand(testSeed)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				shared.Shuffle(10, func(i, j int) {})
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 func TestLogError(t *testing.T) {
 	// Test various error logging scenarios
 	tests := []struct {
@@ -3,9 +3,13 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"reflect"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
@@ -32,6 +36,8 @@ const (
 	MaxPlayerNameLength     = 50
 	MaxMessageLength        = 1000
 	MaxSpecialtiesPerPlayer = 2
+	MaxAnswerLength         = 200 // Reasonable limit for trivia answers
+	MaxFeatureNameLength    = 50
 	MinGridPosition         = 0
 	MaxGridPosition         = 10 // Reasonable upper bound
 )
@@ -41,8 +47,21 @@ var (
 	playerIDRegex   = regexp.MustCompile(`^[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{12}$`)
 	playerNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_\-\s]{1,50}$`)
 	hashRegex       = regexp.MustCompile(`^[A-Z_0-9]{10,50}$`)
+	joinCodeRegex   = regexp.MustCompile(fmt.Sprintf(`^[%s]{%d}$`, constants.JoinCodeAlphabet, constants.JoinCodeLength))
+	presetNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_\-]{0,39}$`)
+	questionIDRegex = regexp.MustCompile(`^[a-z0-9_]+_(easy|medium|hard)_[0-9]+_[0-9]+$`) // category_difficulty_index_timestamp; categories may contain underscores
+	segmentIDRegex  = regexp.MustCompile(`^segment_[a-z][0-9]+$`)                         // segment_a1, segment_b2, etc.
+	fragmentIDRegex = regexp.MustCompile(`^fragment_`)
 )
 
+// playerRoles are the roles a player can pick
+var playerRoles = []string{
+	constants.RoleArtEnthusiast,
+	constants.RoleDetective,
+	constants.RoleTourist,
+	constants.RoleJanitor,
+}
+
 // validatePlayerID validates a player ID format (UUID)
 func validatePlayerID(playerID string) *ValidationError {
 	if playerID == "" {
@@ -54,6 +73,23 @@ func validatePlayerID(playerID string) *ValidationError {
 	return nil
 }
 
+// validateJoinCode validates a room join code after normalization
+func validateJoinCode(code string) *ValidationError {
+	if code == "" {
+		return &ValidationError{Field: "roomCode", Message: "join code cannot be empty"}
+	}
+	if !joinCodeRegex.MatchString(code) {
+		return &ValidationError{Field: "roomCode", Message: constants.ErrInvalidJoinCode}
+	}
+	return nil
+}
+
+// cleanPlayerName trims a name and collapses runs of whitespace inside it to single spaces, so
+// "Ann" and " Ann  " can't pass as different names
+func cleanPlayerName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
 // validatePlayerName validates a player name
 func validatePlayerName(name string) ValidationError {
 	if name == "" {
@@ -77,21 +113,14 @@ func validateRole(role string) *ValidationError {
 		return &ValidationError{Field: "role", Message: "role cannot be empty"}
 	}
 
-	validRoles := map[string]bool{
-		constants.RoleArtEnthusiast: true,
-		constants.RoleDetective:     true,
-		constants.RoleTourist:       true,
-		constants.RoleJanitor:       true,
-	}
-
-	if !validRoles[role] {
+	if !slices.Contains(playerRoles, role) {
 		return &ValidationError{Field: "role", Message: "invalid role selection"}
 	}
 	return nil
 }
 
-// validateSpecialties validates trivia specialty selections
-func validateSpecialties(specialties []string) []ValidationError {
+// validateSpecialties validates trivia specialty selections against the categories the question bank has
+func validateSpecialties(specialties []string, categories []string) []ValidationError {
 	var errors []ValidationError
 
 	if len(specialties) == 0 {
@@ -103,11 +132,6 @@ func validateSpecialties(specialties []string) []ValidationError {
 		errors = append(errors, ValidationError{Field: "specialties", Message: fmt.Sprintf("too many specialties (max %d)", MaxSpecialtiesPerPlayer)})
 	}
 
-	validCategories := make(map[string]bool)
-	for _, cat := range constants.TriviaCategories {
-		validCategories[cat] = true
-	}
-
 	seen := make(map[string]bool)
 	for i, specialty := range specialties {
 		if specialty == "" {
@@ -121,7 +145,7 @@ func validateSpecialties(specialties []string) []ValidationError {
 		}
 		seen[specialty] = true
 
-		if !validCategories[specialty] {
+		if !slices.Contains(categories, specialty) {
 			errors = append(errors, ValidationError{Field: fmt.Sprintf("specialties[%d]", i), Message: "invalid specialty category"})
 		}
 	}
@@ -157,35 +181,22 @@ func validateLocationHash(hash string) ValidationError {
 
 // validateTriviaAnswer validates a trivia answer submission
 func validateTriviaAnswer(questionID, answer string, timestamp int64) []ValidationError {
-	var errors []ValidationError
-
-	if questionID == "" {
-		errors = append(errors, ValidationError{Field: "questionId", Message: "question ID cannot be empty"})
-	} else {
-		// Basic format check - should be category_difficulty_index_timestamp
-		parts := strings.Split(questionID, "_")
-		if len(parts) != 4 {
-			errors = append(errors, ValidationError{Field: "questionId", Message: "invalid question ID format"})
-		}
-	}
+	return checkPayload(TriviaAnswerPayload{QuestionID: questionID, Answer: answer, Timestamp: timestamp})
+}
 
+// validateAnswer validates the text of a trivia answer
+func validateAnswer(field, answer string) []ValidationError {
 	if answer == "" {
-		errors = append(errors, ValidationError{Field: "answer", Message: "answer cannot be empty"})
-	} else {
-		if !utf8.ValidString(answer) {
-			errors = append(errors, ValidationError{Field: "answer", Message: "answer contains invalid UTF-8 characters"})
-		}
-		if len(answer) > 200 { // Reasonable limit for trivia answers
-			errors = append(errors, ValidationError{Field: "answer", Message: "answer too long (max 200 characters)"})
-		}
+		return []ValidationError{{Field: field, Message: "answer cannot be empty"}}
 	}
 
-	if timestamp <= 0 {
-		errors = append(errors, ValidationError{Field: "timestamp", Message: "invalid timestamp"})
-	} else if timestamp > time.Now().Unix() {
-		errors = append(errors, ValidationError{Field: "timestamp", Message: "timestamp cannot be in the future"})
+	var errors []ValidationError
+	if !utf8.ValidString(answer) {
+		errors = append(errors, ValidationError{Field: field, Message: "answer contains invalid UTF-8 characters"})
+	}
+	if len(answer) > MaxAnswerLength {
+		errors = append(errors, ValidationError{Field: field, Message: fmt.Sprintf("answer too long (max %d characters)", MaxAnswerLength)})
 	}
-
 	return errors
 }
 
@@ -206,9 +217,7 @@ func validateSegmentID(segmentID string) ValidationError {
 		return ValidationError{Field: "segmentId", Message: "segment ID cannot be empty"}
 	}
 
-	// Format should be: segment_a1, segment_b2, etc.
-	segmentRegex := regexp.MustCompile(`^segment_[a-z][0-9]+$`)
-	if !segmentRegex.MatchString(segmentID) {
+	if !segmentIDRegex.MatchString(segmentID) {
 		return ValidationError{Field: "segmentId", Message: "invalid segment ID format"}
 	}
 
@@ -217,21 +226,7 @@ func validateSegmentID(segmentID string) ValidationError {
 
 // validateFragmentMove validates a fragment movement request
 func validateFragmentMove(fragmentID string, newPos GridPos, timestamp int64, maxGridSize int, ownership string) []ValidationError {
-	var errors []ValidationError
-
-	if fragmentID == "" {
-		errors = append(errors, ValidationError{Field: "fragmentId", Message: "fragment ID cannot be empty"})
-	} else if !strings.HasPrefix(fragmentID, "fragment_") {
-		errors = append(errors, ValidationError{Field: "fragmentId", Message: "invalid fragment ID format"})
-	}
-
-	if posErr := validateGridPosition(newPos, maxGridSize); posErr != nil {
-		errors = append(errors, *posErr)
-	}
-
-	if timestamp <= 0 {
-		errors = append(errors, ValidationError{Field: "timestamp", Message: "invalid timestamp"})
-	}
+	errors := checkPayloadOnGrid(FragmentMoveRequestPayload{FragmentID: fragmentID, NewPosition: newPos, Timestamp: timestamp}, maxGridSize)
 
 	// Validate ownership format if provided
 	if ownership != "" && ownership != "anyone" {
@@ -247,42 +242,18 @@ func validateFragmentMove(fragmentID string, newPos GridPos, timestamp int64, ma
 
 // validatePieceRecommendation validates a piece recommendation request (no message field)
 func validatePieceRecommendation(toPlayerID, fromFragmentID, toFragmentID string, fromPos, toPos GridPos, maxGridSize int) []ValidationError {
-	var errors []ValidationError
-
-	if playerErr := validatePlayerID(toPlayerID); playerErr != nil {
-		playerErr.Field = "toPlayerId"
-		errors = append(errors, *playerErr)
-	}
-
-	if fromFragmentID == "" {
-		errors = append(errors, ValidationError{Field: "fromFragmentId", Message: "from fragment ID cannot be empty"})
-	} else if !strings.HasPrefix(fromFragmentID, "fragment_") {
-		errors = append(errors, ValidationError{Field: "fromFragmentId", Message: "invalid from fragment ID format"})
-	}
-
-	if toFragmentID == "" {
-		errors = append(errors, ValidationError{Field: "toFragmentId", Message: "to fragment ID cannot be empty"})
-	} else if !strings.HasPrefix(toFragmentID, "fragment_") {
-		errors = append(errors, ValidationError{Field: "toFragmentId", Message: "invalid to fragment ID format"})
-	}
-
-	// Note: Message field removed - no longer supported
-
-	if fromPosErr := validateGridPosition(fromPos, maxGridSize); fromPosErr != nil {
-		fromPosErr.Field = "suggestedFromPos"
-		errors = append(errors, *fromPosErr)
-	}
-
-	if toPosErr := validateGridPosition(toPos, maxGridSize); toPosErr != nil {
-		toPosErr.Field = "suggestedToPos"
-		errors = append(errors, *toPosErr)
-	}
-
-	return errors
+	return checkPayloadOnGrid(PieceRecommendationRequestPayload{
+		ToPlayerID:       toPlayerID,
+		FromFragmentID:   fromFragmentID,
+		ToFragmentID:     toFragmentID,
+		SuggestedFromPos: fromPos,
+		SuggestedToPos:   toPos,
+	}, maxGridSize)
 }
 
-// validateAuthWrapper validates the authentication wrapper
-func validateAuthWrapper(data []byte) (*AuthWrapper, []ValidationError) {
+// validateAuthWrapper validates the authentication wrapper and checks that its session token
+// belongs to the player the connection was opened by
+func validateAuthWrapper(data []byte, tokens *SessionTokens, connectionPlayerID string) (*AuthWrapper, []ValidationError) {
 	var wrapper AuthWrapper
 	var errors []ValidationError
 
@@ -295,6 +266,15 @@ func validateAuthWrapper(data []byte) (*AuthWrapper, []ValidationError) {
 		errors = append(errors, *playerErr)
 	}
 
+	// The token must be current and issued to the player this connection belongs to
+	if wrapper.Auth.Token == "" {
+		errors = append(errors, ValidationError{Field: "auth.token", Message: "session token cannot be empty"})
+	} else if tokenPlayerID, err := tokens.Verify(wrapper.Auth.Token); err != nil {
+		errors = append(errors, ValidationError{Field: "auth.token", Message: err.Error()})
+	} else if tokenPlayerID != connectionPlayerID {
+		errors = append(errors, ValidationError{Field: "auth.token", Message: constants.ErrSessionTokenMismatch})
+	}
+
 	if len(wrapper.Payload) == 0 {
 		errors = append(errors, ValidationError{Field: "payload", Message: "payload cannot be empty"})
 	}
@@ -315,246 +295,618 @@ func validateJSONPayload(payload json.RawMessage, target interface{}) Validation
 	return ValidationError{}
 }
 
-// Specific validation functions for each WebSocket event type
+// Payload rules
 
-// ValidateRoleSelection validates role selection payload
-func ValidateRoleSelection(payload json.RawMessage) (map[string]interface{}, []ValidationError) {
-	var data struct {
-		Role string `json:"role"`
-	}
+// ruleContext is what a rule knows about the game it checks a payload for
+type ruleContext struct {
+	gridSize   int      // Width of the puzzle grid positions must fall inside
+	categories []string // Trivia categories specialties must be picked from
+}
+
+// fieldRule is a constraint a payload field's validate tag can name. A rule both checks values and
+// describes itself in the field's JSON Schema, so what the schema promises is what the server checks.
+type fieldRule struct {
+	required bool                                                                     // Zero values fail the check, so the field must be sent
+	schema   func(field string, s *JSONSchema)                                        // Adds the constraint to the field's schema
+	check    func(field string, value interface{}, ctx ruleContext) []ValidationError // value is never a pointer
+}
+
+// fieldBounds are the inclusive ranges of the integer fields checked by the bounds rule
+var fieldBounds = map[string][2]int{
+	"seconds":          {constants.MinAddTimeSeconds, constants.MaxAddTimeSeconds},
+	"resourceRounds":   {constants.MinResourceRounds, constants.MaxResourceRounds},
+	"roundDuration":    {constants.MinRoundDuration, constants.MaxRoundDuration},
+	"puzzleBaseTime":   {constants.MinPuzzleBaseTime, constants.MaxPuzzleBaseTime},
+	"anchorThreshold":  {constants.MinTokenThreshold, constants.MaxTokenThreshold},
+	"chronosThreshold": {constants.MinTokenThreshold, constants.MaxTokenThreshold},
+	"guideThreshold":   {constants.MinTokenThreshold, constants.MaxTokenThreshold},
+	"clarityThreshold": {constants.MinTokenThreshold, constants.MaxTokenThreshold},
+	"minPlayers":       {constants.MinPlayersFloor, constants.MaxPlayers},
+	"reconnectGrace":   {constants.MinReconnectGrace, constants.MaxReconnectGrace},
+}
+
+// fieldRules are the rules validate tags can name. A tag lists rules separated by commas, checked in
+// order until one fails. The required keyword makes a pointer field mandatory.
+var fieldRules = map[string]fieldRule{
+	"uuid": {
+		required: true,
+		schema: func(field string, s *JSONSchema) {
+			s.Format = "uuid"
+			s.Pattern = playerIDRegex.String()
+		},
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			return checkFormat(field, value.(string), playerIDRegex)
+		},
+	},
+	"fragmentId": {
+		required: true,
+		schema:   func(field string, s *JSONSchema) { s.Pattern = fragmentIDRegex.String() },
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			return checkFormat(field, value.(string), fragmentIDRegex)
+		},
+	},
+	"questionId": {
+		required: true,
+		schema:   func(field string, s *JSONSchema) { s.Pattern = questionIDRegex.String() },
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			return checkFormat(field, value.(string), questionIDRegex)
+		},
+	},
+	"segmentId": {
+		required: true,
+		schema:   func(field string, s *JSONSchema) { s.Pattern = segmentIDRegex.String() },
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			return checkFormat(field, value.(string), segmentIDRegex)
+		},
+	},
+	"role": {
+		required: true,
+		schema:   func(field string, s *JSONSchema) { s.Enum = playerRoles },
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			if roleErr := validateRole(value.(string)); roleErr != nil {
+				return []ValidationError{*roleErr}
+			}
+			return nil
+		},
+	},
+	"specialties": {
+		required: true,
+		schema: func(field string, s *JSONSchema) {
+			s.MinItems = intPtr(1)
+			s.MaxItems = intPtr(MaxSpecialtiesPerPlayer)
+			s.UniqueItems = true
+			s.Items.Description = "A category ID from triviaCategories in available_roles"
+		},
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			return validateSpecialties(value.([]string), ctx.categories)
+		},
+	},
+	"playerName": {
+		required: true,
+		schema: func(field string, s *JSONSchema) {
+			s.MinLength = intPtr(1)
+			s.MaxLength = intPtr(MaxPlayerNameLength)
+			s.Pattern = playerNameRegex.String()
+		},
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			return singleError(validatePlayerName(value.(string)))
+		},
+	},
+	"locationHash": {
+		required: true,
+		schema: func(field string, s *JSONSchema) {
+			s.Enum = slices.Sorted(maps.Values(constants.ResourceStationHashes))
+		},
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			return singleError(validateLocationHash(value.(string)))
+		},
+	},
+	"answer": {
+		required: true,
+		schema: func(field string, s *JSONSchema) {
+			s.MinLength = intPtr(1)
+			s.MaxLength = intPtr(MaxAnswerLength)
+		},
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			return validateAnswer(field, value.(string))
+		},
+	},
+	"timestamp": {
+		required: true,
+		schema: func(field string, s *JSONSchema) {
+			s.Minimum = intPtr(1)
+			s.Description = "Unix time in seconds"
+		},
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			if value.(int64) <= 0 {
+				return []ValidationError{{Field: field, Message: "invalid " + fieldLabel(field)}}
+			}
+			return nil
+		},
+	},
+	"pastTimestamp": {
+		required: true,
+		schema: func(field string, s *JSONSchema) {
+			s.Minimum = intPtr(1)
+			s.Description = "Unix time in seconds, not in the future"
+		},
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			if value.(int64) <= 0 {
+				return []ValidationError{{Field: field, Message: "invalid " + fieldLabel(field)}}
+			}
+			if value.(int64) > time.Now().Unix() {
+				return []ValidationError{{Field: field, Message: fieldLabel(field) + " cannot be in the future"}}
+			}
+			return nil
+		},
+	},
+	"gridPosition": {
+		required: true,
+		schema: func(field string, s *JSONSchema) {
+			coordinate := &JSONSchema{Type: "integer", Minimum: intPtr(MinGridPosition), Maximum: intPtr(constants.MaxGridSize - 1)}
+			*s = JSONSchema{
+				Type:        "object",
+				Description: "Must be inside the puzzle grid, from 0 to gridSize-1",
+				Properties:  map[string]*JSONSchema{"x": coordinate, "y": coordinate},
+				Required:    []string{"x", "y"},
+			}
+		},
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			posErr := validateGridPosition(value.(GridPos), ctx.gridSize)
+			if posErr == nil {
+				return nil
+			}
+			posErr.Field = field + strings.TrimPrefix(posErr.Field, "position")
+			return []ValidationError{*posErr}
+		},
+	},
+	"bounds": {
+		schema: func(field string, s *JSONSchema) {
+			s.Minimum = intPtr(fieldBounds[field][0])
+			s.Maximum = intPtr(fieldBounds[field][1])
+		},
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			number := value.(int)
+			return pointerError(validateSettingRange(field, &number, fieldBounds[field][0], fieldBounds[field][1]))
+		},
+	},
+	"gridSize": {
+		schema: func(field string, s *JSONSchema) {
+			s.Description = "0 sizes the grid from the player count"
+			s.AnyOf = []*JSONSchema{
+				{Const: 0},
+				{Minimum: intPtr(constants.MinGridSize), Maximum: intPtr(constants.MaxGridSize)},
+			}
+		},
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			// A grid size of 0 hands grid sizing back to the player count
+			number := value.(int)
+			if number == 0 {
+				return nil
+			}
+			return pointerError(validateSettingRange(field, &number, constants.MinGridSize, constants.MaxGridSize))
+		},
+	},
+	"difficulty": {
+		schema: func(field string, s *JSONSchema) { s.Enum = slices.Sorted(maps.Keys(validDifficulties)) },
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			if !validDifficulties[value.(string)] {
+				return []ValidationError{{Field: field, Message: "must be easy, medium or hard"}}
+			}
+			return nil
+		},
+	},
+	"preset": {
+		schema: func(field string, s *JSONSchema) { s.Pattern = presetNameRegex.String() },
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			if !presetNameRegex.MatchString(value.(string)) {
+				return []ValidationError{{Field: field, Message: "invalid preset name"}}
+			}
+			return nil
+		},
+	},
+	"protocolVersion": {
+		required: true,
+		schema:   func(field string, s *JSONSchema) { s.Minimum = intPtr(1) },
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			if value.(int) < 1 {
+				return []ValidationError{{Field: field, Message: "protocol version must be at least 1"}}
+			}
+			return nil
+		},
+	},
+	"features": {
+		schema: func(field string, s *JSONSchema) {
+			s.MaxItems = intPtr(constants.MaxHelloFeatures)
+			s.Items.MinLength = intPtr(1)
+			s.Items.MaxLength = intPtr(MaxFeatureNameLength)
+		},
+		check: func(field string, value interface{}, ctx ruleContext) []ValidationError {
+			features := value.([]string)
+			var errors []ValidationError
+			if len(features) > constants.MaxHelloFeatures {
+				errors = append(errors, ValidationError{Field: field, Message: fmt.Sprintf("too many features (max %d)", constants.MaxHelloFeatures)})
+			}
+			for _, feature := range features {
+				if feature == "" || len(feature) > MaxFeatureNameLength {
+					errors = append(errors, ValidationError{Field: field, Message: fmt.Sprintf("feature names must be 1 to %d characters", MaxFeatureNameLength)})
+					break
+				}
+			}
+			return errors
+		},
+	},
+}
+
+// checkPayload checks a payload struct against the rules named by its fields' validate tags, in
+// field order. Optional fields are only checked when sent.
+func checkPayload(data interface{}) []ValidationError {
+	return checkPayloadIn(data, ruleContext{gridSize: constants.MaxGridSize})
+}
+
+// checkPayloadOnGrid is checkPayload for a game whose puzzle grid is gridSize wide
+func checkPayloadOnGrid(data interface{}, gridSize int) []ValidationError {
+	return checkPayloadIn(data, ruleContext{gridSize: gridSize})
+}
+
+// checkPayloadWithCategories is checkPayload for a game whose question bank has categories
+func checkPayloadWithCategories(data interface{}, categories []string) []ValidationError {
+	return checkPayloadIn(data, ruleContext{gridSize: constants.MaxGridSize, categories: categories})
+}
+
+// checkPayloadIn is checkPayload for the game ctx describes
+func checkPayloadIn(data interface{}, ctx ruleContext) []ValidationError {
+	payload := reflect.Indirect(reflect.ValueOf(data))
 
 	var errors []ValidationError
-	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
-		errors = append(errors, jsonErr)
-		return nil, errors
-	}
+	for i := 0; i < payload.NumField(); i++ {
+		field := payload.Type().Field(i)
+		name, optional := jsonFieldName(field)
+		rules := validateTags(field)
+		if name == "" || len(rules) == 0 {
+			continue
+		}
 
-	if roleErr := validateRole(data.Role); roleErr != nil {
-		errors = append(errors, *roleErr)
-	}
+		value := payload.Field(i)
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				if slices.Contains(rules, "required") {
+					errors = append(errors, ValidationError{Field: name, Message: name + " is required"})
+				}
+				continue
+			}
+			value = value.Elem()
+		} else if optional && value.IsZero() {
+			continue
+		}
 
-	result := map[string]interface{}{
-		"role": data.Role,
+		for _, ruleName := range rules {
+			rule, known := fieldRules[ruleName]
+			if !known {
+				continue
+			}
+			if ruleErrors := rule.check(name, value.Interface(), ctx); len(ruleErrors) > 0 {
+				errors = append(errors, ruleErrors...)
+				break
+			}
+		}
 	}
 
-	return result, errors
+	return errors
 }
 
-// ValidateSpecialtySelection validates specialty selection payload
-func ValidateSpecialtySelection(payload json.RawMessage) (map[string]interface{}, []ValidationError) {
-	var data struct {
-		Specialties []string `json:"specialties"`
+// jsonFieldName returns the name a struct field has in JSON, or "" when it isn't encoded, and
+// whether it is omitted when empty
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" || !field.IsExported() {
+		return "", false
 	}
 
-	var errors []ValidationError
-	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
-		errors = append(errors, jsonErr)
-		return nil, errors
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
 	}
+	return name, slices.Contains(strings.Split(options, ","), "omitempty")
+}
 
-	errors = append(errors, validateSpecialties(data.Specialties)...)
-
-	result := map[string]interface{}{
-		"specialties": data.Specialties,
+// validateTags returns the rules a struct field's validate tag names
+func validateTags(field reflect.StructField) []string {
+	tag := field.Tag.Get("validate")
+	if tag == "" {
+		return nil
 	}
+	return strings.Split(tag, ",")
+}
 
-	return result, errors
+// checkFormat checks a required string field against its format
+func checkFormat(field, value string, format *regexp.Regexp) []ValidationError {
+	if value == "" {
+		return []ValidationError{{Field: field, Message: fieldLabel(field) + " cannot be empty"}}
+	}
+	if !format.MatchString(value) {
+		return []ValidationError{{Field: field, Message: "invalid " + fieldLabel(field) + " format"}}
+	}
+	return nil
 }
 
-// ValidateLocationVerification validates location verification payload
-func ValidateLocationVerification(payload json.RawMessage) (map[string]interface{}, []ValidationError) {
-	var data struct {
-		VerifiedHash string `json:"verifiedHash"`
+// fieldLabel turns a JSON field name into words for messages: fromFragmentId becomes "from fragment ID"
+func fieldLabel(field string) string {
+	var words []string
+	start := 0
+	for i, r := range field {
+		if unicode.IsUpper(r) {
+			words = append(words, field[start:i])
+			start = i
+		}
 	}
+	words = append(words, field[start:])
 
-	var errors []ValidationError
-	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
-		errors = append(errors, jsonErr)
-		return nil, errors
+	for i, word := range words {
+		if word = strings.ToLower(word); word == "id" {
+			word = "ID"
+		}
+		words[i] = word
 	}
+	return strings.Join(words, " ")
+}
 
-	if hashErr := validateLocationHash(data.VerifiedHash); hashErr.Field != "" {
-		errors = append(errors, hashErr)
+// singleError adapts helpers that return an empty ValidationError when the value is fine
+func singleError(err ValidationError) []ValidationError {
+	if err.Field == "" {
+		return nil
 	}
+	return []ValidationError{err}
+}
 
-	result := map[string]interface{}{
-		"verifiedHash": data.VerifiedHash,
+// pointerError adapts helpers that return nil when the value is fine
+func pointerError(err *ValidationError) []ValidationError {
+	if err == nil {
+		return nil
 	}
+	return []ValidationError{*err}
+}
 
-	return result, errors
+func intPtr(value int) *int {
+	return &value
 }
 
-// ValidateTriviaAnswer validates trivia answer payload
-func ValidateTriviaAnswer(payload json.RawMessage) (map[string]interface{}, []ValidationError) {
-	var data struct {
-		QuestionID string `json:"questionId"`
-		Answer     string `json:"answer"`
-		Timestamp  int64  `json:"timestamp"`
+// Specific validation functions for each WebSocket event type. Each decodes its message's payload
+// type from messages.go, checks it with checkPayload, then adds any checks that span fields.
+
+// ValidateRoleSelection validates role selection payload
+func ValidateRoleSelection(payload json.RawMessage) (RoleSelectionPayload, []ValidationError) {
+	var data RoleSelectionPayload
+	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
+		return RoleSelectionPayload{}, []ValidationError{jsonErr}
 	}
 
-	var errors []ValidationError
+	return data, checkPayload(data)
+}
+
+// ValidateSpecialtySelection validates specialty selection payload against the loaded trivia categories
+func ValidateSpecialtySelection(payload json.RawMessage, categories []string) (SpecialtySelectionPayload, []ValidationError) {
+	var data SpecialtySelectionPayload
 	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
-		errors = append(errors, jsonErr)
-		return nil, errors
+		return SpecialtySelectionPayload{}, []ValidationError{jsonErr}
+	}
+
+	return data, checkPayloadWithCategories(data, categories)
+}
+
+// ValidateLocationVerification validates location verification payload
+func ValidateLocationVerification(payload json.RawMessage) (LocationVerificationPayload, []ValidationError) {
+	var data LocationVerificationPayload
+	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
+		return LocationVerificationPayload{}, []ValidationError{jsonErr}
 	}
 
-	errors = append(errors, validateTriviaAnswer(data.QuestionID, data.Answer, data.Timestamp)...)
+	return data, checkPayload(data)
+}
 
-	result := map[string]interface{}{
-		"questionId": data.QuestionID,
-		"answer":     strings.TrimSpace(data.Answer),
-		"timestamp":  data.Timestamp,
+// ValidateTriviaAnswer validates trivia answer payload; the answer comes back trimmed
+func ValidateTriviaAnswer(payload json.RawMessage) (TriviaAnswerPayload, []ValidationError) {
+	var data TriviaAnswerPayload
+	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
+		return TriviaAnswerPayload{}, []ValidationError{jsonErr}
 	}
 
-	return result, errors
+	errors := checkPayload(data)
+	data.Answer = strings.TrimSpace(data.Answer)
+
+	return data, errors
 }
 
 // ValidateSegmentCompletion validates segment completion payload
-func ValidateSegmentCompletion(payload json.RawMessage) (map[string]interface{}, []ValidationError) {
-	var data struct {
-		SegmentID           string `json:"segmentId"`
-		CompletionTimestamp int64  `json:"completionTimestamp"`
+func ValidateSegmentCompletion(payload json.RawMessage) (SegmentCompletionPayload, []ValidationError) {
+	var data SegmentCompletionPayload
+	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
+		return SegmentCompletionPayload{}, []ValidationError{jsonErr}
 	}
 
-	var errors []ValidationError
+	return data, checkPayload(data)
+}
+
+// ValidateFragmentMove validates fragment move payload
+func ValidateFragmentMove(payload json.RawMessage, maxGridSize int) (FragmentMoveRequestPayload, []ValidationError) {
+	var data FragmentMoveRequestPayload
 	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
-		errors = append(errors, jsonErr)
-		return nil, errors
+		return FragmentMoveRequestPayload{}, []ValidationError{jsonErr}
 	}
 
-	if segErr := validateSegmentID(data.SegmentID); segErr.Field != "" {
-		errors = append(errors, segErr)
+	return data, checkPayloadOnGrid(data, maxGridSize)
+}
+
+// ValidatePlayerReady validates player ready payload
+func ValidatePlayerReady(payload json.RawMessage) (PlayerReadyPayload, []ValidationError) {
+	var data PlayerReadyPayload
+	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
+		return PlayerReadyPayload{}, []ValidationError{jsonErr}
 	}
 
-	if data.CompletionTimestamp <= 0 {
-		errors = append(errors, ValidationError{Field: "completionTimestamp", Message: "invalid completion timestamp"})
+	return data, checkPayload(data)
+}
+
+// ValidatePieceRecommendationRequest validates piece recommendation request payload
+func ValidatePieceRecommendationRequest(payload json.RawMessage, maxGridSize int) (PieceRecommendationRequestPayload, []ValidationError) {
+	var data PieceRecommendationRequestPayload
+	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
+		return PieceRecommendationRequestPayload{}, []ValidationError{jsonErr}
 	}
 
-	result := map[string]interface{}{
-		"segmentId":           data.SegmentID,
-		"completionTimestamp": data.CompletionTimestamp,
+	return data, checkPayloadOnGrid(data, maxGridSize)
+}
+
+// ValidatePieceRecommendationResponse validates piece recommendation response payload
+func ValidatePieceRecommendationResponse(payload json.RawMessage) (PieceRecommendationResponsePayload, []ValidationError) {
+	var data PieceRecommendationResponsePayload
+	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
+		return PieceRecommendationResponsePayload{}, []ValidationError{jsonErr}
 	}
 
-	return result, errors
+	return data, checkPayload(data)
 }
 
-// ValidateFragmentMove validates fragment move payload
-func ValidateFragmentMove(payload json.RawMessage, maxGridSize int) (map[string]interface{}, []ValidationError) {
-	var data struct {
-		FragmentID  string  `json:"fragmentId"`
-		NewPosition GridPos `json:"newPosition"`
-		Timestamp   int64   `json:"timestamp"`
+// validateSettingRange checks an optional numeric setting against its bounds
+func validateSettingRange(field string, value *int, min, max int) *ValidationError {
+	if value == nil || (*value >= min && *value <= max) {
+		return nil
 	}
+	return &ValidationError{Field: field, Message: fmt.Sprintf("must be between %d and %d", min, max)}
+}
 
-	var errors []ValidationError
+// ValidateHostUpdateSettings validates a host settings change; only the fields present are checked
+func ValidateHostUpdateSettings(payload json.RawMessage) (GameSettingsUpdate, []ValidationError) {
+	var data GameSettingsUpdate
 	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
-		errors = append(errors, jsonErr)
-		return nil, errors
+		return GameSettingsUpdate{}, []ValidationError{jsonErr}
 	}
 
-	errors = append(errors, validateFragmentMove(data.FragmentID, data.NewPosition, data.Timestamp, maxGridSize, "")...)
+	errors := checkPayload(data)
+	if data == (GameSettingsUpdate{}) {
+		errors = append(errors, ValidationError{Field: "payload", Message: "at least one setting is required"})
+	}
 
-	result := map[string]interface{}{
-		"fragmentId":  data.FragmentID,
-		"newPosition": data.NewPosition,
-		"timestamp":   data.Timestamp,
+	return data, errors
+}
+
+// ValidateHostAddTime validates the seconds a host adds to the running round or puzzle
+func ValidateHostAddTime(payload json.RawMessage) (HostAddTimePayload, []ValidationError) {
+	var data HostAddTimePayload
+	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
+		return HostAddTimePayload{}, []ValidationError{jsonErr}
 	}
 
-	return result, errors
+	if errors := checkPayload(data); len(errors) > 0 {
+		return HostAddTimePayload{}, errors
+	}
+
+	return data, nil
 }
 
-// ValidatePlayerReady validates player ready payload
-func ValidatePlayerReady(payload json.RawMessage) (map[string]interface{}, []ValidationError) {
-	var data struct {
-		Ready bool `json:"ready"`
+// ValidateHostAbortGame validates the optional showResults flag of an abort
+func ValidateHostAbortGame(payload json.RawMessage) (HostAbortGamePayload, []ValidationError) {
+	var data HostAbortGamePayload
+	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
+		return HostAbortGamePayload{}, []ValidationError{jsonErr}
 	}
 
-	var errors []ValidationError
+	return data, nil
+}
+
+// ValidateHostKickPlayer validates the player a host removes, and whether they are banned
+func ValidateHostKickPlayer(payload json.RawMessage) (HostKickPlayerPayload, []ValidationError) {
+	var data HostKickPlayerPayload
 	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
-		errors = append(errors, jsonErr)
-		return nil, errors
+		return HostKickPlayerPayload{}, []ValidationError{jsonErr}
 	}
 
-	result := map[string]interface{}{
-		"ready": data.Ready,
+	if errors := checkPayload(data); len(errors) > 0 {
+		return HostKickPlayerPayload{}, errors
 	}
 
-	return result, errors
+	return data, nil
 }
 
-// ValidatePieceRecommendationRequest validates piece recommendation request payload
-func ValidatePieceRecommendationRequest(payload json.RawMessage, maxGridSize int) (map[string]interface{}, []ValidationError) {
-	var data struct {
-		ToPlayerID       string  `json:"toPlayerId"`
-		FromFragmentID   string  `json:"fromFragmentId"`
-		ToFragmentID     string  `json:"toFragmentId"`
-		SuggestedFromPos GridPos `json:"suggestedFromPos"`
-		SuggestedToPos   GridPos `json:"suggestedToPos"`
+// ValidateHostRenamePlayer validates the player a host renames and their new name
+func ValidateHostRenamePlayer(payload json.RawMessage) (HostRenamePlayerPayload, []ValidationError) {
+	var data HostRenamePlayerPayload
+	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
+		return HostRenamePlayerPayload{}, []ValidationError{jsonErr}
 	}
 
-	var errors []ValidationError
+	data.Name = cleanPlayerName(data.Name)
+	if errors := checkPayload(data); len(errors) > 0 {
+		return HostRenamePlayerPayload{}, errors
+	}
+
+	return data, nil
+}
+
+// ValidateHostAssignPlayer validates a role and/or specialties the host gives a player; specialties
+// must be loaded trivia categories
+func ValidateHostAssignPlayer(payload json.RawMessage, categories []string) (HostAssignPlayerPayload, []ValidationError) {
+	var data HostAssignPlayerPayload
 	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
-		errors = append(errors, jsonErr)
-		return nil, errors
+		return HostAssignPlayerPayload{}, []ValidationError{jsonErr}
 	}
 
-	errors = append(errors, validatePieceRecommendation(
-		data.ToPlayerID,
-		data.FromFragmentID,
-		data.ToFragmentID,
-		data.SuggestedFromPos,
-		data.SuggestedToPos,
-		maxGridSize,
-	)...)
+	errors := checkPayloadWithCategories(data, categories)
+	if data.Role == nil && data.Specialties == nil {
+		errors = append(errors, ValidationError{Field: "payload", Message: "role or specialties is required"})
+	}
 
-	result := map[string]interface{}{
-		"toPlayerId":       data.ToPlayerID,
-		"fromFragmentId":   data.FromFragmentID,
-		"toFragmentId":     data.ToFragmentID,
-		"suggestedFromPos": data.SuggestedFromPos,
-		"suggestedToPos":   data.SuggestedToPos,
+	if len(errors) > 0 {
+		return HostAssignPlayerPayload{}, errors
 	}
 
-	return result, errors
+	return data, nil
 }
 
-// ValidatePieceRecommendationResponse validates piece recommendation response payload
-func ValidatePieceRecommendationResponse(payload json.RawMessage) (map[string]interface{}, []ValidationError) {
-	var data struct {
-		RecommendationID string `json:"recommendationId"`
-		Accepted         bool   `json:"accepted"`
+// ValidateEmptyPayload validates payloads that should be empty (like host actions). Fields sent
+// anyway are ignored.
+func ValidateEmptyPayload(payload json.RawMessage) []ValidationError {
+	if len(payload) == 0 {
+		return nil
 	}
 
-	var errors []ValidationError
+	var data map[string]interface{}
 	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
-		errors = append(errors, jsonErr)
-		return nil, errors
+		return []ValidationError{jsonErr}
 	}
 
-	if data.RecommendationID == "" {
-		errors = append(errors, ValidationError{Field: "recommendationId", Message: "recommendation ID cannot be empty"})
-	} else if !playerIDRegex.MatchString(data.RecommendationID) {
-		errors = append(errors, ValidationError{Field: "recommendationId", Message: "invalid recommendation ID format"})
+	return nil
+}
+
+// ValidatePlayerSetName validates the display name a player picks for themselves
+func ValidatePlayerSetName(payload json.RawMessage) (PlayerSetNamePayload, []ValidationError) {
+	var data PlayerSetNamePayload
+	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
+		return PlayerSetNamePayload{}, []ValidationError{jsonErr}
 	}
 
-	result := map[string]interface{}{
-		"recommendationId": data.RecommendationID,
-		"accepted":         data.Accepted,
+	data.Name = cleanPlayerName(data.Name)
+	if errors := checkPayload(data); len(errors) > 0 {
+		return PlayerSetNamePayload{}, errors
 	}
 
-	return result, errors
+	return data, nil
 }
 
-// ValidateEmptyPayload validates payloads that should be empty (like host actions)
-func ValidateEmptyPayload(payload json.RawMessage) (map[string]interface{}, []ValidationError) {
-	var data map[string]interface{}
+// ValidateHello validates the hello a client opens its session with. minProtocolVersion defaults to
+// protocolVersion, for clients that speak a single version.
+func ValidateHello(payload json.RawMessage) (HelloPayload, []ValidationError) {
+	var data HelloPayload
+	if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
+		return HelloPayload{}, []ValidationError{jsonErr}
+	}
 
-	var errors []ValidationError
-	if len(payload) > 0 {
-		if jsonErr := validateJSONPayload(payload, &data); jsonErr.Field != "" {
-			errors = append(errors, jsonErr)
-			return nil, errors
-		}
+	errors := checkPayload(data)
+
+	if data.MinProtocolVersion == 0 {
+		data.MinProtocolVersion = data.ProtocolVersion
+	} else if data.MinProtocolVersion > data.ProtocolVersion {
+		errors = append(errors, ValidationError{Field: "minProtocolVersion", Message: "must be between 1 and protocolVersion"})
 	}
 
 	return data, errors
@@ -6,10 +6,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
+// testTriviaCategories are the categories of the shipped question bank
+var testTriviaCategories = []string{"general", "geography", "history", "music", "science", "video_games"}
+
 func TestValidatePlayerID(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -237,7 +241,7 @@ func TestValidateSpecialties(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			errs := validateSpecialties(tt.specialties)
+			errs := validateSpecialties(tt.specialties, testTriviaCategories)
 			if tt.wantErr {
 				assert.Len(t, errs, tt.errCount)
 				for i, err := range errs {
@@ -329,6 +333,13 @@ func TestValidateTriviaAnswer(t *testing.T) {
 			timestamp:  currentTime,
 			wantErr:    false,
 		},
+		{
+			name:       "Category with an underscore",
+			questionID: "video_games_hard_7_123456",
+			answer:     "Luigi",
+			timestamp:  currentTime,
+			wantErr:    false,
+		},
 		{
 			name:       "Empty question ID",
 			questionID: "",
@@ -600,6 +611,11 @@ func TestValidateFragmentMove(t *testing.T) {
 
 func TestValidateAuthWrapper(t *testing.T) {
 	validPlayerID := uuid.New().String()
+	otherPlayerID := uuid.New().String()
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	tokens := NewSessionTokens([]byte("test-session-key"), clock)
+	token := tokens.Issue(validPlayerID)
+	forged := NewSessionTokens([]byte("some-other-key"), clock).Issue(validPlayerID)
 
 	tests := []struct {
 		name     string
@@ -612,12 +628,42 @@ func TestValidateAuthWrapper(t *testing.T) {
 		{
 			name: "Valid auth wrapper",
 			data: []byte(`{
-				"auth": {"playerId": "` + validPlayerID + `"},
+				"auth": {"playerId": "` + validPlayerID + `", "token": "` + token + `"},
 				"payload": {"test": true}
 			}`),
 			wantErr:  false,
 			playerID: validPlayerID,
 		},
+		{
+			name: "Missing token",
+			data: []byte(`{
+				"auth": {"playerId": "` + validPlayerID + `"},
+				"payload": {"test": true}
+			}`),
+			wantErr:  true,
+			errCount: 1,
+			errMsgs:  []string{"session token cannot be empty"},
+		},
+		{
+			name: "Token signed with another key",
+			data: []byte(`{
+				"auth": {"playerId": "` + validPlayerID + `", "token": "` + forged + `"},
+				"payload": {"test": true}
+			}`),
+			wantErr:  true,
+			errCount: 1,
+			errMsgs:  []string{constants.ErrInvalidSessionToken},
+		},
+		{
+			name: "Another player's token",
+			data: []byte(`{
+				"auth": {"playerId": "` + otherPlayerID + `", "token": "` + tokens.Issue(otherPlayerID) + `"},
+				"payload": {"test": true}
+			}`),
+			wantErr:  true,
+			errCount: 1,
+			errMsgs:  []string{constants.ErrSessionTokenMismatch},
+		},
 		{
 			name:     "Invalid JSON",
 			data:     []byte(`{"auth": {"playerId": "`),
@@ -631,13 +677,13 @@ func TestValidateAuthWrapper(t *testing.T) {
 				"payload": {"test": true}
 			}`),
 			wantErr:  true,
-			errCount: 1,
-			errMsgs:  []string{"player ID cannot be empty"},
+			errCount: 2,
+			errMsgs:  []string{"player ID cannot be empty", "session token cannot be empty"},
 		},
 		{
 			name: "Missing player ID",
 			data: []byte(`{
-				"auth": {},
+				"auth": {"token": "` + token + `"},
 				"payload": {"test": true}
 			}`),
 			wantErr:  true,
@@ -647,7 +693,7 @@ func TestValidateAuthWrapper(t *testing.T) {
 		{
 			name: "Invalid player ID format",
 			data: []byte(`{
-				"auth": {"playerId": "not-a-uuid"},
+				"auth": {"playerId": "not-a-uuid", "token": "` + token + `"},
 				"payload": {"test": true}
 			}`),
 			wantErr:  true,
@@ -658,7 +704,7 @@ func TestValidateAuthWrapper(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			wrapper, errs := validateAuthWrapper(tt.data)
+			wrapper, errs := validateAuthWrapper(tt.data, tokens, validPlayerID)
 			if tt.wantErr {
 				assert.Len(t, errs, tt.errCount)
 				for i, err := range errs {
@@ -720,11 +766,9 @@ func TestValidateRoleSelection(t *testing.T) {
 				for i, err := range errs {
 					assert.Contains(t, err.Error(), tt.errMsgs[i])
 				}
-				// data is not nil even when there are errors
 			} else {
 				assert.Empty(t, errs)
-				assert.NotNil(t, data)
-				assert.Equal(t, tt.role, data["role"])
+				assert.Equal(t, tt.role, data.Role)
 			}
 		})
 	}
@@ -776,18 +820,15 @@ func TestValidateSpecialtySelection(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			data, errs := ValidateSpecialtySelection(tt.payload)
+			data, errs := ValidateSpecialtySelection(tt.payload, testTriviaCategories)
 			if tt.wantErr {
 				assert.Len(t, errs, tt.errCount)
 				for i, err := range errs {
 					assert.Contains(t, err.Error(), tt.errMsgs[i])
 				}
-				// Data may or may not be nil depending on error type
 			} else {
 				assert.Empty(t, errs)
-				assert.NotNil(t, data)
-				specialties := data["specialties"].([]string)
-				assert.Equal(t, tt.specialties, specialties)
+				assert.Equal(t, tt.specialties, data.Specialties)
 			}
 		})
 	}
@@ -827,17 +868,258 @@ func TestValidateEmptyPayload(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			data, errs := ValidateEmptyPayload(tt.payload)
+			errs := ValidateEmptyPayload(tt.payload)
 			if tt.wantErr {
 				assert.Len(t, errs, tt.errCount)
 				if tt.errCount > 0 {
 					assert.Contains(t, errs[0].Error(), tt.errMsg)
 				}
-				assert.Nil(t, data)
 			} else {
 				assert.Empty(t, errs)
-				// data can be nil for empty payloads
 			}
 		})
 	}
 }
+
+func TestValidateHostUpdateSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  json.RawMessage
+		wantErr  bool
+		errCount int
+		errMsgs  []string
+		fields   []string
+	}{
+		{
+			name:    "Partial update",
+			payload: json.RawMessage(`{"resourceRounds": 3, "roundDuration": 45}`),
+			fields:  []string{"resourceRounds", "roundDuration"},
+		},
+		{
+			name:    "Every setting",
+			payload: json.RawMessage(`{"difficulty": "hard", "resourceRounds": 10, "roundDuration": 15, "puzzleBaseTime": 1800, "anchorThreshold": 1, "chronosThreshold": 100, "guideThreshold": 7, "clarityThreshold": 3, "gridSize": 8, "minPlayers": 64}`),
+			fields: []string{"difficulty", "resourceRounds", "roundDuration", "puzzleBaseTime", "anchorThreshold",
+				"chronosThreshold", "guideThreshold", "clarityThreshold", "gridSize", "minPlayers"},
+		},
+		{
+			name:    "Automatic grid size",
+			payload: json.RawMessage(`{"gridSize": 0}`),
+			fields:  []string{"gridSize"},
+		},
+		{
+			name:     "Out of range values",
+			payload:  json.RawMessage(`{"resourceRounds": 0, "roundDuration": 301, "gridSize": 2, "minPlayers": 65}`),
+			wantErr:  true,
+			errCount: 4,
+			errMsgs:  []string{"resourceRounds", "roundDuration", "minPlayers", "gridSize"},
+		},
+		{
+			name:     "Invalid difficulty",
+			payload:  json.RawMessage(`{"difficulty": "extreme"}`),
+			wantErr:  true,
+			errCount: 1,
+			errMsgs:  []string{"must be easy, medium or hard"},
+		},
+		{
+			name:    "Preset with overrides",
+			payload: json.RawMessage(`{"preset": "speed-round", "resourceRounds": 4}`),
+			fields:  []string{"preset", "resourceRounds"},
+		},
+		{
+			name:     "Invalid preset name",
+			payload:  json.RawMessage(`{"preset": "Speed Round!"}`),
+			wantErr:  true,
+			errCount: 1,
+			errMsgs:  []string{"invalid preset name"},
+		},
+		{
+			name:     "No settings",
+			payload:  json.RawMessage(`{}`),
+			wantErr:  true,
+			errCount: 1,
+			errMsgs:  []string{"at least one setting is required"},
+		},
+		{
+			name:     "Wrong type",
+			payload:  json.RawMessage(`{"resourceRounds": "three"}`),
+			wantErr:  true,
+			errCount: 1,
+			errMsgs:  []string{"invalid JSON format"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, errs := ValidateHostUpdateSettings(tt.payload)
+			if tt.wantErr {
+				assert.Len(t, errs, tt.errCount)
+				for i, err := range errs {
+					assert.Contains(t, err.Error(), tt.errMsgs[i])
+				}
+			} else {
+				assert.Empty(t, errs)
+				// Only the settings sent are set
+				encoded, err := json.Marshal(data)
+				assert.NoError(t, err)
+				var set map[string]interface{}
+				assert.NoError(t, json.Unmarshal(encoded, &set))
+				assert.Len(t, set, len(tt.fields))
+				for _, field := range tt.fields {
+					assert.Contains(t, set, field)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateHostAddTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload json.RawMessage
+		wantErr string
+		seconds int
+	}{
+		{name: "Valid", payload: json.RawMessage(`{"seconds": 30}`), seconds: 30},
+		{name: "Largest", payload: json.RawMessage(`{"seconds": 600}`), seconds: 600},
+		{name: "Missing", payload: json.RawMessage(`{}`), wantErr: "seconds is required"},
+		{name: "Zero", payload: json.RawMessage(`{"seconds": 0}`), wantErr: "must be between 1 and 600"},
+		{name: "Too long", payload: json.RawMessage(`{"seconds": 601}`), wantErr: "must be between 1 and 600"},
+		{name: "Wrong type", payload: json.RawMessage(`{"seconds": "30"}`), wantErr: "invalid JSON format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, errs := ValidateHostAddTime(tt.payload)
+			if tt.wantErr != "" {
+				if assert.Len(t, errs, 1) {
+					assert.Contains(t, errs[0].Error(), tt.wantErr)
+				}
+				assert.Zero(t, data)
+			} else {
+				assert.Empty(t, errs)
+				if assert.NotNil(t, data.Seconds) {
+					assert.Equal(t, tt.seconds, *data.Seconds)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateHostAbortGame(t *testing.T) {
+	data, errs := ValidateHostAbortGame(json.RawMessage(`{}`))
+	assert.Empty(t, errs)
+	assert.False(t, data.ShowResults)
+
+	data, errs = ValidateHostAbortGame(json.RawMessage(`{"showResults": true}`))
+	assert.Empty(t, errs)
+	assert.True(t, data.ShowResults)
+
+	data, errs = ValidateHostAbortGame(json.RawMessage(`{"showResults": "yes"}`))
+	if assert.Len(t, errs, 1) {
+		assert.Contains(t, errs[0].Error(), "invalid JSON format")
+	}
+	assert.Zero(t, data)
+}
+
+func TestValidatePlayerReady(t *testing.T) {
+	data, errs := ValidatePlayerReady(json.RawMessage(`{"ready": false}`))
+	assert.Empty(t, errs)
+	if assert.NotNil(t, data.Ready) {
+		assert.False(t, *data.Ready)
+	}
+
+	data, errs = ValidatePlayerReady(json.RawMessage(`{}`))
+	if assert.Len(t, errs, 1) {
+		assert.Contains(t, errs[0].Error(), "ready is required")
+	}
+	assert.Nil(t, data.Ready)
+
+	_, errs = ValidatePieceRecommendationResponse(json.RawMessage(`{"recommendationId": "11111111-2222-3333-4444-555555555555"}`))
+	if assert.Len(t, errs, 1) {
+		assert.Contains(t, errs[0].Error(), "accepted is required")
+	}
+}
+
+func TestValidateHostModeration(t *testing.T) {
+	id := "11111111-2222-3333-4444-555555555555"
+	// Each returns the target player ID so the cases share one table
+	kickPlayer := func(payload json.RawMessage) (string, []ValidationError) {
+		data, errs := ValidateHostKickPlayer(payload)
+		return data.PlayerID, errs
+	}
+	renamePlayer := func(payload json.RawMessage) (string, []ValidationError) {
+		data, errs := ValidateHostRenamePlayer(payload)
+		return data.PlayerID, errs
+	}
+	assignPlayer := func(payload json.RawMessage) (string, []ValidationError) {
+		data, errs := ValidateHostAssignPlayer(payload, testTriviaCategories)
+		return data.PlayerID, errs
+	}
+
+	tests := []struct {
+		name     string
+		validate func(json.RawMessage) (string, []ValidationError)
+		payload  string
+		wantErrs []string
+	}{
+		{name: "Kick", validate: kickPlayer, payload: `{"playerId": "` + id + `", "ban": true}`},
+		{name: "Kick bad ID", validate: kickPlayer, payload: `{"playerId": "player-1"}`, wantErrs: []string{"invalid player ID format"}},
+		{name: "Rename", validate: renamePlayer, payload: `{"playerId": "` + id + `", "name": "Ada L"}`},
+		{name: "Rename bad name", validate: renamePlayer, payload: `{"playerId": "` + id + `", "name": "<b>Ada</b>"}`, wantErrs: []string{"invalid characters"}},
+		{name: "Rename empty", validate: renamePlayer, payload: `{"playerId": "", "name": ""}`, wantErrs: []string{"player ID cannot be empty", "name cannot be empty"}},
+		{name: "Assign role", validate: assignPlayer, payload: `{"playerId": "` + id + `", "role": "tourist"}`},
+		{name: "Assign specialties", validate: assignPlayer, payload: `{"playerId": "` + id + `", "specialties": ["science", "history"]}`},
+		{name: "Assign nothing", validate: assignPlayer, payload: `{"playerId": "` + id + `"}`, wantErrs: []string{"role or specialties is required"}},
+		{name: "Assign invalid", validate: assignPlayer, payload: `{"playerId": "` + id + `", "role": "pilot", "specialties": []}`, wantErrs: []string{"invalid role selection", "at least one specialty"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playerID, errs := tt.validate(json.RawMessage(tt.payload))
+			if len(tt.wantErrs) > 0 {
+				if assert.Len(t, errs, len(tt.wantErrs)) {
+					for i, err := range errs {
+						assert.Contains(t, err.Error(), tt.wantErrs[i])
+					}
+				}
+				assert.Empty(t, playerID)
+			} else {
+				assert.Empty(t, errs)
+				assert.Equal(t, id, playerID)
+			}
+		})
+	}
+}
+
+func TestValidatePlayerSetName(t *testing.T) {
+	data, errs := ValidatePlayerSetName(json.RawMessage(`{"name": "  Ada   Lovelace "}`))
+	assert.Empty(t, errs)
+	assert.Equal(t, "Ada Lovelace", data.Name)
+
+	for _, payload := range []string{`{"name": "   "}`, `{"name": "<b>Ada</b>"}`, `{}`} {
+		data, errs = ValidatePlayerSetName(json.RawMessage(payload))
+		assert.Len(t, errs, 1, payload)
+		assert.Zero(t, data)
+	}
+}
+
+func TestValidateHello(t *testing.T) {
+	data, errs := ValidateHello(json.RawMessage(`{"protocolVersion": 2, "features": ["puzzleDeltas"]}`))
+	assert.Empty(t, errs)
+	assert.Equal(t, 2, data.MinProtocolVersion)
+	assert.Equal(t, []string{"puzzleDeltas"}, data.Features)
+
+	data, errs = ValidateHello(json.RawMessage(`{"protocolVersion": 3, "minProtocolVersion": 1}`))
+	assert.Empty(t, errs)
+	assert.Equal(t, 1, data.MinProtocolVersion)
+
+	for _, payload := range []string{
+		`{}`,
+		`{"protocolVersion": 2, "minProtocolVersion": 3}`,
+		`{"protocolVersion": 2, "features": [""]}`,
+		`{"protocolVersion": "2"}`,
+	} {
+		_, errs = ValidateHello(json.RawMessage(payload))
+		assert.Len(t, errs, 1, payload)
+	}
+}
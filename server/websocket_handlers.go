@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
@@ -18,6 +19,9 @@ var upgrader = websocket.Upgrader{
 	},
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
+	// Clients offering permessage-deflate get compressed messages, and may ask for CBOR instead of JSON
+	EnableCompression: true,
+	Subprotocols:      []string{SubprotocolCBOR, SubprotocolJSON},
 	// Enhanced error handling
 	Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
 		log.Printf("WebSocket upgrade error: %v (status: %d)", reason, status)
@@ -30,7 +34,12 @@ type WebSocketHandler struct {
 	playerManager *PlayerManager
 	gameManager   *GameManager
 	eventHandlers *EventHandlers
+	sessionTokens *SessionTokens     // Signs the tokens players reconnect and authenticate with
+	rateLimits    RateLimitConfig    // Limits on the messages each player sends
+	connections   *ConnectionLimiter // Caps connections per address; shared with other rooms
 	broadcastChan chan BroadcastMessage
+	stopChan      chan struct{}
+	stopOnce      sync.Once
 }
 
 // NewWebSocketHandler creates a new WebSocket handler
@@ -39,7 +48,11 @@ func NewWebSocketHandler(pm *PlayerManager, gm *GameManager, eh *EventHandlers,
 		playerManager: pm,
 		gameManager:   gm,
 		eventHandlers: eh,
+		sessionTokens: NewRandomSessionTokens(),
+		rateLimits:    DefaultRateLimitConfig(),
+		connections:   NewConnectionLimiter(constants.MaxConnectionsPerIP),
 		broadcastChan: bc,
+		stopChan:      make(chan struct{}),
 	}
 }
 
@@ -63,6 +76,15 @@ func (wsh *WebSocketHandler) HandleConnection(w http.ResponseWriter, r *http.Req
 		}
 	}
 
+	// Connections from one address are capped; messages are rate limited once connected
+	ip := clientIP(r)
+	if err := wsh.connections.acquire(ip); err != nil {
+		log.Printf("Refused connection from %s: %v", ip, err)
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	defer wsh.connections.release(ip)
+
 	// Upgrade connection
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -72,18 +94,90 @@ func (wsh *WebSocketHandler) HandleConnection(w http.ResponseWriter, r *http.Req
 	defer conn.Close()
 
 	// Set connection limits
-	conn.SetReadLimit(8192) // 8KB max message size
+	conn.SetReadLimit(constants.MaxClientMessageBytes)
+	conn.SetCompressionLevel(constants.WebSocketCompressionLevel)
+
+	// A player ID alone is no proof; reconnecting clients show the session token they were given
+	playerID, err = wsh.sessionPlayerID(r)
+	if err != nil {
+		wsh.sendConnectionError(conn, err)
+		log.Printf("Rejected reconnection from %s: %v", r.RemoteAddr, err)
+		return
+	}
+
+	player, reconnected, err := wsh.acceptPlayer(playerID, isHost, conn, ip)
+	if err != nil {
+		wsh.sendConnectionError(conn, err)
+		return
+	}
+
+	// Set up enhanced ping/pong handlers
+	conn.SetReadDeadline(time.Now().Add(constants.WebSocketPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(constants.WebSocketPongTimeout))
+		return nil
+	})
+
+	// Set up close handler for immediate disconnect detection
+	conn.SetCloseHandler(func(code int, text string) error {
+		log.Printf("WebSocket closed for player %s (code: %d, reason: %s)", player.ID, code, text)
+		// Immediately handle disconnection for hosts to allow new connections
+		if player.IsHost {
+			wsh.handleHostDisconnection(player)
+		} else {
+			wsh.handleDisconnection(player)
+		}
+		return nil
+	})
 
+	// From here on only the writer goroutine writes to conn; it also sends the keep-alive pings
+	writer := startPlayerWriter(player, newWebSocketTransport(conn))
+	defer writer.stop()
+
+	// Handle initial join
+	if err := wsh.admitPlayer(player, reconnected); err != nil {
+		log.Printf("Error handling player join: %v", err)
+		wsh.sendError(player, errJoinFailed)
+		writer.closeAfterFlush(websocket.CloseInternalServerErr, "failed to join game")
+		<-writer.done
+		return
+	}
+
+	// A failed write or ping closes conn, which ends the read loop as well
+	err = wsh.handlePlayerMessages(player, conn)
+	if err != nil {
+		log.Printf("Message handling error for player %s: %v", player.ID, err)
+	}
+
+	// A player who reconnected already replaced this connection
+	if !writer.superseded() {
+		wsh.handleDisconnection(player)
+	}
+}
+
+// acceptPlayer works out who a new connection belongs to: a returning player or host, or someone new.
+// conn is nil for event streams, and address is the client IP. An error is the reason the connection is refused.
+func (wsh *WebSocketHandler) acceptPlayer(playerID string, isHost bool, conn *websocket.Conn, address string) (*Player, bool, error) {
 	var player *Player
+	reconnected := false
+
+	// A ban covers the banned player's address too, so they can't simply join again without their token
+	if !isHost && wsh.playerManager.IsAddressBanned(address) {
+		log.Printf("Blocked connection from %s, the address of a banned player", address)
+		return nil, false, errPlayerBanned
+	}
 
 	// Handle reconnection or new connection
-	if playerID != "" && !isHost {
+	if playerID != "" && wsh.playerManager.IsBanned(playerID) {
+		log.Printf("Blocked reconnection attempt by banned player %s", playerID)
+		return nil, false, errPlayerBanned
+	} else if playerID != "" && !isHost {
 		// ENHANCED: Check if reconnection is allowed during current phase
+		// Players restored from a snapshot or still within the reconnect grace period may come back
 		phase := wsh.gameManager.GetPhase()
-		if phase == PhasePuzzleAssembly {
-			wsh.sendConnectionError(conn, constants.ErrReconnectionForbidden)
+		if phase == PhasePuzzleAssembly && !wsh.playerManager.IsAwaitingRecovery(playerID) && !wsh.gameManager.HasFragmentHold(playerID) {
 			log.Printf("Blocked reconnection attempt during puzzle assembly phase: player %s", playerID)
-			return
+			return nil, false, errReconnectionForbidden
 		}
 
 		// Attempt regular player reconnection (only allowed in setup and resource gathering)
@@ -93,10 +187,8 @@ func (wsh *WebSocketHandler) HandleConnection(w http.ResponseWriter, r *http.Req
 			player = wsh.playerManager.CreatePlayer(conn, false)
 		} else {
 			player, _ = wsh.playerManager.GetPlayer(playerID)
+			reconnected = true
 			log.Printf("Player %s reconnected during %s phase", playerID, phase.String())
-
-			// Send current game state on reconnection
-			wsh.sendReconnectionState(player)
 		}
 	} else if playerID != "" && isHost {
 		// Host reconnection is ALWAYS allowed
@@ -105,8 +197,7 @@ func (wsh *WebSocketHandler) HandleConnection(w http.ResponseWriter, r *http.Req
 			log.Printf("Host reconnection failed for player %s: %v", playerID, err)
 			// Check if another host is already connected
 			if wsh.playerManager.GetHost() != nil {
-				wsh.sendConnectionError(conn, constants.ErrHostExists)
-				return
+				return nil, false, errHostExists
 			}
 			player = wsh.playerManager.CreatePlayer(conn, true)
 		} else {
@@ -114,14 +205,13 @@ func (wsh *WebSocketHandler) HandleConnection(w http.ResponseWriter, r *http.Req
 			if err := wsh.playerManager.ReconnectPlayer(playerID, conn); err != nil {
 				log.Printf("Host reconnection failed for player %s: %v", playerID, err)
 				if wsh.playerManager.GetConnectedHost() != nil {
-					wsh.sendConnectionError(conn, constants.ErrHostExists)
-					return
+					return nil, false, errHostExists
 				}
 				player = wsh.playerManager.CreatePlayer(conn, true)
 			} else {
 				player = existingPlayer
+				reconnected = true
 				log.Printf("Host %s reconnected", playerID)
-				wsh.sendReconnectionState(player)
 			}
 		}
 	} else {
@@ -130,80 +220,75 @@ func (wsh *WebSocketHandler) HandleConnection(w http.ResponseWriter, r *http.Req
 			// Check if there's already a host
 			existingHost := wsh.playerManager.GetConnectedHost()
 			if existingHost != nil {
-				wsh.sendConnectionError(conn, constants.ErrHostExists)
-				return
+				return nil, false, errHostExists
 			}
 			player = wsh.playerManager.CreatePlayer(conn, true)
 			log.Printf("New host connected: %s", player.ID)
 		} else {
 			// New regular player connection validation
 			if !wsh.canAcceptNewPlayer() {
-				wsh.sendConnectionError(conn, "Cannot join game at this time")
-				return
+				return nil, false, errCannotJoin
 			}
 			player = wsh.playerManager.CreatePlayer(conn, false)
 			log.Printf("New player connected: %s", player.ID)
 		}
 	}
 
-	// Set up enhanced ping/pong handlers
-	conn.SetReadDeadline(time.Now().Add(constants.WebSocketPongTimeout))
-	conn.SetPongHandler(func(string) error {
-		conn.SetReadDeadline(time.Now().Add(constants.WebSocketPongTimeout))
-		return nil
-	})
+	wsh.playerManager.SetPlayerAddress(player, address)
+	return player, reconnected, nil
+}
 
-	// Set up close handler for immediate disconnect detection
-	conn.SetCloseHandler(func(code int, text string) error {
-		log.Printf("WebSocket closed for player %s (code: %d, reason: %s)", player.ID, code, text)
-		// Immediately handle disconnection for hosts to allow new connections
-		if player.IsHost {
-			wsh.handleHostDisconnection(player)
-		} else {
-			wsh.handleDisconnection(player)
+// sessionPlayerID returns the player a reconnecting client's ?token= was issued to, or "" for a
+// new connection. A ?playerId= sent alongside must name the same player.
+func (wsh *WebSocketHandler) sessionPlayerID(r *http.Request) (string, error) {
+	playerID := r.URL.Query().Get("playerId")
+	token := r.URL.Query().Get("token")
+
+	if token == "" {
+		if playerID != "" {
+			return "", errSessionTokenRequired
 		}
-		return nil
+		return "", nil
+	}
+
+	tokenPlayerID, err := wsh.sessionTokens.Verify(token)
+	if err != nil {
+		return "", err
+	}
+	if playerID != "" && playerID != tokenPlayerID {
+		return "", errSessionTokenMismatch
+	}
+
+	return tokenPlayerID, nil
+}
+
+// admitPlayer brings a newly connected or reconnected player into the game
+func (wsh *WebSocketHandler) admitPlayer(player *Player, reconnected bool) error {
+	// Every connection gets a fresh token, which also pushes back its expiry
+	player.mu.Lock()
+	player.SessionToken = wsh.sessionTokens.Issue(player.ID)
+	player.mu.Unlock()
+
+	wsh.gameManager.eventLog.Record(EventPlayerConnected, player.ID, "", connectionRecord{
+		IsHost:      player.IsHost,
+		Reconnected: reconnected,
 	})
 
-	// Start ping ticker
-	pingTicker := time.NewTicker(constants.WebSocketPingInterval)
-	defer pingTicker.Stop()
+	// Send current game state on reconnection
+	if reconnected {
+		wsh.gameManager.ReclaimFragment(player.ID)
+		wsh.sendReconnectionState(player)
+	}
 
-	// Handle initial join
 	if err := wsh.eventHandlers.HandlePlayerJoin(player, nil); err != nil {
-		log.Printf("Error handling player join: %v", err)
-		wsh.sendError(player, "Failed to join game")
-		return
+		return err
 	}
 
 	// Broadcast lobby status
 	wsh.eventHandlers.broadcastLobbyStatus()
 
-	// Message handling goroutine with enhanced error handling
-	messageChan := make(chan error, 1)
-	go func() {
-		messageChan <- wsh.handlePlayerMessages(player)
-	}()
-
-	// Keep connection alive and handle pings
-	for {
-		select {
-		case <-pingTicker.C:
-			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				log.Printf("Ping failed for player %s: %v", player.ID, err)
-				wsh.handleDisconnection(player)
-				return
-			}
-
-		case err := <-messageChan:
-			if err != nil {
-				log.Printf("Message handling error for player %s: %v", player.ID, err)
-			}
-			wsh.handleDisconnection(player)
-			return
-		}
-	}
+	wsh.gameManager.recordCheckpoint(EventPlayerConnected, nil)
+	return nil
 }
 
 // validateConnectionRequest validates the initial connection request - ENHANCED
@@ -232,8 +317,6 @@ func (wsh *WebSocketHandler) validateConnectionRequest(r *http.Request) bool {
 		return false
 	}
 
-	// Additional security: rate limiting could be added here
-	// For now, we'll just log connection attempts
 	log.Printf("Valid connection request from %s (User-Agent: %s)", r.RemoteAddr, userAgent)
 
 	return true
@@ -241,57 +324,73 @@ func (wsh *WebSocketHandler) validateConnectionRequest(r *http.Request) bool {
 
 // canAcceptNewPlayer checks if we can accept a new player connection - ENHANCED
 func (wsh *WebSocketHandler) canAcceptNewPlayer() bool {
+	canJoin, reason := wsh.joinAvailability()
+	if !canJoin {
+		log.Printf("Rejected new player connection: %s", reason)
+	}
+	return canJoin
+}
+
+// joinAvailability reports whether a new player could join right now, and why not if they can't
+func (wsh *WebSocketHandler) joinAvailability() (bool, string) {
 	// Check game phase - only allow new players during setup
 	phase := wsh.gameManager.GetPhase()
 	if phase != PhaseSetup {
-		log.Printf("Rejected new player connection: game is in %s phase (only setup phase allows new players)", phase.String())
-		return false
+		return false, fmt.Sprintf("game is in %s phase (only setup phase allows new players)", phase.String())
 	}
 
 	// Check player limit
 	currentCount := wsh.playerManager.GetPlayerCount()
 	if currentCount >= constants.MaxPlayers {
-		log.Printf("Rejected new player connection: player limit reached (%d/%d)", currentCount, constants.MaxPlayers)
-		return false
+		return false, fmt.Sprintf("player limit reached (%d/%d)", currentCount, constants.MaxPlayers)
 	}
 
-	return true
+	return true, ""
 }
 
-// sendConnectionError sends an error during connection setup - ENHANCED
-func (wsh *WebSocketHandler) sendConnectionError(conn *websocket.Conn, message string) {
-	errorResponse := map[string]interface{}{
-		"error":     message,
-		"type":      "connection_error",
-		"timestamp": time.Now().Unix(),
-	}
+// connectionErrorMessage encodes the error a refused connection is sent before it closes
+func connectionErrorMessage(reason error) []byte {
+	errorResponse := newErrorPayload(reason)
+	errorResponse.Type = "connection_error"
+	errorResponse.Timestamp = time.Now().Unix()
 
-	// Set write deadline for error message
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-
-	err := conn.WriteJSON(BaseMessage{
+	return mustMarshal(BaseMessage{
 		Type:    MsgError,
 		Payload: mustMarshal(errorResponse),
 	})
+}
+
+// sendConnectionError sends an error during connection setup - ENHANCED
+func (wsh *WebSocketHandler) sendConnectionError(conn *websocket.Conn, reason error) {
+	// Set write deadline for error message
+	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+
+	codec := codecFor(conn)
+	frame, err := codec.encode(connectionErrorMessage(reason))
+	if err == nil {
+		err = conn.WriteMessage(codec.frameType(), frame)
+	}
 
 	if err != nil {
 		log.Printf("Failed to send connection error message: %v", err)
 	}
 
-	log.Printf("Sent connection error: %s", message)
+	log.Printf("Sent connection error: %v", reason)
 }
 
 // handlePlayerMessages handles incoming messages with comprehensive validation
-func (wsh *WebSocketHandler) handlePlayerMessages(player *Player) error {
+func (wsh *WebSocketHandler) handlePlayerMessages(player *Player, conn *websocket.Conn) error {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("Recovered from panic in message handler for player %s: %v", player.ID, r)
 		}
 	}()
 
+	// Messages in the negotiated encoding are converted to JSON and validated as such
+	codec := codecFor(conn)
+
 	for {
-		var baseMsg BaseMessage
-		err := player.Connection.ReadJSON(&baseMsg)
+		_, frame, err := conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error for player %s: %v", player.ID, err)
@@ -299,48 +398,74 @@ func (wsh *WebSocketHandler) handlePlayerMessages(player *Player) error {
 			return err
 		}
 
-		// Update last seen
-		player.mu.Lock()
-		player.LastSeen = time.Now()
-		player.mu.Unlock()
+		data, err := codec.decode(frame)
+		if err != nil {
+			return err
+		}
 
-		// Validate message structure
-		if err := wsh.validateBaseMessage(baseMsg); err != nil {
-			wsh.sendValidationError(player, err)
-			continue
+		var baseMsg BaseMessage
+		if err := json.Unmarshal(data, &baseMsg); err != nil {
+			return err
 		}
 
-		// Route message based on type with validation
-		switch baseMsg.Type {
-		case MsgRoleSelection, MsgTriviaSpecialtySelection, MsgResourceLocationVerified,
-			MsgTriviaAnswer, MsgSegmentCompleted, MsgFragmentMoveRequest,
-			MsgPlayerReady, MsgHostStartGame, MsgHostStartPuzzle,
-			MsgPieceRecommendationRequest, MsgPieceRecommendationResponse:
-
-			// These messages require authentication and validation
-			if err := wsh.handleAuthenticatedMessage(player, baseMsg); err != nil {
-				wsh.sendValidationError(player, err)
-				continue
-			}
+		wsh.handleClientMessage(player, baseMsg)
+	}
+}
 
-		default:
-			wsh.sendError(player, fmt.Sprintf("Unknown message type: %s", baseMsg.Type))
-		}
+// handleClientMessage validates and routes one message from a player, whichever transport it came
+// over. Problems are reported back to the player rather than returned.
+func (wsh *WebSocketHandler) handleClientMessage(player *Player, baseMsg BaseMessage) {
+	// Update last seen
+	now := wsh.playerManager.Now()
+	player.mu.Lock()
+	player.LastSeen = now
+	player.mu.Unlock()
+
+	// Limits are checked first so a flood never reaches validation or the game's locks
+	if !wsh.allowMessage(player, baseMsg) {
+		return
+	}
+
+	// Validate message structure
+	if err := wsh.validateBaseMessage(baseMsg); err != nil {
+		wsh.sendRequestError(player, baseMsg, err)
+		return
+	}
+
+	if !clientMessageTypes[baseMsg.Type] {
+		wsh.sendRequestError(player, baseMsg, fmt.Errorf("%w: %s", errUnknownMessageType, baseMsg.Type))
+		return
+	}
+
+	// Tagged messages are acknowledged, and retries of them answered without handling them again
+	if baseMsg.RequestID != "" {
+		wsh.handleTrackedMessage(player, baseMsg)
+		return
+	}
+
+	// These messages require authentication and validation. Untagged messages get no nack, so a
+	// failure the handler has already answered with a reply of its own is not reported twice.
+	if err := wsh.handleAuthenticatedMessage(player, baseMsg); err != nil && !isAnswered(err) {
+		wsh.sendRequestError(player, baseMsg, err)
 	}
 }
 
 // validateBaseMessage validates the basic message structure
 func (wsh *WebSocketHandler) validateBaseMessage(msg BaseMessage) error {
 	if msg.Type == "" {
-		return fmt.Errorf("message type cannot be empty")
+		return fmt.Errorf("%w: message type cannot be empty", errInvalidMessage)
 	}
 
 	if len(msg.Type) > 50 {
-		return fmt.Errorf("message type too long")
+		return fmt.Errorf("%w: message type too long", errInvalidMessage)
+	}
+
+	if len(msg.RequestID) > constants.MaxRequestIDLength {
+		return fmt.Errorf("%w: requestId too long (max %d characters)", errInvalidMessage, constants.MaxRequestIDLength)
 	}
 
 	if len(msg.Payload) > 8192 { // 8KB limit
-		return fmt.Errorf("message payload too large")
+		return fmt.Errorf("%w: message payload too large", errInvalidMessage)
 	}
 
 	return nil
@@ -349,22 +474,41 @@ func (wsh *WebSocketHandler) validateBaseMessage(msg BaseMessage) error {
 // handleAuthenticatedMessage handles messages that require authentication with validation
 func (wsh *WebSocketHandler) handleAuthenticatedMessage(player *Player, baseMsg BaseMessage) error {
 	// Validate and parse authentication wrapper
-	authWrapper, validationErrors := validateAuthWrapper(baseMsg.Payload)
+	authWrapper, validationErrors := validateAuthWrapper(baseMsg.Payload, wsh.sessionTokens, player.ID)
 	if len(validationErrors) > 0 {
-		return fmt.Errorf("authentication validation failed: %v", validationErrors[0])
+		return &CodedError{
+			Code:    CodeAuthenticationFailed,
+			Message: fmt.Sprintf("%s: %v", constants.ErrAuthenticationFailed, validationErrors[0]),
+			Fields:  validationErrors,
+		}
 	}
 
 	// Verify authentication
 	if authWrapper.Auth.PlayerID != player.ID {
-		return fmt.Errorf("authentication failed: player ID mismatch")
+		return fmt.Errorf("%w: player ID mismatch", errAuthenticationFailed)
+	}
+
+	if baseMsg.Type == MsgHello {
+		return wsh.handleHello(player, authWrapper.Payload)
 	}
 
 	// Route to appropriate handler with validation
 	return wsh.routeValidatedMessage(player.ID, baseMsg.Type, authWrapper.Payload)
 }
 
-// routeValidatedMessage routes authenticated and validated messages to appropriate handlers
+// routeValidatedMessage routes authenticated and validated messages to appropriate handlers,
+// recording the message and the state it leaves the game in
 func (wsh *WebSocketHandler) routeValidatedMessage(playerID string, msgType string, payload json.RawMessage) error {
+	wsh.gameManager.eventLog.Record(EventInbound, playerID, msgType, payload)
+
+	err := wsh.dispatchValidatedMessage(playerID, msgType, payload)
+
+	wsh.gameManager.recordCheckpoint(EventInbound+":"+msgType, err)
+	return err
+}
+
+// dispatchValidatedMessage hands a validated message to its handler
+func (wsh *WebSocketHandler) dispatchValidatedMessage(playerID string, msgType string, payload json.RawMessage) error {
 	switch msgType {
 	case MsgRoleSelection:
 		return wsh.handleRoleSelectionWithValidation(playerID, payload)
@@ -378,6 +522,9 @@ func (wsh *WebSocketHandler) routeValidatedMessage(playerID string, msgType stri
 	case MsgHostStartGame:
 		return wsh.handleHostStartGameWithValidation(playerID, payload)
 
+	case MsgHostUpdateSettings:
+		return wsh.handleHostUpdateSettingsWithValidation(playerID, payload)
+
 	case MsgResourceLocationVerified:
 		return wsh.handleLocationVerificationWithValidation(playerID, payload)
 
@@ -393,14 +540,47 @@ func (wsh *WebSocketHandler) routeValidatedMessage(playerID string, msgType stri
 	case MsgHostStartPuzzle:
 		return wsh.handleHostStartPuzzleWithValidation(playerID, payload)
 
+	case MsgHostPause:
+		return wsh.handleHostPauseWithValidation(playerID, payload)
+
+	case MsgHostResume:
+		return wsh.handleHostResumeWithValidation(playerID, payload)
+
+	case MsgHostAddTime:
+		return wsh.handleHostAddTimeWithValidation(playerID, payload)
+
+	case MsgHostAbortGame:
+		return wsh.handleHostAbortGameWithValidation(playerID, payload)
+
+	case MsgHostSkipPhase:
+		return wsh.handleHostSkipPhaseWithValidation(playerID, payload)
+
+	case MsgHostRematch:
+		return wsh.handleHostRematchWithValidation(playerID, payload)
+
+	case MsgHostKickPlayer:
+		return wsh.handleHostKickPlayerWithValidation(playerID, payload)
+
+	case MsgHostRenamePlayer:
+		return wsh.handleHostRenamePlayerWithValidation(playerID, payload)
+
+	case MsgHostAssignPlayer:
+		return wsh.handleHostAssignPlayerWithValidation(playerID, payload)
+
+	case MsgPlayerSetName:
+		return wsh.handlePlayerSetNameWithValidation(playerID, payload)
+
 	case MsgPieceRecommendationRequest:
 		return wsh.handlePieceRecommendationRequestWithValidation(playerID, payload)
 
 	case MsgPieceRecommendationResponse:
 		return wsh.handlePieceRecommendationResponseWithValidation(playerID, payload)
 
+	case MsgPuzzleResyncRequest:
+		return wsh.handlePuzzleResyncRequestWithValidation(playerID, payload)
+
 	default:
-		return fmt.Errorf("unhandled message type: %s", msgType)
+		return fmt.Errorf("%w: %s", errUnknownMessageType, msgType)
 	}
 }
 
@@ -409,64 +589,72 @@ func (wsh *WebSocketHandler) routeValidatedMessage(playerID string, msgType stri
 func (wsh *WebSocketHandler) handleRoleSelectionWithValidation(playerID string, payload json.RawMessage) error {
 	data, errors := ValidateRoleSelection(payload)
 	if len(errors) > 0 {
-		return fmt.Errorf("validation failed: %v", errors)
+		return validationFailure(errors)
 	}
 
-	return wsh.eventHandlers.HandleRoleSelection(playerID, mustMarshal(data))
+	return wsh.eventHandlers.HandleRoleSelection(playerID, data)
 }
 
 func (wsh *WebSocketHandler) handleSpecialtySelectionWithValidation(playerID string, payload json.RawMessage) error {
-	data, errors := ValidateSpecialtySelection(payload)
+	data, errors := ValidateSpecialtySelection(payload, wsh.gameManager.triviaManager.GetAvailableCategories())
 	if len(errors) > 0 {
-		return fmt.Errorf("validation failed: %v", errors)
+		return validationFailure(errors)
 	}
 
-	return wsh.eventHandlers.HandleTriviaSpecialtySelection(playerID, mustMarshal(data))
+	return wsh.eventHandlers.HandleTriviaSpecialtySelection(playerID, data)
 }
 
 func (wsh *WebSocketHandler) handlePlayerReadyWithValidation(playerID string, payload json.RawMessage) error {
 	data, errors := ValidatePlayerReady(payload)
 	if len(errors) > 0 {
-		return fmt.Errorf("validation failed: %v", errors)
+		return validationFailure(errors)
 	}
 
-	return wsh.eventHandlers.HandlePlayerReady(playerID, mustMarshal(data))
+	return wsh.eventHandlers.HandlePlayerReady(playerID, data)
 }
 
 func (wsh *WebSocketHandler) handleHostStartGameWithValidation(playerID string, payload json.RawMessage) error {
-	data, errors := ValidateEmptyPayload(payload)
+	if errors := ValidateEmptyPayload(payload); len(errors) > 0 {
+		return validationFailure(errors)
+	}
+
+	return wsh.eventHandlers.HandleHostStartGame(playerID)
+}
+
+func (wsh *WebSocketHandler) handleHostUpdateSettingsWithValidation(playerID string, payload json.RawMessage) error {
+	data, errors := ValidateHostUpdateSettings(payload)
 	if len(errors) > 0 {
-		return fmt.Errorf("validation failed: %v", errors)
+		return validationFailure(errors)
 	}
 
-	return wsh.eventHandlers.HandleHostStartGame(playerID, mustMarshal(data))
+	return wsh.eventHandlers.HandleHostUpdateSettings(playerID, data)
 }
 
 func (wsh *WebSocketHandler) handleLocationVerificationWithValidation(playerID string, payload json.RawMessage) error {
 	data, errors := ValidateLocationVerification(payload)
 	if len(errors) > 0 {
-		return fmt.Errorf("validation failed: %v", errors)
+		return validationFailure(errors)
 	}
 
-	return wsh.eventHandlers.HandleResourceLocationVerified(playerID, mustMarshal(data))
+	return wsh.eventHandlers.HandleResourceLocationVerified(playerID, data)
 }
 
 func (wsh *WebSocketHandler) handleTriviaAnswerWithValidation(playerID string, payload json.RawMessage) error {
 	data, errors := ValidateTriviaAnswer(payload)
 	if len(errors) > 0 {
-		return fmt.Errorf("validation failed: %v", errors)
+		return validationFailure(errors)
 	}
 
-	return wsh.eventHandlers.HandleTriviaAnswer(playerID, mustMarshal(data))
+	return wsh.eventHandlers.HandleTriviaAnswer(playerID, data)
 }
 
 func (wsh *WebSocketHandler) handleSegmentCompletionWithValidation(playerID string, payload json.RawMessage) error {
 	data, errors := ValidateSegmentCompletion(payload)
 	if len(errors) > 0 {
-		return fmt.Errorf("validation failed: %v", errors)
+		return validationFailure(errors)
 	}
 
-	return wsh.eventHandlers.HandleSegmentCompleted(playerID, mustMarshal(data))
+	return wsh.eventHandlers.HandleSegmentCompleted(playerID, data)
 }
 
 // handleFragmentMoveWithValidation handles fragment moves with ENHANCED ownership validation
@@ -481,12 +669,12 @@ func (wsh *WebSocketHandler) handleFragmentMoveWithValidation(playerID string, p
 
 	data, errors := ValidateFragmentMove(payload, maxGridSize)
 	if len(errors) > 0 {
-		return fmt.Errorf("validation failed: %v", errors)
+		return validationFailure(errors)
 	}
 
 	// ENHANCED: Additional ownership pre-validation before passing to game manager
 	wsh.gameManager.mu.RLock()
-	fragment, exists := wsh.gameManager.state.PuzzleFragments[data["fragmentId"].(string)]
+	fragment, exists := wsh.gameManager.state.PuzzleFragments[data.FragmentID]
 	wsh.gameManager.mu.RUnlock()
 
 	if exists {
@@ -495,29 +683,123 @@ func (wsh *WebSocketHandler) handleFragmentMoveWithValidation(playerID string, p
 			// Send specific ownership error response
 			player, _ := wsh.playerManager.GetPlayer(playerID)
 			if player != nil {
-				sendToPlayer(player, MsgFragmentMoveResponse, map[string]interface{}{
-					"status":     "denied",
-					"reason":     err.Error(),
-					"fragmentId": data["fragmentId"].(string),
-					"errorType":  "ownership_violation",
+				sendToPlayer(player, MsgFragmentMoveResponse, FragmentMoveResponsePayload{
+					Status:     "denied",
+					Code:       errorCodeOf(err),
+					Reason:     err.Error(),
+					FragmentID: data.FragmentID,
+					ErrorType:  "ownership_violation",
 				})
 			}
 
 			log.Printf("Fragment move denied for player %s: %v", playerID, err)
-			return nil // Don't return error to avoid double error messages
+			return alreadyAnswered(err)
 		}
 	}
 
-	return wsh.eventHandlers.HandleFragmentMoveRequest(playerID, mustMarshal(data))
+	return wsh.eventHandlers.HandleFragmentMoveRequest(playerID, data)
 }
 
 func (wsh *WebSocketHandler) handleHostStartPuzzleWithValidation(playerID string, payload json.RawMessage) error {
-	data, errors := ValidateEmptyPayload(payload)
+	if errors := ValidateEmptyPayload(payload); len(errors) > 0 {
+		return validationFailure(errors)
+	}
+
+	return wsh.eventHandlers.HandleHostStartPuzzle(playerID)
+}
+
+func (wsh *WebSocketHandler) handleHostPauseWithValidation(playerID string, payload json.RawMessage) error {
+	if errors := ValidateEmptyPayload(payload); len(errors) > 0 {
+		return validationFailure(errors)
+	}
+
+	return wsh.eventHandlers.HandleHostPause(playerID)
+}
+
+func (wsh *WebSocketHandler) handleHostResumeWithValidation(playerID string, payload json.RawMessage) error {
+	if errors := ValidateEmptyPayload(payload); len(errors) > 0 {
+		return validationFailure(errors)
+	}
+
+	return wsh.eventHandlers.HandleHostResume(playerID)
+}
+
+func (wsh *WebSocketHandler) handleHostAddTimeWithValidation(playerID string, payload json.RawMessage) error {
+	data, errors := ValidateHostAddTime(payload)
 	if len(errors) > 0 {
-		return fmt.Errorf("validation failed: %v", errors)
+		return validationFailure(errors)
 	}
 
-	return wsh.eventHandlers.HandleHostStartPuzzle(playerID, mustMarshal(data))
+	return wsh.eventHandlers.HandleHostAddTime(playerID, data)
+}
+
+func (wsh *WebSocketHandler) handleHostAbortGameWithValidation(playerID string, payload json.RawMessage) error {
+	data, errors := ValidateHostAbortGame(payload)
+	if len(errors) > 0 {
+		return validationFailure(errors)
+	}
+
+	return wsh.eventHandlers.HandleHostAbortGame(playerID, data)
+}
+
+func (wsh *WebSocketHandler) handleHostSkipPhaseWithValidation(playerID string, payload json.RawMessage) error {
+	if errors := ValidateEmptyPayload(payload); len(errors) > 0 {
+		return validationFailure(errors)
+	}
+
+	return wsh.eventHandlers.HandleHostSkipPhase(playerID)
+}
+
+func (wsh *WebSocketHandler) handleHostRematchWithValidation(playerID string, payload json.RawMessage) error {
+	if errors := ValidateEmptyPayload(payload); len(errors) > 0 {
+		return validationFailure(errors)
+	}
+
+	return wsh.eventHandlers.HandleHostRematch(playerID)
+}
+
+func (wsh *WebSocketHandler) handleHostKickPlayerWithValidation(playerID string, payload json.RawMessage) error {
+	data, errors := ValidateHostKickPlayer(payload)
+	if len(errors) > 0 {
+		return validationFailure(errors)
+	}
+
+	return wsh.eventHandlers.HandleHostKickPlayer(playerID, data)
+}
+
+func (wsh *WebSocketHandler) handleHostRenamePlayerWithValidation(playerID string, payload json.RawMessage) error {
+	data, errors := ValidateHostRenamePlayer(payload)
+	if len(errors) > 0 {
+		return validationFailure(errors)
+	}
+
+	return wsh.eventHandlers.HandleHostRenamePlayer(playerID, data)
+}
+
+func (wsh *WebSocketHandler) handleHostAssignPlayerWithValidation(playerID string, payload json.RawMessage) error {
+	data, errors := ValidateHostAssignPlayer(payload, wsh.gameManager.triviaManager.GetAvailableCategories())
+	if len(errors) > 0 {
+		return validationFailure(errors)
+	}
+
+	return wsh.eventHandlers.HandleHostAssignPlayer(playerID, data)
+}
+
+func (wsh *WebSocketHandler) handlePlayerSetNameWithValidation(playerID string, payload json.RawMessage) error {
+	data, errors := ValidatePlayerSetName(payload)
+	if len(errors) > 0 {
+		return validationFailure(errors)
+	}
+
+	return wsh.eventHandlers.HandlePlayerSetName(playerID, data)
+}
+
+func (wsh *WebSocketHandler) handlePuzzleResyncRequestWithValidation(playerID string, payload json.RawMessage) error {
+	if errors := ValidateEmptyPayload(payload); len(errors) > 0 {
+		return validationFailure(errors)
+	}
+
+	return wsh.eventHandlers.HandlePuzzleResyncRequest(playerID)
 }
 
 func (wsh *WebSocketHandler) handlePieceRecommendationRequestWithValidation(playerID string, payload json.RawMessage) error {
@@ -531,36 +813,43 @@ func (wsh *WebSocketHandler) handlePieceRecommendationRequestWithValidation(play
 
 	data, errors := ValidatePieceRecommendationRequest(payload, maxGridSize)
 	if len(errors) > 0 {
-		return fmt.Errorf("validation failed: %v", errors)
+		return validationFailure(errors)
 	}
 
-	return wsh.eventHandlers.HandlePieceRecommendationRequest(playerID, mustMarshal(data))
+	return wsh.eventHandlers.HandlePieceRecommendationRequest(playerID, data)
 }
 
 func (wsh *WebSocketHandler) handlePieceRecommendationResponseWithValidation(playerID string, payload json.RawMessage) error {
 	data, errors := ValidatePieceRecommendationResponse(payload)
 	if len(errors) > 0 {
-		return fmt.Errorf("validation failed: %v", errors)
+		return validationFailure(errors)
 	}
 
-	return wsh.eventHandlers.HandlePieceRecommendationResponse(playerID, mustMarshal(data))
+	return wsh.eventHandlers.HandlePieceRecommendationResponse(playerID, data)
 }
 
-// sendValidationError sends a detailed validation error to the player
-func (wsh *WebSocketHandler) sendValidationError(player *Player, err error) {
-	log.Printf("Validation error for player %s: %v", player.ID, err)
+// sendRequestError tells the player why a message they sent failed, echoing its type and requestId
+func (wsh *WebSocketHandler) sendRequestError(player *Player, baseMsg BaseMessage, err error) {
+	log.Printf("Request error for player %s (%s): %v", player.ID, baseMsg.Type, err)
+	sendToPlayer(player, MsgError, requestErrorPayload(baseMsg, err))
 
-	errorResponse := map[string]interface{}{
-		"error":   "Validation failed",
-		"details": err.Error(),
-		"type":    "validation_error",
+	// A client that can't speak the protocol can't do anything else either
+	if errorCodeOf(err) == CodeProtocolUnsupported {
+		refuseProtocol(player, err)
 	}
-
-	sendToPlayer(player, MsgError, errorResponse)
 }
 
 // handleHostDisconnection handles immediate host disconnection cleanup
 func (wsh *WebSocketHandler) handleHostDisconnection(player *Player) {
+	wsh.gameManager.eventLog.Record(EventHostDisconnected, player.ID, "", nil)
+
+	wsh.removeDisconnectedHost(player)
+
+	wsh.gameManager.recordCheckpoint(EventHostDisconnected, nil)
+}
+
+// removeDisconnectedHost removes the host so a new one can connect and tells the players
+func (wsh *WebSocketHandler) removeDisconnectedHost(player *Player) {
 	log.Printf("Host %s disconnected - immediately cleaning up for new host connection", player.ID)
 
 	// Mark as disconnected
@@ -577,11 +866,14 @@ func (wsh *WebSocketHandler) handleHostDisconnection(player *Player) {
 	// Notify players that host disconnected
 	wsh.broadcastChan <- BroadcastMessage{
 		Type: MsgError,
-		Payload: map[string]interface{}{
-			"error":            "Host disconnected - new host can now connect",
-			"type":             "host_disconnected",
-			"phase":            phase.String(),
-			"reconnectionInfo": "A new host can connect immediately",
+		Payload: HostDisconnectedPayload{
+			ErrorPayload: ErrorPayload{
+				Code:  CodeHostDisconnected,
+				Error: "Host disconnected - new host can now connect",
+				Type:  "host_disconnected",
+			},
+			Phase:            phase.String(),
+			ReconnectionInfo: "A new host can connect immediately",
 		},
 	}
 
@@ -590,6 +882,15 @@ func (wsh *WebSocketHandler) handleHostDisconnection(player *Player) {
 
 // handleDisconnection handles player disconnection with ENHANCED fragment ownership handling
 func (wsh *WebSocketHandler) handleDisconnection(player *Player) {
+	wsh.gameManager.eventLog.Record(EventPlayerDisconnected, player.ID, "", nil)
+	defer wsh.gameManager.recordCheckpoint(EventPlayerDisconnected, nil)
+
+	// A kicked player's connection closes after the host already removed them from the game
+	if _, err := wsh.playerManager.GetPlayer(player.ID); err != nil {
+		log.Printf("Player %s left after being removed by the host", player.ID)
+		return
+	}
+
 	log.Printf("Player %s disconnected", player.ID)
 
 	// Mark as disconnected
@@ -609,22 +910,26 @@ func (wsh *WebSocketHandler) handleDisconnection(player *Player) {
 		log.Printf("Player %s disconnected during resource gathering - can reconnect", player.ID)
 
 	case PhasePuzzleAssembly:
-		// ENHANCED: During puzzle assembly, handle fragment ownership transfer
+		// ENHANCED: During puzzle assembly the fragment waits for the player, then goes to everyone
+		status := PlayerDisconnectedNotice{
+			PlayerDisconnected:  player.ID,
+			Phase:               "puzzle_assembly",
+			ReconnectionAllowed: false,
+		}
 		if !player.IsHost {
-			wsh.gameManager.handleFragmentDisconnection(player.ID)
+			if deadline, held := wsh.gameManager.holdFragment(player.ID); held {
+				status.ReconnectionAllowed = true
+				status.ReconnectDeadline = deadline.Unix()
+			}
 		}
 
 		// Notify others about disconnection and fragment changes
 		wsh.broadcastChan <- BroadcastMessage{
-			Type: MsgCentralPuzzleState,
-			Payload: map[string]interface{}{
-				"playerDisconnected":  player.ID,
-				"phase":               "puzzle_assembly",
-				"reconnectionAllowed": false,
-			},
+			Type:    MsgCentralPuzzleState,
+			Payload: status,
 		}
 
-		log.Printf("Player %s disconnected during puzzle assembly - fragment converted to unassigned", player.ID)
+		log.Printf("Player %s disconnected during puzzle assembly (may reconnect: %v)", player.ID, status.ReconnectionAllowed)
 
 	case PhasePostGame:
 		// No special handling needed
@@ -634,7 +939,7 @@ func (wsh *WebSocketHandler) handleDisconnection(player *Player) {
 	// Handle host disconnection (fallback for non-close events)
 	if player.IsHost {
 		log.Printf("Host %s disconnected via non-close event - using immediate cleanup", player.ID)
-		wsh.handleHostDisconnection(player)
+		wsh.removeDisconnectedHost(player)
 		return
 	}
 }
@@ -645,23 +950,28 @@ func (wsh *WebSocketHandler) sendReconnectionState(player *Player) {
 
 	player.mu.RLock()
 	isHost := player.IsHost
+	sessionToken := player.SessionToken
 	player.mu.RUnlock()
 
 	if isHost {
 		// Host gets comprehensive state information
-		sendToPlayer(player, MsgAvailableRoles, map[string]interface{}{
-			"playerId": player.ID,
-			"isHost":   true,
-			"message":  fmt.Sprintf("Reconnected as host during %s phase", phase.String()),
+		sendToPlayer(player, MsgAvailableRoles, AvailableRolesPayload{
+			PlayerID:     player.ID,
+			SessionToken: sessionToken,
+			IsHost:       true,
+			Message:      fmt.Sprintf("Reconnected as host during %s phase", phase.String()),
 		})
 	} else {
 		// Regular player gets role information
 		roles := wsh.playerManager.GetAvailableRoles()
-		sendToPlayer(player, MsgAvailableRoles, map[string]interface{}{
-			"playerId":         player.ID,
-			"isHost":           false,
-			"roles":            roles,
-			"triviaCategories": constants.TriviaCategories,
+		categories := wsh.gameManager.triviaManager.GetCategoryDetails()
+		sendToPlayer(player, MsgAvailableRoles, AvailableRolesPayload{
+			PlayerID:              player.ID,
+			SessionToken:          sessionToken,
+			IsHost:                false,
+			Roles:                 roles,
+			TriviaCategories:      categoryIDs(categories),
+			TriviaCategoryDetails: categories,
 		})
 	}
 
@@ -673,12 +983,15 @@ func (wsh *WebSocketHandler) sendReconnectionState(player *Player) {
 	case PhaseResourceGathering:
 		if !isHost {
 			// Send resource phase info to regular players
-			sendToPlayer(player, MsgResourcePhaseStart, map[string]interface{}{
-				"resourceHashes": constants.ResourceStationHashes,
+			sendToPlayer(player, MsgResourcePhaseStart, ResourcePhaseStartPayload{
+				ResourceHashes: constants.ResourceStationHashes,
 			})
 
 			// Send current progress
 			wsh.gameManager.sendTeamProgressUpdate()
+
+			// Players recovering from a server restart lost their pending question
+			wsh.gameManager.ensureTriviaQuestion(player)
 		}
 
 	case PhasePuzzleAssembly:
@@ -686,9 +999,9 @@ func (wsh *WebSocketHandler) sendReconnectionState(player *Player) {
 			// Host gets complete puzzle state for monitoring
 			wsh.gameManager.sendCompletePuzzleStateToHost()
 		} else {
-			// NOTE: Regular players cannot reconnect during puzzle assembly
-			// This case should not occur due to connection restrictions
-			log.Printf("WARNING: Regular player %s reconnected during puzzle assembly - this should not happen", player.ID)
+			// NOTE: Regular players can only reconnect during puzzle assembly after a snapshot restore
+			// or within the reconnect grace period
+			wsh.gameManager.sendPuzzleRecoveryState(player)
 		}
 
 	case PhasePostGame:
@@ -696,16 +1009,15 @@ func (wsh *WebSocketHandler) sendReconnectionState(player *Player) {
 		log.Printf("Player %s reconnected during post-game phase", player.ID)
 	}
 
+	// A paused clock would otherwise look like a frozen client
+	wsh.gameManager.sendPausedState(player)
+
 	log.Printf("Sent reconnection state to %s (host: %v) for phase %s", player.ID, isHost, phase.String())
 }
 
-// sendError sends an error message to a player
-func (wsh *WebSocketHandler) sendError(player *Player, message string) {
-	errorResponse := map[string]interface{}{
-		"error": message,
-		"type":  "general_error",
-	}
-	sendToPlayer(player, MsgError, errorResponse)
+// sendError sends an error message to a player that isn't about a message they sent
+func (wsh *WebSocketHandler) sendError(player *Player, err error) {
+	sendToPlayer(player, MsgError, newErrorPayload(err))
 }
 
 // StartBroadcaster starts the message broadcaster goroutine with enhanced error handling
@@ -717,11 +1029,23 @@ func (wsh *WebSocketHandler) StartBroadcaster() {
 			}
 		}()
 
-		for msg := range wsh.broadcastChan {
+		for {
+			var msg BroadcastMessage
+			select {
+			case m, ok := <-wsh.broadcastChan:
+				if !ok {
+					return
+				}
+				msg = m
+			case <-wsh.stopChan:
+				return
+			}
+
 			players := wsh.playerManager.GetAllPlayers()
 
 			successCount := 0
 			failureCount := 0
+			recipients := make([]string, 0, len(players))
 
 			for _, player := range players {
 				// Apply filter if present
@@ -735,15 +1059,18 @@ func (wsh *WebSocketHandler) StartBroadcaster() {
 				player.mu.RUnlock()
 
 				if connected {
-					if err := sendToPlayer(player, msg.Type, msg.Payload); err != nil {
+					if err := writeToPlayer(player, msg.Type, msg.Payload, false); err != nil {
 						log.Printf("Error broadcasting to player %s: %v", player.ID, err)
 						failureCount++
 					} else {
 						successCount++
+						recipients = append(recipients, player.ID)
 					}
 				}
 			}
 
+			wsh.gameManager.eventLog.RecordBroadcast(msg.Type, msg.Payload, recipients)
+
 			// Log broadcast statistics for monitoring
 			if failureCount > 0 {
 				log.Printf("Broadcast complete: %d successful, %d failed", successCount, failureCount)
@@ -751,3 +1078,11 @@ func (wsh *WebSocketHandler) StartBroadcaster() {
 		}
 	}()
 }
+
+// StopBroadcaster stops the broadcaster goroutine without closing the broadcast channel,
+// so late senders (timers, disconnect handlers) never panic on a closed channel
+func (wsh *WebSocketHandler) StopBroadcaster() {
+	wsh.stopOnce.Do(func() {
+		close(wsh.stopChan)
+	})
+}
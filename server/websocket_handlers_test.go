@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/MaxThePrisberry/canvas-conundrum/server/constants"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
@@ -11,9 +12,9 @@ import (
 func TestGameManagerCreation(t *testing.T) {
 	// Test that we can create the core game components
 	playerMgr := NewPlayerManager()
-	triviaMgr := NewTriviaManager()
+	triviaMgr := NewTriviaManager(realClock{}, testSeed)
 	broadcastChan := make(chan BroadcastMessage, 256)
-	gameMgr := NewGameManager(playerMgr, triviaMgr, broadcastChan)
+	gameMgr := NewGameManager(playerMgr, triviaMgr, broadcastChan, realClock{}, testSeed)
 
 	assert.NotNil(t, playerMgr)
 	assert.NotNil(t, triviaMgr)
@@ -328,9 +329,9 @@ func TestPlayerIDValidationInConnection(t *testing.T) {
 func TestHostDisconnectionHandler(t *testing.T) {
 	// Setup components
 	playerManager := NewPlayerManager()
-	triviaManager := NewTriviaManager()
+	triviaManager := NewTriviaManager(realClock{}, testSeed)
 	broadcastChan := make(chan BroadcastMessage, 256)
-	gameManager := NewGameManager(playerManager, triviaManager, broadcastChan)
+	gameManager := NewGameManager(playerManager, triviaManager, broadcastChan, realClock{}, testSeed)
 	eventHandlers := NewEventHandlers(gameManager, playerManager, broadcastChan)
 	wsHandler := NewWebSocketHandler(playerManager, gameManager, eventHandlers, broadcastChan)
 
@@ -352,9 +353,9 @@ func TestHostDisconnectionHandler(t *testing.T) {
 	select {
 	case msg := <-broadcastChan:
 		assert.Equal(t, MsgError, msg.Type)
-		payload := msg.Payload.(map[string]interface{})
-		assert.Contains(t, payload["error"], "Host disconnected - new host can now connect")
-		assert.Equal(t, "host_disconnected", payload["type"])
+		payload := msg.Payload.(HostDisconnectedPayload)
+		assert.Contains(t, payload.Error, "Host disconnected - new host can now connect")
+		assert.Equal(t, "host_disconnected", payload.Type)
 	case <-time.After(100 * time.Millisecond):
 		t.Fatal("Expected broadcast message for host disconnection")
 	}
@@ -366,9 +367,9 @@ func TestHostDisconnectionHandler(t *testing.T) {
 // TestHostConnectionBlocking tests that connected hosts block new host connections
 func TestHostConnectionBlocking(t *testing.T) {
 	playerManager := NewPlayerManager()
-	triviaManager := NewTriviaManager()
+	triviaManager := NewTriviaManager(realClock{}, testSeed)
 	broadcastChan := make(chan BroadcastMessage, 256)
-	gameManager := NewGameManager(playerManager, triviaManager, broadcastChan)
+	gameManager := NewGameManager(playerManager, triviaManager, broadcastChan, realClock{}, testSeed)
 	eventHandlers := NewEventHandlers(gameManager, playerManager, broadcastChan)
 	wsHandler := NewWebSocketHandler(playerManager, gameManager, eventHandlers, broadcastChan)
 
@@ -401,9 +402,9 @@ func TestHostConnectionBlocking(t *testing.T) {
 // TestWebSocketCloseHandlerBehavior tests the close handler logic
 func TestWebSocketCloseHandlerBehavior(t *testing.T) {
 	playerManager := NewPlayerManager()
-	triviaManager := NewTriviaManager()
+	triviaManager := NewTriviaManager(realClock{}, testSeed)
 	broadcastChan := make(chan BroadcastMessage, 256)
-	gameManager := NewGameManager(playerManager, triviaManager, broadcastChan)
+	gameManager := NewGameManager(playerManager, triviaManager, broadcastChan, realClock{}, testSeed)
 	eventHandlers := NewEventHandlers(gameManager, playerManager, broadcastChan)
 	wsHandler := NewWebSocketHandler(playerManager, gameManager, eventHandlers, broadcastChan)
 
@@ -459,9 +460,9 @@ func TestWebSocketCloseHandlerBehavior(t *testing.T) {
 // TestFallbackDisconnectionForHost tests that regular disconnection also handles hosts
 func TestFallbackDisconnectionForHost(t *testing.T) {
 	playerManager := NewPlayerManager()
-	triviaManager := NewTriviaManager()
+	triviaManager := NewTriviaManager(realClock{}, testSeed)
 	broadcastChan := make(chan BroadcastMessage, 256)
-	gameManager := NewGameManager(playerManager, triviaManager, broadcastChan)
+	gameManager := NewGameManager(playerManager, triviaManager, broadcastChan, realClock{}, testSeed)
 	eventHandlers := NewEventHandlers(gameManager, playerManager, broadcastChan)
 	wsHandler := NewWebSocketHandler(playerManager, gameManager, eventHandlers, broadcastChan)
 
@@ -483,9 +484,9 @@ func TestFallbackDisconnectionForHost(t *testing.T) {
 // TestConcurrentHostDisconnectionHandling tests thread safety
 func TestConcurrentHostDisconnectionHandling(t *testing.T) {
 	playerManager := NewPlayerManager()
-	triviaManager := NewTriviaManager()
+	triviaManager := NewTriviaManager(realClock{}, testSeed)
 	broadcastChan := make(chan BroadcastMessage, 256)
-	gameManager := NewGameManager(playerManager, triviaManager, broadcastChan)
+	gameManager := NewGameManager(playerManager, triviaManager, broadcastChan, realClock{}, testSeed)
 	eventHandlers := NewEventHandlers(gameManager, playerManager, broadcastChan)
 	wsHandler := NewWebSocketHandler(playerManager, gameManager, eventHandlers, broadcastChan)
 
@@ -536,3 +537,82 @@ drained:
 	// Cleanup
 	triviaManager.Shutdown()
 }
+
+func TestPuzzleReconnectionWithinGrace(t *testing.T) {
+	playerManager := NewPlayerManager()
+	triviaManager := NewTriviaManager(realClock{}, testSeed)
+	broadcastChan := make(chan BroadcastMessage, 256)
+	gameManager := NewGameManager(playerManager, triviaManager, broadcastChan, realClock{}, testSeed)
+	eventHandlers := NewEventHandlers(gameManager, playerManager, broadcastChan)
+	wsHandler := NewWebSocketHandler(playerManager, gameManager, eventHandlers, broadcastChan)
+	defer triviaManager.Shutdown()
+	defer gameManager.Stop()
+
+	players := make([]*Player, 4)
+	for i := range players {
+		players[i] = playerManager.CreatePlayer(nil, false)
+	}
+	gameManager.startPuzzlePhase()
+	for len(broadcastChan) > 0 {
+		<-broadcastChan
+	}
+
+	// Dropping out keeps the fragment and tells everyone the player may come back
+	wsHandler.handleDisconnection(players[0])
+	assert.True(t, gameManager.HasFragmentHold(players[0].ID))
+
+	var status *PlayerDisconnectedNotice
+	for len(broadcastChan) > 0 {
+		if msg := <-broadcastChan; msg.Type == MsgCentralPuzzleState {
+			notice := msg.Payload.(PlayerDisconnectedNotice)
+			status = &notice
+		}
+	}
+	if assert.NotNil(t, status) {
+		assert.Equal(t, players[0].ID, status.PlayerDisconnected)
+		assert.True(t, status.ReconnectionAllowed)
+		assert.NotZero(t, status.ReconnectDeadline)
+	}
+
+	// Coming back reclaims it
+	assert.NoError(t, playerManager.ReconnectPlayer(players[0].ID, nil))
+	assert.NoError(t, wsHandler.admitPlayer(players[0], true))
+	assert.False(t, gameManager.HasFragmentHold(players[0].ID))
+
+	gameManager.mu.RLock()
+	fragment := gameManager.state.PuzzleFragments["fragment_"+players[0].ID]
+	gameManager.mu.RUnlock()
+	assert.Equal(t, players[0].ID, fragment.PlayerID)
+	assert.False(t, fragment.IsUnassigned)
+}
+
+func TestBannedPlayerCannotJoinAgain(t *testing.T) {
+	playerManager := NewPlayerManager()
+	triviaManager := NewTriviaManager(realClock{}, testSeed)
+	broadcastChan := make(chan BroadcastMessage, 256)
+	gameManager := NewGameManager(playerManager, triviaManager, broadcastChan, realClock{}, testSeed)
+	eventHandlers := NewEventHandlers(gameManager, playerManager, broadcastChan)
+	wsHandler := NewWebSocketHandler(playerManager, gameManager, eventHandlers, broadcastChan)
+	defer triviaManager.Shutdown()
+	defer gameManager.Stop()
+
+	host, _, err := wsHandler.acceptPlayer("", true, nil, "198.51.100.1")
+	assert.NoError(t, err)
+	banned, _, err := wsHandler.acceptPlayer("", false, nil, "198.51.100.7")
+	assert.NoError(t, err)
+	_, err = playerManager.RemovePlayer(banned.ID, true)
+	assert.NoError(t, err)
+
+	// Neither with their old identity nor as someone new
+	_, _, err = wsHandler.acceptPlayer(banned.ID, false, nil, "198.51.100.8")
+	assert.EqualError(t, err, constants.ErrPlayerBanned)
+	_, _, err = wsHandler.acceptPlayer("", false, nil, "198.51.100.7")
+	assert.EqualError(t, err, constants.ErrPlayerBanned)
+
+	// Other addresses, and the host, are unaffected
+	_, _, err = wsHandler.acceptPlayer("", false, nil, "198.51.100.8")
+	assert.NoError(t, err)
+	_, reconnected, err := wsHandler.acceptPlayer(host.ID, true, nil, "198.51.100.7")
+	assert.NoError(t, err)
+	assert.True(t, reconnected)
+}